@@ -45,3 +45,20 @@ func (cache *memoryCache) Set(ctx context.Context, key string, value string, ttl
 	cache.store.Set(key, value, ttl)
 	return nil
 }
+
+// Add an item in the memory cache if the key does not exist
+func (cache *memoryCache) Add(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	ctx, span := cache.tracer.Start(ctx)
+	defer span.End()
+
+	return cache.store.Add(key, value, ttl) == nil, nil
+}
+
+// Delete an item from the memory cache
+func (cache *memoryCache) Delete(ctx context.Context, key string) error {
+	ctx, span := cache.tracer.Start(ctx)
+	defer span.End()
+
+	cache.store.Delete(key)
+	return nil
+}
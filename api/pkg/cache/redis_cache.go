@@ -51,3 +51,26 @@ func (cache *redisCache) Set(ctx context.Context, key string, value string, ttl
 	}
 	return nil
 }
+
+// Add an item in the redis cache if the key does not exist
+func (cache *redisCache) Add(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	ctx, span := cache.tracer.Start(ctx)
+	defer span.End()
+
+	added, err := cache.client.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		return false, cache.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, fmt.Sprintf("cannot add item in redis with key [%s]", key)))
+	}
+	return added, nil
+}
+
+// Delete an item from the redis cache
+func (cache *redisCache) Delete(ctx context.Context, key string) error {
+	ctx, span := cache.tracer.Start(ctx)
+	defer span.End()
+
+	if err := cache.client.Del(ctx, key).Err(); err != nil {
+		return cache.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, fmt.Sprintf("cannot delete item in redis with key [%s]", key)))
+	}
+	return nil
+}
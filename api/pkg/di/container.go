@@ -19,6 +19,8 @@ import (
 	"gorm.io/plugin/opentelemetry/tracing"
 
 	"github.com/NdoleStudio/httpsms/pkg/discord"
+	"github.com/NdoleStudio/httpsms/pkg/mqtt"
+	"github.com/NdoleStudio/httpsms/pkg/slack"
 
 	mexporter "github.com/GoogleCloudPlatform/opentelemetry-operations-go/exporter/metric"
 	cloudtrace "github.com/GoogleCloudPlatform/opentelemetry-operations-go/exporter/trace"
@@ -81,6 +83,9 @@ type Container struct {
 	version         string
 	app             *fiber.App
 	eventDispatcher *services.EventDispatcher
+	mqttService     *services.IntegrationMQTTService
+	redisClient     *redis.Client
+	streamClient    *redis.Client
 	logger          telemetry.Logger
 }
 
@@ -128,6 +133,9 @@ func NewContainer(projectID string, version string) (container *Container) {
 
 	container.RegisterEventRoutes()
 
+	container.RegisterEventStreamRoutes()
+	container.RegisterEventStreamListeners()
+
 	container.RegisterNotificationListeners()
 	container.RegisterEmailNotificationListeners()
 
@@ -142,9 +150,27 @@ func NewContainer(projectID string, version string) (container *Container) {
 	container.RegisterIntegration3CXRoutes()
 	container.RegisterIntegration3CXListeners()
 
+	container.RegisterIntegrationMQTTRoutes()
+	container.RegisterIntegrationMQTTListeners()
+
 	container.RegisterDiscordRoutes()
 	container.RegisterDiscordListeners()
 
+	container.RegisterSlackRoutes()
+	container.RegisterSlackListeners()
+
+	container.RegisterCampaignRoutes()
+	container.RegisterCampaignListeners()
+
+	container.RegisterSuppressionRoutes()
+	container.RegisterSuppressionListeners()
+
+	container.RegisterContactListRoutes()
+	container.RegisterContactListListeners()
+
+	container.RegisterAutoReplyRuleRoutes()
+	container.RegisterAutoReplyListeners()
+
 	container.RegisterMarketingListeners()
 
 	// this has to be last since it registers the /* route
@@ -190,6 +216,12 @@ func (container *Container) AuthenticatedMiddleware() fiber.Handler {
 	return middlewares.Authenticated(container.Tracer())
 }
 
+// IdempotencyMiddleware creates a new instance of middlewares.Idempotency
+func (container *Container) IdempotencyMiddleware() fiber.Handler {
+	container.logger.Debug("creating middlewares.Idempotency")
+	return middlewares.Idempotency(container.Logger(), container.Tracer(), container.IdempotencyService())
+}
+
 // AuthRouter creates router for authenticated requests
 func (container *Container) AuthRouter() fiber.Router {
 	container.logger.Debug("creating authRouter")
@@ -341,14 +373,54 @@ ALTER TABLE discords ADD CONSTRAINT IF NOT EXISTS uni_discords_server_id CHECK (
 		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.Webhook{})))
 	}
 
+	if err = db.AutoMigrate(&entities.WebhookDelivery{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.WebhookDelivery{})))
+	}
+
 	if err = db.AutoMigrate(&entities.Discord{}); err != nil {
 		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.Discord{})))
 	}
 
+	if err = db.AutoMigrate(&entities.Slack{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.Slack{})))
+	}
+
 	if err = db.AutoMigrate(&entities.Integration3CX{}); err != nil {
 		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.Integration3CX{})))
 	}
 
+	if err = db.AutoMigrate(&entities.IntegrationMQTT{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.IntegrationMQTT{})))
+	}
+
+	if err = db.AutoMigrate(&entities.Campaign{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.Campaign{})))
+	}
+
+	if err = db.AutoMigrate(&entities.Suppression{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.Suppression{})))
+	}
+
+	if err = db.AutoMigrate(&entities.ContactList{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.ContactList{})))
+	}
+
+	if err = db.AutoMigrate(&entities.ContactListMember{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.ContactListMember{})))
+	}
+
+	if err = db.AutoMigrate(&entities.AutoReplyRule{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.AutoReplyRule{})))
+	}
+
+	if err = db.AutoMigrate(&entities.AutoReplyCooldown{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.AutoReplyCooldown{})))
+	}
+
+	if err = db.AutoMigrate(&entities.OutOfOfficeCooldown{}); err != nil {
+		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot migrate %T", &entities.OutOfOfficeCooldown{})))
+	}
+
 	return container.db
 }
 
@@ -373,6 +445,36 @@ func (container *Container) InMemoryCache() cache.Cache {
 // Cache creates a new instance of cache.Cache
 func (container *Container) Cache() cache.Cache {
 	container.logger.Debug("creating cache.Cache")
+	return cache.NewRedisCache(container.Tracer(), container.RedisClient())
+}
+
+// RedisClient creates an instance of redis.Client if it has not been created already
+func (container *Container) RedisClient() *redis.Client {
+	if container.redisClient != nil {
+		return container.redisClient
+	}
+
+	container.redisClient = container.newRedisClient(container.redisOptions())
+	return container.redisClient
+}
+
+// StreamRedisClient creates an instance of redis.Client for the event stream if it has not been created already.
+// Every connected event stream client blocks a connection while it waits for events, so a dedicated connection
+// pool is used to make sure that the connections of the RedisClient are not exhausted.
+func (container *Container) StreamRedisClient() *redis.Client {
+	if container.streamClient != nil {
+		return container.streamClient
+	}
+
+	opt := container.redisOptions()
+	opt.PoolSize = services.EventStreamMaxConnections + 100
+	opt.PoolTimeout = 10 * time.Second
+
+	container.streamClient = container.newRedisClient(opt)
+	return container.streamClient
+}
+
+func (container *Container) redisOptions() *redis.Options {
 	opt, err := redis.ParseURL(os.Getenv("REDIS_URL"))
 	if err != nil {
 		container.logger.Fatal(stacktrace.Propagate(err, fmt.Sprintf("cannot parse redis url [%s]", os.Getenv("REDIS_URL"))))
@@ -380,20 +482,24 @@ func (container *Container) Cache() cache.Cache {
 	opt.TLSConfig = &tls.Config{
 		MinVersion: tls.VersionTLS12,
 	}
+	return opt
+}
 
+func (container *Container) newRedisClient(opt *redis.Options) *redis.Client {
+	container.logger.Debug("creating redis.Client")
 	redisClient := redis.NewClient(opt)
 
 	// Enable tracing instrumentation.
-	if err = redisotel.InstrumentTracing(redisClient); err != nil {
+	if err := redisotel.InstrumentTracing(redisClient); err != nil {
 		container.logger.Error(stacktrace.Propagate(err, "cannot instrument redis tracing"))
 	}
 
 	// Enable metrics instrumentation.
-	if err = redisotel.InstrumentMetrics(redisClient); err != nil {
+	if err := redisotel.InstrumentMetrics(redisClient); err != nil {
 		container.logger.Fatal(stacktrace.Propagate(err, "cannot instrument redis metrics"))
 	}
 
-	return cache.NewRedisCache(container.Tracer(), redisClient)
+	return redisClient
 }
 
 // FirebaseAuthClient creates a new instance of auth.Client
@@ -497,6 +603,9 @@ func (container *Container) MessageHandlerValidator() (validator *validators.Mes
 		container.Logger(),
 		container.Tracer(),
 		container.PhoneService(),
+		container.CampaignService(),
+		container.SuppressionService(),
+		container.ContactListService(),
 		container.TurnstileTokenValidator(),
 	)
 }
@@ -520,6 +629,8 @@ func (container *Container) BulkMessageHandlerValidator() (validator *validators
 		container.Tracer(),
 		container.PhoneService(),
 		container.UserService(),
+		container.CampaignService(),
+		container.SuppressionService(),
 	)
 }
 
@@ -574,6 +685,16 @@ func (container *Container) BillingHandlerValidator() (validator *validators.Bil
 	)
 }
 
+// SlackHandlerValidator creates a new instance of validators.SlackHandlerValidator
+func (container *Container) SlackHandlerValidator() (validator *validators.SlackHandlerValidator) {
+	container.logger.Debug(fmt.Sprintf("creating %T", validator))
+	return validators.NewSlackHandlerValidator(
+		container.Logger(),
+		container.Tracer(),
+		container.SlackClient(),
+	)
+}
+
 // DiscordHandlerValidator creates a new instance of validators.DiscordHandlerValidator
 func (container *Container) DiscordHandlerValidator() (validator *validators.DiscordHandlerValidator) {
 	container.logger.Debug(fmt.Sprintf("creating %T", validator))
@@ -685,6 +806,16 @@ func (container *Container) Integration3CXRepository() (repository repositories.
 	)
 }
 
+// IntegrationMQTTRepository creates a new instance of repositories.IntegrationMQTTRepository
+func (container *Container) IntegrationMQTTRepository() (repository repositories.IntegrationMQTTRepository) {
+	container.logger.Debug("creating GORM repositories.IntegrationMQTTRepository")
+	return repositories.NewGormIntegrationMQTTRepository(
+		container.Logger(),
+		container.Tracer(),
+		container.DB(),
+	)
+}
+
 // PhoneRepository creates a new instance of repositories.PhoneRepository
 func (container *Container) PhoneRepository() (repository repositories.PhoneRepository) {
 	container.logger.Debug("creating GORM repositories.PhoneRepository")
@@ -705,6 +836,16 @@ func (container *Container) BillingUsageRepository() (repository repositories.Bi
 	)
 }
 
+// SlackRepository creates a new instance of repositories.SlackRepository
+func (container *Container) SlackRepository() (repository repositories.SlackRepository) {
+	container.logger.Debug("creating GORM repositories.SlackRepository")
+	return repositories.NewGormSlackRepository(
+		container.Logger(),
+		container.Tracer(),
+		container.DB(),
+	)
+}
+
 // DiscordRepository creates a new instance of repositories.DiscordRepository
 func (container *Container) DiscordRepository() (repository repositories.DiscordRepository) {
 	container.logger.Debug("creating GORM repositories.DiscordRepository")
@@ -725,6 +866,16 @@ func (container *Container) WebhookRepository() (repository repositories.Webhook
 	)
 }
 
+// WebhookDeliveryRepository creates a new instance of repositories.WebhookDeliveryRepository
+func (container *Container) WebhookDeliveryRepository() (repository repositories.WebhookDeliveryRepository) {
+	container.logger.Debug("creating GORM repositories.WebhookDeliveryRepository")
+	return repositories.NewGormWebhookDeliveryRepository(
+		container.Logger(),
+		container.Tracer(),
+		container.DB(),
+	)
+}
+
 // PhoneNotificationRepository creates a new instance of repositories.PhoneNotificationRepository
 func (container *Container) PhoneNotificationRepository() (repository repositories.PhoneNotificationRepository) {
 	container.logger.Debug("creating GORM repositories.PhoneNotificationRepository")
@@ -778,6 +929,17 @@ func (container *Container) BillingService() (service *services.BillingService)
 		container.UserEmailFactory(),
 		container.BillingUsageRepository(),
 		container.UserRepository(),
+		os.Getenv("BILLING_BY_SEGMENT") == "true",
+	)
+}
+
+// IdempotencyService creates a new instance of services.IdempotencyService
+func (container *Container) IdempotencyService() (service *services.IdempotencyService) {
+	container.logger.Debug(fmt.Sprintf("creating %T", service))
+	return services.NewIdempotencyService(
+		container.Logger(),
+		container.Tracer(),
+		container.Cache(),
 	)
 }
 
@@ -793,6 +955,267 @@ func (container *Container) DiscordService() (service *services.DiscordService)
 	)
 }
 
+// SlackService creates a new instance of services.SlackService
+func (container *Container) SlackService() (service *services.SlackService) {
+	container.logger.Debug(fmt.Sprintf("creating %T", service))
+	return services.NewSlackService(
+		container.Logger(),
+		container.Tracer(),
+		container.SlackClient(),
+		container.SlackRepository(),
+	)
+}
+
+// CampaignRepository creates a new instance of repositories.CampaignRepository
+func (container *Container) CampaignRepository() (repository repositories.CampaignRepository) {
+	container.logger.Debug("creating GORM repositories.CampaignRepository")
+	return repositories.NewGormCampaignRepository(
+		container.Logger(),
+		container.Tracer(),
+		container.DB(),
+	)
+}
+
+// CampaignService creates a new instance of services.CampaignService
+func (container *Container) CampaignService() (service *services.CampaignService) {
+	container.logger.Debug(fmt.Sprintf("creating %T", service))
+	return services.NewCampaignService(
+		container.Logger(),
+		container.Tracer(),
+		container.CampaignRepository(),
+		container.EventDispatcher(),
+	)
+}
+
+// CampaignHandlerValidator creates a new instance of validators.CampaignHandlerValidator
+func (container *Container) CampaignHandlerValidator() (validator *validators.CampaignHandlerValidator) {
+	container.logger.Debug(fmt.Sprintf("creating %T", validator))
+	return validators.NewCampaignHandlerValidator(
+		container.Logger(),
+		container.Tracer(),
+	)
+}
+
+// CampaignHandler creates a new instance of handlers.CampaignHandler
+func (container *Container) CampaignHandler() (h *handlers.CampaignHandler) {
+	container.logger.Debug(fmt.Sprintf("creating %T", h))
+	return handlers.NewCampaignHandler(
+		container.Logger(),
+		container.Tracer(),
+		container.CampaignService(),
+		container.CampaignHandlerValidator(),
+	)
+}
+
+// RegisterCampaignRoutes registers routes for the /campaigns prefix
+func (container *Container) RegisterCampaignRoutes() {
+	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.CampaignHandler{}))
+	container.CampaignHandler().RegisterRoutes(container.App(), container.AuthenticatedMiddleware())
+}
+
+// RegisterCampaignListeners registers event listeners for listeners.CampaignListener
+func (container *Container) RegisterCampaignListeners() {
+	container.logger.Debug(fmt.Sprintf("registering listeners for %T", listeners.CampaignListener{}))
+	_, routes := listeners.NewCampaignListener(
+		container.Logger(),
+		container.Tracer(),
+		container.CampaignService(),
+	)
+
+	for event, handler := range routes {
+		container.EventDispatcher().Subscribe(event, handler)
+	}
+}
+
+// SuppressionRepository creates a new instance of repositories.SuppressionRepository
+func (container *Container) SuppressionRepository() (repository repositories.SuppressionRepository) {
+	container.logger.Debug("creating GORM repositories.SuppressionRepository")
+	return repositories.NewGormSuppressionRepository(
+		container.Logger(),
+		container.Tracer(),
+		container.DB(),
+	)
+}
+
+// SuppressionService creates a new instance of services.SuppressionService
+func (container *Container) SuppressionService() (service *services.SuppressionService) {
+	container.logger.Debug(fmt.Sprintf("creating %T", service))
+	return services.NewSuppressionService(
+		container.Logger(),
+		container.Tracer(),
+		container.SuppressionRepository(),
+		container.PhoneService(),
+		container.MessageService(),
+	)
+}
+
+// SuppressionHandlerValidator creates a new instance of validators.SuppressionHandlerValidator
+func (container *Container) SuppressionHandlerValidator() (validator *validators.SuppressionHandlerValidator) {
+	container.logger.Debug(fmt.Sprintf("creating %T", validator))
+	return validators.NewSuppressionHandlerValidator(
+		container.Logger(),
+		container.Tracer(),
+	)
+}
+
+// SuppressionHandler creates a new instance of handlers.SuppressionHandler
+func (container *Container) SuppressionHandler() (h *handlers.SuppressionHandler) {
+	container.logger.Debug(fmt.Sprintf("creating %T", h))
+	return handlers.NewSuppressionHandler(
+		container.Logger(),
+		container.Tracer(),
+		container.SuppressionService(),
+		container.SuppressionHandlerValidator(),
+	)
+}
+
+// RegisterSuppressionRoutes registers routes for the /suppressions prefix
+func (container *Container) RegisterSuppressionRoutes() {
+	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.SuppressionHandler{}))
+	container.SuppressionHandler().RegisterRoutes(container.App(), container.AuthenticatedMiddleware())
+}
+
+// RegisterSuppressionListeners registers event listeners for listeners.SuppressionListener
+func (container *Container) RegisterSuppressionListeners() {
+	container.logger.Debug(fmt.Sprintf("registering listeners for %T", listeners.SuppressionListener{}))
+	_, routes := listeners.NewSuppressionListener(
+		container.Logger(),
+		container.Tracer(),
+		container.SuppressionService(),
+	)
+
+	for event, handler := range routes {
+		container.EventDispatcher().Subscribe(event, handler)
+	}
+}
+
+// ContactListRepository creates a new instance of repositories.ContactListRepository
+func (container *Container) ContactListRepository() (repository repositories.ContactListRepository) {
+	container.logger.Debug("creating GORM repositories.ContactListRepository")
+	return repositories.NewGormContactListRepository(
+		container.Logger(),
+		container.Tracer(),
+		container.DB(),
+	)
+}
+
+// ContactListService creates a new instance of services.ContactListService
+func (container *Container) ContactListService() (service *services.ContactListService) {
+	container.logger.Debug(fmt.Sprintf("creating %T", service))
+	return services.NewContactListService(
+		container.Logger(),
+		container.Tracer(),
+		container.ContactListRepository(),
+		container.SuppressionService(),
+		container.MessageService(),
+	)
+}
+
+// ContactListHandlerValidator creates a new instance of validators.ContactListHandlerValidator
+func (container *Container) ContactListHandlerValidator() (validator *validators.ContactListHandlerValidator) {
+	container.logger.Debug(fmt.Sprintf("creating %T", validator))
+	return validators.NewContactListHandlerValidator(
+		container.Logger(),
+		container.Tracer(),
+		container.ContactListService(),
+	)
+}
+
+// ContactListHandler creates a new instance of handlers.ContactListHandler
+func (container *Container) ContactListHandler() (h *handlers.ContactListHandler) {
+	container.logger.Debug(fmt.Sprintf("creating %T", h))
+	return handlers.NewContactListHandler(
+		container.Logger(),
+		container.Tracer(),
+		container.ContactListService(),
+		container.ContactListHandlerValidator(),
+	)
+}
+
+// RegisterContactListRoutes registers routes for the /contact-lists prefix
+func (container *Container) RegisterContactListRoutes() {
+	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.ContactListHandler{}))
+	container.ContactListHandler().RegisterRoutes(container.App(), container.AuthenticatedMiddleware())
+}
+
+// RegisterContactListListeners registers event listeners for listeners.ContactListListener
+func (container *Container) RegisterContactListListeners() {
+	container.logger.Debug(fmt.Sprintf("registering listeners for %T", listeners.ContactListListener{}))
+	_, routes := listeners.NewContactListListener(
+		container.Logger(),
+		container.Tracer(),
+		container.ContactListService(),
+	)
+
+	for event, handler := range routes {
+		container.EventDispatcher().Subscribe(event, handler)
+	}
+}
+
+// AutoReplyRuleRepository creates a new instance of repositories.AutoReplyRuleRepository
+func (container *Container) AutoReplyRuleRepository() (repository repositories.AutoReplyRuleRepository) {
+	container.logger.Debug("creating GORM repositories.AutoReplyRuleRepository")
+	return repositories.NewGormAutoReplyRuleRepository(
+		container.Logger(),
+		container.Tracer(),
+		container.DB(),
+	)
+}
+
+// AutoReplyService creates a new instance of services.AutoReplyService
+func (container *Container) AutoReplyService() (service *services.AutoReplyService) {
+	container.logger.Debug(fmt.Sprintf("creating %T", service))
+	return services.NewAutoReplyService(
+		container.Logger(),
+		container.Tracer(),
+		container.AutoReplyRuleRepository(),
+		container.UserRepository(),
+		container.PhoneService(),
+		container.MessageService(),
+	)
+}
+
+// AutoReplyRuleHandlerValidator creates a new instance of validators.AutoReplyRuleHandlerValidator
+func (container *Container) AutoReplyRuleHandlerValidator() (validator *validators.AutoReplyRuleHandlerValidator) {
+	container.logger.Debug(fmt.Sprintf("creating %T", validator))
+	return validators.NewAutoReplyRuleHandlerValidator(
+		container.Logger(),
+		container.Tracer(),
+		container.PhoneService(),
+	)
+}
+
+// AutoReplyRuleHandler creates a new instance of handlers.AutoReplyRuleHandler
+func (container *Container) AutoReplyRuleHandler() (h *handlers.AutoReplyRuleHandler) {
+	container.logger.Debug(fmt.Sprintf("creating %T", h))
+	return handlers.NewAutoReplyRuleHandler(
+		container.Logger(),
+		container.Tracer(),
+		container.AutoReplyService(),
+		container.AutoReplyRuleHandlerValidator(),
+	)
+}
+
+// RegisterAutoReplyRuleRoutes registers routes for the /auto-reply-rules prefix
+func (container *Container) RegisterAutoReplyRuleRoutes() {
+	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.AutoReplyRuleHandler{}))
+	container.AutoReplyRuleHandler().RegisterRoutes(container.App(), container.AuthenticatedMiddleware())
+}
+
+// RegisterAutoReplyListeners registers event listeners for listeners.AutoReplyListener
+func (container *Container) RegisterAutoReplyListeners() {
+	container.logger.Debug(fmt.Sprintf("registering listeners for %T", listeners.AutoReplyListener{}))
+	_, routes := listeners.NewAutoReplyListener(
+		container.Logger(),
+		container.Tracer(),
+		container.AutoReplyService(),
+	)
+
+	for event, handler := range routes {
+		container.EventDispatcher().Subscribe(event, handler)
+	}
+}
+
 // WebhookService creates a new instance of services.WebhookService
 func (container *Container) WebhookService() (service *services.WebhookService) {
 	container.logger.Debug(fmt.Sprintf("creating %T", service))
@@ -801,6 +1224,8 @@ func (container *Container) WebhookService() (service *services.WebhookService)
 		container.Tracer(),
 		container.HTTPClient("webhook"),
 		container.WebhookRepository(),
+		container.WebhookDeliveryRepository(),
+		container.UserRepository(),
 		container.EventDispatcher(),
 	)
 }
@@ -816,6 +1241,24 @@ func (container *Container) Integration3CXService() (service *services.Integrati
 	)
 }
 
+// IntegrationMQTTService creates a new instance of services.IntegrationMQTTService. The same instance is reused so
+// that the connections to the MQTT brokers are shared.
+func (container *Container) IntegrationMQTTService() (service *services.IntegrationMQTTService) {
+	if container.mqttService != nil {
+		return container.mqttService
+	}
+
+	container.logger.Debug(fmt.Sprintf("creating %T", service))
+	container.mqttService = services.NewIntegrationMQTTService(
+		container.Logger(),
+		container.Tracer(),
+		mqtt.NewClient,
+		container.IntegrationMQTTRepository(),
+		container.Cache(),
+	)
+	return container.mqttService
+}
+
 // HTTPClient creates a new http.Client
 func (container *Container) HTTPClient(name string) *http.Client {
 	container.logger.Debug(fmt.Sprintf("creating %s %T", name, http.DefaultClient))
@@ -963,6 +1406,7 @@ func (container *Container) MessageHandler() (handler *handlers.MessageHandler)
 		container.MessageHandlerValidator(),
 		container.BillingService(),
 		container.MessageService(),
+		container.ContactListService(),
 	)
 }
 
@@ -1061,6 +1505,45 @@ func (container *Container) Integration3CXHandler() (handler *handlers.Integrati
 	)
 }
 
+// IntegrationMQTTHandlerValidator creates a new instance of validators.IntegrationMQTTHandlerValidator
+func (container *Container) IntegrationMQTTHandlerValidator() (validator *validators.IntegrationMQTTHandlerValidator) {
+	container.logger.Debug(fmt.Sprintf("creating %T", validator))
+	return validators.NewIntegrationMQTTHandlerValidator(
+		container.Logger(),
+		container.Tracer(),
+	)
+}
+
+// IntegrationMQTTHandler creates a new instance of handlers.IntegrationMQTTHandler
+func (container *Container) IntegrationMQTTHandler() (handler *handlers.IntegrationMQTTHandler) {
+	container.logger.Debug(fmt.Sprintf("creating %T", handler))
+
+	return handlers.NewIntegrationMQTTHandler(
+		container.Logger(),
+		container.Tracer(),
+		container.IntegrationMQTTHandlerValidator(),
+		container.IntegrationMQTTService(),
+		container.MessageService(),
+		container.BillingService(),
+		container.MessageHandlerValidator(),
+	)
+}
+
+// SlackHandler creates a new instance of handlers.SlackHandler
+func (container *Container) SlackHandler() (handler *handlers.SlackHandler) {
+	container.logger.Debug(fmt.Sprintf("creating %T", handler))
+
+	return handlers.NewSlackHandler(
+		container.Logger(),
+		container.Tracer(),
+		container.SlackHandlerValidator(),
+		container.SlackService(),
+		container.MessageService(),
+		container.BillingService(),
+		container.MessageHandlerValidator(),
+	)
+}
+
 // DiscordHandler creates a new instance of handlers.DiscordHandler
 func (container *Container) DiscordHandler() (handler *handlers.DiscordHandler) {
 	container.logger.Debug(fmt.Sprintf("creating %T", handler))
@@ -1096,6 +1579,14 @@ func (container *Container) LemonsqueezyClient() (client *lemonsqueezy.Client) {
 	)
 }
 
+// SlackClient creates a new instance of slack.Client
+func (container *Container) SlackClient() (client *slack.Client) {
+	container.logger.Debug(fmt.Sprintf("creating %T", client))
+	return slack.New(
+		slack.WithHTTPClient(container.HTTPClient("slack")),
+	)
+}
+
 // DiscordClient creates a new instance of discord.Client
 func (container *Container) DiscordClient() (client *discord.Client) {
 	container.logger.Debug(fmt.Sprintf("creating %T", client))
@@ -1118,6 +1609,26 @@ func (container *Container) RegisterIntegration3CXRoutes() {
 	container.Integration3CXHandler().RegisterRoutes(container.App(), container.BearerAPIKeyMiddleware(), container.AuthenticatedMiddleware())
 }
 
+// RegisterIntegrationMQTTRoutes registers routes for the /v1/mqtt-integrations prefix and subscribes to the command
+// topics of the MQTT integrations
+func (container *Container) RegisterIntegrationMQTTRoutes() {
+	container.logger.Debug(fmt.Sprintf("registering [%T] routes", &handlers.IntegrationMQTTHandler{}))
+	handler := container.IntegrationMQTTHandler()
+	handler.RegisterRoutes(container.App(), container.AuthenticatedMiddleware())
+
+	go func() {
+		if err := container.IntegrationMQTTService().Subscribe(context.Background(), handler.OnCommand); err != nil {
+			container.logger.Error(stacktrace.Propagate(err, "cannot subscribe to the command topics of the mqtt integrations"))
+		}
+	}()
+}
+
+// RegisterSlackRoutes registers routes for the /slack prefix
+func (container *Container) RegisterSlackRoutes() {
+	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.SlackHandler{}))
+	container.SlackHandler().RegisterRoutes(container.App(), container.AuthenticatedMiddleware())
+}
+
 // RegisterDiscordRoutes registers routes for the /discord prefix
 func (container *Container) RegisterDiscordRoutes() {
 	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.DiscordHandler{}))
@@ -1208,6 +1719,20 @@ func (container *Container) RegisterBillingListeners() {
 	}
 }
 
+// RegisterSlackListeners registers event listeners for listeners.SlackListener
+func (container *Container) RegisterSlackListeners() {
+	container.logger.Debug(fmt.Sprintf("registering listeners for %T", listeners.SlackListener{}))
+	_, routes := listeners.NewSlackListener(
+		container.Logger(),
+		container.Tracer(),
+		container.SlackService(),
+	)
+
+	for event, handler := range routes {
+		container.EventDispatcher().Subscribe(event, handler)
+	}
+}
+
 // RegisterDiscordListeners registers event listeners for listeners.DiscordListener
 func (container *Container) RegisterDiscordListeners() {
 	container.logger.Debug(fmt.Sprintf("registering listeners for %T", listeners.DiscordListener{}))
@@ -1250,6 +1775,20 @@ func (container *Container) RegisterIntegration3CXListeners() {
 	}
 }
 
+// RegisterIntegrationMQTTListeners registers event listeners for listeners.IntegrationMQTTListener
+func (container *Container) RegisterIntegrationMQTTListeners() {
+	container.logger.Debug(fmt.Sprintf("registering listeners for %T", listeners.IntegrationMQTTListener{}))
+	_, routes := listeners.NewIntegrationMQTTListener(
+		container.Logger(),
+		container.Tracer(),
+		container.IntegrationMQTTService(),
+	)
+
+	for event, handler := range routes {
+		container.EventDispatcher().Subscribe(event, handler)
+	}
+}
+
 // RegisterWebhookListeners registers event listeners for listeners.WebhookListener
 func (container *Container) RegisterWebhookListeners() {
 	container.logger.Debug(fmt.Sprintf("registering listeners for %T", listeners.WebhookListener{}))
@@ -1273,6 +1812,11 @@ func (container *Container) MessageService() (service *services.MessageService)
 		container.MessageRepository(),
 		container.EventDispatcher(),
 		container.PhoneService(),
+		container.SuppressionRepository(),
+		container.UserRepository(),
+		container.NotificationService(),
+		container.CampaignRepository(),
+		container.Cache(),
 	)
 }
 
@@ -1285,6 +1829,8 @@ func (container *Container) NotificationService() (service *services.PhoneNotifi
 		container.FirebaseMessagingClient(),
 		container.PhoneRepository(),
 		container.PhoneNotificationRepository(),
+		container.CampaignRepository(),
+		container.UserRepository(),
 		container.EventDispatcher(),
 	)
 }
@@ -1292,13 +1838,13 @@ func (container *Container) NotificationService() (service *services.PhoneNotifi
 // RegisterMessageRoutes registers routes for the /messages prefix
 func (container *Container) RegisterMessageRoutes() {
 	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.MessageHandler{}))
-	container.MessageHandler().RegisterRoutes(container.AuthRouter())
+	container.MessageHandler().RegisterRoutes(container.AuthRouter(), container.IdempotencyMiddleware())
 }
 
 // RegisterBulkMessageRoutes registers routes for the /bulk-messages prefix
 func (container *Container) RegisterBulkMessageRoutes() {
 	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.BulkMessageHandler{}))
-	container.BulkMessageHandler().RegisterRoutes(container.AuthRouter())
+	container.BulkMessageHandler().RegisterRoutes(container.AuthRouter(), container.IdempotencyMiddleware())
 }
 
 // RegisterMessageThreadRoutes registers routes for the /message-threads prefix
@@ -1319,10 +1865,12 @@ func (container *Container) RegisterBillingRoutes() {
 	container.BillingHandler().RegisterRoutes(container.AuthRouter())
 }
 
-// RegisterWebhookRoutes registers routes for the /webhooks prefix
+// RegisterWebhookRoutes registers routes for the /webhooks prefix and deletes the expired webhook deliveries periodically
 func (container *Container) RegisterWebhookRoutes() {
 	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.WebhookHandler{}))
 	container.WebhookHandler().RegisterRoutes(container.App(), container.AuthenticatedMiddleware())
+
+	go container.WebhookService().CleanupDeliveries(context.Background())
 }
 
 // RegisterPhoneRoutes registers routes for the /phone prefix
@@ -1343,6 +1891,66 @@ func (container *Container) RegisterEventRoutes() {
 	container.EventsHandler().RegisterRoutes(container.AuthRouter())
 }
 
+// RegisterEventStreamRoutes registers routes for the /events/stream and /events/ws prefix
+func (container *Container) RegisterEventStreamRoutes() {
+	container.logger.Debug(fmt.Sprintf("registering %T routes", &handlers.EventStreamHandler{}))
+	container.EventStreamHandler().RegisterRoutes(container.App(), container.AuthenticatedMiddleware())
+}
+
+// RegisterEventStreamListeners registers event listeners for listeners.EventStreamListener
+func (container *Container) RegisterEventStreamListeners() {
+	container.logger.Debug(fmt.Sprintf("registering listeners for %T", listeners.EventStreamListener{}))
+	_, routes := listeners.NewEventStreamListener(
+		container.Logger(),
+		container.Tracer(),
+		container.EventStreamService(),
+	)
+
+	for event, handler := range routes {
+		container.EventDispatcher().Subscribe(event, handler)
+	}
+}
+
+// EventStreamHandler creates a new instance of handlers.EventStreamHandler
+func (container *Container) EventStreamHandler() (handler *handlers.EventStreamHandler) {
+	container.logger.Debug(fmt.Sprintf("creating %T", handler))
+	return handlers.NewEventStreamHandler(
+		container.Logger(),
+		container.Tracer(),
+		container.EventStreamHandlerValidator(),
+		container.EventStreamService(),
+	)
+}
+
+// EventStreamHandlerValidator creates a new instance of validators.EventStreamHandlerValidator
+func (container *Container) EventStreamHandlerValidator() (validator *validators.EventStreamHandlerValidator) {
+	container.logger.Debug(fmt.Sprintf("creating %T", validator))
+	return validators.NewEventStreamHandlerValidator(
+		container.Logger(),
+		container.Tracer(),
+	)
+}
+
+// EventStreamService creates a new instance of services.EventStreamService
+func (container *Container) EventStreamService() (service *services.EventStreamService) {
+	container.logger.Debug(fmt.Sprintf("creating %T", service))
+	return services.NewEventStreamService(
+		container.Logger(),
+		container.Tracer(),
+		container.EventStreamRepository(),
+	)
+}
+
+// EventStreamRepository creates the redis version of repositories.EventStreamRepository
+func (container *Container) EventStreamRepository() repositories.EventStreamRepository {
+	container.logger.Debug("creating redis repositories.EventStreamRepository")
+	return repositories.NewRedisEventStreamRepository(
+		container.Logger(),
+		container.Tracer(),
+		container.StreamRedisClient(),
+	)
+}
+
 // RegisterSwaggerRoutes registers routes for swagger
 func (container *Container) RegisterSwaggerRoutes() {
 	container.logger.Debug(fmt.Sprintf("registering %T routes", swagger.HandlerDefault))
@@ -127,6 +127,54 @@ func (factory *hermesNotificationEmailFactory) WebhookSendFailed(user *entities.
 	}, nil
 }
 
+func (factory *hermesNotificationEmailFactory) WebhookDisabled(user *entities.User, payload *events.WebhookDisabledPayload) (*Email, error) {
+	email := hermes.Email{
+		Body: hermes.Body{
+			Title: "Hello",
+			Intros: []string{
+				fmt.Sprintf("We disabled your webhook at %s because it failed %d times in a row. httpSMS will not forward events to your webserver until you enable the webhook again.", user.UserTimeString(payload.DisabledAt), payload.ConsecutiveFailures),
+			},
+			Dictionary: []hermes.Entry{
+				{Key: "Server URL", Value: payload.WebhookURL},
+				{Key: "Last Event Name", Value: payload.EventType},
+				{Key: "Error Message / HTTP Response", Value: payload.ErrorMessage},
+			},
+			Actions: []hermes.Action{
+				{
+					Instructions: "Once your webserver is back online, you can enable the webhook on the httpSMS website under the settings page. We will send a test ping to your webserver before enabling it.",
+					Button: hermes.Button{
+						Color:     "#329ef4",
+						TextColor: "#FFFFFF",
+						Text:      "WEBHOOK SETTINGS",
+						Link:      "https://httpsms.com/settings/#webhook-settings",
+					},
+				},
+			},
+			Signature: "Cheers",
+			Outros: []string{
+				"Don't hesitate to contact us by replying to this email.",
+			},
+		},
+	}
+
+	html, err := factory.generator.GenerateHTML(email)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot generate html email")
+	}
+
+	text, err := factory.generator.GeneratePlainText(email)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot generate text email")
+	}
+
+	return &Email{
+		ToEmail: user.Email,
+		Subject: "📢 Your httpSMS webhook has been disabled",
+		HTML:    html,
+		Text:    text,
+	}, nil
+}
+
 func (factory *hermesNotificationEmailFactory) MessageExpired(user *entities.User, payload *events.MessageSendExpiredPayload) (*Email, error) {
 	email := hermes.Email{
 		Body: hermes.Body{
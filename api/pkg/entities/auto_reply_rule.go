@@ -0,0 +1,151 @@
+package entities
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// AutoReplyMatchType determines how the content of an inbound message is matched by an AutoReplyRule
+type AutoReplyMatchType string
+
+const (
+	// AutoReplyMatchTypeKeyword matches inbound messages which are equal to the pattern ignoring the case
+	AutoReplyMatchTypeKeyword = AutoReplyMatchType("keyword")
+
+	// AutoReplyMatchTypeRegex matches inbound messages with the regular expression in the pattern
+	AutoReplyMatchTypeRegex = AutoReplyMatchType("regex")
+
+	// AutoReplyMatchTypeAny matches all inbound messages
+	AutoReplyMatchTypeAny = AutoReplyMatchType("any")
+)
+
+const (
+	// AutoReplyMinCooldownSeconds is the minimum cooldown of an AutoReplyRule. It prevents endless reply loops with other auto responders.
+	AutoReplyMinCooldownSeconds = 60
+
+	// AutoReplyDefaultCooldownSeconds is the cooldown of an AutoReplyRule when it is not set
+	AutoReplyDefaultCooldownSeconds = 3600
+)
+
+// String converts the AutoReplyMatchType to a string
+func (matchType AutoReplyMatchType) String() string {
+	return string(matchType)
+}
+
+// AutoReplyRule is a rule which sends an automatic reply to an inbound message
+type AutoReplyRule struct {
+	ID     uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	UserID UserID    `json:"user_id" gorm:"index:idx_auto_reply_rules__user_id__owner" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	Owner  string    `json:"owner" gorm:"index:idx_auto_reply_rules__user_id__owner" example:"+18005550199"`
+	Name   string    `json:"name" example:"Opening hours"`
+
+	// Priority determines the order in which the rules are evaluated. Only the first matching rule sends a reply.
+	Priority  uint               `json:"priority" example:"1"`
+	MatchType AutoReplyMatchType `json:"match_type" example:"keyword"`
+	Pattern   string             `json:"pattern" example:"HOURS"`
+
+	// Contacts limits the rule to inbound messages from these contacts. The rule applies to all contacts when it is empty.
+	Contacts pq.StringArray `json:"contacts" gorm:"type:text[]" swaggertype:"array,string" example:"[+18005550100]"`
+
+	// StartTime and EndTime limit the rule to a daily time window in the timezone of the user e.g. 18:00 to 08:00
+	StartTime *string `json:"start_time" example:"18:00"`
+	EndTime   *string `json:"end_time" example:"08:00"`
+
+	// CooldownSeconds is the minimum duration between replies of this rule to the same contact
+	CooldownSeconds uint `json:"cooldown_seconds" example:"3600"`
+
+	// Reply is the template of the reply. It supports the {{contact}}, {{owner}} and {{content}} placeholders.
+	Reply     string    `json:"reply" example:"We are open from 08:00 to 18:00, Monday to Friday."`
+	Enabled   bool      `json:"enabled" example:"true"`
+	CreatedAt time.Time `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	UpdatedAt time.Time `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
+}
+
+// Matches checks if the rule applies to an inbound message received at a time in the timezone of the user
+func (rule *AutoReplyRule) Matches(contact string, content string, timestamp time.Time) bool {
+	return rule.Enabled && rule.matchesContact(contact) && rule.matchesContent(content) && rule.IsActiveAt(timestamp)
+}
+
+// Cooldown returns the cooldown as time.Duration. It is never less than AutoReplyMinCooldownSeconds.
+func (rule *AutoReplyRule) Cooldown() time.Duration {
+	return time.Duration(max(rule.CooldownSeconds, AutoReplyMinCooldownSeconds)) * time.Second
+}
+
+// RenderReply replaces the placeholders in the reply template
+func (rule *AutoReplyRule) RenderReply(owner string, contact string, content string) string {
+	return strings.NewReplacer(
+		"{{contact}}", contact,
+		"{{owner}}", owner,
+		"{{content}}", content,
+	).Replace(rule.Reply)
+}
+
+// IsActiveAt checks if the time of the day is in the time window of the rule
+func (rule *AutoReplyRule) IsActiveAt(timestamp time.Time) bool {
+	if rule.StartTime == nil || rule.EndTime == nil {
+		return true
+	}
+
+	start, err := time.Parse("15:04", *rule.StartTime)
+	if err != nil {
+		return false
+	}
+
+	end, err := time.Parse("15:04", *rule.EndTime)
+	if err != nil {
+		return false
+	}
+
+	minutes := timestamp.Hour()*60 + timestamp.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+
+	if startMinutes <= endMinutes {
+		return minutes >= startMinutes && minutes < endMinutes
+	}
+
+	// the window goes over midnight e.g. 18:00 to 08:00
+	return minutes >= startMinutes || minutes < endMinutes
+}
+
+func (rule *AutoReplyRule) matchesContact(contact string) bool {
+	if len(rule.Contacts) == 0 {
+		return true
+	}
+
+	for _, item := range rule.Contacts {
+		if item == contact {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *AutoReplyRule) matchesContent(content string) bool {
+	switch rule.MatchType {
+	case AutoReplyMatchTypeAny:
+		return true
+	case AutoReplyMatchTypeKeyword:
+		return NormalizeKeyword(content) == NormalizeKeyword(rule.Pattern)
+	case AutoReplyMatchTypeRegex:
+		expression, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return false
+		}
+		return expression.MatchString(content)
+	default:
+		return false
+	}
+}
+
+// AutoReplyCooldown is the last time an AutoReplyRule sent a reply to a contact
+type AutoReplyCooldown struct {
+	AutoReplyRuleID uuid.UUID `json:"auto_reply_rule_id" gorm:"primaryKey;type:uuid;"`
+	Contact         string    `json:"contact" gorm:"primaryKey"`
+	UserID          UserID    `json:"user_id" gorm:"index"`
+	RepliedAt       time.Time `json:"replied_at"`
+}
@@ -0,0 +1,119 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// BusinessHours is a time window on a day of the week when a phone is in the office e.g. monday from 09:00 to 17:00
+type BusinessHours struct {
+	// Day is the day of the week in lower case e.g. monday
+	Day       string `json:"day" example:"monday"`
+	StartTime string `json:"start_time" example:"09:00"`
+	EndTime   string `json:"end_time" example:"17:00"`
+}
+
+// Weekday returns the Day as time.Weekday
+func (hours BusinessHours) Weekday() (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), hours.Day) {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// window returns the start and end of the business hours on a date
+func (hours BusinessHours) window(date time.Time) (time.Time, time.Time, bool) {
+	start, err := time.Parse("15:04", hours.StartTime)
+	if err != nil {
+		return date, date, false
+	}
+
+	end, err := time.Parse("15:04", hours.EndTime)
+	if err != nil || !end.After(start) {
+		return date, date, false
+	}
+
+	return time.Date(date.Year(), date.Month(), date.Day(), start.Hour(), start.Minute(), 0, 0, date.Location()),
+		time.Date(date.Year(), date.Month(), date.Day(), end.Hour(), end.Minute(), 0, 0, date.Location()),
+		true
+}
+
+// maxOutOfOfficeLookBackDays is the number of days checked when searching for the start of an out-of-office window
+const maxOutOfOfficeLookBackDays = 400
+
+// HasOutOfOfficeReply checks if the phone sends an out-of-office reply outside business hours and on holidays
+func (phone *Phone) HasOutOfOfficeReply() bool {
+	return phone.OutOfOfficeReply != nil && strings.TrimSpace(*phone.OutOfOfficeReply) != "" && (len(phone.BusinessHours) > 0 || len(phone.Holidays) > 0)
+}
+
+// IsOutOfOffice checks if a time in the timezone of the user is outside the business hours or on a holiday
+func (phone *Phone) IsOutOfOffice(timestamp time.Time) bool {
+	for _, window := range phone.businessWindows(timestamp) {
+		if !timestamp.Before(window[0]) && timestamp.Before(window[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// OutOfOfficeSince returns the start of the out-of-office window which contains the time.
+// It returns the zero time.Time when the phone was never in the office in the recent past.
+func (phone *Phone) OutOfOfficeSince(timestamp time.Time) time.Time {
+	for offset := 0; offset < maxOutOfOfficeLookBackDays; offset++ {
+		var since time.Time
+		for _, window := range phone.businessWindows(timestamp.AddDate(0, 0, -offset)) {
+			if !window[1].After(timestamp) && window[1].After(since) {
+				since = window[1]
+			}
+		}
+
+		if !since.IsZero() {
+			return since
+		}
+	}
+	return time.Time{}
+}
+
+// IsHoliday checks if the date of a time in the timezone of the user is a holiday
+func (phone *Phone) IsHoliday(timestamp time.Time) bool {
+	date := timestamp.Format(time.DateOnly)
+	for _, holiday := range phone.Holidays {
+		if holiday == date {
+			return true
+		}
+	}
+	return false
+}
+
+// businessWindows returns the time windows when the phone is in the office on the date of a time
+func (phone *Phone) businessWindows(timestamp time.Time) [][2]time.Time {
+	if phone.IsHoliday(timestamp) {
+		return nil
+	}
+
+	date := time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, timestamp.Location())
+	if len(phone.BusinessHours) == 0 {
+		return [][2]time.Time{{date, date.AddDate(0, 0, 1)}}
+	}
+
+	var windows [][2]time.Time
+	for _, hours := range phone.BusinessHours {
+		if day, ok := hours.Weekday(); !ok || day != date.Weekday() {
+			continue
+		}
+		if start, end, ok := hours.window(date); ok {
+			windows = append(windows, [2]time.Time{start, end})
+		}
+	}
+	return windows
+}
+
+// OutOfOfficeCooldown is the last time a phone sent an out-of-office reply to a contact
+type OutOfOfficeCooldown struct {
+	UserID    UserID    `json:"user_id" gorm:"primaryKey"`
+	Owner     string    `json:"owner" gorm:"primaryKey"`
+	Contact   string    `json:"contact" gorm:"primaryKey"`
+	RepliedAt time.Time `json:"replied_at"`
+}
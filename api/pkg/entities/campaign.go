@@ -0,0 +1,104 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CampaignStatus is the status of a campaign
+type CampaignStatus string
+
+const (
+	// CampaignStatusActive means messages in the campaign are being sent
+	CampaignStatusActive = CampaignStatus("active")
+
+	// CampaignStatusPaused means messages in the campaign which have not been sent to the phone are held
+	CampaignStatusPaused = CampaignStatus("paused")
+
+	// CampaignStatusCanceled means messages in the campaign which have not been sent to the phone will not be sent
+	CampaignStatusCanceled = CampaignStatus("canceled")
+
+	// CampaignStatusCompleted means all the messages in the campaign have been processed
+	CampaignStatusCompleted = CampaignStatus("completed")
+)
+
+// Campaign groups messages which are sent together e.g. from a bulk upload
+type Campaign struct {
+	ID          uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	UserID      UserID         `json:"user_id" gorm:"index" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	Name        string         `json:"name" example:"Black Friday Promotion"`
+	Status      CampaignStatus `json:"status" example:"active"`
+	PausedAt    *time.Time     `json:"paused_at" example:"2022-06-05T14:26:09.527976+03:00"`
+	CanceledAt  *time.Time     `json:"canceled_at" example:"2022-06-05T14:26:09.527976+03:00"`
+	CompletedAt *time.Time     `json:"completed_at" example:"2022-06-05T14:26:09.527976+03:00"`
+	CreatedAt   time.Time      `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	UpdatedAt   time.Time      `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
+}
+
+// IsActive checks if the campaign is active
+func (campaign *Campaign) IsActive() bool {
+	return campaign.Status == CampaignStatusActive
+}
+
+// IsPaused checks if the campaign is paused
+func (campaign *Campaign) IsPaused() bool {
+	return campaign.Status == CampaignStatusPaused
+}
+
+// IsCanceled checks if the campaign is canceled
+func (campaign *Campaign) IsCanceled() bool {
+	return campaign.Status == CampaignStatusCanceled
+}
+
+// IsCompleted checks if the campaign is completed
+func (campaign *Campaign) IsCompleted() bool {
+	return campaign.Status == CampaignStatusCompleted
+}
+
+// CanAddMessages checks if new messages can be added to the campaign. A completed campaign is active again when new messages are added.
+func (campaign *Campaign) CanAddMessages() bool {
+	return campaign.IsActive() || campaign.IsPaused() || campaign.IsCompleted()
+}
+
+// Pause registers a campaign as paused
+func (campaign *Campaign) Pause(timestamp time.Time) *Campaign {
+	campaign.Status = CampaignStatusPaused
+	campaign.PausedAt = &timestamp
+	campaign.UpdatedAt = timestamp
+	return campaign
+}
+
+// Resume registers a paused campaign as active
+func (campaign *Campaign) Resume(timestamp time.Time) *Campaign {
+	campaign.Status = CampaignStatusActive
+	campaign.PausedAt = nil
+	campaign.UpdatedAt = timestamp
+	return campaign
+}
+
+// Cancel registers a campaign as canceled
+func (campaign *Campaign) Cancel(timestamp time.Time) *Campaign {
+	campaign.Status = CampaignStatusCanceled
+	campaign.CanceledAt = &timestamp
+	campaign.UpdatedAt = timestamp
+	return campaign
+}
+
+// CampaignStats are the live counters of the messages in a Campaign
+type CampaignStats struct {
+	Total     uint `json:"total" example:"100"`
+	Pending   uint `json:"pending" example:"10"`
+	Scheduled uint `json:"scheduled" example:"10"`
+	Sending   uint `json:"sending" example:"5"`
+	Sent      uint `json:"sent" example:"20"`
+	Delivered uint `json:"delivered" example:"40"`
+	Failed    uint `json:"failed" example:"10"`
+	Expired   uint `json:"expired" example:"5"`
+	Replies   uint `json:"replies" example:"7"`
+}
+
+// Outstanding is the number of messages which have not been processed by the phone
+func (stats *CampaignStats) Outstanding() uint {
+	return stats.Pending + stats.Scheduled + stats.Sending
+}
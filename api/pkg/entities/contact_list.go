@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContactListAction is the action in an inbound message for a ContactList
+type ContactListAction string
+
+const (
+	// ContactListActionJoin adds the contact to a ContactList e.g. "JOIN ALERTS"
+	ContactListActionJoin = ContactListAction("JOIN")
+
+	// ContactListActionLeave removes the contact from a ContactList e.g. "LEAVE ALERTS"
+	ContactListActionLeave = ContactListAction("LEAVE")
+)
+
+// ContactList is a named list which contacts join and leave by sending a keyword
+type ContactList struct {
+	ID         uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	UserID     UserID    `json:"user_id" gorm:"uniqueIndex:idx_contact_lists__user_id__keyword" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	Name       string    `json:"name" example:"Weather Alerts"`
+	Keyword    string    `json:"keyword" gorm:"uniqueIndex:idx_contact_lists__user_id__keyword" example:"ALERTS"`
+	JoinReply  *string   `json:"join_reply" example:"You have joined the weather alerts. Reply LEAVE ALERTS to leave."`
+	LeaveReply *string   `json:"leave_reply" example:"You have left the weather alerts."`
+	CreatedAt  time.Time `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	UpdatedAt  time.Time `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
+}
+
+// ContactListMember is a contact which has joined a ContactList
+type ContactListMember struct {
+	ID            uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	ContactListID uuid.UUID `json:"contact_list_id" gorm:"type:uuid;uniqueIndex:idx_contact_list_members__contact_list_id__contact" example:"a9f6bc56-0ec9-4b0b-9f6a-4d7f4d1b6c8e"`
+	UserID        UserID    `json:"user_id" gorm:"index" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	Contact       string    `json:"contact" gorm:"uniqueIndex:idx_contact_list_members__contact_list_id__contact" example:"+18005550100"`
+
+	// Owner is the phone number which received the join message. It is nil when the contact was added with the API
+	Owner *string `json:"owner" example:"+18005550199"`
+
+	CreatedAt time.Time `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+}
+
+// ParseContactListCommand parses an inbound message like "JOIN ALERTS" into the action and the keyword of the ContactList
+func ParseContactListCommand(content string) (ContactListAction, string, bool) {
+	fields := strings.Fields(NormalizeKeyword(content))
+	if len(fields) != 2 {
+		return "", "", false
+	}
+
+	action := ContactListAction(fields[0])
+	if action != ContactListActionJoin && action != ContactListActionLeave {
+		return "", "", false
+	}
+
+	return action, NormalizeKeyword(fields[1]), true
+}
@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// IntegrationMQTT stores the MQTT broker to which the events of a user are published
+type IntegrationMQTT struct {
+	ID            uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	UserID        UserID         `json:"user_id" gorm:"index" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	Name          string         `json:"name" example:"Home Assistant"`
+	Host          string         `json:"host" example:"broker.hivemq.com"`
+	Port          uint           `json:"port" example:"8883"`
+	UseTLS        bool           `json:"use_tls" example:"true"`
+	Username      *string        `json:"username" example:"httpsms"`
+	Password      *string        `json:"-"`
+	TopicTemplate string         `json:"topic_template" example:"httpsms/{owner}/{event_type}"`
+	QoS           uint8          `json:"qos" gorm:"column:qos" example:"1"`
+	Events        pq.StringArray `json:"events" example:"[message.phone.received]" gorm:"type:text[]" swaggertype:"array,string"`
+	CommandTopic  *string        `json:"command_topic" example:"httpsms/commands/send"`
+	// SharedSubscription subscribes to the command topic with a shared subscription which requires a broker supporting MQTT 5
+	SharedSubscription bool      `json:"shared_subscription" example:"false"`
+	CreatedAt          time.Time `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	UpdatedAt          time.Time `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
+}
+
+// TableName overrides the table name used by IntegrationMQTT
+func (IntegrationMQTT) TableName() string {
+	return "integration_mqtt"
+}
+
+// HasCommandTopic determines if the integration subscribes to a topic for sending messages
+func (integration *IntegrationMQTT) HasCommandTopic() bool {
+	return integration.CommandTopic != nil && *integration.CommandTopic != ""
+}
+
+// SubscriptionTopic is the topic filter used to subscribe to the command topic
+func (integration *IntegrationMQTT) SubscriptionTopic() string {
+	if integration.SharedSubscription {
+		return "$share/httpsms/" + *integration.CommandTopic
+	}
+	return *integration.CommandTopic
+}
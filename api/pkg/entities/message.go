@@ -81,17 +81,28 @@ func (s SIM) String() string {
 	return string(s)
 }
 
+// Alternate returns the other SIM card of a phone with 2 SIM slots
+func (s SIM) Alternate() SIM {
+	if s == SIM2 {
+		return SIM1
+	}
+	return SIM2
+}
+
 // Message represents a message sent between 2 phone numbers
 type Message struct {
-	ID        uuid.UUID     `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
-	RequestID *string       `json:"request_id" example:"153554b5-ae44-44a0-8f4f-7bbac5657ad4"`
-	Owner     string        `json:"owner" example:"+18005550199"`
-	UserID    UserID        `json:"user_id" gorm:"index:idx_messages__user_id" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
-	Contact   string        `json:"contact" example:"+18005550100"`
-	Content   string        `json:"content" example:"This is a sample text message"`
-	Encrypted bool          `json:"encrypted" example:"false" gorm:"default:false"`
-	Type      MessageType   `json:"type" example:"mobile-terminated"`
-	Status    MessageStatus `json:"status" example:"pending"`
+	ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	RequestID *string   `json:"request_id" example:"153554b5-ae44-44a0-8f4f-7bbac5657ad4"`
+	// CallbackURL receives the sending, sent, delivered, failed and expired events of the message
+	CallbackURL *string       `json:"callback_url" example:"https://example.com/sms/status"`
+	CampaignID  *uuid.UUID    `json:"campaign_id" gorm:"type:uuid;index:idx_messages__campaign_id" example:"a9f6bc56-0ec9-4b0b-9f6a-4d7f4d1b6c8e"`
+	Owner       string        `json:"owner" example:"+18005550199"`
+	UserID      UserID        `json:"user_id" gorm:"index:idx_messages__user_id" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	Contact     string        `json:"contact" example:"+18005550100"`
+	Content     string        `json:"content" example:"This is a sample text message"`
+	Encrypted   bool          `json:"encrypted" example:"false" gorm:"default:false"`
+	Type        MessageType   `json:"type" example:"mobile-terminated"`
+	Status      MessageStatus `json:"status" example:"pending"`
 	// SIM is the SIM card to use to send the message
 	// * SMS1: use the SIM card in slot 1
 	// * SMS2: use the SIM card in slot 2
@@ -109,14 +120,46 @@ type Message struct {
 	NotificationScheduledAt *time.Time `json:"scheduled_at" example:"2022-06-05T14:26:09.527976+03:00"`
 	SentAt                  *time.Time `json:"sent_at" example:"2022-06-05T14:26:09.527976+03:00"`
 	ScheduledSendTime       *time.Time `json:"scheduled_send_time" example:"2022-06-05T14:26:09.527976+03:00"`
-	DeliveredAt             *time.Time `json:"delivered_at" example:"2022-06-05T14:26:09.527976+03:00"`
-	ExpiredAt               *time.Time `json:"expired_at" example:"2022-06-05T14:26:09.527976+03:00"`
-	FailedAt                *time.Time `json:"failed_at" example:"2022-06-05T14:26:09.527976+03:00"`
-	CanBePolled             bool       `json:"can_be_polled" example:"false"`
-	SendAttemptCount        uint       `json:"send_attempt_count" example:"0"`
-	MaxSendAttempts         uint       `json:"max_send_attempts" example:"1"`
-	ReceivedAt              *time.Time `json:"received_at" example:"2022-06-05T14:26:09.527976+03:00"`
-	FailureReason           *string    `json:"failure_reason" example:"UNKNOWN"`
+	// Encoding is the character encoding of the content which is either GSM-7 or UCS-2
+	Encoding MessageEncoding `json:"encoding" example:"GSM-7"`
+
+	// Segments is the number of SMS parts needed to send the content
+	Segments uint `json:"segments" example:"1"`
+
+	// Normalizations are the characters which were replaced with GSM-7 equivalents before sending the message
+	Normalizations []MessageNormalization `json:"normalizations" gorm:"type:jsonb;serializer:json"`
+
+	// ParentID groups the parts of a long message which was split into multiple messages
+	ParentID *uuid.UUID `json:"parent_id" gorm:"type:uuid;index:idx_messages__parent_id" example:"e1d1d8a5-4f5c-4c2b-8a5a-3f1c2d9f7b6e"`
+
+	// PartNumber is the position of the message in the parts of the split message starting from 1
+	PartNumber uint `json:"part_number" example:"1"`
+
+	// PartCount is the total number of parts of the split message
+	PartCount uint `json:"part_count" example:"3"`
+
+	// ExpiresAt is the time after which the message is no longer useful and will not be sent by the phone
+	ExpiresAt *time.Time `json:"expires_at" example:"2022-06-05T14:36:09.527976+03:00"`
+
+	// RetryPolicy determines when and how the message is sent again after it fails or expires
+	RetryPolicy *RetryPolicy `json:"retry_policy" gorm:"type:jsonb;serializer:json"`
+
+	// NextAttemptAt is the time when the message will be sent again after it failed or expired
+	NextAttemptAt *time.Time `json:"next_attempt_at" example:"2022-06-05T14:27:09.527976+03:00"`
+
+	// DeferredUntil is the end of the quiet hours of the phone when the message was deferred
+	DeferredUntil    *time.Time `json:"deferred_until" example:"2022-06-06T08:00:00+03:00"`
+	DeliveredAt      *time.Time `json:"delivered_at" example:"2022-06-05T14:26:09.527976+03:00"`
+	ExpiredAt        *time.Time `json:"expired_at" example:"2022-06-05T14:26:09.527976+03:00"`
+	FailedAt         *time.Time `json:"failed_at" example:"2022-06-05T14:26:09.527976+03:00"`
+	CanBePolled      bool       `json:"can_be_polled" example:"false"`
+	SendAttemptCount uint       `json:"send_attempt_count" example:"0"`
+	MaxSendAttempts  uint       `json:"max_send_attempts" example:"1"`
+	ReceivedAt       *time.Time `json:"received_at" example:"2022-06-05T14:26:09.527976+03:00"`
+	FailureReason    *string    `json:"failure_reason" example:"UNKNOWN"`
+
+	// FailureCode is the canonical reason why the message could not be sent e.g. NO_SERVICE, RADIO_OFF, FCM_TOKEN_INVALID, PHONE_OFFLINE or EXPIRED
+	FailureCode *MessageFailureCode `json:"failure_code" example:"NO_SERVICE"`
 }
 
 // IsSending determines if a message is being sent
@@ -144,9 +187,56 @@ func (message *Message) IsExpired() bool {
 	return message.Status == MessageStatusExpired
 }
 
-// CanBeRescheduled checks if a message can be rescheduled
+// CanBeRescheduled checks if a message can be rescheduled after it expires
 func (message *Message) CanBeRescheduled() bool {
-	return message.SendAttemptCount < message.MaxSendAttempts
+	return message.CanBeRetried(message.ExpiryFailureCode())
+}
+
+// ExpiryFailureCode returns the MessageFailureCode of a message which is expiring.
+// The phone is offline when it did not fetch the message before the expiration.
+func (message *Message) ExpiryFailureCode() MessageFailureCode {
+	if message.IsSending() {
+		return MessageFailureCodeExpired
+	}
+	return MessageFailureCodePhoneOffline
+}
+
+// CanBeRetried checks if a message can be sent again after failing with the failure code
+func (message *Message) CanBeRetried(code MessageFailureCode) bool {
+	return message.SendAttemptCount < message.MaxSendAttempts &&
+		!message.HasLapsed(time.Now().UTC()) &&
+		message.RetryPolicySanitized().IsRetriable(code)
+}
+
+// RetryPolicySanitized returns the RetryPolicy of the message using the default policy when it is not set
+func (message *Message) RetryPolicySanitized() *RetryPolicy {
+	if message.RetryPolicy == nil {
+		return DefaultRetryPolicy(message.MaxSendAttempts)
+	}
+	return message.RetryPolicy
+}
+
+// ScheduleRetry configures the next attempt of sending a message switching the SIM if the RetryPolicy alternates SIM cards
+func (message *Message) ScheduleRetry(nextAttemptAt time.Time) *Message {
+	message.NextAttemptAt = &nextAttemptAt
+	if message.RetryPolicySanitized().AlternateSIM {
+		message.SIM = message.SIM.Alternate()
+	}
+	return message
+}
+
+// RetryAfterFailure registers a failed message which will be sent again
+func (message *Message) RetryAfterFailure(timestamp time.Time, code MessageFailureCode, errorMessage string) *Message {
+	message.Status = MessageStatusPending
+	message.FailureCode = &code
+	message.FailureReason = &errorMessage
+	message.updateOrderTimestamp(timestamp)
+	return message
+}
+
+// HasLapsed checks if the validity period of the message set with ExpiresAt has passed
+func (message *Message) HasLapsed(timestamp time.Time) bool {
+	return message.ExpiresAt != nil && !message.ExpiresAt.After(timestamp)
 }
 
 // IsSent determines if a message has been sent
@@ -166,9 +256,10 @@ func (message *Message) Sent(timestamp time.Time) *Message {
 }
 
 // Failed registers a message as failed
-func (message *Message) Failed(timestamp time.Time, errorMessage string) *Message {
+func (message *Message) Failed(timestamp time.Time, code MessageFailureCode, errorMessage string) *Message {
 	message.FailedAt = &timestamp
 	message.Status = MessageStatusFailed
+	message.FailureCode = &code
 	message.FailureReason = &errorMessage
 	message.updateOrderTimestamp(timestamp)
 	return message
@@ -194,17 +285,19 @@ func (message *Message) AddSendAttemptCount() *Message {
 }
 
 // Expired registers a message as expired
-func (message *Message) Expired(timestamp time.Time) *Message {
+func (message *Message) Expired(timestamp time.Time, code MessageFailureCode) *Message {
 	message.ExpiredAt = &timestamp
 	message.Status = MessageStatusExpired
+	message.FailureCode = &code
 	message.CanBePolled = true
 	message.updateOrderTimestamp(timestamp)
 	return message
 }
 
 // NotificationScheduled registers a message as scheduled
-func (message *Message) NotificationScheduled(timestamp time.Time) *Message {
+func (message *Message) NotificationScheduled(timestamp time.Time, deferredUntil *time.Time) *Message {
 	message.NotificationScheduledAt = &timestamp
+	message.DeferredUntil = deferredUntil
 
 	if message.IsExpired() || message.IsPending() {
 		message.Status = MessageStatusScheduled
@@ -0,0 +1,175 @@
+package entities
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf16"
+)
+
+// MessageEncoding is the character encoding used to send an SMS message
+type MessageEncoding string
+
+const (
+	// MessageEncodingGSM7 is the 7-bit GSM alphabet which fits 160 characters in a single SMS
+	MessageEncodingGSM7 = MessageEncoding("GSM-7")
+
+	// MessageEncodingUCS2 is the 16-bit encoding used when the content has characters outside the GSM alphabet. It fits 70 characters in a single SMS
+	MessageEncodingUCS2 = MessageEncoding("UCS-2")
+)
+
+// encryptionIVLength is the length of the initialization vector which is prepended to the AES-CFB ciphertext of an encrypted message
+const encryptionIVLength = 16
+
+const (
+	gsm7SingleSegmentLength = 160
+	gsm7MultiSegmentLength  = 153
+	ucs2SingleSegmentLength = 70
+	ucs2MultiSegmentLength  = 67
+)
+
+// gsm7Characters are the characters in the GSM 03.38 basic character set
+var gsm7Characters = toRuneSet("@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà")
+
+// gsm7ExtendedCharacters are the characters in the GSM 03.38 extension table which use 2 septets
+var gsm7ExtendedCharacters = toRuneSet("\f^{}\\[~]|€")
+
+// String converts the MessageEncoding to a string
+func (encoding MessageEncoding) String() string {
+	return string(encoding)
+}
+
+// CalculateSegments returns the encoding and the number of SMS segments needed to send the content
+func CalculateSegments(content string) (MessageEncoding, uint) {
+	septets, ok := gsm7Length(content)
+	if ok {
+		return MessageEncodingGSM7, segmentCount(septets, gsm7SingleSegmentLength, gsm7MultiSegmentLength)
+	}
+	return MessageEncodingUCS2, segmentCount(len(utf16.Encode([]rune(content))), ucs2SingleSegmentLength, ucs2MultiSegmentLength)
+}
+
+// EstimateSegments returns the encoding and the number of SMS segments of the content. The content of an encrypted
+// message is the base64 ciphertext so the segments are estimated from the length of the plaintext which is the length
+// of the ciphertext without the IV. Each byte of the UTF-8 plaintext is counted as a GSM-7 character.
+func EstimateSegments(content string, encrypted bool) (MessageEncoding, uint) {
+	if !encrypted {
+		return CalculateSegments(content)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(content)
+	if err != nil || len(ciphertext) < encryptionIVLength {
+		return CalculateSegments(content)
+	}
+
+	return MessageEncodingGSM7, segmentCount(len(ciphertext)-encryptionIVLength, gsm7SingleSegmentLength, gsm7MultiSegmentLength)
+}
+
+// IsGSM7Character checks if a character can be encoded with the GSM-7 alphabet
+func IsGSM7Character(character rune) bool {
+	_, basic := gsm7Characters[character]
+	_, extended := gsm7ExtendedCharacters[character]
+	return basic || extended
+}
+
+// gsm7Length returns the number of septets needed to encode the content with GSM-7 and false if the content cannot be encoded with GSM-7
+func gsm7Length(content string) (int, bool) {
+	length := 0
+	for _, character := range content {
+		if _, ok := gsm7Characters[character]; ok {
+			length++
+			continue
+		}
+		if _, ok := gsm7ExtendedCharacters[character]; ok {
+			length += 2
+			continue
+		}
+		return 0, false
+	}
+	return length, true
+}
+
+func segmentCount(length int, singleSegmentLength int, multiSegmentLength int) uint {
+	if length <= singleSegmentLength {
+		return 1
+	}
+	return uint((length + multiSegmentLength - 1) / multiSegmentLength)
+}
+
+func toRuneSet(characters string) map[rune]struct{} {
+	result := make(map[rune]struct{}, len(characters))
+	for _, character := range characters {
+		result[character] = struct{}{}
+	}
+	return result
+}
+
+// MessageNormalization is a character in the content of a message which was replaced with a GSM-7 equivalent
+type MessageNormalization struct {
+	Original    string `json:"original" example:"“"`
+	Replacement string `json:"replacement" example:"\""`
+	Count       uint   `json:"count" example:"2"`
+}
+
+// gsm7Transliterations are the GSM-7 equivalents of common characters which are not in the GSM-7 alphabet
+var gsm7Transliterations = map[rune]string{
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'", '´': "'", '`': "'",
+	'“': "\"", '”': "\"", '„': "\"", '‟': "\"", '″': "\"", '«': "\"", '»': "\"",
+	'–': "-", '—': "-", '―': "-", '‐': "-", '‑': "-", '‒': "-", '−': "-",
+	'…': "...", '•': "*", '·': ".", '×': "x", '÷': "/",
+	'\u00a0': " ", '\u2002': " ", '\u2003': " ", '\u2009': " ", '\u200a': " ", '\u202f': " ", '\t': " ",
+	'\u200b': "", '\u200c': "", '\u200d': "", '\ufeff': "",
+	'á': "a", 'â': "a", 'ã': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'Á': "A", 'À': "A", 'Â': "A", 'Ã': "A", 'Ā': "A", 'Ă': "A", 'Ą': "A",
+	'ç': "c", 'ć': "c", 'č': "c", 'Ć': "C", 'Č': "C",
+	'ď': "d", 'Ď': "D", 'đ': "d", 'Đ': "D",
+	'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'È': "E", 'Ê': "E", 'Ë': "E", 'Ē': "E", 'Ė': "E", 'Ę': "E", 'Ě': "E",
+	'ğ': "g", 'Ğ': "G",
+	'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'Í': "I", 'Ì': "I", 'Î': "I", 'Ï': "I", 'Ī': "I", 'Į': "I", 'İ': "I",
+	'ł': "l", 'Ł': "L", 'ľ': "l", 'Ľ': "L",
+	'ń': "n", 'ň': "n", 'Ń': "N", 'Ň': "N",
+	'ó': "o", 'ô': "o", 'õ': "o", 'ō': "o", 'ő': "o",
+	'Ó': "O", 'Ò': "O", 'Ô': "O", 'Õ': "O", 'Ō': "O", 'Ő': "O",
+	'ř': "r", 'Ř': "R",
+	'ś': "s", 'š': "s", 'ş': "s", 'ș': "s", 'Ś': "S", 'Š': "S", 'Ş': "S", 'Ș': "S",
+	'ť': "t", 'ţ': "t", 'ț': "t", 'Ť': "T", 'Ţ': "T", 'Ț': "T",
+	'ú': "u", 'û': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'Ú': "U", 'Ù': "U", 'Û': "U", 'Ū': "U", 'Ů': "U", 'Ű': "U", 'Ų': "U",
+	'ý': "y", 'ÿ': "y", 'Ý': "Y", 'Ÿ': "Y",
+	'ź': "z", 'ż': "z", 'ž': "z", 'Ź': "Z", 'Ż': "Z", 'Ž': "Z",
+	'œ': "oe", 'Œ': "OE",
+}
+
+// NormalizeGSM7 replaces characters which are not in the GSM-7 alphabet with their GSM-7 equivalents.
+// Characters without an equivalent are kept so the message is still sent with the UCS-2 encoding.
+func NormalizeGSM7(content string) (string, []MessageNormalization) {
+	var builder strings.Builder
+	builder.Grow(len(content))
+
+	counts := map[rune]uint{}
+	var order []rune
+	for _, character := range content {
+		replacement, ok := gsm7Transliterations[character]
+		if !ok || IsGSM7Character(character) {
+			builder.WriteRune(character)
+			continue
+		}
+
+		if _, exists := counts[character]; !exists {
+			order = append(order, character)
+		}
+		counts[character]++
+		builder.WriteString(replacement)
+	}
+
+	normalizations := make([]MessageNormalization, 0, len(order))
+	for _, character := range order {
+		normalizations = append(normalizations, MessageNormalization{
+			Original:    string(character),
+			Replacement: gsm7Transliterations[character],
+			Count:       counts[character],
+		})
+	}
+
+	return builder.String(), normalizations
+}
@@ -0,0 +1,164 @@
+package entities
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		encoding MessageEncoding
+		segments uint
+	}{
+		{
+			name:     "empty content",
+			content:  "",
+			encoding: MessageEncodingGSM7,
+			segments: 1,
+		},
+		{
+			name:     "160 GSM-7 characters fit in a single segment",
+			content:  strings.Repeat("a", 160),
+			encoding: MessageEncodingGSM7,
+			segments: 1,
+		},
+		{
+			name:     "161 GSM-7 characters need 2 segments",
+			content:  strings.Repeat("a", 161),
+			encoding: MessageEncodingGSM7,
+			segments: 2,
+		},
+		{
+			name:     "306 GSM-7 characters fit in 2 segments",
+			content:  strings.Repeat("a", 306),
+			encoding: MessageEncodingGSM7,
+			segments: 2,
+		},
+		{
+			name:     "307 GSM-7 characters need 3 segments",
+			content:  strings.Repeat("a", 307),
+			encoding: MessageEncodingGSM7,
+			segments: 3,
+		},
+		{
+			name:     "extended GSM-7 characters use 2 septets",
+			content:  strings.Repeat("€", 80),
+			encoding: MessageEncodingGSM7,
+			segments: 1,
+		},
+		{
+			name:     "extended GSM-7 characters over a single segment",
+			content:  strings.Repeat("€", 81),
+			encoding: MessageEncodingGSM7,
+			segments: 2,
+		},
+		{
+			name:     "70 UCS-2 characters fit in a single segment",
+			content:  strings.Repeat("ж", 70),
+			encoding: MessageEncodingUCS2,
+			segments: 1,
+		},
+		{
+			name:     "a single non GSM-7 character switches to UCS-2",
+			content:  strings.Repeat("a", 70) + "ж",
+			encoding: MessageEncodingUCS2,
+			segments: 2,
+		},
+		{
+			name:     "134 UCS-2 characters fit in 2 segments",
+			content:  strings.Repeat("ж", 134),
+			encoding: MessageEncodingUCS2,
+			segments: 2,
+		},
+		{
+			name:     "emoji use 2 UTF-16 code units",
+			content:  strings.Repeat("😀", 35),
+			encoding: MessageEncodingUCS2,
+			segments: 1,
+		},
+		{
+			name:     "emoji over a single segment",
+			content:  strings.Repeat("😀", 36),
+			encoding: MessageEncodingUCS2,
+			segments: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			t.Parallel()
+
+			// Act
+			encoding, segments := CalculateSegments(tt.content)
+
+			// Assert
+			assert.Equal(t, tt.encoding, encoding)
+			assert.Equal(t, tt.segments, segments)
+		})
+	}
+}
+
+func TestEstimateSegments(t *testing.T) {
+	ciphertext := func(plaintextLength int) string {
+		return base64.StdEncoding.EncodeToString(make([]byte, encryptionIVLength+plaintextLength))
+	}
+
+	tests := []struct {
+		name      string
+		content   string
+		encrypted bool
+		encoding  MessageEncoding
+		segments  uint
+	}{
+		{
+			name:      "plain content is calculated",
+			content:   strings.Repeat("ж", 71),
+			encrypted: false,
+			encoding:  MessageEncodingUCS2,
+			segments:  2,
+		},
+		{
+			name:      "encrypted content uses the plaintext length",
+			content:   ciphertext(160),
+			encrypted: true,
+			encoding:  MessageEncodingGSM7,
+			segments:  1,
+		},
+		{
+			name:      "encrypted content over a single segment",
+			content:   ciphertext(161),
+			encrypted: true,
+			encoding:  MessageEncodingGSM7,
+			segments:  2,
+		},
+		{
+			name:      "invalid ciphertext is calculated as plain content",
+			content:   "not base64!",
+			encrypted: true,
+			encoding:  MessageEncodingGSM7,
+			segments:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			t.Parallel()
+
+			// Act
+			encoding, segments := EstimateSegments(tt.content, tt.encrypted)
+
+			// Assert
+			assert.Equal(t, tt.encoding, encoding)
+			assert.Equal(t, tt.segments, segments)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package entities
+
+import "strings"
+
+// MessageFailureCode is the canonical reason why a message could not be sent
+type MessageFailureCode string
+
+const (
+	// MessageFailureCodeGenericFailure is used when the phone reports a generic failure while sending the message
+	MessageFailureCodeGenericFailure = MessageFailureCode("GENERIC_FAILURE")
+
+	// MessageFailureCodeNoService is used when the phone has no cellular service
+	MessageFailureCodeNoService = MessageFailureCode("NO_SERVICE")
+
+	// MessageFailureCodeNullPDU is used when the phone could not create the SMS PDU
+	MessageFailureCodeNullPDU = MessageFailureCode("NULL_PDU")
+
+	// MessageFailureCodeRadioOff is used when the radio of the phone is turned off e.g. in airplane mode
+	MessageFailureCodeRadioOff = MessageFailureCode("RADIO_OFF")
+
+	// MessageFailureCodeLimitExceeded is used when the phone or the carrier refuses to send more messages
+	MessageFailureCodeLimitExceeded = MessageFailureCode("LIMIT_EXCEEDED")
+
+	// MessageFailureCodeSendingDisabled is used when outgoing messages are disabled on the mobile app
+	MessageFailureCodeSendingDisabled = MessageFailureCode("SENDING_DISABLED")
+
+	// MessageFailureCodeEncryptionError is used when the mobile app cannot decrypt an encrypted message
+	MessageFailureCodeEncryptionError = MessageFailureCode("ENCRYPTION_ERROR")
+
+	// MessageFailureCodeFcmTokenInvalid is used when the push notification cannot be sent to the phone
+	MessageFailureCodeFcmTokenInvalid = MessageFailureCode("FCM_TOKEN_INVALID")
+
+	// MessageFailureCodePhoneNotFound is used when the phone of the message does not exist
+	MessageFailureCodePhoneNotFound = MessageFailureCode("PHONE_NOT_FOUND")
+
+	// MessageFailureCodePhoneOffline is used when the phone did not fetch the message before the message expiration
+	MessageFailureCodePhoneOffline = MessageFailureCode("PHONE_OFFLINE")
+
+	// MessageFailureCodeExpired is used when the phone fetched the message but did not send it before the message expiration
+	// or when the message was not sent before its expires_at time.
+	MessageFailureCodeExpired = MessageFailureCode("EXPIRED")
+
+	// MessageFailureCodeCampaignCanceled is used when the campaign of the message was canceled before the message was sent
+	MessageFailureCodeCampaignCanceled = MessageFailureCode("CAMPAIGN_CANCELED")
+
+	// MessageFailureCodeUnknown is used for all the other failures
+	MessageFailureCodeUnknown = MessageFailureCode("UNKNOWN")
+)
+
+// MessageFailureCodes are all the canonical failure codes
+var MessageFailureCodes = []MessageFailureCode{
+	MessageFailureCodeGenericFailure,
+	MessageFailureCodeNoService,
+	MessageFailureCodeNullPDU,
+	MessageFailureCodeRadioOff,
+	MessageFailureCodeLimitExceeded,
+	MessageFailureCodeSendingDisabled,
+	MessageFailureCodeEncryptionError,
+	MessageFailureCodeFcmTokenInvalid,
+	MessageFailureCodePhoneNotFound,
+	MessageFailureCodePhoneOffline,
+	MessageFailureCodeExpired,
+	MessageFailureCodeCampaignCanceled,
+	MessageFailureCodeUnknown,
+}
+
+// String converts the MessageFailureCode to a string
+func (code MessageFailureCode) String() string {
+	return string(code)
+}
+
+// IsValid checks if the MessageFailureCode is one of the canonical failure codes
+func (code MessageFailureCode) IsValid() bool {
+	for _, value := range MessageFailureCodes {
+		if code == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyFailure maps the error message reported by the mobile app when a message fails to a MessageFailureCode
+func ClassifyFailure(errorMessage string) MessageFailureCode {
+	code := MessageFailureCode(strings.ToUpper(strings.TrimSpace(errorMessage)))
+	if code.IsValid() {
+		return code
+	}
+
+	lower := strings.ToLower(errorMessage)
+	switch {
+	case strings.Contains(lower, "outgoing messages have been disabled"):
+		return MessageFailureCodeSendingDisabled
+	case strings.Contains(lower, "encrypt"):
+		return MessageFailureCodeEncryptionError
+	case strings.Contains(lower, "fcm token"), strings.Contains(lower, "reinstall the httpsms app"):
+		return MessageFailureCodeFcmTokenInvalid
+	default:
+		return MessageFailureCodeUnknown
+	}
+}
+
+// MessageFailureCodeStrings converts a list of MessageFailureCode to strings
+func MessageFailureCodeStrings(codes []MessageFailureCode) []string {
+	result := make([]string, 0, len(codes))
+	for _, code := range codes {
+		result = append(result, code.String())
+	}
+	return result
+}
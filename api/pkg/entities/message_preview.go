@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessagePreview is the result of a dry run of sending a message. The message is neither stored nor sent.
+type MessagePreview struct {
+	Owner   string `json:"owner" example:"+18005550199"`
+	Contact string `json:"contact" example:"+18005550100"`
+
+	// Content is the content which will be sent after applying the GSM-7 normalization
+	Content string `json:"content" example:"This is a sample text message"`
+
+	// Parts are the contents of the linked messages when the message is split
+	Parts []string `json:"parts" example:"(1/2) This is a sample,(2/2) text message"`
+
+	SIM             SIM                    `json:"sim" example:"SIM1"`
+	Encoding        MessageEncoding        `json:"encoding" example:"GSM-7"`
+	Segments        uint                   `json:"segments" example:"1"`
+	Normalizations  []MessageNormalization `json:"normalizations"`
+	MaxSendAttempts uint                   `json:"max_send_attempts" example:"2"`
+	ExpiresAt       *time.Time             `json:"expires_at" example:"2022-06-05T14:36:09.527976+03:00"`
+
+	// EstimatedSendTime is when the message is expected to be sent to the phone based on the send_at time, the quiet hours and the queue of the phone
+	EstimatedSendTime time.Time `json:"estimated_send_time" example:"2022-06-05T14:26:09.527976+03:00"`
+
+	// DuplicateOf is the ID of the original message when the message will be collapsed into a recent message with the same content
+	DuplicateOf *uuid.UUID `json:"duplicate_of" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+
+	// Error is the reason why the message cannot be sent when previewing messages in bulk
+	Error *string `json:"error" example:"the contact [+18005550100] opted out of receiving messages"`
+}
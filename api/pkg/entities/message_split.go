@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// SplitMessage splits the content at word boundaries into parts which each fit in a single SMS segment including the "(1/3) " prefix.
+// The content is returned as a single part without a prefix when it already fits in a single SMS segment.
+func SplitMessage(content string) []string {
+	encoding, segments := CalculateSegments(content)
+	if segments <= 1 {
+		return []string{content}
+	}
+
+	limit := gsm7SingleSegmentLength
+	length := func(value string) int {
+		septets, _ := gsm7Length(value)
+		return septets
+	}
+	if encoding == MessageEncodingUCS2 {
+		limit = ucs2SingleSegmentLength
+		length = func(value string) int {
+			return len(utf16.Encode([]rune(value)))
+		}
+	}
+
+	count := int(segments)
+	for {
+		parts := splitWords(content, limit-len(splitPrefix(count, count)), length)
+		if len(parts) > count {
+			// the prefix becomes longer when the number of parts has more digits
+			count = len(parts)
+			continue
+		}
+
+		for index, part := range parts {
+			parts[index] = splitPrefix(index+1, len(parts)) + part
+		}
+		return parts
+	}
+}
+
+func splitPrefix(part int, count int) string {
+	return fmt.Sprintf("(%d/%d) ", part, count)
+}
+
+// splitWords splits the content at spaces into parts which are not longer than the limit. Words longer than the limit are split.
+func splitWords(content string, limit int, length func(string) int) []string {
+	var parts []string
+	current := ""
+
+	for _, word := range strings.Split(content, " ") {
+		for length(word) > limit {
+			if strings.TrimSpace(current) != "" {
+				parts = append(parts, strings.TrimSpace(current))
+			}
+			current = ""
+
+			head := splitHead(word, limit, length)
+			parts = append(parts, head)
+			word = word[len(head):]
+		}
+
+		switch {
+		case current == "":
+			current = word
+		case length(current+" "+word) <= limit:
+			current += " " + word
+		default:
+			if strings.TrimSpace(current) != "" {
+				parts = append(parts, strings.TrimSpace(current))
+			}
+			current = word
+		}
+	}
+
+	if strings.TrimSpace(current) != "" {
+		parts = append(parts, strings.TrimSpace(current))
+	}
+
+	return parts
+}
+
+// splitHead returns the longest prefix of the word which is not longer than the limit
+func splitHead(word string, limit int, length func(string) int) string {
+	end := 0
+	for index, character := range word {
+		if length(word[:index+len(string(character))]) > limit {
+			break
+		}
+		end = index + len(string(character))
+	}
+	return word[:end]
+}
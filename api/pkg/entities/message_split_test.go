@@ -0,0 +1,126 @@
+package entities
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitMessage(t *testing.T) {
+	t.Run("content in a single segment is not split", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		content := strings.Repeat("a", 160)
+
+		// Act
+		parts := SplitMessage(content)
+
+		// Assert
+		assert.Equal(t, []string{content}, parts)
+	})
+
+	t.Run("content is split at word boundaries with a prefix", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		word := strings.Repeat("a", 9)
+		content := strings.TrimSpace(strings.Repeat(word+" ", 20))
+
+		// Act
+		parts := SplitMessage(content)
+
+		// Assert
+		assert.Equal(t, 2, len(parts))
+		assert.True(t, strings.HasPrefix(parts[0], "(1/2) "))
+		assert.True(t, strings.HasPrefix(parts[1], "(2/2) "))
+		assert.Equal(t, content, strings.TrimPrefix(parts[0], "(1/2) ")+" "+strings.TrimPrefix(parts[1], "(2/2) "))
+		for _, part := range parts {
+			_, segments := CalculateSegments(part)
+			assert.Equal(t, uint(1), segments)
+			assert.False(t, strings.HasSuffix(part, " "))
+		}
+	})
+
+	t.Run("words longer than a segment are split", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		content := strings.Repeat("a", 400)
+
+		// Act
+		parts := SplitMessage(content)
+
+		// Assert
+		assert.Equal(t, 3, len(parts))
+		joined := ""
+		for index, part := range parts {
+			_, segments := CalculateSegments(part)
+			assert.Equal(t, uint(1), segments)
+			joined += strings.TrimPrefix(part, splitPrefix(index+1, len(parts)))
+		}
+		assert.Equal(t, content, joined)
+	})
+
+	t.Run("UCS-2 content fits in UCS-2 segments", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		content := strings.TrimSpace(strings.Repeat("привет ", 30))
+
+		// Act
+		parts := SplitMessage(content)
+
+		// Assert
+		assert.True(t, len(parts) > 1)
+		for _, part := range parts {
+			encoding, segments := CalculateSegments(part)
+			assert.Equal(t, MessageEncodingUCS2, encoding)
+			assert.Equal(t, uint(1), segments)
+		}
+	})
+
+	t.Run("multi-byte characters are not split", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		content := strings.Repeat("😀", 100)
+
+		// Act
+		parts := SplitMessage(content)
+
+		// Assert
+		joined := ""
+		for index, part := range parts {
+			_, segments := CalculateSegments(part)
+			assert.Equal(t, uint(1), segments)
+			joined += strings.TrimPrefix(part, splitPrefix(index+1, len(parts)))
+		}
+		assert.Equal(t, content, joined)
+	})
+
+	t.Run("the prefix grows with the number of parts", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		content := strings.TrimSpace(strings.Repeat("word ", 400))
+
+		// Act
+		parts := SplitMessage(content)
+
+		// Assert
+		assert.True(t, len(parts) >= 10)
+		assert.True(t, strings.HasPrefix(parts[len(parts)-1], splitPrefix(len(parts), len(parts))))
+		for _, part := range parts {
+			_, segments := CalculateSegments(part)
+			assert.Equal(t, uint(1), segments)
+		}
+	})
+}
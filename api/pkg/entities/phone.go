@@ -1,11 +1,19 @@
 package entities
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// DefaultOptOutKeywords are the opt-out keywords of a new phone
+var DefaultOptOutKeywords = []string{"STOP", "STOPALL", "UNSUBSCRIBE", "CANCEL", "END", "QUIT"}
+
+// DefaultOptInKeywords are the opt-in keywords of a new phone
+var DefaultOptInKeywords = []string{"START", "UNSTOP", "SUBSCRIBE"}
+
 // Phone represents an android phone which has installed the http sms app
 type Phone struct {
 	ID                uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
@@ -22,6 +30,40 @@ type Phone struct {
 
 	MissedCallAutoReply *string `json:"missed_call_auto_reply" example:"This phone cannot receive calls. Please send an SMS instead."`
 
+	// OptOutKeywords add the contact to the suppression list when an inbound message matches one of them
+	OptOutKeywords pq.StringArray `json:"opt_out_keywords" gorm:"type:text[]" swaggertype:"array,string" example:"[STOP,UNSUBSCRIBE]"`
+
+	// OptInKeywords remove the contact from the suppression list when an inbound message matches one of them
+	OptInKeywords pq.StringArray `json:"opt_in_keywords" gorm:"type:text[]" swaggertype:"array,string" example:"[START]"`
+
+	// OptOutReply is sent to the contact after opting out
+	OptOutReply *string `json:"opt_out_reply" example:"You have been unsubscribed and will not receive any more messages. Reply START to subscribe again."`
+
+	// OptInReply is sent to the contact after opting in
+	OptInReply *string `json:"opt_in_reply" example:"You have been subscribed again. Reply STOP to unsubscribe."`
+
+	// BusinessHours are the weekly time windows in the timezone of the user when the phone is in the office
+	BusinessHours []BusinessHours `json:"business_hours" gorm:"type:jsonb;serializer:json"`
+
+	// Holidays are dates in the format YYYY-MM-DD when the phone is out of the office for the whole day
+	Holidays pq.StringArray `json:"holidays" gorm:"type:text[]" swaggertype:"array,string" example:"[2024-12-25]"`
+
+	// RetryPolicy determines when and how messages are sent again after they fail or expire. MaxSendAttempts is used when it is not set.
+	RetryPolicy *RetryPolicy `json:"retry_policy" gorm:"type:jsonb;serializer:json"`
+
+	// NormalizeGSM7 replaces characters like smart quotes with their GSM-7 equivalents by default when sending messages
+	NormalizeGSM7 bool `json:"normalize_gsm7" example:"false"`
+
+	// QuietHoursStart and QuietHoursEnd are a daily time window e.g. 21:00 to 08:00 when outgoing messages are deferred until the end of the window
+	QuietHoursStart *string `json:"quiet_hours_start" example:"21:00"`
+	QuietHoursEnd   *string `json:"quiet_hours_end" example:"08:00"`
+
+	// QuietHoursRecipientTimezone evaluates the quiet hours in the timezone of the recipient inferred from the phone number instead of the timezone of the user
+	QuietHoursRecipientTimezone bool `json:"quiet_hours_recipient_timezone" example:"false"`
+
+	// OutOfOfficeReply is sent once per out-of-office window to contacts who send a message or call outside business hours
+	OutOfOfficeReply *string `json:"out_of_office_reply" example:"We are closed right now. Our business hours are Monday to Friday from 09:00 to 17:00."`
+
 	CreatedAt time.Time `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
 	UpdatedAt time.Time `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
 }
@@ -46,3 +88,40 @@ func (phone *Phone) MaxSendAttemptsSanitized() uint {
 	}
 	return phone.MaxSendAttempts
 }
+
+// IsOptOutKeyword checks if the content of an inbound message is an opt-out keyword.
+// The DefaultOptOutKeywords are used for phones which were created before the keywords could be configured.
+func (phone *Phone) IsOptOutKeyword(content string) bool {
+	if phone.OptOutKeywords == nil {
+		return phone.matchesKeyword(DefaultOptOutKeywords, content)
+	}
+	return phone.matchesKeyword(phone.OptOutKeywords, content)
+}
+
+// IsOptInKeyword checks if the content of an inbound message is an opt-in keyword.
+// The DefaultOptInKeywords are used for phones which were created before the keywords could be configured.
+func (phone *Phone) IsOptInKeyword(content string) bool {
+	if phone.OptInKeywords == nil {
+		return phone.matchesKeyword(DefaultOptInKeywords, content)
+	}
+	return phone.matchesKeyword(phone.OptInKeywords, content)
+}
+
+// NormalizeKeyword trims the whitespace and punctuation around a keyword and converts it to upper case
+func NormalizeKeyword(value string) string {
+	return strings.ToUpper(strings.Trim(value, " \t\r\n.!?,;:\"'"))
+}
+
+func (phone *Phone) matchesKeyword(keywords []string, content string) bool {
+	content = NormalizeKeyword(content)
+	if content == "" {
+		return false
+	}
+
+	for _, keyword := range keywords {
+		if NormalizeKeyword(keyword) == content {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,12 @@ const (
 	PhoneNotificationStatusSent = "sent"
 	// PhoneNotificationStatusFailed is the status when a notification could not be sent.
 	PhoneNotificationStatusFailed = "failed"
+	// PhoneNotificationStatusHeld is the status when a notification was not sent because its campaign is paused
+	PhoneNotificationStatusHeld = "held"
+	// PhoneNotificationStatusReleased is the status when a held notification has been scheduled again
+	PhoneNotificationStatusReleased = "released"
+	// PhoneNotificationStatusDeferred is the status when a notification was scheduled again because it was due in the quiet hours of the phone
+	PhoneNotificationStatusDeferred = "deferred"
 )
 
 // PhoneNotificationStatus is the status of a phone notification
@@ -20,12 +26,19 @@ type PhoneNotificationStatus string
 
 // PhoneNotification represents an FCM notification to a mobile phone
 type PhoneNotification struct {
-	ID          uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;"`
-	MessageID   uuid.UUID `json:"message_id"`
-	UserID      UserID    `json:"user_id"`
-	PhoneID     uuid.UUID `json:"phone_id"`
+	ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;"`
+	MessageID  uuid.UUID  `json:"message_id"`
+	CampaignID *uuid.UUID `json:"campaign_id" gorm:"type:uuid;index"`
+	UserID     UserID     `json:"user_id"`
+	PhoneID    uuid.UUID  `json:"phone_id"`
+	// Contact is the recipient of the message. It is used to apply the quiet hours in the timezone of the recipient when the notification is rescheduled.
+	Contact     string    `json:"contact"`
 	Status      string    `json:"status"`
 	ScheduledAt time.Time `json:"scheduled_at"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	// DeferredUntil is the end of the quiet hours when the notification was deferred
+	DeferredUntil *time.Time `json:"deferred_until"`
+	// ExpiresAt is the time after which the message of the notification should not be sent
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 }
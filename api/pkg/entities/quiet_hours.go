@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// HasQuietHours checks if outgoing messages of the phone are deferred during quiet hours
+func (phone *Phone) HasQuietHours() bool {
+	return phone.QuietHoursStart != nil && strings.TrimSpace(*phone.QuietHoursStart) != "" &&
+		phone.QuietHoursEnd != nil && strings.TrimSpace(*phone.QuietHoursEnd) != "" &&
+		*phone.QuietHoursStart != *phone.QuietHoursEnd
+}
+
+// QuietHoursEndAt returns the end of the quiet hours when the time is inside the quiet hours of the phone.
+// The quiet hours are evaluated in the timezone of the time and they can go over midnight e.g. 21:00 to 08:00
+func (phone *Phone) QuietHoursEndAt(timestamp time.Time) *time.Time {
+	if !phone.HasQuietHours() {
+		return nil
+	}
+
+	start, err := time.Parse("15:04", *phone.QuietHoursStart)
+	if err != nil {
+		return nil
+	}
+
+	end, err := time.Parse("15:04", *phone.QuietHoursEnd)
+	if err != nil {
+		return nil
+	}
+
+	minutes := timestamp.Hour()*60 + timestamp.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+
+	endOfToday := time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), end.Hour(), end.Minute(), 0, 0, timestamp.Location())
+	switch {
+	case startMinutes < endMinutes && minutes >= startMinutes && minutes < endMinutes:
+		return &endOfToday
+	case startMinutes > endMinutes && minutes < endMinutes:
+		return &endOfToday
+	case startMinutes > endMinutes && minutes >= startMinutes:
+		endOfTomorrow := endOfToday.AddDate(0, 0, 1)
+		return &endOfTomorrow
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// maxRetryDelay is the longest delay between 2 attempts of sending a message
+const maxRetryDelay = 24 * time.Hour
+
+// RetryPolicy determines when and how a message is sent again after it fails or expires
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the message is sent to the phone including the first attempt
+	MaxAttempts uint `json:"max_attempts" example:"3"`
+
+	// RetryOn are the failure codes which are retried e.g. PHONE_OFFLINE, EXPIRED, GENERIC_FAILURE, NO_SERVICE or RADIO_OFF
+	RetryOn []string `json:"retry_on" example:"PHONE_OFFLINE,EXPIRED,NO_SERVICE,RADIO_OFF"`
+
+	// InitialDelaySeconds is the delay before the first retry
+	InitialDelaySeconds uint `json:"initial_delay_seconds" example:"30"`
+
+	// BackoffMultiplier multiplies the delay after each retry. A multiplier of 1 uses the same delay for every retry.
+	BackoffMultiplier float64 `json:"backoff_multiplier" example:"2"`
+
+	// MaxDelaySeconds is the maximum delay between retries. A value of 0 means there is no maximum.
+	MaxDelaySeconds uint `json:"max_delay_seconds" example:"600"`
+
+	// AlternateSIM sends each retry with the other SIM card of the phone
+	AlternateSIM bool `json:"alternate_sim" example:"false"`
+}
+
+// DefaultRetryPolicy is the policy used when neither the phone nor the request has a RetryPolicy.
+// Expired messages are retried immediately with the same SIM.
+func DefaultRetryPolicy(maxAttempts uint) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		RetryOn:     MessageFailureCodeStrings([]MessageFailureCode{MessageFailureCodePhoneOffline, MessageFailureCodeExpired}),
+	}
+}
+
+// IsRetriable checks if the failure code can be retried
+func (policy *RetryPolicy) IsRetriable(code MessageFailureCode) bool {
+	for _, value := range policy.RetryOn {
+		if strings.EqualFold(value, code.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the backoff delay before the next attempt when the message has already been attempted the given number of times
+func (policy *RetryPolicy) Delay(attempts uint) time.Duration {
+	if policy.InitialDelaySeconds == 0 || attempts == 0 {
+		return 0
+	}
+
+	multiplier := math.Max(policy.BackoffMultiplier, 1)
+	seconds := float64(policy.InitialDelaySeconds) * math.Pow(multiplier, float64(attempts-1))
+	if policy.MaxDelaySeconds > 0 {
+		seconds = math.Min(seconds, float64(policy.MaxDelaySeconds))
+	}
+	seconds = math.Min(seconds, maxRetryDelay.Seconds())
+
+	return time.Duration(seconds * float64(time.Second))
+}
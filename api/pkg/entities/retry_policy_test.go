@@ -0,0 +1,206 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	t.Run("expired and offline messages are retried immediately", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Act
+		policy := DefaultRetryPolicy(3)
+
+		// Assert
+		assert.Equal(t, uint(3), policy.MaxAttempts)
+		assert.True(t, policy.IsRetriable(MessageFailureCodeExpired))
+		assert.True(t, policy.IsRetriable(MessageFailureCodePhoneOffline))
+		assert.False(t, policy.IsRetriable(MessageFailureCodeNoService))
+		assert.Equal(t, time.Duration(0), policy.Delay(1))
+		assert.False(t, policy.AlternateSIM)
+	})
+}
+
+func TestRetryPolicy_IsRetriable(t *testing.T) {
+	t.Run("failure codes are compared case-insensitively", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		policy := &RetryPolicy{RetryOn: []string{"no_service", "RADIO_OFF"}}
+
+		// Act & Assert
+		assert.True(t, policy.IsRetriable(MessageFailureCodeNoService))
+		assert.True(t, policy.IsRetriable(MessageFailureCodeRadioOff))
+		assert.False(t, policy.IsRetriable(MessageFailureCodeGenericFailure))
+	})
+
+	t.Run("a policy without failure codes is never retried", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		policy := &RetryPolicy{MaxAttempts: 5}
+
+		// Act & Assert
+		assert.False(t, policy.IsRetriable(MessageFailureCodeExpired))
+	})
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		attempts uint
+		delay    time.Duration
+	}{
+		{
+			name:     "no delay before the first attempt",
+			policy:   RetryPolicy{InitialDelaySeconds: 30, BackoffMultiplier: 2},
+			attempts: 0,
+			delay:    0,
+		},
+		{
+			name:     "no delay without an initial delay",
+			policy:   RetryPolicy{BackoffMultiplier: 2},
+			attempts: 3,
+			delay:    0,
+		},
+		{
+			name:     "initial delay after the first attempt",
+			policy:   RetryPolicy{InitialDelaySeconds: 30, BackoffMultiplier: 2},
+			attempts: 1,
+			delay:    30 * time.Second,
+		},
+		{
+			name:     "delay grows with the backoff multiplier",
+			policy:   RetryPolicy{InitialDelaySeconds: 30, BackoffMultiplier: 2},
+			attempts: 3,
+			delay:    120 * time.Second,
+		},
+		{
+			name:     "fractional backoff multiplier",
+			policy:   RetryPolicy{InitialDelaySeconds: 10, BackoffMultiplier: 1.5},
+			attempts: 2,
+			delay:    15 * time.Second,
+		},
+		{
+			name:     "multiplier below 1 keeps the same delay",
+			policy:   RetryPolicy{InitialDelaySeconds: 30, BackoffMultiplier: 0.5},
+			attempts: 4,
+			delay:    30 * time.Second,
+		},
+		{
+			name:     "delay is capped by the max delay",
+			policy:   RetryPolicy{InitialDelaySeconds: 30, BackoffMultiplier: 2, MaxDelaySeconds: 100},
+			attempts: 5,
+			delay:    100 * time.Second,
+		},
+		{
+			name:     "delay is capped at 24 hours without a max delay",
+			policy:   RetryPolicy{InitialDelaySeconds: 3600, BackoffMultiplier: 10},
+			attempts: 10,
+			delay:    24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			t.Parallel()
+
+			// Act
+			delay := tt.policy.Delay(tt.attempts)
+
+			// Assert
+			assert.Equal(t, tt.delay, delay)
+		})
+	}
+}
+
+func TestMessage_CanBeRetried(t *testing.T) {
+	t.Run("uses the default policy when the message has no policy", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		message := &Message{SendAttemptCount: 1, MaxSendAttempts: 2}
+
+		// Act & Assert
+		assert.True(t, message.CanBeRetried(MessageFailureCodeExpired))
+		assert.False(t, message.CanBeRetried(MessageFailureCodeNoService))
+	})
+
+	t.Run("is not retried when all attempts are used", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		message := &Message{SendAttemptCount: 2, MaxSendAttempts: 2}
+
+		// Act & Assert
+		assert.False(t, message.CanBeRetried(MessageFailureCodeExpired))
+	})
+
+	t.Run("is not retried when the validity period has lapsed", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		expiresAt := time.Now().UTC().Add(-time.Minute)
+		message := &Message{SendAttemptCount: 1, MaxSendAttempts: 3, ExpiresAt: &expiresAt}
+
+		// Act & Assert
+		assert.False(t, message.CanBeRetried(MessageFailureCodeExpired))
+	})
+
+	t.Run("uses the failure codes of the message policy", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		message := &Message{
+			SendAttemptCount: 1,
+			MaxSendAttempts:  3,
+			RetryPolicy:      &RetryPolicy{RetryOn: []string{MessageFailureCodeNoService.String()}},
+		}
+
+		// Act & Assert
+		assert.True(t, message.CanBeRetried(MessageFailureCodeNoService))
+		assert.False(t, message.CanBeRetried(MessageFailureCodeExpired))
+	})
+}
+
+func TestMessage_ScheduleRetry(t *testing.T) {
+	t.Run("switches the SIM when the policy alternates SIM cards", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		nextAttemptAt := time.Now().UTC().Add(time.Minute)
+		message := &Message{SIM: SIM1, RetryPolicy: &RetryPolicy{AlternateSIM: true}}
+
+		// Act
+		message.ScheduleRetry(nextAttemptAt)
+
+		// Assert
+		assert.Equal(t, SIM2, message.SIM)
+		assert.Equal(t, nextAttemptAt, *message.NextAttemptAt)
+	})
+
+	t.Run("keeps the SIM with the default policy", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Act
+		message := (&Message{SIM: SIM1}).ScheduleRetry(time.Now().UTC())
+
+		// Assert
+		assert.Equal(t, SIM1, message.SIM)
+	})
+}
@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Slack stores the slack integration of a user
+type Slack struct {
+	ID                uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	UserID            UserID    `json:"user_id" gorm:"index" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	Name              string    `json:"name" example:"Support Team"`
+	TeamID            string    `json:"team_id" gorm:"uniqueIndex:idx_slacks_team_id" example:"T0123ABCDEF"`
+	IncomingChannelID string    `json:"incoming_channel_id" example:"C0123ABCDEF"`
+	BotToken          string    `json:"-"`
+	CreatedAt         time.Time `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	UpdatedAt         time.Time `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
+}
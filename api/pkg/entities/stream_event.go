@@ -0,0 +1,11 @@
+package entities
+
+import "encoding/json"
+
+// StreamEvent is a CloudEvent in the realtime event stream of a user
+type StreamEvent struct {
+	// ID is the position of the event in the stream. It is used to resume the stream with the Last-Event-ID header.
+	ID    string          `json:"id" example:"1717596362302-0"`
+	Type  string          `json:"type" example:"message.phone.received"`
+	Event json.RawMessage `json:"event" swaggertype:"object"`
+}
@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Suppression is a contact which has opted out of receiving messages from a user
+type Suppression struct {
+	ID      uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	UserID  UserID    `json:"user_id" gorm:"uniqueIndex:idx_suppressions__user_id__contact" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	Contact string    `json:"contact" gorm:"uniqueIndex:idx_suppressions__user_id__contact" example:"+18005550100"`
+
+	// Owner is the phone number which received the opt-out message. It is nil when the contact was added with the API
+	Owner *string `json:"owner" example:"+18005550199"`
+
+	// Keyword is the opt-out keyword sent by the contact. It is nil when the contact was added with the API
+	Keyword *string `json:"keyword" example:"STOP"`
+
+	CreatedAt time.Time `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	UpdatedAt time.Time `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
+}
@@ -70,24 +70,42 @@ const SubscriptionName200KMonthly = SubscriptionName("200k-monthly")
 // SubscriptionName20KYearly represents a yearly 20k subscription
 const SubscriptionName20KYearly = SubscriptionName("20k-yearly")
 
+// DuplicateMessageAction is the action taken when the same content is sent to the same contact within the DuplicateMessageWindowSeconds of a user
+type DuplicateMessageAction string
+
+const (
+	// DuplicateMessageActionBlock rejects the duplicate message
+	DuplicateMessageActionBlock = DuplicateMessageAction("block")
+
+	// DuplicateMessageActionCollapse returns the original message instead of sending the duplicate message
+	DuplicateMessageActionCollapse = DuplicateMessageAction("collapse")
+)
+
 // User stores information about a user
 type User struct {
-	ID                               UserID           `json:"id" gorm:"primaryKey;type:string;" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
-	Email                            string           `json:"email" example:"name@email.com"`
-	APIKey                           string           `json:"api_key" gorm:"uniqueIndex:idx_users_api_key" example:"x-api-key"`
-	Timezone                         string           `json:"timezone" example:"Europe/Helsinki" gorm:"default:Africa/Accra"`
-	ActivePhoneID                    *uuid.UUID       `json:"active_phone_id" gorm:"type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
-	SubscriptionName                 SubscriptionName `json:"subscription_name" example:"free"`
-	SubscriptionID                   *string          `json:"subscription_id" example:"8f9c71b8-b84e-4417-8408-a62274f65a08"`
-	SubscriptionStatus               *string          `json:"subscription_status" example:"on_trial"`
-	SubscriptionRenewsAt             *time.Time       `json:"subscription_renews_at" example:"2022-06-05T14:26:02.302718+03:00"`
-	SubscriptionEndsAt               *time.Time       `json:"subscription_ends_at" example:"2022-06-05T14:26:02.302718+03:00"`
-	NotificationMessageStatusEnabled bool             `json:"notification_message_status_enabled" gorm:"default:true" example:"true"`
-	NotificationWebhookEnabled       bool             `json:"notification_webhook_enabled" gorm:"default:true" example:"true"`
-	NotificationHeartbeatEnabled     bool             `json:"notification_heartbeat_enabled" gorm:"default:true" example:"true"`
-	NotificationNewsletterEnabled    bool             `json:"notification_newsletter_enabled" gorm:"default:true" example:"true"`
-	CreatedAt                        time.Time        `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
-	UpdatedAt                        time.Time        `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
+	ID                                  UserID                 `json:"id" gorm:"primaryKey;type:string;" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	Email                               string                 `json:"email" example:"name@email.com"`
+	APIKey                              string                 `json:"api_key" gorm:"uniqueIndex:idx_users_api_key" example:"x-api-key"`
+	Timezone                            string                 `json:"timezone" example:"Europe/Helsinki" gorm:"default:Africa/Accra"`
+	ActivePhoneID                       *uuid.UUID             `json:"active_phone_id" gorm:"type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	SubscriptionName                    SubscriptionName       `json:"subscription_name" example:"free"`
+	SubscriptionID                      *string                `json:"subscription_id" example:"8f9c71b8-b84e-4417-8408-a62274f65a08"`
+	SubscriptionStatus                  *string                `json:"subscription_status" example:"on_trial"`
+	SubscriptionRenewsAt                *time.Time             `json:"subscription_renews_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	SubscriptionEndsAt                  *time.Time             `json:"subscription_ends_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	NotificationMessageStatusEnabled    bool                   `json:"notification_message_status_enabled" gorm:"default:true" example:"true"`
+	NotificationWebhookEnabled          bool                   `json:"notification_webhook_enabled" gorm:"default:true" example:"true"`
+	NotificationHeartbeatEnabled        bool                   `json:"notification_heartbeat_enabled" gorm:"default:true" example:"true"`
+	NotificationNewsletterEnabled       bool                   `json:"notification_newsletter_enabled" gorm:"default:true" example:"true"`
+	DuplicateMessageWindowSeconds       uint                   `json:"duplicate_message_window_seconds" gorm:"default:0" example:"60"`
+	DuplicateMessageAction              DuplicateMessageAction `json:"duplicate_message_action" gorm:"default:block" example:"block"`
+	ContactRateLimit                    uint                   `json:"contact_rate_limit" gorm:"default:0" example:"10"`
+	ContactRateLimitWindowSeconds       uint                   `json:"contact_rate_limit_window_seconds" gorm:"default:3600" example:"3600"`
+	CallbackSigningKey                  *string                `json:"callback_signing_key" example:"DGW8NwQp7mxKaSZ72Xq9v67SLqSbWQvckzzmK8D6rvd7NywSEkdMJtuxKyEkYnCY"`
+	PreviousCallbackSigningKey          *string                `json:"previous_callback_signing_key" example:"Kq9v67SLqSbWQvckzzmK8D6rvd7NywSEkdMJtuxKyEkYnCYDGW8NwQp7mxKaSZ72X"`
+	PreviousCallbackSigningKeyExpiresAt *time.Time             `json:"previous_callback_signing_key_expires_at" example:"2022-06-06T14:26:02.302718+03:00"`
+	CreatedAt                           time.Time              `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	UpdatedAt                           time.Time              `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
 }
 
 // IsOnProPlan checks if a user is on the pro plan
@@ -127,3 +145,30 @@ func (user User) Location() *time.Location {
 	}
 	return location
 }
+
+// DuplicateMessageWindow is the duration in which the same content cannot be sent to the same contact
+func (user User) DuplicateMessageWindow() time.Duration {
+	return time.Duration(user.DuplicateMessageWindowSeconds) * time.Second
+}
+
+// CallbackWebhook is the entities.Webhook used to send the events of a message to its callback URL. Requests are signed with
+// the WebhookSignatureSchemeHMAC scheme when the user has a CallbackSigningKey.
+func (user User) CallbackWebhook(callbackURL string) *Webhook {
+	webhook := &Webhook{
+		UserID:                      user.ID,
+		URL:                         callbackURL,
+		SignatureScheme:             WebhookSignatureSchemeHMAC,
+		PayloadFormat:               WebhookPayloadFormatCloudEvent,
+		PreviousSigningKey:          user.PreviousCallbackSigningKey,
+		PreviousSigningKeyExpiresAt: user.PreviousCallbackSigningKeyExpiresAt,
+	}
+	if user.CallbackSigningKey != nil {
+		webhook.SigningKey = *user.CallbackSigningKey
+	}
+	return webhook
+}
+
+// ContactRateLimitWindow is the duration in which at most ContactRateLimit messages can be sent to the same contact
+func (user User) ContactRateLimitWindow() time.Duration {
+	return time.Duration(user.ContactRateLimitWindowSeconds) * time.Second
+}
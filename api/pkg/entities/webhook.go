@@ -1,20 +1,142 @@
 package entities
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
+// WebhookSignatureScheme is the method used to authenticate the requests which are sent to a webhook
+type WebhookSignatureScheme string
+
+const (
+	// WebhookSignatureSchemeJWT sends a short-lived JWT signed with the signing key in the Authorization header
+	WebhookSignatureSchemeJWT = WebhookSignatureScheme("jwt")
+
+	// WebhookSignatureSchemeHMAC sends an HMAC-SHA256 signature of the timestamp and the request body in the X-Httpsms-Signature header
+	WebhookSignatureSchemeHMAC = WebhookSignatureScheme("hmac-sha256")
+)
+
+// String converts the WebhookSignatureScheme to a string
+func (scheme WebhookSignatureScheme) String() string {
+	return string(scheme)
+}
+
+// WebhookPayloadFormat is the format of the request body which is sent to a webhook
+type WebhookPayloadFormat string
+
+const (
+	// WebhookPayloadFormatCloudEvent sends the event as a JSON encoded CloudEvent
+	WebhookPayloadFormatCloudEvent = WebhookPayloadFormat("cloudevent")
+
+	// WebhookPayloadFormatDiscord sends the event as a Discord webhook message with an embed
+	WebhookPayloadFormatDiscord = WebhookPayloadFormat("discord")
+
+	// WebhookPayloadFormatSlack sends the event as a Slack incoming webhook message
+	WebhookPayloadFormatSlack = WebhookPayloadFormat("slack")
+
+	// WebhookPayloadFormatTeams sends the event as a Microsoft Teams adaptive card
+	WebhookPayloadFormatTeams = WebhookPayloadFormat("teams")
+
+	// WebhookPayloadFormatForm sends the fields of the event as a form-encoded body
+	WebhookPayloadFormatForm = WebhookPayloadFormat("form")
+
+	// WebhookPayloadFormatTemplate renders the PayloadTemplate of the webhook with Go text/template
+	WebhookPayloadFormatTemplate = WebhookPayloadFormat("template")
+)
+
+// WebhookPayloadFormats are all the supported values of WebhookPayloadFormat
+var WebhookPayloadFormats = []WebhookPayloadFormat{
+	WebhookPayloadFormatCloudEvent,
+	WebhookPayloadFormatDiscord,
+	WebhookPayloadFormatSlack,
+	WebhookPayloadFormatTeams,
+	WebhookPayloadFormatForm,
+	WebhookPayloadFormatTemplate,
+}
+
+// String converts the WebhookPayloadFormat to a string
+func (format WebhookPayloadFormat) String() string {
+	return string(format)
+}
+
+// WebhookPayloadFormatStrings returns the supported payload formats as strings
+func WebhookPayloadFormatStrings() []string {
+	values := make([]string, 0, len(WebhookPayloadFormats))
+	for _, format := range WebhookPayloadFormats {
+		values = append(values, format.String())
+	}
+	return values
+}
+
+// WebhookHTTPMethods are the HTTP methods which can be used to send events to a webhook
+var WebhookHTTPMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch}
+
+// WebhookStatus is the health status of a webhook
+type WebhookStatus string
+
+const (
+	// WebhookStatusActive means events are sent to the webhook
+	WebhookStatusActive = WebhookStatus("active")
+
+	// WebhookStatusDisabled means the webhook failed too many times in a row and events are no longer sent to it
+	WebhookStatusDisabled = WebhookStatus("disabled")
+)
+
+// WebhookDefaultFailureThreshold is the number of consecutive failed events after which a webhook is disabled
+const WebhookDefaultFailureThreshold = 20
+
 // Webhook stores the webhooks of a user
 type Webhook struct {
-	ID           uuid.UUID      `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
-	UserID       UserID         `json:"user_id" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
-	URL          string         `json:"url" example:"https://example.com"`
-	SigningKey   string         `json:"signing_key" example:"DGW8NwQp7mxKaSZ72Xq9v67SLqSbWQvckzzmK8D6rvd7NywSEkdMJtuxKyEkYnCY"`
-	PhoneNumbers pq.StringArray `json:"phone_numbers" example:"[+18005550199,+18005550100]" gorm:"type:text[]" swaggertype:"array,string"`
-	Events       pq.StringArray `json:"events" example:"[message.phone.received]" gorm:"type:text[]" swaggertype:"array,string"`
-	CreatedAt    time.Time      `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
-	UpdatedAt    time.Time      `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
+	ID                          uuid.UUID              `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	UserID                      UserID                 `json:"user_id" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	URL                         string                 `json:"url" example:"https://example.com"`
+	SigningKey                  string                 `json:"signing_key" example:"DGW8NwQp7mxKaSZ72Xq9v67SLqSbWQvckzzmK8D6rvd7NywSEkdMJtuxKyEkYnCY"`
+	SignatureScheme             WebhookSignatureScheme `json:"signature_scheme" gorm:"default:jwt" example:"hmac-sha256"`
+	PreviousSigningKey          *string                `json:"previous_signing_key" example:"Kq9v67SLqSbWQvckzzmK8D6rvd7NywSEkdMJtuxKyEkYnCYDGW8NwQp7mxKaSZ72X"`
+	PreviousSigningKeyExpiresAt *time.Time             `json:"previous_signing_key_expires_at" example:"2022-06-06T14:26:02.302718+03:00"`
+	PhoneNumbers                pq.StringArray         `json:"phone_numbers" example:"[+18005550199,+18005550100]" gorm:"type:text[]" swaggertype:"array,string"`
+	Events                      pq.StringArray         `json:"events" example:"[message.phone.received]" gorm:"type:text[]" swaggertype:"array,string"`
+	PayloadFormat               WebhookPayloadFormat   `json:"payload_format" gorm:"default:cloudevent" example:"cloudevent"`
+	PayloadTemplate             *string                `json:"payload_template" example:"{\"text\": \"{{ .Data.content }}\"}"`
+	HTTPMethod                  string                 `json:"http_method" gorm:"default:POST" example:"POST"`
+	Headers                     map[string]string      `json:"headers" gorm:"type:jsonb;serializer:json" swaggertype:"object,string" example:"X-Api-Key:secret"`
+	Filter                      *WebhookFilter         `json:"filter" gorm:"type:jsonb;serializer:json"`
+	Status                      WebhookStatus          `json:"status" gorm:"default:active" example:"active"`
+	FailureThreshold            uint                   `json:"failure_threshold" gorm:"default:20" example:"20"`
+	ConsecutiveFailures         uint                   `json:"consecutive_failures" gorm:"default:0" example:"0"`
+	LastSuccessAt               *time.Time             `json:"last_success_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	LastFailureAt               *time.Time             `json:"last_failure_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	DisabledAt                  *time.Time             `json:"disabled_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	CreatedAt                   time.Time              `json:"created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+	UpdatedAt                   time.Time              `json:"updated_at" example:"2022-06-05T14:26:10.303278+03:00"`
+}
+
+// SigningKeys returns the active signing keys of the webhook. The previous key is included until its overlap period expires.
+func (webhook *Webhook) SigningKeys(now time.Time) []string {
+	keys := []string{webhook.SigningKey}
+	if webhook.PreviousSigningKey != nil && webhook.PreviousSigningKeyExpiresAt != nil && webhook.PreviousSigningKeyExpiresAt.After(now) {
+		keys = append(keys, *webhook.PreviousSigningKey)
+	}
+	return keys
+}
+
+// UsesHMACSignature checks if the requests to the webhook are signed with the WebhookSignatureSchemeHMAC scheme
+func (webhook *Webhook) UsesHMACSignature() bool {
+	return webhook.SignatureScheme == WebhookSignatureSchemeHMAC
+}
+
+// IsDisabled checks if the webhook was disabled after failing too many times in a row
+func (webhook *Webhook) IsDisabled() bool {
+	return webhook.Status == WebhookStatusDisabled
+}
+
+// Method returns the HTTP method used to send events to the webhook
+func (webhook *Webhook) Method() string {
+	if webhook.HTTPMethod == "" {
+		return http.MethodPost
+	}
+	return webhook.HTTPMethod
 }
@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookDeliveryStatus is the result of sending an event to a webhook
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryStatusSucceeded means the webhook responded with a 2xx or 3xx status code
+	WebhookDeliveryStatusSucceeded = WebhookDeliveryStatus("succeeded")
+
+	// WebhookDeliveryStatusRetrying means the attempt failed and the event will be sent again
+	WebhookDeliveryStatusRetrying = WebhookDeliveryStatus("retrying")
+
+	// WebhookDeliveryStatusFailed means the attempt failed and the event will not be sent again
+	WebhookDeliveryStatusFailed = WebhookDeliveryStatus("failed")
+)
+
+// WebhookDelivery is a single attempt of sending an event to an entities.Webhook
+type WebhookDelivery struct {
+	ID                  uuid.UUID             `json:"id" gorm:"primaryKey;type:uuid;" example:"32343a19-da5e-4b1b-a767-3298a73703cb"`
+	WebhookID           uuid.UUID             `json:"webhook_id" gorm:"type:uuid;index:idx_webhook_deliveries__webhook_id" example:"32343a19-da5e-4b1b-a767-3298a73703ca"`
+	UserID              UserID                `json:"user_id" gorm:"index:idx_webhook_deliveries__user_id" example:"WB7DRDWrJZRGbYrv2CKGkqbzvqdC"`
+	EventID             string                `json:"event_id" example:"32343a19-da5e-4b1b-a767-3298a73703cc"`
+	EventType           string                `json:"event_type" example:"message.phone.received"`
+	Owner               string                `json:"owner" example:"+18005550199"`
+	URL                 string                `json:"url" example:"https://example.com"`
+	Attempt             uint                  `json:"attempt" example:"1"`
+	Status              WebhookDeliveryStatus `json:"status" example:"succeeded"`
+	RequestBody         string                `json:"request_body" example:"{\"type\":\"message.phone.received\"}"`
+	ResponseStatusCode  *int                  `json:"response_status_code" example:"200"`
+	ResponseBody        *string               `json:"response_body" example:"OK"`
+	ErrorMessage        *string               `json:"error_message" example:"Internal Server Error"`
+	LatencyMilliseconds int64                 `json:"latency_milliseconds" example:"153"`
+	NextAttemptAt       *time.Time            `json:"next_attempt_at" example:"2022-06-05T14:26:32.302718+03:00"`
+	RedeliveryOf        *uuid.UUID            `json:"redelivery_of" gorm:"type:uuid" example:"32343a19-da5e-4b1b-a767-3298a73703cd"`
+	Event               string                `json:"-"`
+	CreatedAt           time.Time             `json:"created_at" gorm:"index:idx_webhook_deliveries__created_at" example:"2022-06-05T14:26:02.302718+03:00"`
+}
+
+// IsCallback checks if the event was sent to the callback URL of a message instead of an entities.Webhook
+func (delivery *WebhookDelivery) IsCallback() bool {
+	return delivery.WebhookID == uuid.Nil
+}
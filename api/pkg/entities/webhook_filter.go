@@ -0,0 +1,136 @@
+package entities
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// webhookFilterPatternsLimit is the maximum number of compiled contact patterns which are cached
+const webhookFilterPatternsLimit = 1000
+
+// webhookFilterPatterns caches the compiled ContactPattern of filters because webhooks are loaded for every event.
+// An invalid pattern is cached as nil.
+var webhookFilterPatterns = struct {
+	sync.RWMutex
+	patterns map[string]*regexp.Regexp
+}{patterns: map[string]*regexp.Regexp{}}
+
+// WebhookFilter restricts the events which are sent to a webhook using the payload of the event.
+// All the conditions which are set must match, and an event without a field which is used by a condition does not match.
+type WebhookFilter struct {
+	// ContactPrefixes matches events where the contact starts with one of the prefixes e.g. +1800
+	ContactPrefixes []string `json:"contact_prefixes" example:"+1800,MYBANK"`
+
+	// ContactPattern is a regular expression which must match the contact of the event
+	ContactPattern *string `json:"contact_pattern" example:"^\\+1800[0-9]+$"`
+
+	// ContentKeywords matches events where the content contains one of the keywords. The comparison is case-insensitive.
+	ContentKeywords []string `json:"content_keywords" example:"OTP,code"`
+
+	// SIM matches events which were sent or received with the SIM card e.g. SIM1
+	SIM *SIM `json:"sim" example:"SIM1"`
+
+	// MessageType matches events of messages with the type e.g. mobile-originated
+	MessageType *MessageType `json:"message_type" example:"mobile-originated"`
+
+	// Encrypted matches events where the content of the message is encrypted or not
+	Encrypted *bool `json:"encrypted" example:"false"`
+}
+
+// WebhookFilterSubject contains the fields of an event which are used by a WebhookFilter. Fields which are not in the event are nil.
+type WebhookFilterSubject struct {
+	Contact     *string
+	Content     *string
+	SIM         *SIM
+	MessageType *MessageType
+	Encrypted   *bool
+}
+
+// IsEmpty checks if the filter has no conditions
+func (filter *WebhookFilter) IsEmpty() bool {
+	return filter == nil || (len(filter.ContactPrefixes) == 0 &&
+		filter.ContactPattern == nil &&
+		len(filter.ContentKeywords) == 0 &&
+		filter.SIM == nil &&
+		filter.MessageType == nil &&
+		filter.Encrypted == nil)
+}
+
+// Matches checks if all the conditions of the filter match the subject
+func (filter *WebhookFilter) Matches(subject WebhookFilterSubject) bool {
+	if filter.IsEmpty() {
+		return true
+	}
+
+	if len(filter.ContactPrefixes) > 0 && (subject.Contact == nil || !filter.hasPrefix(*subject.Contact)) {
+		return false
+	}
+
+	if filter.ContactPattern != nil {
+		pattern := filter.contactRegexp()
+		if pattern == nil || subject.Contact == nil || !pattern.MatchString(*subject.Contact) {
+			return false
+		}
+	}
+
+	if len(filter.ContentKeywords) > 0 && (subject.Content == nil || !filter.hasKeyword(*subject.Content)) {
+		return false
+	}
+
+	if filter.SIM != nil && (subject.SIM == nil || *subject.SIM != *filter.SIM) {
+		return false
+	}
+
+	if filter.MessageType != nil && (subject.MessageType == nil || *subject.MessageType != *filter.MessageType) {
+		return false
+	}
+
+	if filter.Encrypted != nil && (subject.Encrypted == nil || *subject.Encrypted != *filter.Encrypted) {
+		return false
+	}
+
+	return true
+}
+
+// contactRegexp returns the compiled ContactPattern or nil when the pattern is not a valid regular expression
+func (filter *WebhookFilter) contactRegexp() *regexp.Regexp {
+	webhookFilterPatterns.RLock()
+	pattern, ok := webhookFilterPatterns.patterns[*filter.ContactPattern]
+	webhookFilterPatterns.RUnlock()
+	if ok {
+		return pattern
+	}
+
+	pattern, err := regexp.Compile(*filter.ContactPattern)
+	if err != nil {
+		pattern = nil
+	}
+
+	webhookFilterPatterns.Lock()
+	defer webhookFilterPatterns.Unlock()
+	if len(webhookFilterPatterns.patterns) >= webhookFilterPatternsLimit {
+		webhookFilterPatterns.patterns = map[string]*regexp.Regexp{}
+	}
+	webhookFilterPatterns.patterns[*filter.ContactPattern] = pattern
+	return pattern
+}
+
+func (filter *WebhookFilter) hasPrefix(contact string) bool {
+	for _, prefix := range filter.ContactPrefixes {
+		if strings.HasPrefix(contact, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (filter *WebhookFilter) hasKeyword(content string) bool {
+	content = strings.ToLower(content)
+	for _, keyword := range filter.ContentKeywords {
+		if strings.Contains(content, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
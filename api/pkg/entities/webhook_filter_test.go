@@ -0,0 +1,159 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookFilter_Matches(t *testing.T) {
+	contact := "+18005550100"
+	content := "Your OTP is 1234"
+	sim := SIM1
+	messageType := MessageType(MessageTypeMobileOriginated)
+	encrypted := false
+
+	subject := WebhookFilterSubject{
+		Contact:     &contact,
+		Content:     &content,
+		SIM:         &sim,
+		MessageType: &messageType,
+		Encrypted:   &encrypted,
+	}
+
+	tests := []struct {
+		name    string
+		filter  *WebhookFilter
+		subject WebhookFilterSubject
+		matches bool
+	}{
+		{
+			name:    "nil filter matches every event",
+			filter:  nil,
+			subject: subject,
+			matches: true,
+		},
+		{
+			name:    "empty filter matches an event without fields",
+			filter:  &WebhookFilter{},
+			subject: WebhookFilterSubject{},
+			matches: true,
+		},
+		{
+			name:    "contact prefix matches",
+			filter:  &WebhookFilter{ContactPrefixes: []string{"+44", "+1800"}},
+			subject: subject,
+			matches: true,
+		},
+		{
+			name:    "contact prefix does not match",
+			filter:  &WebhookFilter{ContactPrefixes: []string{"+44"}},
+			subject: subject,
+			matches: false,
+		},
+		{
+			name:    "contact prefix does not match an event without a contact",
+			filter:  &WebhookFilter{ContactPrefixes: []string{"+1800"}},
+			subject: WebhookFilterSubject{},
+			matches: false,
+		},
+		{
+			name:    "contact pattern matches",
+			filter:  &WebhookFilter{ContactPattern: stringPointer(`^\+1800[0-9]+$`)},
+			subject: subject,
+			matches: true,
+		},
+		{
+			name:    "contact pattern does not match",
+			filter:  &WebhookFilter{ContactPattern: stringPointer(`^\+44`)},
+			subject: subject,
+			matches: false,
+		},
+		{
+			name:    "invalid contact pattern does not match",
+			filter:  &WebhookFilter{ContactPattern: stringPointer(`^(\+1800`)},
+			subject: subject,
+			matches: false,
+		},
+		{
+			name:    "content keyword matches case-insensitively",
+			filter:  &WebhookFilter{ContentKeywords: []string{"otp"}},
+			subject: subject,
+			matches: true,
+		},
+		{
+			name:    "content keyword does not match",
+			filter:  &WebhookFilter{ContentKeywords: []string{"invoice"}},
+			subject: subject,
+			matches: false,
+		},
+		{
+			name:    "sim does not match",
+			filter:  &WebhookFilter{SIM: simPointer(SIM2)},
+			subject: subject,
+			matches: false,
+		},
+		{
+			name:    "message type matches",
+			filter:  &WebhookFilter{MessageType: &messageType},
+			subject: subject,
+			matches: true,
+		},
+		{
+			name:    "encrypted does not match an event without the field",
+			filter:  &WebhookFilter{Encrypted: &encrypted},
+			subject: WebhookFilterSubject{Contact: &contact},
+			matches: false,
+		},
+		{
+			name: "all conditions must match",
+			filter: &WebhookFilter{
+				ContactPrefixes: []string{"+1800"},
+				ContentKeywords: []string{"OTP"},
+				SIM:             simPointer(SIM2),
+			},
+			subject: subject,
+			matches: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			t.Parallel()
+
+			// Act
+			matches := tt.filter.Matches(tt.subject)
+
+			// Assert
+			assert.Equal(t, tt.matches, matches)
+		})
+	}
+}
+
+func TestWebhookFilter_contactRegexp(t *testing.T) {
+	t.Run("the compiled pattern is reused", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		filter := &WebhookFilter{ContactPattern: stringPointer(`^\+1555[0-9]+$`)}
+
+		// Act
+		first := filter.contactRegexp()
+		second := (&WebhookFilter{ContactPattern: stringPointer(`^\+1555[0-9]+$`)}).contactRegexp()
+
+		// Assert
+		assert.NotNil(t, first)
+		assert.Same(t, first, second)
+	})
+}
+
+func stringPointer(value string) *string {
+	return &value
+}
+
+func simPointer(value SIM) *SIM {
+	return &value
+}
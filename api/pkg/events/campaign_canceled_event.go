@@ -0,0 +1,19 @@
+package events
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+
+	"github.com/google/uuid"
+)
+
+// EventTypeCampaignCanceled is emitted when a campaign is canceled
+const EventTypeCampaignCanceled = "campaign.canceled"
+
+// CampaignCanceledPayload is the payload of the EventTypeCampaignCanceled event
+type CampaignCanceledPayload struct {
+	CampaignID uuid.UUID       `json:"campaign_id"`
+	UserID     entities.UserID `json:"user_id"`
+	Timestamp  time.Time       `json:"timestamp"`
+}
@@ -0,0 +1,22 @@
+package events
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+
+	"github.com/google/uuid"
+)
+
+// EventTypeCampaignCompleted is emitted when all the messages in a campaign have been processed
+const EventTypeCampaignCompleted = "campaign.completed"
+
+// CampaignCompletedPayload is the payload of the EventTypeCampaignCompleted event
+type CampaignCompletedPayload struct {
+	CampaignID  uuid.UUID              `json:"campaign_id"`
+	UserID      entities.UserID        `json:"user_id"`
+	Owner       string                 `json:"owner"`
+	Name        string                 `json:"name"`
+	Stats       entities.CampaignStats `json:"stats"`
+	CompletedAt time.Time              `json:"completed_at"`
+}
@@ -0,0 +1,22 @@
+package events
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+
+	"github.com/google/uuid"
+)
+
+// EventTypeCampaignMessageUpdated is emitted when the status of a message in a campaign is updated
+const EventTypeCampaignMessageUpdated = "campaign.message.updated"
+
+// CampaignMessageUpdatedPayload is the payload of the EventTypeCampaignMessageUpdated event
+type CampaignMessageUpdatedPayload struct {
+	CampaignID uuid.UUID              `json:"campaign_id"`
+	MessageID  uuid.UUID              `json:"message_id"`
+	UserID     entities.UserID        `json:"user_id"`
+	Owner      string                 `json:"owner"`
+	Status     entities.MessageStatus `json:"status"`
+	Timestamp  time.Time              `json:"timestamp"`
+}
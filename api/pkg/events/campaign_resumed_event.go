@@ -0,0 +1,19 @@
+package events
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+
+	"github.com/google/uuid"
+)
+
+// EventTypeCampaignResumed is emitted when a paused campaign is resumed
+const EventTypeCampaignResumed = "campaign.resumed"
+
+// CampaignResumedPayload is the payload of the EventTypeCampaignResumed event
+type CampaignResumedPayload struct {
+	CampaignID uuid.UUID       `json:"campaign_id"`
+	UserID     entities.UserID `json:"user_id"`
+	Timestamp  time.Time       `json:"timestamp"`
+}
@@ -13,15 +13,25 @@ const EventTypeMessageAPISent = "message.api.sent"
 
 // MessageAPISentPayload is the payload of the EventTypeMessageSent event
 type MessageAPISentPayload struct {
-	MessageID         uuid.UUID       `json:"message_id"`
-	UserID            entities.UserID `json:"user_id"`
-	Owner             string          `json:"owner"`
-	RequestID         *string         `json:"request_id"`
-	MaxSendAttempts   uint            `json:"max_send_attempts"`
-	Contact           string          `json:"contact"`
-	ScheduledSendTime *time.Time      `json:"scheduled_send_time"`
-	RequestReceivedAt time.Time       `json:"request_received_at"`
-	Content           string          `json:"content"`
-	Encrypted         bool            `json:"encrypted"`
-	SIM               entities.SIM    `json:"sim"`
+	MessageID         uuid.UUID                       `json:"message_id"`
+	UserID            entities.UserID                 `json:"user_id"`
+	Owner             string                          `json:"owner"`
+	RequestID         *string                         `json:"request_id"`
+	CallbackURL       *string                         `json:"callback_url"`
+	CampaignID        *uuid.UUID                      `json:"campaign_id"`
+	MaxSendAttempts   uint                            `json:"max_send_attempts"`
+	Contact           string                          `json:"contact"`
+	ScheduledSendTime *time.Time                      `json:"scheduled_send_time"`
+	RequestReceivedAt time.Time                       `json:"request_received_at"`
+	Content           string                          `json:"content"`
+	Encrypted         bool                            `json:"encrypted"`
+	SIM               entities.SIM                    `json:"sim"`
+	Encoding          entities.MessageEncoding        `json:"encoding"`
+	Segments          uint                            `json:"segments"`
+	Normalizations    []entities.MessageNormalization `json:"normalizations"`
+	ParentID          *uuid.UUID                      `json:"parent_id"`
+	PartNumber        uint                            `json:"part_number"`
+	PartCount         uint                            `json:"part_count"`
+	ExpiresAt         *time.Time                      `json:"expires_at"`
+	RetryPolicy       *entities.RetryPolicy           `json:"retry_policy"`
 }
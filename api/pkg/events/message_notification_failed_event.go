@@ -13,10 +13,11 @@ const EventTypeMessageNotificationFailed = "message.notification.failed"
 
 // MessageNotificationFailedPayload is the payload of the EventTypeMessageNotificationFailed event
 type MessageNotificationFailedPayload struct {
-	MessageID            uuid.UUID       `json:"message_id"`
-	UserID               entities.UserID `json:"user_id"`
-	NotificationID       uuid.UUID       `json:"notification_id"`
-	PhoneID              uuid.UUID       `json:"phone_id"`
-	ErrorMessage         string          `json:"error_message"`
-	NotificationFailedAt time.Time       `json:"notification_failed_at"`
+	MessageID            uuid.UUID                   `json:"message_id"`
+	UserID               entities.UserID             `json:"user_id"`
+	NotificationID       uuid.UUID                   `json:"notification_id"`
+	PhoneID              uuid.UUID                   `json:"phone_id"`
+	ErrorMessage         string                      `json:"error_message"`
+	FailureCode          entities.MessageFailureCode `json:"failure_code"`
+	NotificationFailedAt time.Time                   `json:"notification_failed_at"`
 }
@@ -23,4 +23,5 @@ type MessageNotificationScheduledPayload struct {
 	PhoneID        uuid.UUID       `json:"phone_id"`
 	ScheduledAt    time.Time       `json:"scheduled_at"`
 	NotificationID uuid.UUID       `json:"notification_id"`
+	DeferredUntil  *time.Time      `json:"deferred_until"`
 }
@@ -14,8 +14,11 @@ const EventTypeMessageNotificationSend = "message.notification.send"
 // MessageNotificationSendPayload is the payload of the EventTypeMessageNotificationSend event
 type MessageNotificationSendPayload struct {
 	MessageID      uuid.UUID       `json:"id"`
+	CampaignID     *uuid.UUID      `json:"campaign_id"`
 	UserID         entities.UserID `json:"user_id"`
 	PhoneID        uuid.UUID       `json:"phone_id"`
+	Contact        string          `json:"contact"`
 	ScheduledAt    time.Time       `json:"scheduled_at"`
 	NotificationID uuid.UUID       `json:"notification_id"`
+	ExpiresAt      *time.Time      `json:"expires_at"`
 }
@@ -12,13 +12,14 @@ const EventTypeMessagePhoneSending = "message.phone.sending"
 
 // MessagePhoneSendingPayload is the payload of the EventTypeMessageSent event
 type MessagePhoneSendingPayload struct {
-	ID        uuid.UUID       `json:"id"`
-	UserID    entities.UserID `json:"user_id"`
-	RequestID *string         `json:"request_id"`
-	Timestamp time.Time       `json:"timestamp"`
-	Owner     string          `json:"owner"`
-	Encrypted bool            `json:"encrypted"`
-	Contact   string          `json:"contact"`
-	Content   string          `json:"content"`
-	SIM       entities.SIM    `json:"sim"`
+	ID          uuid.UUID       `json:"id"`
+	UserID      entities.UserID `json:"user_id"`
+	RequestID   *string         `json:"request_id"`
+	CallbackURL *string         `json:"callback_url"`
+	Timestamp   time.Time       `json:"timestamp"`
+	Owner       string          `json:"owner"`
+	Encrypted   bool            `json:"encrypted"`
+	Contact     string          `json:"contact"`
+	Content     string          `json:"content"`
+	SIM         entities.SIM    `json:"sim"`
 }
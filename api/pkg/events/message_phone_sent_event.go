@@ -13,13 +13,17 @@ const EventTypeMessagePhoneSent = "message.phone.sent"
 
 // MessagePhoneSentPayload is the payload of the EventTypeMessagePhoneSent event
 type MessagePhoneSentPayload struct {
-	ID        uuid.UUID       `json:"id"`
-	UserID    entities.UserID `json:"user_id"`
-	RequestID *string         `json:"request_id"`
-	Owner     string          `json:"owner"`
-	Contact   string          `json:"contact"`
-	Encrypted bool            `json:"encrypted"`
-	Timestamp time.Time       `json:"timestamp"`
-	Content   string          `json:"content"`
-	SIM       entities.SIM    `json:"sim"`
+	ID          uuid.UUID                `json:"id"`
+	UserID      entities.UserID          `json:"user_id"`
+	RequestID   *string                  `json:"request_id"`
+	CallbackURL *string                  `json:"callback_url"`
+	Owner       string                   `json:"owner"`
+	Contact     string                   `json:"contact"`
+	Encrypted   bool                     `json:"encrypted"`
+	Timestamp   time.Time                `json:"timestamp"`
+	Content     string                   `json:"content"`
+	SIM         entities.SIM             `json:"sim"`
+	Encoding    entities.MessageEncoding `json:"encoding"`
+	Segments    uint                     `json:"segments"`
+	ParentID    *uuid.UUID               `json:"parent_id"`
 }
@@ -13,7 +13,8 @@ const EventTypeMessageSendExpiredCheck = "message.send.expired.check"
 
 // MessageSendExpiredCheckPayload is the payload of the EventTypeMessageSendExpiredCheck event
 type MessageSendExpiredCheckPayload struct {
-	MessageID   uuid.UUID       `json:"message_id"`
-	ScheduledAt time.Time       `json:"scheduled_at"`
-	UserID      entities.UserID `json:"user_id"`
+	MessageID          uuid.UUID       `json:"message_id"`
+	ScheduledAt        time.Time       `json:"scheduled_at"`
+	NotificationSentAt time.Time       `json:"notification_sent_at"`
+	UserID             entities.UserID `json:"user_id"`
 }
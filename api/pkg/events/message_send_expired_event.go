@@ -13,15 +13,20 @@ const EventTypeMessageSendExpired = "message.send.expired"
 
 // MessageSendExpiredPayload is the payload of the EventTypeMessageSendExpired event
 type MessageSendExpiredPayload struct {
-	MessageID        uuid.UUID       `json:"message_id"`
-	Owner            string          `json:"owner"`
-	SendAttemptCount uint            `json:"send_attempt_count"`
-	IsFinal          bool            `json:"is_final"`
-	RequestID        *string         `json:"request_id"`
-	Contact          string          `json:"contact"`
-	Encrypted        bool            `json:"encrypted"`
-	UserID           entities.UserID `json:"user_id"`
-	Timestamp        time.Time       `json:"timestamp"`
-	Content          string          `json:"content"`
-	SIM              entities.SIM    `json:"sim"`
+	MessageID        uuid.UUID                   `json:"message_id"`
+	Owner            string                      `json:"owner"`
+	SendAttemptCount uint                        `json:"send_attempt_count"`
+	IsFinal          bool                        `json:"is_final"`
+	RequestID        *string                     `json:"request_id"`
+	CallbackURL      *string                     `json:"callback_url"`
+	Contact          string                      `json:"contact"`
+	Encrypted        bool                        `json:"encrypted"`
+	UserID           entities.UserID             `json:"user_id"`
+	Timestamp        time.Time                   `json:"timestamp"`
+	Content          string                      `json:"content"`
+	SIM              entities.SIM                `json:"sim"`
+	Encoding         entities.MessageEncoding    `json:"encoding"`
+	Segments         uint                        `json:"segments"`
+	ParentID         *uuid.UUID                  `json:"parent_id"`
+	FailureCode      entities.MessageFailureCode `json:"failure_code"`
 }
@@ -12,14 +12,19 @@ const EventTypeMessageSendFailed = "message.send.failed"
 
 // MessageSendFailedPayload is the payload of the EventTypeMessageSendFailed event
 type MessageSendFailedPayload struct {
-	ID           uuid.UUID       `json:"id"`
-	ErrorMessage string          `json:"error_message"`
-	UserID       entities.UserID `json:"user_id"`
-	Owner        string          `json:"owner"`
-	RequestID    *string         `json:"request_id"`
-	Contact      string          `json:"contact"`
-	Timestamp    time.Time       `json:"timestamp"`
-	Encrypted    bool            `json:"encrypted"`
-	Content      string          `json:"content"`
-	SIM          entities.SIM    `json:"sim"`
+	ID           uuid.UUID                   `json:"id"`
+	ErrorMessage string                      `json:"error_message"`
+	FailureCode  entities.MessageFailureCode `json:"failure_code"`
+	UserID       entities.UserID             `json:"user_id"`
+	Owner        string                      `json:"owner"`
+	RequestID    *string                     `json:"request_id"`
+	CallbackURL  *string                     `json:"callback_url"`
+	Contact      string                      `json:"contact"`
+	Timestamp    time.Time                   `json:"timestamp"`
+	Encrypted    bool                        `json:"encrypted"`
+	Content      string                      `json:"content"`
+	SIM          entities.SIM                `json:"sim"`
+	Encoding     entities.MessageEncoding    `json:"encoding"`
+	Segments     uint                        `json:"segments"`
+	ParentID     *uuid.UUID                  `json:"parent_id"`
 }
@@ -8,17 +8,25 @@ import (
 	"github.com/google/uuid"
 )
 
-// EventTypeMessageSendRetry is emitted when the phone a message expires and is being retried
+// EventTypeMessageSendRetry is emitted when a message expires or fails and is being retried
 const EventTypeMessageSendRetry = "message.send.retry"
 
 // MessageSendRetryPayload is the payload of the EventTypeMessageSendRetry event
 type MessageSendRetryPayload struct {
-	MessageID uuid.UUID       `json:"message_id"`
-	Owner     string          `json:"owner"`
-	Contact   string          `json:"contact"`
-	Encrypted bool            `json:"encrypted"`
-	UserID    entities.UserID `json:"user_id"`
-	Timestamp time.Time       `json:"timestamp"`
-	Content   string          `json:"content"`
-	SIM       entities.SIM    `json:"sim"`
+	MessageID  uuid.UUID       `json:"message_id"`
+	CampaignID *uuid.UUID      `json:"campaign_id"`
+	Owner      string          `json:"owner"`
+	Contact    string          `json:"contact"`
+	Encrypted  bool            `json:"encrypted"`
+	UserID     entities.UserID `json:"user_id"`
+	Timestamp  time.Time       `json:"timestamp"`
+	Content    string          `json:"content"`
+	SIM        entities.SIM    `json:"sim"`
+	ExpiresAt  *time.Time      `json:"expires_at"`
+	// Attempt is the number of the next attempt of sending the message starting from 1 for the first attempt
+	Attempt uint `json:"attempt"`
+	// NextAttemptAt is the time when the message will be sent to the phone again
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// FailureCode is the reason of the retry e.g. PHONE_OFFLINE
+	FailureCode entities.MessageFailureCode `json:"failure_code"`
 }
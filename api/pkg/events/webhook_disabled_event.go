@@ -0,0 +1,23 @@
+package events
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/google/uuid"
+)
+
+// EventTypeWebhookDisabled is emitted when a webhook is disabled after failing too many times in a row
+const EventTypeWebhookDisabled = "webhook.disabled"
+
+// WebhookDisabledPayload is the payload of the EventTypeWebhookDisabled event
+type WebhookDisabledPayload struct {
+	WebhookID           uuid.UUID       `json:"webhook_id"`
+	WebhookURL          string          `json:"webhook_url"`
+	UserID              entities.UserID `json:"user_id"`
+	Owner               string          `json:"owner"`
+	ConsecutiveFailures uint            `json:"consecutive_failures"`
+	EventType           string          `json:"event_type"`
+	ErrorMessage        string          `json:"error_message"`
+	DisabledAt          time.Time       `json:"disabled_at"`
+}
@@ -0,0 +1,18 @@
+package events
+
+import (
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/google/uuid"
+)
+
+// EventTypeWebhookPing is sent to a webhook to check that it can receive events
+const EventTypeWebhookPing = "webhook.ping"
+
+// WebhookPingPayload is the payload of the EventTypeWebhookPing event
+type WebhookPingPayload struct {
+	WebhookID uuid.UUID       `json:"webhook_id"`
+	UserID    entities.UserID `json:"user_id"`
+	Timestamp time.Time       `json:"timestamp"`
+}
@@ -0,0 +1,20 @@
+package events
+
+import (
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/google/uuid"
+)
+
+// EventTypeWebhookSendRetry is emitted when a webhook event failed and is scheduled to be sent again
+const EventTypeWebhookSendRetry = "webhook.send.retry"
+
+// WebhookSendRetryPayload is the payload of the EventTypeWebhookSendRetry event
+type WebhookSendRetryPayload struct {
+	WebhookID    uuid.UUID       `json:"webhook_id"`
+	UserID       entities.UserID `json:"user_id"`
+	Owner        string          `json:"owner"`
+	Event        string          `json:"event"`
+	Attempt      uint            `json:"attempt"`
+	RedeliveryOf *uuid.UUID      `json:"redelivery_of"`
+	CallbackURL  *string         `json:"callback_url"`
+}
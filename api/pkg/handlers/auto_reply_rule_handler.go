@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/NdoleStudio/httpsms/pkg/validators"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+// AutoReplyRuleHandler handles auto reply rule http requests
+type AutoReplyRuleHandler struct {
+	handler
+	logger    telemetry.Logger
+	tracer    telemetry.Tracer
+	service   *services.AutoReplyService
+	validator *validators.AutoReplyRuleHandlerValidator
+}
+
+// NewAutoReplyRuleHandler creates a new AutoReplyRuleHandler
+func NewAutoReplyRuleHandler(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	service *services.AutoReplyService,
+	validator *validators.AutoReplyRuleHandlerValidator,
+) (h *AutoReplyRuleHandler) {
+	return &AutoReplyRuleHandler{
+		logger:    logger.WithService(fmt.Sprintf("%T", h)),
+		tracer:    tracer,
+		service:   service,
+		validator: validator,
+	}
+}
+
+// RegisterRoutes registers the routes for the AutoReplyRuleHandler
+func (h *AutoReplyRuleHandler) RegisterRoutes(app *fiber.App, middlewares ...fiber.Handler) {
+	router := app.Group("/v1/auto-reply-rules")
+	router.Get("/", h.computeRoute(middlewares, h.Index)...)
+	router.Post("/", h.computeRoute(middlewares, h.Store)...)
+	router.Get("/:autoReplyRuleID", h.computeRoute(middlewares, h.Show)...)
+	router.Put("/:autoReplyRuleID", h.computeRoute(middlewares, h.Update)...)
+	router.Delete("/:autoReplyRuleID", h.computeRoute(middlewares, h.Delete)...)
+}
+
+// Index returns the auto reply rules of a user
+// @Summary      Get auto reply rules of a user
+// @Description  Get the auto reply rules of a user sorted by the owner and priority in ascending order.
+// @Security	 ApiKeyAuth
+// @Tags         AutoReplyRules
+// @Accept       json
+// @Produce      json
+// @Param        skip		query  int  	false	"number of auto reply rules to skip"		minimum(0)
+// @Param        query		query  string  	false 	"filter auto reply rules containing query"
+// @Param        limit		query  int  	false	"number of auto reply rules to return"	minimum(1)	maximum(100)
+// @Success      200 		{object}	responses.AutoReplyRulesResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /auto-reply-rules 	[get]
+func (h *AutoReplyRuleHandler) Index(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.AutoReplyRuleIndex
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateIndex(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while fetching auto reply rules [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while fetching auto reply rules")
+	}
+
+	rules, err := h.service.Index(ctx, h.userIDFomContext(c), request.ToIndexParams())
+	if err != nil {
+		msg := fmt.Sprintf("cannot get auto reply rules with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("fetched %d %s", len(rules), h.pluralize("auto reply rule", len(rules))), rules)
+}
+
+// Store an auto reply rule
+// @Summary      Store an auto reply rule
+// @Description  Store an auto reply rule which sends an automatic reply to matching messages received by a phone
+// @Security	 ApiKeyAuth
+// @Tags         AutoReplyRules
+// @Accept       json
+// @Produce      json
+// @Param        payload   	body 		requests.AutoReplyRuleStore  	true "Payload of the auto reply rule request"
+// @Success      201 		{object}	responses.AutoReplyRuleResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /auto-reply-rules [post]
+func (h *AutoReplyRuleHandler) Store(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.AutoReplyRuleStore
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall body [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateStore(ctx, h.userIDFomContext(c), request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while storing auto reply rule [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while storing auto reply rule")
+	}
+
+	rule, err := h.service.Store(ctx, request.ToStoreParams(h.userFromContext(c)))
+	if err != nil {
+		msg := fmt.Sprintf("cannot store auto reply rule with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseCreated(c, "auto reply rule created successfully", rule)
+}
+
+// Show an auto reply rule
+// @Summary      Get an auto reply rule
+// @Description  Get an auto reply rule of the authenticated user
+// @Security	 ApiKeyAuth
+// @Tags         AutoReplyRules
+// @Accept       json
+// @Produce      json
+// @Param 		 autoReplyRuleID	path		string 							true 	"ID of the auto reply rule"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      200 		{object}	responses.AutoReplyRuleResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /auto-reply-rules/{autoReplyRuleID} [get]
+func (h *AutoReplyRuleHandler) Show(c *fiber.Ctx) error {
+	ctx, span, _ := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	rule, response := h.loadAutoReplyRule(ctx, c)
+	if rule == nil {
+		return response
+	}
+
+	return h.responseOK(c, "auto reply rule fetched successfully", rule)
+}
+
+// Update an auto reply rule
+// @Summary      Update an auto reply rule
+// @Description  Update the matching conditions and the reply of an auto reply rule
+// @Security	 ApiKeyAuth
+// @Tags         AutoReplyRules
+// @Accept       json
+// @Produce      json
+// @Param 		 autoReplyRuleID	path		string 							true 	"ID of the auto reply rule"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        payload   	body 		requests.AutoReplyRuleUpdate  	true "Payload of the auto reply rule request"
+// @Success      200 		{object}	responses.AutoReplyRuleResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /auto-reply-rules/{autoReplyRuleID} [put]
+func (h *AutoReplyRuleHandler) Update(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.AutoReplyRuleUpdate
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall body [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	request.AutoReplyRuleID = c.Params("autoReplyRuleID")
+	if errors := h.validator.ValidateUpdate(ctx, h.userIDFomContext(c), request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while updating auto reply rule [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while updating auto reply rule")
+	}
+
+	rule, err := h.service.Update(ctx, request.ToUpdateParams(h.userFromContext(c)))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return h.responseNotFound(c, fmt.Sprintf("cannot find auto reply rule with ID [%s]", request.AutoReplyRuleID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot update auto reply rule with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "auto reply rule updated successfully", rule)
+}
+
+// Delete an auto reply rule
+// @Summary      Delete auto reply rule
+// @Description  Delete an auto reply rule
+// @Security	 ApiKeyAuth
+// @Tags         AutoReplyRules
+// @Accept       json
+// @Produce      json
+// @Param 		 autoReplyRuleID	path		string 							true 	"ID of the auto reply rule"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      204		{object}    responses.NoContent
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /auto-reply-rules/{autoReplyRuleID} [delete]
+func (h *AutoReplyRuleHandler) Delete(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	autoReplyRuleID := c.Params("autoReplyRuleID")
+	if errors := h.validator.ValidateUUID(ctx, autoReplyRuleID, "autoReplyRuleID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while deleting auto reply rule with ID [%s]", spew.Sdump(errors), autoReplyRuleID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while deleting auto reply rule")
+	}
+
+	if err := h.service.Delete(ctx, h.userIDFomContext(c), uuid.MustParse(autoReplyRuleID)); err != nil {
+		msg := fmt.Sprintf("cannot delete auto reply rule with ID [%+#v]", autoReplyRuleID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseNoContent(c, "auto reply rule deleted successfully")
+}
+
+func (h *AutoReplyRuleHandler) loadAutoReplyRule(ctx context.Context, c *fiber.Ctx) (*entities.AutoReplyRule, error) {
+	ctx, span, ctxLogger := h.tracer.StartWithLogger(ctx, h.logger)
+	defer span.End()
+
+	autoReplyRuleID := c.Params("autoReplyRuleID")
+	if errors := h.validator.ValidateUUID(ctx, autoReplyRuleID, "autoReplyRuleID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while loading auto reply rule with ID [%s]", spew.Sdump(errors), autoReplyRuleID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return nil, h.responseUnprocessableEntity(c, errors, "validation errors while loading auto reply rule")
+	}
+
+	rule, err := h.service.Load(ctx, h.userIDFomContext(c), uuid.MustParse(autoReplyRuleID))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return nil, h.responseNotFound(c, fmt.Sprintf("cannot find auto reply rule with ID [%s]", autoReplyRuleID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load auto reply rule with ID [%s]", autoReplyRuleID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return nil, h.responseInternalServerError(c)
+	}
+
+	return rule, nil
+}
@@ -1,10 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
-	"sync"
+	"strings"
 
-	"github.com/NdoleStudio/httpsms/pkg/requests"
 	"github.com/google/uuid"
 
 	"github.com/NdoleStudio/httpsms/pkg/services"
@@ -43,20 +43,25 @@ func NewBulkMessageHandler(
 }
 
 // RegisterRoutes registers the routes for the MessageHandler
-func (h *BulkMessageHandler) RegisterRoutes(router fiber.Router) {
-	router.Post("/bulk-messages", h.Store)
+func (h *BulkMessageHandler) RegisterRoutes(router fiber.Router, idempotency fiber.Handler) {
+	router.Post("/bulk-messages", idempotency, h.Store)
 }
 
 // Store sends bulk SMS messages from a CSV file.
 // @Summary      Store bulk SMS file
-// @Description  Sends bulk SMS messages to multiple users from a CSV file.
+// @Description  Sends bulk SMS messages to multiple users from a CSV file. When dry_run is true, the response contains a preview of each message and nothing is stored or sent.
 // @Security	 ApiKeyAuth
 // @Tags         BulkSMS
-// @Accept       json
+// @Accept       multipart/form-data
 // @Produce      json
+// @Param        document		formData	file	true	"The CSV or Excel file containing the messages to send"
+// @Param        campaign_id	formData	string	false	"ID of the campaign to add the messages to"
+// @Param        dry_run		formData	bool	false	"Validate the file and preview the messages without sending them"
+// @Param        Idempotency-Key	header	string	false	"Unique key to make sure the file is processed only once when the upload is retried"
 // @Success      202 		{object}	responses.NoContent
 // @Failure      400		{object}	responses.BadRequest
 // @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      409		{object}	responses.Conflict
 // @Failure      422		{object}	responses.UnprocessableEntity
 // @Failure      500		{object}	responses.InternalServerError
 // @Router       /bulk-messages [post]
@@ -71,36 +76,53 @@ func (h *BulkMessageHandler) Store(c *fiber.Ctx) error {
 		return h.responseBadRequest(c, err)
 	}
 
-	messages, validationErrors := h.validator.ValidateStore(ctx, h.userIDFomContext(c), file)
+	campaignID := strings.TrimSpace(c.FormValue("campaign_id"))
+	messages, validationErrors := h.validator.ValidateStore(ctx, h.userIDFomContext(c), file, campaignID)
 	if len(validationErrors) != 0 {
 		msg := fmt.Sprintf("validation errors [%s], while sending bulk sms from CSV file [%s] for [%s]", spew.Sdump(validationErrors), file.Filename, h.userIDFomContext(c))
 		ctxLogger.Warn(stacktrace.NewError(msg))
 		return h.responseUnprocessableEntity(c, validationErrors, "validation errors while sending bulk SMS")
 	}
 
-	if msg := h.billingService.IsEntitledWithCount(ctx, h.userIDFomContext(c), uint(len(messages))); msg != nil {
+	requestID := uuid.New()
+	var campaign *uuid.UUID
+	if campaignID != "" {
+		id := uuid.MustParse(campaignID)
+		campaign = &id
+	}
+
+	params := make([]services.MessageSendParams, 0, len(messages))
+	segments := make([]uint, 0, len(messages))
+	for _, message := range messages {
+		params = append(params, message.ToMessageSendParams(h.userIDFomContext(c), requestID, campaign, c.OriginalURL()))
+		segments = append(segments, params[len(params)-1].Segments())
+	}
+
+	if msg := h.billingService.IsEntitledToSend(ctx, h.userIDFomContext(c), segments...); msg != nil {
 		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("user with ID [%s] is not entitled to send [%d] messages", h.userIDFomContext(c), len(messages))))
 		return h.responsePaymentRequired(c, *msg)
 	}
 
-	requestID := uuid.New()
-	wg := sync.WaitGroup{}
-	for _, message := range messages {
-		wg.Add(1)
-		go func(message *requests.BulkMessage) {
-			_, err = h.messageService.SendMessage(
-				ctx,
-				message.ToMessageSendParams(h.userIDFomContext(c), requestID, c.OriginalURL()),
-			)
-
-			if err != nil {
-				msg := fmt.Sprintf("cannot send message with paylod [%s]", c.Body())
-				ctxLogger.Error(stacktrace.Propagate(err, msg))
-			}
-			wg.Done()
-		}(message)
+	if c.FormValue("dry_run") == "true" || c.QueryBool("dry_run") {
+		return h.storeDryRun(ctx, ctxLogger, c, params)
+	}
+
+	if _, err = h.messageService.SendMessages(ctx, params); err != nil {
+		msg := fmt.Sprintf("cannot send [%d] messages from bulk file for user [%s]", len(messages), h.userIDFomContext(c))
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
 	}
 
-	wg.Wait()
 	return h.responseAccepted(c, fmt.Sprintf("Added %d messages to the queue", len(messages)))
 }
+
+func (h *BulkMessageHandler) storeDryRun(ctx context.Context, ctxLogger telemetry.Logger, c *fiber.Ctx, params []services.MessageSendParams) error {
+	previews, err := h.messageService.PreviewMessages(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot preview [%d] messages from bulk file for user [%s]", len(params), h.userIDFomContext(c))
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("dry run completed, [%d] messages were not sent", len(previews)), previews)
+}
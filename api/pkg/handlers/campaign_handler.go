@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/NdoleStudio/httpsms/pkg/validators"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+// CampaignHandler handles campaign http requests
+type CampaignHandler struct {
+	handler
+	logger    telemetry.Logger
+	tracer    telemetry.Tracer
+	service   *services.CampaignService
+	validator *validators.CampaignHandlerValidator
+}
+
+// NewCampaignHandler creates a new CampaignHandler
+func NewCampaignHandler(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	service *services.CampaignService,
+	validator *validators.CampaignHandlerValidator,
+) (h *CampaignHandler) {
+	return &CampaignHandler{
+		logger:    logger.WithService(fmt.Sprintf("%T", h)),
+		tracer:    tracer,
+		service:   service,
+		validator: validator,
+	}
+}
+
+// RegisterRoutes registers the routes for the CampaignHandler
+func (h *CampaignHandler) RegisterRoutes(app *fiber.App, middlewares ...fiber.Handler) {
+	router := app.Group("/v1/campaigns")
+	router.Get("/", h.computeRoute(middlewares, h.Index)...)
+	router.Post("/", h.computeRoute(middlewares, h.Store)...)
+	router.Get("/:campaignID", h.computeRoute(middlewares, h.Show)...)
+	router.Get("/:campaignID/stats", h.computeRoute(middlewares, h.Stats)...)
+	router.Post("/:campaignID/pause", h.computeRoute(middlewares, h.Pause)...)
+	router.Post("/:campaignID/resume", h.computeRoute(middlewares, h.Resume)...)
+	router.Post("/:campaignID/cancel", h.computeRoute(middlewares, h.Cancel)...)
+	router.Delete("/:campaignID", h.computeRoute(middlewares, h.Delete)...)
+}
+
+// Index returns the campaigns of a user
+// @Summary      Get campaigns of a user
+// @Description  Get the campaigns of a user sorted by the created time in descending order.
+// @Security	 ApiKeyAuth
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        skip		query  int  	false	"number of campaigns to skip"		minimum(0)
+// @Param        query		query  string  	false 	"filter campaigns containing query"
+// @Param        limit		query  int  	false	"number of campaigns to return"	minimum(1)	maximum(100)
+// @Success      200 		{object}	responses.CampaignsResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /campaigns 	[get]
+func (h *CampaignHandler) Index(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.CampaignIndex
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateIndex(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while fetching campaigns [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while fetching campaigns")
+	}
+
+	campaigns, err := h.service.Index(ctx, h.userIDFomContext(c), request.ToIndexParams())
+	if err != nil {
+		msg := fmt.Sprintf("cannot get campaigns with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("fetched %d %s", len(campaigns), h.pluralize("campaign", len(campaigns))), campaigns)
+}
+
+// Store a campaign
+// @Summary      Store a campaign
+// @Description  Store a campaign which can be used to group messages sent with the API or from a bulk upload
+// @Security	 ApiKeyAuth
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param        payload   	body 		requests.CampaignStore  	true "Payload of the campaign request"
+// @Success      201 		{object}	responses.CampaignResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /campaigns [post]
+func (h *CampaignHandler) Store(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.CampaignStore
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall body [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateStore(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while storing campaign [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while storing campaign")
+	}
+
+	campaign, err := h.service.Store(ctx, request.ToStoreParams(h.userFromContext(c)))
+	if err != nil {
+		msg := fmt.Sprintf("cannot store campaign with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseCreated(c, "campaign created successfully", campaign)
+}
+
+// Show a campaign
+// @Summary      Get a campaign
+// @Description  Get a campaign of the authenticated user
+// @Security	 ApiKeyAuth
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param 		 campaignID	path		string 							true 	"ID of the campaign"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      200 		{object}	responses.CampaignResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /campaigns/{campaignID} [get]
+func (h *CampaignHandler) Show(c *fiber.Ctx) error {
+	ctx, span, _ := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	campaign, response := h.loadCampaign(ctx, c)
+	if campaign == nil {
+		return response
+	}
+
+	return h.responseOK(c, "campaign fetched successfully", campaign)
+}
+
+// Stats returns the live counters of a campaign
+// @Summary      Get the statistics of a campaign
+// @Description  Get the number of pending, sent, delivered, failed and expired messages in a campaign and the number of replies
+// @Security	 ApiKeyAuth
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param 		 campaignID	path		string 							true 	"ID of the campaign"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      200 		{object}	responses.CampaignStatsResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /campaigns/{campaignID}/stats [get]
+func (h *CampaignHandler) Stats(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	campaign, response := h.loadCampaign(ctx, c)
+	if campaign == nil {
+		return response
+	}
+
+	stats, err := h.service.Stats(ctx, campaign)
+	if err != nil {
+		msg := fmt.Sprintf("cannot fetch stats for campaign with ID [%s]", campaign.ID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "campaign stats fetched successfully", stats)
+}
+
+// Pause a campaign
+// @Summary      Pause a campaign
+// @Description  Pause an active campaign. Messages which have not been sent to the phone are held until the campaign is resumed.
+// @Security	 ApiKeyAuth
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param 		 campaignID	path		string 							true 	"ID of the campaign"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      200 		{object}	responses.CampaignResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /campaigns/{campaignID}/pause [post]
+func (h *CampaignHandler) Pause(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	campaign, response := h.loadCampaign(ctx, c)
+	if campaign == nil {
+		return response
+	}
+
+	if !campaign.IsActive() {
+		return h.responseInvalidStatus(c, campaign, "only an active campaign can be paused")
+	}
+
+	status := campaign.Status
+	campaign, err := h.service.Pause(ctx, campaign)
+	if stacktrace.GetCode(err) == services.ErrCodeCampaignStatusChanged {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot pause campaign with ID [%s]", c.Params("campaignID"))))
+		return h.responseStatusChanged(c, status, "only an active campaign can be paused")
+	}
+
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot pause campaign with ID [%s]", c.Params("campaignID"))))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "campaign paused successfully", campaign)
+}
+
+// Resume a campaign
+// @Summary      Resume a campaign
+// @Description  Resume a paused campaign. Messages which were held while the campaign was paused will be sent to the phone.
+// @Security	 ApiKeyAuth
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param 		 campaignID	path		string 							true 	"ID of the campaign"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      200 		{object}	responses.CampaignResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /campaigns/{campaignID}/resume [post]
+func (h *CampaignHandler) Resume(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	campaign, response := h.loadCampaign(ctx, c)
+	if campaign == nil {
+		return response
+	}
+
+	if !campaign.IsPaused() {
+		return h.responseInvalidStatus(c, campaign, "only a paused campaign can be resumed")
+	}
+
+	status := campaign.Status
+	campaign, err := h.service.Resume(ctx, c.OriginalURL(), campaign)
+	if stacktrace.GetCode(err) == services.ErrCodeCampaignStatusChanged {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot resume campaign with ID [%s]", c.Params("campaignID"))))
+		return h.responseStatusChanged(c, status, "only a paused campaign can be resumed")
+	}
+
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot resume campaign with ID [%s]", c.Params("campaignID"))))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "campaign resumed successfully", campaign)
+}
+
+// Cancel a campaign
+// @Summary      Cancel a campaign
+// @Description  Cancel an active or paused campaign. Messages which have not been sent to the phone will be marked as failed.
+// @Security	 ApiKeyAuth
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param 		 campaignID	path		string 							true 	"ID of the campaign"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      200 		{object}	responses.CampaignResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /campaigns/{campaignID}/cancel [post]
+func (h *CampaignHandler) Cancel(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	campaign, response := h.loadCampaign(ctx, c)
+	if campaign == nil {
+		return response
+	}
+
+	if !campaign.IsActive() && !campaign.IsPaused() {
+		return h.responseInvalidStatus(c, campaign, "only an active or paused campaign can be canceled")
+	}
+
+	status := campaign.Status
+	campaign, err := h.service.Cancel(ctx, c.OriginalURL(), campaign)
+	if stacktrace.GetCode(err) == services.ErrCodeCampaignStatusChanged {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot cancel campaign with ID [%s]", c.Params("campaignID"))))
+		return h.responseStatusChanged(c, status, "only an active or paused campaign can be canceled")
+	}
+
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot cancel campaign with ID [%s]", c.Params("campaignID"))))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "campaign canceled successfully", campaign)
+}
+
+// Delete a campaign
+// @Summary      Delete campaign
+// @Description  Delete a campaign for a user. The messages in the campaign are not deleted.
+// @Security	 ApiKeyAuth
+// @Tags         Campaigns
+// @Accept       json
+// @Produce      json
+// @Param 		 campaignID	path		string 							true 	"ID of the campaign"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      204		{object}    responses.NoContent
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /campaigns/{campaignID} [delete]
+func (h *CampaignHandler) Delete(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	campaignID := c.Params("campaignID")
+	if errors := h.validator.ValidateUUID(ctx, campaignID, "campaignID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while deleting campaign with ID [%s]", spew.Sdump(errors), campaignID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while deleting campaign")
+	}
+
+	if err := h.service.Delete(ctx, h.userIDFomContext(c), uuid.MustParse(campaignID)); err != nil {
+		msg := fmt.Sprintf("cannot delete campaign with ID [%+#v]", campaignID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "campaign deleted successfully", nil)
+}
+
+func (h *CampaignHandler) loadCampaign(ctx context.Context, c *fiber.Ctx) (*entities.Campaign, error) {
+	ctx, span, ctxLogger := h.tracer.StartWithLogger(ctx, h.logger)
+	defer span.End()
+
+	campaignID := c.Params("campaignID")
+	if errors := h.validator.ValidateUUID(ctx, campaignID, "campaignID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while loading campaign with ID [%s]", spew.Sdump(errors), campaignID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return nil, h.responseUnprocessableEntity(c, errors, "validation errors while loading campaign")
+	}
+
+	campaign, err := h.service.Load(ctx, h.userIDFomContext(c), uuid.MustParse(campaignID))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return nil, h.responseNotFound(c, fmt.Sprintf("cannot find campaign with ID [%s]", campaignID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load campaign with ID [%s]", campaignID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return nil, h.responseInternalServerError(c)
+	}
+
+	return campaign, nil
+}
+
+func (h *CampaignHandler) responseStatusChanged(c *fiber.Ctx, status entities.CampaignStatus, message string) error {
+	errors := url.Values{}
+	errors.Add("status", fmt.Sprintf("%s. The status of the campaign changed from [%s] while it was being updated", message, status))
+	return h.responseUnprocessableEntity(c, errors, "validation errors while updating campaign")
+}
+
+func (h *CampaignHandler) responseInvalidStatus(c *fiber.Ctx, campaign *entities.Campaign, message string) error {
+	errors := url.Values{}
+	errors.Add("status", fmt.Sprintf("%s. The campaign [%s] has status [%s]", message, campaign.ID, campaign.Status))
+	return h.responseUnprocessableEntity(c, errors, "validation errors while updating campaign")
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/NdoleStudio/httpsms/pkg/validators"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+// ContactListHandler handles contact list http requests
+type ContactListHandler struct {
+	handler
+	logger    telemetry.Logger
+	tracer    telemetry.Tracer
+	service   *services.ContactListService
+	validator *validators.ContactListHandlerValidator
+}
+
+// NewContactListHandler creates a new ContactListHandler
+func NewContactListHandler(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	service *services.ContactListService,
+	validator *validators.ContactListHandlerValidator,
+) (h *ContactListHandler) {
+	return &ContactListHandler{
+		logger:    logger.WithService(fmt.Sprintf("%T", h)),
+		tracer:    tracer,
+		service:   service,
+		validator: validator,
+	}
+}
+
+// RegisterRoutes registers the routes for the ContactListHandler
+func (h *ContactListHandler) RegisterRoutes(app *fiber.App, middlewares ...fiber.Handler) {
+	router := app.Group("/v1/contact-lists")
+	router.Get("/", h.computeRoute(middlewares, h.Index)...)
+	router.Post("/", h.computeRoute(middlewares, h.Store)...)
+	router.Get("/:contactListID", h.computeRoute(middlewares, h.Show)...)
+	router.Put("/:contactListID", h.computeRoute(middlewares, h.Update)...)
+	router.Delete("/:contactListID", h.computeRoute(middlewares, h.Delete)...)
+	router.Get("/:contactListID/members", h.computeRoute(middlewares, h.IndexMembers)...)
+	router.Post("/:contactListID/members", h.computeRoute(middlewares, h.StoreMember)...)
+	router.Delete("/:contactListID/members/:memberID", h.computeRoute(middlewares, h.DeleteMember)...)
+}
+
+// Index returns the contact lists of a user
+// @Summary      Get contact lists of a user
+// @Description  Get the contact lists of a user sorted by the created time in descending order.
+// @Security	 ApiKeyAuth
+// @Tags         ContactLists
+// @Accept       json
+// @Produce      json
+// @Param        skip		query  int  	false	"number of contact lists to skip"		minimum(0)
+// @Param        query		query  string  	false 	"filter contact lists containing query"
+// @Param        limit		query  int  	false	"number of contact lists to return"	minimum(1)	maximum(100)
+// @Success      200 		{object}	responses.ContactListsResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /contact-lists 	[get]
+func (h *ContactListHandler) Index(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.ContactListIndex
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateIndex(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while fetching contact lists [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while fetching contact lists")
+	}
+
+	lists, err := h.service.Index(ctx, h.userIDFomContext(c), request.ToIndexParams())
+	if err != nil {
+		msg := fmt.Sprintf("cannot get contact lists with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("fetched %d %s", len(lists), h.pluralize("contact list", len(lists))), lists)
+}
+
+// Store a contact list
+// @Summary      Store a contact list
+// @Description  Store a contact list which contacts can join or leave by sending "JOIN <keyword>" or "LEAVE <keyword>"
+// @Security	 ApiKeyAuth
+// @Tags         ContactLists
+// @Accept       json
+// @Produce      json
+// @Param        payload   	body 		requests.ContactListStore  	true "Payload of the contact list request"
+// @Success      201 		{object}	responses.ContactListResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /contact-lists [post]
+func (h *ContactListHandler) Store(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.ContactListStore
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall body [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateStore(ctx, h.userIDFomContext(c), request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while storing contact list [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while storing contact list")
+	}
+
+	list, err := h.service.Store(ctx, request.ToStoreParams(h.userFromContext(c)))
+	if err != nil {
+		msg := fmt.Sprintf("cannot store contact list with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseCreated(c, "contact list created successfully", list)
+}
+
+// Show a contact list
+// @Summary      Get a contact list
+// @Description  Get a contact list of the authenticated user
+// @Security	 ApiKeyAuth
+// @Tags         ContactLists
+// @Accept       json
+// @Produce      json
+// @Param 		 contactListID	path		string 							true 	"ID of the contact list"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      200 		{object}	responses.ContactListResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /contact-lists/{contactListID} [get]
+func (h *ContactListHandler) Show(c *fiber.Ctx) error {
+	ctx, span, _ := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	list, response := h.loadContactList(ctx, c)
+	if list == nil {
+		return response
+	}
+
+	return h.responseOK(c, "contact list fetched successfully", list)
+}
+
+// Update a contact list
+// @Summary      Update a contact list
+// @Description  Update the name, keyword and confirmation replies of a contact list
+// @Security	 ApiKeyAuth
+// @Tags         ContactLists
+// @Accept       json
+// @Produce      json
+// @Param 		 contactListID	path		string 							true 	"ID of the contact list"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        payload   	body 		requests.ContactListUpdate  	true "Payload of the contact list request"
+// @Success      200 		{object}	responses.ContactListResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /contact-lists/{contactListID} [put]
+func (h *ContactListHandler) Update(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.ContactListUpdate
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall body [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	request.ContactListID = c.Params("contactListID")
+	if errors := h.validator.ValidateUpdate(ctx, h.userIDFomContext(c), request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while updating contact list [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while updating contact list")
+	}
+
+	list, err := h.service.Update(ctx, request.ToUpdateParams(h.userFromContext(c)))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return h.responseNotFound(c, fmt.Sprintf("cannot find contact list with ID [%s]", request.ContactListID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot update contact list with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "contact list updated successfully", list)
+}
+
+// Delete a contact list
+// @Summary      Delete contact list
+// @Description  Delete a contact list and all its members
+// @Security	 ApiKeyAuth
+// @Tags         ContactLists
+// @Accept       json
+// @Produce      json
+// @Param 		 contactListID	path		string 							true 	"ID of the contact list"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      204		{object}    responses.NoContent
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /contact-lists/{contactListID} [delete]
+func (h *ContactListHandler) Delete(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	contactListID := c.Params("contactListID")
+	if errors := h.validator.ValidateUUID(ctx, contactListID, "contactListID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while deleting contact list with ID [%s]", spew.Sdump(errors), contactListID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while deleting contact list")
+	}
+
+	if err := h.service.Delete(ctx, h.userIDFomContext(c), uuid.MustParse(contactListID)); err != nil {
+		msg := fmt.Sprintf("cannot delete contact list with ID [%+#v]", contactListID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseNoContent(c, "contact list deleted successfully")
+}
+
+// IndexMembers returns the members of a contact list
+// @Summary      Get the members of a contact list
+// @Description  Get the contacts which joined a contact list sorted by the created time in descending order.
+// @Security	 ApiKeyAuth
+// @Tags         ContactLists
+// @Accept       json
+// @Produce      json
+// @Param 		 contactListID	path		string 	true 	"ID of the contact list"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        skip		query  int  	false	"number of members to skip"		minimum(0)
+// @Param        query		query  string  	false 	"filter members containing query"
+// @Param        limit		query  int  	false	"number of members to return"	minimum(1)	maximum(100)
+// @Success      200 		{object}	responses.ContactListMembersResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /contact-lists/{contactListID}/members 	[get]
+func (h *ContactListHandler) IndexMembers(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.ContactListIndex
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateIndex(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while fetching contact list members [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while fetching contact list members")
+	}
+
+	list, response := h.loadContactList(ctx, c)
+	if list == nil {
+		return response
+	}
+
+	members, err := h.service.IndexMembers(ctx, list, request.ToIndexParams())
+	if err != nil {
+		msg := fmt.Sprintf("cannot get members of contact list [%s] with params [%+#v]", list.ID, request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("fetched %d %s", len(members), h.pluralize("member", len(members))), members)
+}
+
+// StoreMember adds a contact to a contact list
+// @Summary      Add a contact to a contact list
+// @Description  Add a contact to a contact list without sending a confirmation message
+// @Security	 ApiKeyAuth
+// @Tags         ContactLists
+// @Accept       json
+// @Produce      json
+// @Param 		 contactListID	path		string 							true 	"ID of the contact list"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        payload   	body 		requests.ContactListMemberStore  	true "Payload of the contact list member request"
+// @Success      201 		{object}	responses.ContactListMemberResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /contact-lists/{contactListID}/members [post]
+func (h *ContactListHandler) StoreMember(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.ContactListMemberStore
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall body [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateMemberStore(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while storing contact list member [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while storing contact list member")
+	}
+
+	list, response := h.loadContactList(ctx, c)
+	if list == nil {
+		return response
+	}
+
+	member, err := h.service.AddMember(ctx, list, request.Contact, nil)
+	if err != nil {
+		msg := fmt.Sprintf("cannot add contact [%s] to contact list [%s]", request.Contact, list.ID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseCreated(c, "contact added to the contact list successfully", member)
+}
+
+// DeleteMember removes a contact from a contact list
+// @Summary      Remove a contact from a contact list
+// @Description  Remove a contact from a contact list without sending a confirmation message
+// @Security	 ApiKeyAuth
+// @Tags         ContactLists
+// @Accept       json
+// @Produce      json
+// @Param 		 contactListID	path		string 							true 	"ID of the contact list"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param 		 memberID	path		string 							true 	"ID of the contact list member"	default(32343a19-da5e-4b1b-a767-3298a73703cb)
+// @Success      204		{object}    responses.NoContent
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /contact-lists/{contactListID}/members/{memberID} [delete]
+func (h *ContactListHandler) DeleteMember(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	memberID := c.Params("memberID")
+	if errors := h.validator.ValidateUUID(ctx, memberID, "memberID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while deleting contact list member with ID [%s]", spew.Sdump(errors), memberID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while deleting contact list member")
+	}
+
+	list, response := h.loadContactList(ctx, c)
+	if list == nil {
+		return response
+	}
+
+	if err := h.service.DeleteMember(ctx, list, uuid.MustParse(memberID)); err != nil {
+		msg := fmt.Sprintf("cannot delete member [%s] of contact list [%s]", memberID, list.ID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseNoContent(c, "contact removed from the contact list successfully")
+}
+
+func (h *ContactListHandler) loadContactList(ctx context.Context, c *fiber.Ctx) (*entities.ContactList, error) {
+	ctx, span, ctxLogger := h.tracer.StartWithLogger(ctx, h.logger)
+	defer span.End()
+
+	contactListID := c.Params("contactListID")
+	if errors := h.validator.ValidateUUID(ctx, contactListID, "contactListID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while loading contact list with ID [%s]", spew.Sdump(errors), contactListID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return nil, h.responseUnprocessableEntity(c, errors, "validation errors while loading contact list")
+	}
+
+	list, err := h.service.Load(ctx, h.userIDFomContext(c), uuid.MustParse(contactListID))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return nil, h.responseNotFound(c, fmt.Sprintf("cannot find contact list with ID [%s]", contactListID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load contact list with ID [%s]", contactListID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return nil, h.responseInternalServerError(c)
+	}
+
+	return list, nil
+}
@@ -364,7 +364,8 @@ func (h *DiscordHandler) sendSMS(ctx context.Context, c *fiber.Ctx, payload map[
 		)
 	}
 
-	if msg := h.billingService.IsEntitled(ctx, discord.UserID); msg != nil {
+	params := request.ToMessageSendParams(discord.UserID, c.OriginalURL())
+	if msg := h.billingService.IsEntitledToSend(ctx, discord.UserID, params.Segments()); msg != nil {
 		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("user with ID [%s] can't send a message", discord.UserID)))
 		return c.JSON(
 			fiber.Map{
@@ -382,7 +383,7 @@ func (h *DiscordHandler) sendSMS(ctx context.Context, c *fiber.Ctx, payload map[
 		)
 	}
 
-	message, err := h.messageService.SendMessage(ctx, request.ToMessageSendParams(discord.UserID, c.OriginalURL()))
+	message, err := h.messageService.SendMessage(ctx, params)
 	if err != nil {
 		msg := fmt.Sprintf("cannot send message with paylod [%s] from discord server [%s]", c.Body(), discord.ServerID)
 		ctxLogger.Error(stacktrace.Propagate(err, msg))
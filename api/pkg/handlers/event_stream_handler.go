@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/NdoleStudio/httpsms/pkg/validators"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// eventStreamHeartbeatInterval is the longest time a connection is idle before a heartbeat is sent
+	eventStreamHeartbeatInterval = 15 * time.Second
+
+	// eventStreamMaxDuration is the duration after which a connection is closed. Clients reconnect with the last event ID.
+	eventStreamMaxDuration = time.Hour
+)
+
+// EventStreamHandler streams the events of a user over Server-Sent Events and WebSocket
+type EventStreamHandler struct {
+	handler
+	logger    telemetry.Logger
+	tracer    telemetry.Tracer
+	validator *validators.EventStreamHandlerValidator
+	service   *services.EventStreamService
+}
+
+// NewEventStreamHandler creates a new EventStreamHandler
+func NewEventStreamHandler(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	validator *validators.EventStreamHandlerValidator,
+	service *services.EventStreamService,
+) (h *EventStreamHandler) {
+	return &EventStreamHandler{
+		logger:    logger.WithService(fmt.Sprintf("%T", h)),
+		tracer:    tracer,
+		validator: validator,
+		service:   service,
+	}
+}
+
+// RegisterRoutes registers the routes for the EventStreamHandler
+func (h *EventStreamHandler) RegisterRoutes(app *fiber.App, middlewares ...fiber.Handler) {
+	router := app.Group("/v1/events")
+	router.Get("/stream", h.computeRoute(middlewares, h.Stream)...)
+	router.Get("/ws", h.computeRoute(middlewares, h.WebSocket)...)
+}
+
+// Stream sends the events of a user as Server-Sent Events
+// @Summary      Stream events with Server-Sent Events
+// @Description  Stream the received messages, message status changes and phone online/offline events of a user as they happen. The ID of each event can be used to resume the stream with the Last-Event-ID header. Events are kept for 1 hour.
+// @Security	 ApiKeyAuth
+// @Tags         Events
+// @Produce      text/event-stream
+// @Param        event_types		query  string  	false	"comma separated list of events to stream e.g. message.phone.received,message.phone.delivered"
+// @Param        last_event_id		query  string  	false	"resume the stream after the event with this ID"
+// @Success      200
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      429		{object}	responses.TooManyRequests
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /events/stream [get]
+func (h *EventStreamHandler) Stream(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.EventStream
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if request.LastEventID == "" {
+		request.LastEventID = c.Get("Last-Event-ID")
+	}
+
+	if errors := h.validator.ValidateStream(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while streaming events [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while streaming events")
+	}
+
+	cursor, err := h.service.Cursor(ctx, h.userIDFomContext(c), request.LastEventID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot get the cursor of the event stream with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	params := request.ToReadParams(h.userIDFomContext(c), cursor)
+
+	disconnect, err := h.service.Connect(params.UserID)
+	if err != nil {
+		return h.responseConnectError(c, ctxLogger, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(writer *bufio.Writer) {
+		defer disconnect()
+
+		_, _ = fmt.Fprintf(writer, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+		if err := writer.Flush(); err != nil {
+			return
+		}
+
+		h.stream(context.Background(), params, func(streamEvents []*entities.StreamEvent) error {
+			if len(streamEvents) == 0 {
+				_, _ = fmt.Fprint(writer, ": heartbeat\n\n")
+			}
+			for _, event := range streamEvents {
+				_, _ = fmt.Fprintf(writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Event)
+			}
+			return writer.Flush()
+		})
+	})
+
+	return nil
+}
+
+// WebSocket sends the events of a user over a WebSocket connection
+// @Summary      Stream events with WebSocket
+// @Description  Stream the received messages, message status changes and phone online/offline events of a user over a WebSocket connection. Each message is a JSON object with the ID, type and CloudEvent of the event. Events are kept for 1 hour.
+// @Security	 ApiKeyAuth
+// @Tags         Events
+// @Param        event_types		query  string  	false	"comma separated list of events to stream e.g. message.phone.received,message.phone.delivered"
+// @Param        last_event_id		query  string  	false	"resume the stream after the event with this ID"
+// @Success      101
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      429		{object}	responses.TooManyRequests
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /events/ws [get]
+func (h *EventStreamHandler) WebSocket(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	if !websocket.IsWebSocketUpgrade(c) {
+		return h.responseUpgradeRequired(c, "The request must be a WebSocket upgrade request")
+	}
+
+	var request requests.EventStream
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateStream(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while streaming events [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while streaming events")
+	}
+
+	cursor, err := h.service.Cursor(ctx, h.userIDFomContext(c), request.LastEventID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot get the cursor of the event stream with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	params := request.ToReadParams(h.userIDFomContext(c), cursor)
+
+	disconnect, err := h.service.Connect(params.UserID)
+	if err != nil {
+		return h.responseConnectError(c, ctxLogger, err)
+	}
+
+	err = websocket.New(func(conn *websocket.Conn) {
+		defer disconnect()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// the connection is closed when the client stops reading
+		go func() {
+			defer cancel()
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		h.stream(ctx, params, func(streamEvents []*entities.StreamEvent) error {
+			if len(streamEvents) == 0 {
+				return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventStreamHeartbeatInterval))
+			}
+			for _, event := range streamEvents {
+				if err := conn.WriteJSON(event); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})(c)
+	if err != nil {
+		disconnect()
+	}
+	return err
+}
+
+func (h *EventStreamHandler) responseConnectError(c *fiber.Ctx, ctxLogger telemetry.Logger, err error) error {
+	ctxLogger.Warn(stacktrace.Propagate(err, "cannot open the event stream"))
+	if stacktrace.GetCode(err) == services.ErrCodeEventStreamServerLimit {
+		return h.responseServiceUnavailable(c, "The server cannot open more event streams, try again later.")
+	}
+	return h.responseTooManyRequests(c, url.Values{"connections": []string{"You have too many open event streams. Close an event stream before opening a new one."}}, "too many open event streams")
+}
+
+// stream reads the event stream and sends the events until the client disconnects or eventStreamMaxDuration elapses
+func (h *EventStreamHandler) stream(ctx context.Context, params *services.EventStreamReadParams, send func(streamEvents []*entities.StreamEvent) error) {
+	ctx, cancel := context.WithTimeout(ctx, eventStreamMaxDuration)
+	defer cancel()
+
+	params.Timeout = eventStreamHeartbeatInterval
+	for ctx.Err() == nil {
+		streamEvents, cursor, err := h.service.Read(ctx, params)
+		if err != nil && ctx.Err() == nil {
+			h.logger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot read the event stream of user [%s] after [%s]", params.UserID, params.Cursor)))
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		params.Cursor = cursor
+		if err = send(streamEvents); err != nil {
+			h.logger.Info(fmt.Sprintf("closed the event stream of user [%s] at [%s]: %s", params.UserID, params.Cursor, err.Error()))
+			return
+		}
+	}
+}
@@ -42,6 +42,13 @@ func (h *handler) responseForbidden(c *fiber.Ctx) error {
 	})
 }
 
+func (h *handler) responseUpgradeRequired(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{
+		"status":  "error",
+		"message": message,
+	})
+}
+
 func (h *handler) responseUnprocessableEntity(c *fiber.Ctx, errors url.Values, message string) error {
 	return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
 		"status":  "error",
@@ -50,6 +57,21 @@ func (h *handler) responseUnprocessableEntity(c *fiber.Ctx, errors url.Values, m
 	})
 }
 
+func (h *handler) responseTooManyRequests(c *fiber.Ctx, errors url.Values, message string) error {
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"status":  "error",
+		"message": message,
+		"data":    errors,
+	})
+}
+
+func (h *handler) responseServiceUnavailable(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+		"status":  "error",
+		"message": message,
+	})
+}
+
 func (h *handler) responseNotFound(c *fiber.Ctx, message string) error {
 	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 		"status":  "error",
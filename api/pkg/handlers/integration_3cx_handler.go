@@ -67,13 +67,14 @@ func (h *Integration3CXHandler) Messages(c *fiber.Ctx) error {
 		return h.responseBadRequest(c, err)
 	}
 
-	if msg := h.billingService.IsEntitled(ctx, h.userIDFomContext(c)); msg != nil {
+	request.Sanitize()
+	params := request.ToMessageSendParams(h.userIDFomContext(c), c.OriginalURL())
+	if msg := h.billingService.IsEntitledToSend(ctx, h.userIDFomContext(c), params.Segments()); msg != nil {
 		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("user with ID [%s] can't send a [3cx] message", h.userIDFomContext(c))))
 		return h.responsePaymentRequired(c, *msg)
 	}
 
-	request.Sanitize()
-	message, err := h.messageService.SendMessage(ctx, request.ToMessageSendParams(h.userIDFomContext(c), c.OriginalURL()))
+	message, err := h.messageService.SendMessage(ctx, params)
 	if err != nil {
 		msg := fmt.Sprintf("cannot send [3cx] message with paylod [%s]", c.Body())
 		ctxLogger.Error(stacktrace.Propagate(err, msg))
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/NdoleStudio/httpsms/pkg/validators"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+// IntegrationMQTTHandler handles the MQTT integrations of a user
+type IntegrationMQTTHandler struct {
+	handler
+	logger           telemetry.Logger
+	tracer           telemetry.Tracer
+	validator        *validators.IntegrationMQTTHandlerValidator
+	service          *services.IntegrationMQTTService
+	messageService   *services.MessageService
+	billingService   *services.BillingService
+	messageValidator *validators.MessageHandlerValidator
+}
+
+// NewIntegrationMQTTHandler creates a new IntegrationMQTTHandler
+func NewIntegrationMQTTHandler(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	validator *validators.IntegrationMQTTHandlerValidator,
+	service *services.IntegrationMQTTService,
+	messageService *services.MessageService,
+	billingService *services.BillingService,
+	messageValidator *validators.MessageHandlerValidator,
+) (h *IntegrationMQTTHandler) {
+	return &IntegrationMQTTHandler{
+		logger:           logger.WithService(fmt.Sprintf("%T", h)),
+		tracer:           tracer,
+		validator:        validator,
+		service:          service,
+		messageService:   messageService,
+		billingService:   billingService,
+		messageValidator: messageValidator,
+	}
+}
+
+// RegisterRoutes registers the routes for the IntegrationMQTTHandler
+func (h *IntegrationMQTTHandler) RegisterRoutes(app *fiber.App, middlewares ...fiber.Handler) {
+	router := app.Group("/v1/mqtt-integrations")
+	router.Get("/", h.computeRoute(middlewares, h.Index)...)
+	router.Post("/", h.computeRoute(middlewares, h.Store)...)
+	router.Put("/:integrationID", h.computeRoute(middlewares, h.Update)...)
+	router.Delete("/:integrationID", h.computeRoute(middlewares, h.Delete)...)
+}
+
+// Index returns the MQTT integrations of a user
+// @Summary      Get MQTT integrations of a user
+// @Description  Get the MQTT integrations of a user
+// @Security	 ApiKeyAuth
+// @Tags         MQTTIntegration
+// @Accept       json
+// @Produce      json
+// @Param        skip		query  int  	false	"number of mqtt integrations to skip"		minimum(0)
+// @Param        query		query  string  	false 	"filter mqtt integrations containing query"
+// @Param        limit		query  int  	false	"number of mqtt integrations to return"	minimum(1)	maximum(20)
+// @Success      200 		{object}	responses.IntegrationMQTTsResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /mqtt-integrations 	[get]
+func (h *IntegrationMQTTHandler) Index(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.IntegrationMQTTIndex
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateIndex(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while fetching mqtt integrations [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while fetching mqtt integrations")
+	}
+
+	integrations, err := h.service.Index(ctx, h.userIDFomContext(c), request.ToIndexParams())
+	if err != nil {
+		msg := fmt.Sprintf("cannot get mqtt integrations with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("fetched %d mqtt %s", len(integrations), h.pluralize("integration", len(integrations))), integrations)
+}
+
+// Store an entities.IntegrationMQTT
+// @Summary      Store MQTT integration
+// @Description  Store an MQTT integration which publishes the events of the authenticated user to an MQTT broker
+// @Security	 ApiKeyAuth
+// @Tags         MQTTIntegration
+// @Accept       json
+// @Produce      json
+// @Param        payload   	body 		requests.IntegrationMQTTStore  		true "Payload of the mqtt integration request"
+// @Success      201 		{object}	responses.IntegrationMQTTResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure 	 402	    {object}	responses.PaymentRequired
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /mqtt-integrations [post]
+func (h *IntegrationMQTTHandler) Store(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.IntegrationMQTTStore
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall body [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateStore(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while storing mqtt integration [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while storing mqtt integration")
+	}
+
+	integrations, err := h.service.Index(ctx, h.userIDFomContext(c), repositories.IndexParams{Skip: 0, Limit: 1})
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot index mqtt integrations for user [%s]", h.userIDFomContext(c))))
+		return h.responseInternalServerError(c)
+	}
+
+	if len(integrations) > 0 {
+		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("user with ID [%s] wants to create more than 1 mqtt integration", h.userIDFomContext(c))))
+		return h.responsePaymentRequired(c, "You can't create more than 1 mqtt integration contact us to upgrade your account.")
+	}
+
+	integration, err := h.service.Store(ctx, request.ToStoreParams(h.userFromContext(c)))
+	if err != nil {
+		msg := fmt.Sprintf("cannot store mqtt integration with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseCreated(c, "mqtt integration created successfully", integration)
+}
+
+// Update an entities.IntegrationMQTT
+// @Summary      Update an MQTT integration
+// @Description  Update an MQTT integration for the currently authenticated user
+// @Security	 ApiKeyAuth
+// @Tags         MQTTIntegration
+// @Accept       json
+// @Produce      json
+// @Param 		 integrationID	path		string 							true 	"ID of the mqtt integration" 					default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        payload   		body 		requests.IntegrationMQTTUpdate  true 	"Payload of mqtt integration to update"
+// @Success      200 		{object}	responses.IntegrationMQTTResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /mqtt-integrations/{integrationID} 	[put]
+func (h *IntegrationMQTTHandler) Update(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.IntegrationMQTTUpdate
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall params [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	request.IntegrationID = c.Params("integrationID")
+	if errors := h.validator.ValidateUpdate(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while updating mqtt integration [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while updating mqtt integration")
+	}
+
+	integration, err := h.service.Update(ctx, request.ToUpdateParams(h.userFromContext(c)))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return h.responseNotFound(c, fmt.Sprintf("cannot find mqtt integration with ID [%s]", request.IntegrationID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot update mqtt integration with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "mqtt integration updated successfully", integration)
+}
+
+// Delete an entities.IntegrationMQTT
+// @Summary      Delete MQTT integration
+// @Description  Delete an MQTT integration for a user
+// @Security	 ApiKeyAuth
+// @Tags         MQTTIntegration
+// @Accept       json
+// @Produce      json
+// @Param 		 integrationID 	path		string 				true 	"ID of the mqtt integration"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      204		{object}    responses.NoContent
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /mqtt-integrations/{integrationID} [delete]
+func (h *IntegrationMQTTHandler) Delete(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	integrationID := c.Params("integrationID")
+	if errors := h.validator.ValidateUUID(ctx, integrationID, "integrationID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while deleting mqtt integration with ID [%s]", spew.Sdump(errors), integrationID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while deleting mqtt integration")
+	}
+
+	err := h.service.Delete(ctx, h.userIDFomContext(c), uuid.MustParse(integrationID))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return h.responseNotFound(c, fmt.Sprintf("cannot find mqtt integration with ID [%s]", integrationID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete mqtt integration with ID [%+#v]", integrationID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseNoContent(c, "mqtt integration deleted successfully")
+}
+
+// OnCommand sends the SMS in a message received on the command topic of an entities.IntegrationMQTT. The payload is
+// the same as the payload of POST /v1/messages/send.
+func (h *IntegrationMQTTHandler) OnCommand(ctx context.Context, integration *entities.IntegrationMQTT, payload []byte) error {
+	ctx, span, ctxLogger := h.tracer.StartWithLogger(ctx, h.logger)
+	defer span.End()
+
+	var request requests.MessageSend
+	if err := json.Unmarshal(payload, &request); err != nil {
+		msg := fmt.Sprintf("cannot unmarshal [%s] into [%T]", payload, request)
+		return h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if errors := h.messageValidator.ValidateMessageSend(ctx, integration.UserID, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while sending payload [%s] from mqtt integration [%s]", spew.Sdump(errors), payload, integration.ID)
+		return h.tracer.WrapErrorSpan(span, stacktrace.NewError(msg))
+	}
+
+	if request.DryRun {
+		ctxLogger.Info(fmt.Sprintf("validated dry run payload [%s] from mqtt integration [%s]", payload, integration.ID))
+		return nil
+	}
+
+	source := fmt.Sprintf("mqtt://%s:%d/%s", integration.Host, integration.Port, *integration.CommandTopic)
+	params := request.ToMessageSendParams(integration.UserID, source)
+	if msg := h.billingService.IsEntitledToSend(ctx, integration.UserID, params.Segments()); msg != nil {
+		return h.tracer.WrapErrorSpan(span, stacktrace.NewError(fmt.Sprintf("user with ID [%s] can't send a message: %s", integration.UserID, *msg)))
+	}
+
+	message, err := h.messageService.SendMessage(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot send message with payload [%s] from mqtt integration [%s]", payload, integration.ID)
+		return h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("sent message with ID [%s] from mqtt integration [%s]", message.ID, integration.ID))
+	return nil
+}
@@ -1,13 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"strings"
-	"sync"
 	"time"
 
-	"github.com/NdoleStudio/httpsms/pkg/entities"
-
 	"github.com/NdoleStudio/httpsms/pkg/repositories"
 	"github.com/google/uuid"
 
@@ -23,11 +22,12 @@ import (
 // MessageHandler handles message http requests.
 type MessageHandler struct {
 	handler
-	logger         telemetry.Logger
-	tracer         telemetry.Tracer
-	billingService *services.BillingService
-	validator      *validators.MessageHandlerValidator
-	service        *services.MessageService
+	logger             telemetry.Logger
+	tracer             telemetry.Tracer
+	billingService     *services.BillingService
+	validator          *validators.MessageHandlerValidator
+	service            *services.MessageService
+	contactListService *services.ContactListService
 }
 
 // NewMessageHandler creates a new MessageHandler
@@ -37,20 +37,22 @@ func NewMessageHandler(
 	validator *validators.MessageHandlerValidator,
 	billingService *services.BillingService,
 	service *services.MessageService,
+	contactListService *services.ContactListService,
 ) (h *MessageHandler) {
 	return &MessageHandler{
-		logger:         logger.WithService(fmt.Sprintf("%T", h)),
-		tracer:         tracer,
-		validator:      validator,
-		billingService: billingService,
-		service:        service,
+		logger:             logger.WithService(fmt.Sprintf("%T", h)),
+		tracer:             tracer,
+		validator:          validator,
+		billingService:     billingService,
+		service:            service,
+		contactListService: contactListService,
 	}
 }
 
-// RegisterRoutes registers the routes for the MessageHandler
-func (h *MessageHandler) RegisterRoutes(router fiber.Router) {
-	router.Post("/messages/send", h.PostSend)
-	router.Post("/messages/bulk-send", h.BulkSend)
+// RegisterRoutes registers the routes for the MessageHandler. The idempotency middleware is used for the routes which send messages.
+func (h *MessageHandler) RegisterRoutes(router fiber.Router, idempotency fiber.Handler) {
+	router.Post("/messages/send", idempotency, h.PostSend)
+	router.Post("/messages/bulk-send", idempotency, h.BulkSend)
 	router.Post("/messages/receive", h.PostReceive)
 	router.Post("/messages/calls/missed", h.PostCallMissed)
 	router.Get("/messages/outstanding", h.GetOutstanding)
@@ -62,16 +64,20 @@ func (h *MessageHandler) RegisterRoutes(router fiber.Router) {
 
 // PostSend a new entities.Message
 // @Summary      Send a new SMS message
-// @Description  Add a new SMS message to be sent by the android phone
+// @Description  Add a new SMS message to be sent by the android phone. When split is true, the response contains the list of messages for the parts of the content. When dry_run is true, the response contains a preview of the message which is not stored or sent.
 // @Security	 ApiKeyAuth
 // @Tags         Messages
 // @Accept       json
 // @Produce      json
 // @Param        payload   body requests.MessageSend  true  "PostSend message request payload"
+// @Param        dry_run	query	bool	false	"validate the request and preview the message without sending it"
+// @Param        Idempotency-Key	header	string	false	"Unique key to make sure the request is processed only once when it is retried"
 // @Success      200  {object}  responses.MessageResponse
 // @Failure      400  {object}  responses.BadRequest
 // @Failure 	 401  {object}	responses.Unauthorized
+// @Failure      409  {object}  responses.Conflict
 // @Failure      422  {object}  responses.UnprocessableEntity
+// @Failure      429  {object}  responses.TooManyRequests
 // @Failure      500  {object}  responses.InternalServerError
 // @Router       /messages/send [post]
 func (h *MessageHandler) PostSend(c *fiber.Ctx) error {
@@ -86,6 +92,7 @@ func (h *MessageHandler) PostSend(c *fiber.Ctx) error {
 		ctxLogger.Warn(stacktrace.Propagate(err, msg))
 		return h.responseBadRequest(c, err)
 	}
+	request.DryRun = request.DryRun || c.QueryBool("dry_run")
 
 	if errors := h.validator.ValidateMessageSend(ctx, h.userIDFomContext(c), request.Sanitize()); len(errors) != 0 {
 		msg := fmt.Sprintf("validation errors [%s], while sending payload [%s]", spew.Sdump(errors), c.Body())
@@ -93,12 +100,36 @@ func (h *MessageHandler) PostSend(c *fiber.Ctx) error {
 		return h.responseUnprocessableEntity(c, errors, "validation errors while sending message")
 	}
 
-	if msg := h.billingService.IsEntitled(ctx, h.userIDFomContext(c)); msg != nil {
+	params := request.ToMessageSendParams(h.userIDFomContext(c), c.OriginalURL())
+	segments := []uint{params.Segments()}
+	if request.Split {
+		segments = params.PartSegments()
+	}
+
+	if msg := h.billingService.IsEntitledToSend(ctx, h.userIDFomContext(c), segments...); msg != nil {
 		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("user with ID [%s] can't send a message", h.userIDFomContext(c))))
 		return h.responsePaymentRequired(c, *msg)
 	}
 
+	if request.DryRun {
+		return h.postSendDryRun(ctx, ctxLogger, c, request)
+	}
+
+	if request.Split {
+		return h.postSendSplit(ctx, ctxLogger, c, request)
+	}
+
 	message, err := h.service.SendMessage(ctx, request.ToMessageSendParams(h.userIDFomContext(c), c.OriginalURL()))
+	if stacktrace.GetCode(err) == services.ErrCodeContactSuppressed {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot send message to suppressed contact [%s]", request.To)))
+		return h.responseUnprocessableEntity(c, map[string][]string{"to": {fmt.Sprintf("the contact [%s] opted out of receiving messages and is on your suppression list", request.To)}}, "validation errors while sending message")
+	}
+
+	if limited := h.contactLimitError(err, request.To); limited != nil {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot send message to rate limited contact [%s]", request.To)))
+		return h.responseTooManyRequests(c, limited, "too many messages sent to the contact")
+	}
+
 	if err != nil {
 		msg := fmt.Sprintf("cannot send message with paylod [%s]", c.Body())
 		ctxLogger.Error(stacktrace.Propagate(err, msg))
@@ -108,17 +139,83 @@ func (h *MessageHandler) PostSend(c *fiber.Ctx) error {
 	return h.responseOK(c, "message added to queue", message)
 }
 
+func (h *MessageHandler) postSendSplit(ctx context.Context, ctxLogger telemetry.Logger, c *fiber.Ctx, request requests.MessageSend) error {
+	messages, err := h.service.SendSplitMessage(ctx, request.ToMessageSendParams(h.userIDFomContext(c), c.OriginalURL()))
+	if stacktrace.GetCode(err) == services.ErrCodeContactSuppressed {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot send split message to suppressed contact [%s]", request.To)))
+		return h.responseUnprocessableEntity(c, map[string][]string{"to": {fmt.Sprintf("the contact [%s] opted out of receiving messages and is on your suppression list", request.To)}}, "validation errors while sending message")
+	}
+
+	if limited := h.contactLimitError(err, request.To); limited != nil {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot send split message to rate limited contact [%s]", request.To)))
+		return h.responseTooManyRequests(c, limited, "too many messages sent to the contact")
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot send split message with paylod [%s]", c.Body())
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("[%d] messages added to queue", len(messages)), messages)
+}
+
+func (h *MessageHandler) postSendDryRun(ctx context.Context, ctxLogger telemetry.Logger, c *fiber.Ctx, request requests.MessageSend) error {
+	preview, err := h.service.PreviewMessage(ctx, request.ToMessageSendParams(h.userIDFomContext(c), c.OriginalURL()), request.Split, 0)
+	if stacktrace.GetCode(err) == services.ErrCodeContactSuppressed {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot preview message to suppressed contact [%s]", request.To)))
+		return h.responseUnprocessableEntity(c, map[string][]string{"to": {fmt.Sprintf("the contact [%s] opted out of receiving messages and is on your suppression list", request.To)}}, "validation errors while sending message")
+	}
+
+	if limited := h.contactLimitError(err, request.To); limited != nil {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot preview message to rate limited contact [%s]", request.To)))
+		return h.responseTooManyRequests(c, limited, "too many messages sent to the contact")
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot preview message with paylod [%s]", c.Body())
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "dry run completed, the message was not sent", preview)
+}
+
+// segments returns the number of SMS segments of each message
+func (h *MessageHandler) segments(params []services.MessageSendParams) []uint {
+	result := make([]uint, 0, len(params))
+	for _, message := range params {
+		result = append(result, message.Segments())
+	}
+	return result
+}
+
+// contactLimitError returns the validation error when the message was not sent because of the duplicate message window or the contact rate limit of the user
+func (h *MessageHandler) contactLimitError(err error, contact string) url.Values {
+	switch stacktrace.GetCode(err) {
+	case services.ErrCodeDuplicateMessage:
+		return url.Values{"content": {fmt.Sprintf("the same message was recently sent to the contact [%s], wait before sending it again", contact)}}
+	case services.ErrCodeContactRateLimited:
+		return url.Values{"to": {fmt.Sprintf("you have sent too many messages to the contact [%s], wait before sending another message", contact)}}
+	default:
+		return nil
+	}
+}
+
 // BulkSend a bulk entities.Message
 // @Summary      Send bulk SMS messages
-// @Description  Add bulk SMS messages to be sent by the android phone
+// @Description  Add bulk SMS messages to be sent by the android phone. When dry_run is true, the response contains a preview of each message and nothing is stored or sent.
 // @Security	 ApiKeyAuth
 // @Tags         Messages
 // @Accept       json
 // @Produce      json
 // @Param        payload   body requests.MessageBulkSend  true  "Bulk send message request payload"
+// @Param        dry_run	query	bool	false	"validate the request and preview the messages without sending them"
+// @Param        Idempotency-Key	header	string	false	"Unique key to make sure the request is processed only once when it is retried"
 // @Success      200  {object}  []responses.MessagesResponse
 // @Failure      400  {object}  responses.BadRequest
 // @Failure 	 401  {object}	responses.Unauthorized
+// @Failure      409  {object}  responses.Conflict
 // @Failure      422  {object}  responses.UnprocessableEntity
 // @Failure      500  {object}  responses.InternalServerError
 // @Router       /messages/bulk-send [post]
@@ -134,6 +231,7 @@ func (h *MessageHandler) BulkSend(c *fiber.Ctx) error {
 		ctxLogger.Warn(stacktrace.Propagate(err, msg))
 		return h.responseBadRequest(c, err)
 	}
+	request.DryRun = request.DryRun || c.QueryBool("dry_run")
 
 	if errors := h.validator.ValidateMessageBulkSend(ctx, h.userIDFomContext(c), request.Sanitize()); len(errors) != 0 {
 		msg := fmt.Sprintf("validation errors [%s], while sending payload [%s]", spew.Sdump(errors), c.Body())
@@ -141,29 +239,48 @@ func (h *MessageHandler) BulkSend(c *fiber.Ctx) error {
 		return h.responseUnprocessableEntity(c, errors, "validation errors while sending messages")
 	}
 
-	if msg := h.billingService.IsEntitledWithCount(ctx, h.userIDFomContext(c), uint(len(request.To))); msg != nil {
+	if request.ContactListID != "" {
+		contacts, err := h.contactListService.Contacts(ctx, h.userIDFomContext(c), uuid.MustParse(request.ContactListID))
+		if err != nil {
+			msg := fmt.Sprintf("cannot fetch contacts of contact list [%s] for user [%s]", request.ContactListID, h.userIDFomContext(c))
+			ctxLogger.Error(stacktrace.Propagate(err, msg))
+			return h.responseInternalServerError(c)
+		}
+
+		request.AddContacts(contacts)
+		if len(request.To) == 0 {
+			return h.responseUnprocessableEntity(c, map[string][]string{"contact_list_id": {fmt.Sprintf("the contact list [%s] has no members which can receive messages", request.ContactListID)}}, "validation errors while sending messages")
+		}
+
+		if len(request.To) > 1000 {
+			ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("cannot send [%d] messages to contact list [%s] for user [%s]", len(request.To), request.ContactListID, h.userIDFomContext(c))))
+			return h.responseUnprocessableEntity(c, map[string][]string{"contact_list_id": {fmt.Sprintf("you cannot send more than 1000 messages at once, the contact list has [%d] recipients", len(request.To))}}, "validation errors while sending messages")
+		}
+	}
+
+	params := request.ToMessageSendParams(h.userIDFomContext(c), c.OriginalURL())
+	if msg := h.billingService.IsEntitledToSend(ctx, h.userIDFomContext(c), h.segments(params)...); msg != nil {
 		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("user with ID [%s] is not entitled to send [%d] messages", h.userIDFomContext(c), len(request.To))))
 		return h.responsePaymentRequired(c, *msg)
 	}
 
-	wg := sync.WaitGroup{}
-	params := request.ToMessageSendParams(h.userIDFomContext(c), c.OriginalURL())
-	responses := make([]*entities.Message, len(params))
-
-	for index, message := range params {
-		wg.Add(1)
-		go func(message services.MessageSendParams, index int) {
-			response, err := h.service.SendMessage(ctx, message)
-			if err != nil {
-				msg := fmt.Sprintf("cannot send message with paylod [%s]", c.Body())
-				ctxLogger.Error(stacktrace.Propagate(err, msg))
-			}
-			responses[index] = response
-			wg.Done()
-		}(message, index)
-	}
-
-	wg.Wait()
+	if request.DryRun {
+		previews, err := h.service.PreviewMessages(ctx, params)
+		if err != nil {
+			msg := fmt.Sprintf("cannot preview messages with paylod [%s]", c.Body())
+			ctxLogger.Error(stacktrace.Propagate(err, msg))
+			return h.responseInternalServerError(c)
+		}
+		return h.responseOK(c, fmt.Sprintf("dry run completed, [%d] messages were not sent", len(previews)), previews)
+	}
+
+	responses, err := h.service.SendMessages(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot send messages with paylod [%s]", c.Body())
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
 	return h.responseOK(c, fmt.Sprintf("[%d] messages processed successfully", len(responses)), responses)
 }
 
@@ -475,6 +592,7 @@ func (h *MessageHandler) PostCallMissed(c *fiber.Ctx) error {
 // @Param        owners		query  string  	true 	"the owner's phone numbers" 		default(+18005550199,+18005550100)
 // @Param        skip		query  int  	false	"number of messages to skip"		minimum(0)
 // @Param        query		query  string  	false 	"filter messages containing query"
+// @Param        failure_codes	query  string  	false 	"filter messages by failure codes"	default(PHONE_OFFLINE,NO_SERVICE)
 // @Param        limit		query  int  	false	"number of messages to return"		minimum(1)	maximum(200)
 // @Success      200 		{object}	responses.MessagesResponse
 // @Failure      400		{object}	responses.BadRequest
@@ -0,0 +1,508 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/slack"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/NdoleStudio/httpsms/pkg/validators"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+// slackSignatureMaxAge is the maximum age of a signed slack request to prevent replay attacks
+const slackSignatureMaxAge = 5 * time.Minute
+
+// SlackHandler handles slack events
+type SlackHandler struct {
+	handler
+	logger           telemetry.Logger
+	tracer           telemetry.Tracer
+	billingService   *services.BillingService
+	messageValidator *validators.MessageHandlerValidator
+	validator        *validators.SlackHandlerValidator
+	service          *services.SlackService
+	messageService   *services.MessageService
+}
+
+// NewSlackHandler creates a new SlackHandler
+func NewSlackHandler(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	validator *validators.SlackHandlerValidator,
+	service *services.SlackService,
+	messageService *services.MessageService,
+	billingService *services.BillingService,
+	messageValidator *validators.MessageHandlerValidator,
+) (h *SlackHandler) {
+	return &SlackHandler{
+		logger:           logger.WithService(fmt.Sprintf("%T", h)),
+		tracer:           tracer,
+		validator:        validator,
+		service:          service,
+		messageService:   messageService,
+		billingService:   billingService,
+		messageValidator: messageValidator,
+	}
+}
+
+// RegisterRoutes registers the routes for the SlackHandler
+func (h *SlackHandler) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler, middlewares ...fiber.Handler) {
+	router := app.Group("slack")
+	router.Post("/command", h.computeRoute(middlewares, h.Command)...)
+	router.Post("/interaction", h.computeRoute(middlewares, h.Interaction)...)
+
+	authRouter := app.Group("v1/slack-integrations")
+	authRouter.Post("/", h.computeRoute(append(middlewares, authMiddleware), h.Store)...)
+	authRouter.Get("/", h.computeRoute(append(middlewares, authMiddleware), h.Index)...)
+	authRouter.Delete("/:slackID", h.computeRoute(append(middlewares, authMiddleware), h.Delete)...)
+	authRouter.Put("/:slackID", h.computeRoute(append(middlewares, authMiddleware), h.Update)...)
+}
+
+// Index returns the slack integrations of a user
+// @Summary      Get slack integrations of a user
+// @Description  Get the slack integrations of a user
+// @Security	 ApiKeyAuth
+// @Tags         SlackIntegration
+// @Accept       json
+// @Produce      json
+// @Param        skip		query  int  	false	"number of slack integrations to skip"		minimum(0)
+// @Param        query		query  string  	false 	"filter slack integrations containing query"
+// @Param        limit		query  int  	false	"number of slack integrations to return"	minimum(1)	maximum(20)
+// @Success      200 		{object}	responses.SlacksResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /slack-integrations 	[get]
+func (h *SlackHandler) Index(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.SlackIndex
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateIndex(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while fetching slack integrations [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while fetching slack integrations")
+	}
+
+	slackIntegrations, err := h.service.Index(ctx, h.userIDFomContext(c), request.ToIndexParams())
+	if err != nil {
+		msg := fmt.Sprintf("cannot get slack integrations with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("fetched %d slack %s", len(slackIntegrations), h.pluralize("integration", len(slackIntegrations))), slackIntegrations)
+}
+
+// Delete a slack integration
+// @Summary      Delete slack integration
+// @Description  Delete a slack integration for a user
+// @Security	 ApiKeyAuth
+// @Tags         SlackIntegration
+// @Accept       json
+// @Produce      json
+// @Param 		 slackID 	path		string 				true 	"ID of the slack integration"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      204		{object}    responses.NoContent
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /slack-integrations/{slackID} [delete]
+func (h *SlackHandler) Delete(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	slackID := c.Params("slackID")
+	if errors := h.validator.ValidateUUID(ctx, slackID, "slackID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while deleting slack integration with ID [%s]", spew.Sdump(errors), slackID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while deleting slack integration")
+	}
+
+	err := h.service.Delete(ctx, h.userIDFomContext(c), uuid.MustParse(slackID))
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete slack integration with ID [%+#v]", slackID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "slack integration deleted successfully", nil)
+}
+
+// Update an entities.Slack
+// @Summary      Update a slack integration
+// @Description  Update a slack integration for the currently authenticated user
+// @Security	 ApiKeyAuth
+// @Tags         SlackIntegration
+// @Accept       json
+// @Produce      json
+// @Param 		 slackID	path		string 							true 	"ID of the slack integration" 					default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        payload   	body 		requests.SlackUpdate  			true 	"Payload of slack integration to update"
+// @Success      200 		{object}	responses.SlackResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /slack-integrations/{slackID} 	[put]
+func (h *SlackHandler) Update(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.SlackUpdate
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall params [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	request.SlackID = c.Params("slackID")
+	if errors := h.validator.ValidateUpdate(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while updating slack integration [%+#v]", spew.Sdump(errors), request.SlackID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while updating slack integration")
+	}
+
+	slackIntegration, err := h.service.Update(ctx, request.ToUpdateParams(h.userFromContext(c)))
+	if err != nil {
+		msg := fmt.Sprintf("cannot update slack integration with ID [%s]", request.SlackID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "slack integration updated successfully", slackIntegration)
+}
+
+// Store an entities.Slack
+// @Summary      Store slack integration
+// @Description  Store a slack integration for the authenticated user
+// @Security	 ApiKeyAuth
+// @Tags         SlackIntegration
+// @Accept       json
+// @Produce      json
+// @Param        payload   	body 		requests.SlackStore  		true "Payload of the slack integration request"
+// @Success      201 		{object}	responses.SlackResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /slack-integrations [post]
+func (h *SlackHandler) Store(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.SlackStore
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall body [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateStore(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while storing slack integration with name [%s]", spew.Sdump(errors), request.Name)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while storing slack integration")
+	}
+
+	slackIntegrations, err := h.service.Index(ctx, h.userIDFomContext(c), repositories.IndexParams{Skip: 0, Limit: 1})
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot index slack integrations for user [%s]", h.userIDFomContext(c))))
+		return h.responseInternalServerError(c)
+	}
+
+	if len(slackIntegrations) > 0 {
+		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("user with ID [%s] wants to create more than 1 slack integration", h.userIDFomContext(c))))
+		return h.responsePaymentRequired(c, "You can't create more than 1 slack integration contact us to upgrade your account.")
+	}
+
+	slackIntegration, err := h.service.Store(ctx, request.ToStoreParams(h.userFromContext(c)))
+	if err != nil {
+		msg := fmt.Sprintf("cannot store slack integration with name [%s]", request.Name)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseCreated(c, "slack integration created successfully", slackIntegration)
+}
+
+// Command consumes the /sms slash command
+// @Summary      Consume a slack slash command
+// @Description  Send an SMS with the /sms [from] [to] [message] slash command in a slack workspace
+// @Tags         Slack
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Success      200 		{object}	responses.NoContent
+// @Failure      401    	{object}	responses.Unauthorized
+// @Router       /slack/command [post]
+func (h *SlackHandler) Command(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	if verified := h.verifyRequest(ctxLogger, c); !verified {
+		return h.responseUnauthorized(c)
+	}
+
+	slackIntegration, err := h.service.GetByTeamID(ctx, c.FormValue("team_id"))
+	if err != nil {
+		msg := fmt.Sprintf("cannot get slack integration by team ID [%s]", c.FormValue("team_id"))
+		ctxLogger.Error(h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return h.slackError(c, "We cannot find the link between your slack workspace and an account on <https://httpsms.com/settings|httpsms.com>.")
+	}
+
+	from, to, content := h.parseCommand(c.FormValue("text"))
+	request := requests.MessageSend{From: from, To: to, Content: content}
+
+	message, errors := h.sendSMS(ctx, slackIntegration, request, c.OriginalURL())
+	if len(errors) > 0 {
+		return h.slackError(c, errors...)
+	}
+
+	return c.JSON(fiber.Map{
+		"response_type": "in_channel",
+		"text":          "✔ sending sms",
+		"blocks":        h.messageBlocks("*✔ sending sms*", message),
+	})
+}
+
+// Interaction consumes a slack interaction
+// @Summary      Consume a slack interaction
+// @Description  Handle the "Reply" button on a received message and the reply modal which sends the SMS
+// @Tags         Slack
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Success      200 		{object}	responses.NoContent
+// @Failure      400		{object}	responses.BadRequest
+// @Failure      401    	{object}	responses.Unauthorized
+// @Router       /slack/interaction [post]
+func (h *SlackHandler) Interaction(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	if verified := h.verifyRequest(ctxLogger, c); !verified {
+		return h.responseUnauthorized(c)
+	}
+
+	payload := new(slack.InteractionPayload)
+	if err := json.Unmarshal([]byte(c.FormValue("payload")), payload); err != nil {
+		msg := fmt.Sprintf("cannot unmarshall [%s] to [%T]", c.FormValue("payload"), payload)
+		ctxLogger.Error(h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return h.responseBadRequest(c, err)
+	}
+
+	slackIntegration, err := h.service.GetByTeamID(ctx, payload.Team.ID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot get slack integration by team ID [%s]", payload.Team.ID)
+		ctxLogger.Error(h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return c.SendStatus(fiber.StatusOK)
+	}
+
+	switch {
+	case payload.Type == slack.InteractionTypeBlockActions && payload.ActionID() == services.SlackActionReply:
+		return h.openReplyModal(ctx, c, slackIntegration, payload)
+	case payload.Type == slack.InteractionTypeViewSubmission && payload.View.CallbackID == services.SlackViewReply:
+		return h.sendReply(ctx, c, slackIntegration, payload)
+	default:
+		ctxLogger.Info(fmt.Sprintf("ignoring slack interaction of type [%s] for team [%s]", payload.Type, payload.Team.ID))
+		return c.SendStatus(fiber.StatusOK)
+	}
+}
+
+func (h *SlackHandler) openReplyModal(ctx context.Context, c *fiber.Ctx, slackIntegration *entities.Slack, payload *slack.InteractionPayload) error {
+	ctx, span, ctxLogger := h.tracer.StartWithLogger(ctx, h.logger)
+	defer span.End()
+
+	reply := new(services.SlackReply)
+	if err := json.Unmarshal([]byte(payload.ActionValue()), reply); err != nil {
+		msg := fmt.Sprintf("cannot unmarshall [%s] to [%T]", payload.ActionValue(), reply)
+		ctxLogger.Error(h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return h.responseBadRequest(c, err)
+	}
+
+	reply.ChannelID = payload.Channel.ID
+	reply.ThreadTS = payload.Message.TS
+
+	if err := h.service.OpenReplyModal(ctx, slackIntegration, payload.TriggerID, reply); err != nil {
+		msg := fmt.Sprintf("cannot open reply modal for slack integration [%s]", slackIntegration.ID)
+		ctxLogger.Error(h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *SlackHandler) sendReply(ctx context.Context, c *fiber.Ctx, slackIntegration *entities.Slack, payload *slack.InteractionPayload) error {
+	ctx, span, ctxLogger := h.tracer.StartWithLogger(ctx, h.logger)
+	defer span.End()
+
+	reply := new(services.SlackReply)
+	if err := json.Unmarshal([]byte(payload.View.PrivateMetadata), reply); err != nil {
+		msg := fmt.Sprintf("cannot unmarshall [%s] to [%T]", payload.View.PrivateMetadata, reply)
+		ctxLogger.Error(h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return h.responseBadRequest(c, err)
+	}
+
+	request := requests.MessageSend{
+		From:    reply.Owner,
+		To:      reply.Contact,
+		Content: payload.InputValue(services.SlackInputContent, services.SlackInputContent),
+	}
+
+	message, errors := h.sendSMS(ctx, slackIntegration, request, c.OriginalURL())
+	if len(errors) > 0 {
+		return c.JSON(fiber.Map{
+			"response_action": "errors",
+			"errors":          fiber.Map{services.SlackInputContent: strings.Join(errors, " ")},
+		})
+	}
+
+	h.service.SendReplyConfirmation(ctx, slackIntegration, reply, message)
+	return c.JSON(fiber.Map{"response_action": "clear"})
+}
+
+// sendSMS validates and sends an SMS for a slack workspace. It returns the errors which are displayed in slack.
+func (h *SlackHandler) sendSMS(ctx context.Context, slackIntegration *entities.Slack, request requests.MessageSend, source string) (*entities.Message, []string) {
+	ctx, span, ctxLogger := h.tracer.StartWithLogger(ctx, h.logger)
+	defer span.End()
+
+	if errors := h.messageValidator.ValidateMessageSend(ctx, slackIntegration.UserID, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while sending SMS from slack team [%s]", spew.Sdump(errors), slackIntegration.TeamID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+
+		var result []string
+		for _, value := range errors {
+			result = append(result, value...)
+		}
+		return nil, result
+	}
+
+	params := request.ToMessageSendParams(slackIntegration.UserID, source)
+	if msg := h.billingService.IsEntitledToSend(ctx, slackIntegration.UserID, params.Segments()); msg != nil {
+		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("user with ID [%s] can't send a message", slackIntegration.UserID)))
+		return nil, []string{*msg}
+	}
+
+	message, err := h.messageService.SendMessage(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot send message from slack team [%s]", slackIntegration.TeamID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return nil, []string{"Internal server error while sending SMS. Please try again later or contact support."}
+	}
+
+	return message, nil
+}
+
+// parseCommand splits the text of the /sms [from] [to] [message] slash command
+func (h *SlackHandler) parseCommand(text string) (from string, to string, content string) {
+	from, rest, _ := strings.Cut(strings.TrimSpace(text), " ")
+	to, content, _ = strings.Cut(strings.TrimSpace(rest), " ")
+	return from, to, strings.TrimSpace(content)
+}
+
+func (h *SlackHandler) messageBlocks(title string, message *entities.Message) []fiber.Map {
+	return []fiber.Map{
+		{
+			"type": "section",
+			"text": fiber.Map{"type": "mrkdwn", "text": title},
+		},
+		{
+			"type": "section",
+			"fields": []fiber.Map{
+				{"type": "mrkdwn", "text": "*From:*\n" + message.Owner},
+				{"type": "mrkdwn", "text": "*To:*\n" + message.Contact},
+			},
+		},
+		{
+			"type": "section",
+			"text": fiber.Map{"type": "plain_text", "text": message.Content},
+		},
+		{
+			"type":     "context",
+			"elements": []fiber.Map{{"type": "mrkdwn", "text": "MessageID: " + message.ID.String()}},
+		},
+	}
+}
+
+func (h *SlackHandler) slackError(c *fiber.Ctx, errors ...string) error {
+	blocks := []fiber.Map{
+		{
+			"type": "section",
+			"text": fiber.Map{"type": "mrkdwn", "text": "*⚠️ error while sending message*"},
+		},
+	}
+	for _, value := range errors {
+		blocks = append(blocks, fiber.Map{
+			"type": "context",
+			"elements": []fiber.Map{
+				{"type": "mrkdwn", "text": value},
+			},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"response_type": "ephemeral",
+		"text":          "⚠️ error while sending message",
+		"blocks":        append(blocks, fiber.Map{"type": "context", "elements": []fiber.Map{{"type": "mrkdwn", "text": "Usage: `/sms [from] [to] [message]`"}}}),
+	})
+}
+
+// verifyRequest implements the verification of requests from slack using the signing secret, as documented here:
+// https://api.slack.com/authentication/verifying-requests-from-slack
+func (h *SlackHandler) verifyRequest(ctxLogger telemetry.Logger, c *fiber.Ctx) bool {
+	signature := c.Get("X-Slack-Signature")
+	if signature == "" {
+		ctxLogger.Info("X-Slack-Signature header is empty")
+		return false
+	}
+
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "v0="))
+	if err != nil {
+		ctxLogger.Info(fmt.Sprintf("cannot decode X-Slack-Signature [%s]", signature))
+		return false
+	}
+
+	timestamp := c.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		ctxLogger.Info(fmt.Sprintf("cannot parse X-Slack-Request-Timestamp [%s]", timestamp))
+		return false
+	}
+
+	if math.Abs(time.Since(time.Unix(seconds, 0)).Seconds()) > slackSignatureMaxAge.Seconds() {
+		ctxLogger.Info(fmt.Sprintf("X-Slack-Request-Timestamp [%s] is older than [%s]", timestamp, slackSignatureMaxAge))
+		return false
+	}
+
+	secret := os.Getenv("SLACK_SIGNING_SECRET")
+	if secret == "" {
+		ctxLogger.Error(stacktrace.NewError("SLACK_SIGNING_SECRET env variable is empty"))
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(c.Body())
+
+	return hmac.Equal(sig, mac.Sum(nil))
+}
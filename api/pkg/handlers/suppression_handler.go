@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/NdoleStudio/httpsms/pkg/validators"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+// SuppressionHandler handles suppression list http requests
+type SuppressionHandler struct {
+	handler
+	logger    telemetry.Logger
+	tracer    telemetry.Tracer
+	service   *services.SuppressionService
+	validator *validators.SuppressionHandlerValidator
+}
+
+// NewSuppressionHandler creates a new SuppressionHandler
+func NewSuppressionHandler(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	service *services.SuppressionService,
+	validator *validators.SuppressionHandlerValidator,
+) (h *SuppressionHandler) {
+	return &SuppressionHandler{
+		logger:    logger.WithService(fmt.Sprintf("%T", h)),
+		tracer:    tracer,
+		service:   service,
+		validator: validator,
+	}
+}
+
+// RegisterRoutes registers the routes for the SuppressionHandler
+func (h *SuppressionHandler) RegisterRoutes(app *fiber.App, middlewares ...fiber.Handler) {
+	router := app.Group("/v1/suppressions")
+	router.Get("/", h.computeRoute(middlewares, h.Index)...)
+	router.Post("/", h.computeRoute(middlewares, h.Store)...)
+	router.Delete("/:suppressionID", h.computeRoute(middlewares, h.Delete)...)
+}
+
+// Index returns the suppression list of a user
+// @Summary      Get the suppression list of a user
+// @Description  Get the contacts which opted out of receiving messages sorted by the created time in descending order.
+// @Security	 ApiKeyAuth
+// @Tags         Suppressions
+// @Accept       json
+// @Produce      json
+// @Param        skip		query  int  	false	"number of suppressions to skip"		minimum(0)
+// @Param        query		query  string  	false 	"filter suppressions containing query"
+// @Param        limit		query  int  	false	"number of suppressions to return"	minimum(1)	maximum(100)
+// @Success      200 		{object}	responses.SuppressionsResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /suppressions 	[get]
+func (h *SuppressionHandler) Index(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.SuppressionIndex
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateIndex(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while fetching suppressions [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while fetching suppressions")
+	}
+
+	suppressions, err := h.service.Index(ctx, h.userIDFomContext(c), request.ToIndexParams())
+	if err != nil {
+		msg := fmt.Sprintf("cannot get suppressions with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("fetched %d %s", len(suppressions), h.pluralize("suppression", len(suppressions))), suppressions)
+}
+
+// Store a suppression
+// @Summary      Add a contact to the suppression list
+// @Description  Add a contact to the suppression list so that no messages can be sent to the contact
+// @Security	 ApiKeyAuth
+// @Tags         Suppressions
+// @Accept       json
+// @Produce      json
+// @Param        payload   	body 		requests.SuppressionStore  	true "Payload of the suppression request"
+// @Success      201 		{object}	responses.SuppressionResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /suppressions [post]
+func (h *SuppressionHandler) Store(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.SuppressionStore
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall body [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateStore(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while storing suppression [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while storing suppression")
+	}
+
+	suppression, err := h.service.Store(ctx, request.ToStoreParams(h.userFromContext(c)))
+	if err != nil {
+		msg := fmt.Sprintf("cannot store suppression with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseCreated(c, "contact added to the suppression list successfully", suppression)
+}
+
+// Delete a suppression
+// @Summary      Remove a contact from the suppression list
+// @Description  Remove a contact from the suppression list so that messages can be sent to the contact again
+// @Security	 ApiKeyAuth
+// @Tags         Suppressions
+// @Accept       json
+// @Produce      json
+// @Param 		 suppressionID	path		string 							true 	"ID of the suppression"	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      204		{object}    responses.NoContent
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /suppressions/{suppressionID} [delete]
+func (h *SuppressionHandler) Delete(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	suppressionID := c.Params("suppressionID")
+	if errors := h.validator.ValidateUUID(ctx, suppressionID, "suppressionID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while deleting suppression with ID [%s]", spew.Sdump(errors), suppressionID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while deleting suppression")
+	}
+
+	if err := h.service.Delete(ctx, h.userIDFomContext(c), uuid.MustParse(suppressionID)); err != nil {
+		msg := fmt.Sprintf("cannot delete suppression with ID [%+#v]", suppressionID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseNoContent(c, "contact removed from the suppression list successfully")
+}
@@ -44,6 +44,8 @@ func (h *UserHandler) RegisterRoutes(router fiber.Router) {
 	router.Delete("/users/me", h.Delete)
 	router.Delete("/users/:userID/api-keys", h.DeleteAPIKey)
 	router.Put("/users/:userID/notifications", h.UpdateNotifications)
+	router.Put("/users/:userID/message-limits", h.UpdateMessageLimits)
+	router.Post("/users/:userID/rotate-callback-signing-key", h.RotateCallbackSigningKey)
 	router.Get("/users/subscription-update-url", h.subscriptionUpdateURL)
 	router.Delete("/users/subscription", h.cancelSubscription)
 }
@@ -184,6 +186,94 @@ func (h *UserHandler) UpdateNotifications(c *fiber.Ctx) error {
 	return h.responseOK(c, "user notification settings updated successfully", user)
 }
 
+// UpdateMessageLimits an entities.User
+// @Summary      Update message limits
+// @Description  Update the duplicate message protection and the per contact rate limit of a user. A window or limit of 0 disables the check.
+// @Security	 ApiKeyAuth
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Param 		 userID 	path		string 								true 	"ID of the user to update" 				default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        payload   	body 		requests.UserMessageLimitsUpdate	true 	"User message limits to update"
+// @Success      200 		{object}	responses.UserResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /users/{userID}/message-limits [put]
+func (h *UserHandler) UpdateMessageLimits(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.UserMessageLimitsUpdate
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall params [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateMessageLimitsUpdate(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while updating message limits [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while updating message limits")
+	}
+
+	user, err := h.service.UpdateMessageLimits(ctx, h.userIDFomContext(c), request.ToUserMessageLimitsUpdateParams())
+	if err != nil {
+		msg := fmt.Sprintf("cannot update message limits for [%T] with ID [%s]", user, h.userIDFomContext(c))
+		ctxLogger.Error(h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "user message limits updated successfully", user)
+}
+
+// RotateCallbackSigningKey replaces the key which signs the requests to the callback URL of messages
+// @Summary      Rotate the callback signing key of a user
+// @Description  Replace the key which signs the requests to the callback URL of messages. Requests are signed with both the new and the previous key until the overlap period expires so that receivers can be updated without downtime.
+// @Security	 ApiKeyAuth
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Param 		 userID 	path		string 									true 	"ID of the user to update" 				default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        payload   	body 		requests.UserCallbackSigningKeyRotate	true 	"Payload of the new signing key"
+// @Success      200 		{object}	responses.UserResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401    	{object}	responses.Unauthorized
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /users/{userID}/rotate-callback-signing-key [post]
+func (h *UserHandler) RotateCallbackSigningKey(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	if c.Params("userID") != string(h.userIDFomContext(c)) {
+		return h.responseUnauthorized(c)
+	}
+
+	var request requests.UserCallbackSigningKeyRotate
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall params [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	if errors := h.validator.ValidateCallbackSigningKeyRotate(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while rotating the callback signing key of user [%s]", spew.Sdump(errors), h.userIDFomContext(c))
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while rotating the callback signing key")
+	}
+
+	user, err := h.service.RotateCallbackSigningKey(ctx, h.userIDFomContext(c), request.ToRotateParams())
+	if err != nil {
+		msg := fmt.Sprintf("cannot rotate the callback signing key for [%T] with ID [%s]", user, h.userIDFomContext(c))
+		ctxLogger.Error(h.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "callback signing key rotated successfully", user)
+}
+
 // subscriptionUpdateURL returns the subscription update URL for the authenticated entities.User
 // @Summary      Currently authenticated user subscription update URL
 // @Description  Fetches the subscription URL of the authenticated user.
@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"fmt"
+	"net/url"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
 
 	"github.com/NdoleStudio/httpsms/pkg/repositories"
 
@@ -47,6 +50,11 @@ func (h *WebhookHandler) RegisterRoutes(app *fiber.App, middlewares ...fiber.Han
 	router.Post("/", h.computeRoute(middlewares, h.Store)...)
 	router.Put("/:webhookID", h.computeRoute(middlewares, h.Update)...)
 	router.Delete("/:webhookID", h.computeRoute(middlewares, h.Delete)...)
+	router.Get("/:webhookID/deliveries", h.computeRoute(middlewares, h.IndexDeliveries)...)
+	router.Post("/:webhookID/deliveries/:deliveryID/redeliver", h.computeRoute(middlewares, h.Redeliver)...)
+	router.Post("/:webhookID/rotate-signing-key", h.computeRoute(middlewares, h.RotateSigningKey)...)
+	router.Post("/:webhookID/enable", h.computeRoute(middlewares, h.Enable)...)
+	router.Post("/:webhookID/test", h.computeRoute(middlewares, h.Test)...)
 }
 
 // Index returns the webhooks of a user
@@ -223,3 +231,257 @@ func (h *WebhookHandler) Update(c *fiber.Ctx) error {
 
 	return h.responseOK(c, "webhook updated successfully", user)
 }
+
+// IndexDeliveries returns the delivery log of a webhook
+// @Summary      Get deliveries of a webhook
+// @Description  Get the log of requests which were sent to a webhook including the response status code, latency and attempt number
+// @Security	 ApiKeyAuth
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param 		 webhookID	path		string 	true 	"ID of the webhook" 						default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        skip		query  		int  	false	"number of deliveries to skip"			minimum(0)
+// @Param        query		query  		string  false 	"filter deliveries by event type, event ID or status"
+// @Param        limit		query  		int  	false	"number of deliveries to return"		minimum(1)	maximum(100)
+// @Success      200 		{object}	responses.WebhookDeliveriesResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /webhooks/{webhookID}/deliveries 	[get]
+func (h *WebhookHandler) IndexDeliveries(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.WebhookDeliveryIndex
+	if err := c.QueryParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall URL [%s] into %T", c.OriginalURL(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	request.WebhookID = c.Params("webhookID")
+	if errors := h.validator.ValidateDeliveryIndex(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while fetching webhook deliveries [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while fetching webhook deliveries")
+	}
+
+	deliveries, err := h.service.IndexDeliveries(ctx, h.userIDFomContext(c), uuid.MustParse(request.WebhookID), request.ToIndexParams())
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return h.responseNotFound(c, fmt.Sprintf("cannot find webhook with ID [%s]", request.WebhookID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot get webhook deliveries with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("fetched %d webhook deliveries", len(deliveries)), deliveries)
+}
+
+// Redeliver sends the event of a webhook delivery again
+// @Summary      Redeliver a webhook event
+// @Description  Send the event of a webhook delivery to the webhook again. The new attempt is added to the delivery log.
+// @Security	 ApiKeyAuth
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param 		 webhookID	path		string 	true 	"ID of the webhook" 			default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param 		 deliveryID	path		string 	true 	"ID of the webhook delivery" 	default(32343a19-da5e-4b1b-a767-3298a73703cb)
+// @Success      200 		{object}	responses.WebhookDeliveryResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /webhooks/{webhookID}/deliveries/{deliveryID}/redeliver 	[post]
+func (h *WebhookHandler) Redeliver(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	webhookID := c.Params("webhookID")
+	if errors := h.validator.ValidateUUID(ctx, webhookID, "webhookID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while redelivering webhook with ID [%s]", spew.Sdump(errors), webhookID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while redelivering webhook event")
+	}
+
+	deliveryID := c.Params("deliveryID")
+	if errors := h.validator.ValidateUUID(ctx, deliveryID, "deliveryID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while redelivering webhook delivery with ID [%s]", spew.Sdump(errors), deliveryID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while redelivering webhook event")
+	}
+
+	delivery, err := h.service.Redeliver(ctx, h.userIDFomContext(c), uuid.MustParse(webhookID), uuid.MustParse(deliveryID))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return h.responseNotFound(c, fmt.Sprintf("cannot find delivery with ID [%s] for webhook with ID [%s]", deliveryID, webhookID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot redeliver webhook delivery with ID [%s] for webhook [%s]", deliveryID, webhookID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("webhook event redelivered with status [%s]", delivery.Status), delivery)
+}
+
+// RotateSigningKey replaces the signing key of a webhook
+// @Summary      Rotate the signing key of a webhook
+// @Description  Replace the signing key of a webhook. Requests are signed with both the new and the previous key until the overlap period expires so that receivers can be updated without downtime.
+// @Security	 ApiKeyAuth
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param 		 webhookID	path		string 								true 	"ID of the webhook" 	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        payload   	body 		requests.WebhookSigningKeyRotate  	true 	"Payload of the new signing key"
+// @Success      200 		{object}	responses.WebhookResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /webhooks/{webhookID}/rotate-signing-key 	[post]
+func (h *WebhookHandler) RotateSigningKey(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.WebhookSigningKeyRotate
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall params [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	request.WebhookID = c.Params("webhookID")
+	if errors := h.validator.ValidateRotateSigningKey(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while rotating signing key of webhook [%s]", spew.Sdump(errors), request.WebhookID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while rotating webhook signing key")
+	}
+
+	webhook, err := h.service.RotateSigningKey(ctx, request.ToRotateParams(h.userFromContext(c)))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return h.responseNotFound(c, fmt.Sprintf("cannot find webhook with ID [%s]", request.WebhookID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot rotate signing key of webhook with ID [%s]", request.WebhookID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "webhook signing key rotated successfully", webhook)
+}
+
+// Enable activates a disabled webhook
+// @Summary      Enable a webhook
+// @Description  Send a test ping to a webhook which was disabled after failing too many times in a row and enable it again when the ping is successful.
+// @Security	 ApiKeyAuth
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param 		 webhookID	path		string 	true 	"ID of the webhook" 	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Success      200 		{object}	responses.WebhookResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /webhooks/{webhookID}/enable 	[post]
+func (h *WebhookHandler) Enable(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	webhookID := c.Params("webhookID")
+	if errors := h.validator.ValidateUUID(ctx, webhookID, "webhookID"); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while enabling webhook with ID [%s]", spew.Sdump(errors), webhookID)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while enabling webhook")
+	}
+
+	webhook, delivery, err := h.service.Enable(ctx, c.OriginalURL(), h.userIDFomContext(c), uuid.MustParse(webhookID))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return h.responseNotFound(c, fmt.Sprintf("cannot find webhook with ID [%s]", webhookID))
+	}
+
+	if stacktrace.GetCode(err) == services.ErrCodeWebhookPingFailed {
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot enable webhook with ID [%s]", webhookID)))
+		return h.responseUnprocessableEntity(c, h.pingFailedError(delivery), "the test ping to the webhook failed")
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot enable webhook with ID [%s]", webhookID)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, "webhook enabled successfully", webhook)
+}
+
+// Test sends a test event to a webhook
+// @Summary      Send a test event to a webhook
+// @Description  Send a synthetic event with a realistic payload to a webhook using the same signing and transport as real events. The response contains the status code, latency and body returned by the webhook.
+// @Security	 ApiKeyAuth
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param 		 webhookID	path		string 					true 	"ID of the webhook" 	default(32343a19-da5e-4b1b-a767-3298a73703ca)
+// @Param        payload   	body 		requests.WebhookTest  	true 	"Type of the test event"
+// @Success      200 		{object}	responses.WebhookDeliveryResponse
+// @Failure      400		{object}	responses.BadRequest
+// @Failure 	 401	    {object}	responses.Unauthorized
+// @Failure      404		{object}	responses.NotFound
+// @Failure      422		{object}	responses.UnprocessableEntity
+// @Failure      500		{object}	responses.InternalServerError
+// @Router       /webhooks/{webhookID}/test 	[post]
+func (h *WebhookHandler) Test(c *fiber.Ctx) error {
+	ctx, span, ctxLogger := h.tracer.StartFromFiberCtxWithLogger(c, h.logger)
+	defer span.End()
+
+	var request requests.WebhookTest
+	if err := c.BodyParser(&request); err != nil {
+		msg := fmt.Sprintf("cannot marshall params [%s] into [%T]", c.Body(), request)
+		ctxLogger.Warn(stacktrace.Propagate(err, msg))
+		return h.responseBadRequest(c, err)
+	}
+
+	request.WebhookID = c.Params("webhookID")
+	if errors := h.validator.ValidateTest(ctx, request.Sanitize()); len(errors) != 0 {
+		msg := fmt.Sprintf("validation errors [%s], while testing webhook [%+#v]", spew.Sdump(errors), request)
+		ctxLogger.Warn(stacktrace.NewError(msg))
+		return h.responseUnprocessableEntity(c, errors, "validation errors while testing webhook")
+	}
+
+	delivery, err := h.service.Test(ctx, request.ToTestParams(h.userFromContext(c), c.OriginalURL()))
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return h.responseNotFound(c, fmt.Sprintf("cannot find webhook with ID [%s]", request.WebhookID))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot test webhook with params [%+#v]", request)
+		ctxLogger.Error(stacktrace.Propagate(err, msg))
+		return h.responseInternalServerError(c)
+	}
+
+	return h.responseOK(c, fmt.Sprintf("test event sent to webhook with status [%s]", delivery.Status), delivery)
+}
+
+func (h *WebhookHandler) pingFailedError(delivery *entities.WebhookDelivery) url.Values {
+	result := url.Values{}
+	if delivery.ResponseStatusCode != nil {
+		result.Add("webhookID", fmt.Sprintf("The webhook at [%s] responded to the test ping with the HTTP status code [%d]", delivery.URL, *delivery.ResponseStatusCode))
+		return result
+	}
+
+	errorMessage := "no response"
+	if delivery.ErrorMessage != nil {
+		errorMessage = *delivery.ErrorMessage
+	}
+	result.Add("webhookID", fmt.Sprintf("The webhook at [%s] could not be reached with the test ping: %s", delivery.URL, errorMessage))
+	return result
+}
@@ -0,0 +1,74 @@
+package listeners
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/palantir/stacktrace"
+)
+
+// CampaignListener handles cloud events which update an entities.Campaign
+type CampaignListener struct {
+	logger  telemetry.Logger
+	tracer  telemetry.Tracer
+	service *services.CampaignService
+}
+
+// NewCampaignListener creates a new instance of CampaignListener
+func NewCampaignListener(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	service *services.CampaignService,
+) (l *CampaignListener, routes map[string]events.EventListener) {
+	l = &CampaignListener{
+		logger:  logger.WithService(fmt.Sprintf("%T", l)),
+		tracer:  tracer,
+		service: service,
+	}
+
+	return l, map[string]events.EventListener{
+		events.EventTypeCampaignMessageUpdated: l.onCampaignMessageUpdated,
+		events.UserAccountDeleted:              l.onUserAccountDeleted,
+	}
+}
+
+// onCampaignMessageUpdated handles the events.EventTypeCampaignMessageUpdated event
+func (listener *CampaignListener) onCampaignMessageUpdated(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload events.CampaignMessageUpdatedPayload
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.HandleMessageUpdated(ctx, event.Source(), &payload); err != nil {
+		msg := fmt.Sprintf("cannot handle [%s] event with ID [%s] for campaign [%s]", event.Type(), event.ID(), payload.CampaignID)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (listener *CampaignListener) onUserAccountDeleted(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload events.UserAccountDeletedPayload
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.DeleteAllForUser(ctx, payload.UserID); err != nil {
+		msg := fmt.Sprintf("cannot delete [entities.Campaign] for user [%s] on [%s] event with ID [%s]", payload.UserID, event.Type(), event.ID())
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
@@ -35,6 +35,7 @@ func NewEmailNotificationListener(
 		events.EventTypeMessageSendFailed:  l.OnMessageSendFailed,
 		events.EventTypeWebhookSendFailed:  l.OnWebhookSendFailed,
 		events.EventTypeDiscordSendFailed:  l.OnDiscordSendFailed,
+		events.EventTypeWebhookDisabled:    l.onWebhookDisabled,
 	}
 }
 
@@ -113,3 +114,22 @@ func (listener *EmailNotificationListener) OnDiscordSendFailed(ctx context.Conte
 
 	return nil
 }
+
+// onWebhookDisabled handles the events.EventTypeWebhookDisabled event
+func (listener *EmailNotificationListener) onWebhookDisabled(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	payload := new(events.WebhookDisabledPayload)
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.NotifyWebhookDisabled(ctx, payload); err != nil {
+		msg := fmt.Sprintf("cannot process [%s] event with ID [%s]", event.Type(), event.ID())
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package listeners
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/palantir/stacktrace"
+)
+
+// EventStreamListener publishes events to the realtime event stream of a user
+type EventStreamListener struct {
+	logger  telemetry.Logger
+	tracer  telemetry.Tracer
+	service *services.EventStreamService
+}
+
+// NewEventStreamListener creates a new instance of EventStreamListener
+func NewEventStreamListener(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	service *services.EventStreamService,
+) (l *EventStreamListener, routes map[string]events.EventListener) {
+	l = &EventStreamListener{
+		logger:  logger.WithService(fmt.Sprintf("%T", l)),
+		tracer:  tracer,
+		service: service,
+	}
+
+	routes = make(map[string]events.EventListener, len(services.StreamEventTypes))
+	for _, eventType := range services.StreamEventTypes {
+		routes[eventType] = l.onEvent
+	}
+
+	return l, routes
+}
+
+// onEvent handles the events in services.StreamEventTypes
+func (listener *EventStreamListener) onEvent(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload struct {
+		UserID entities.UserID `json:"user_id"`
+	}
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.Publish(ctx, payload.UserID, event); err != nil {
+		msg := fmt.Sprintf("cannot process [%s] event with ID [%s]", event.Type(), event.ID())
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package listeners
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/palantir/stacktrace"
+)
+
+// IntegrationMQTTListener publishes events to the MQTT brokers of users
+type IntegrationMQTTListener struct {
+	logger  telemetry.Logger
+	tracer  telemetry.Tracer
+	service *services.IntegrationMQTTService
+}
+
+// NewIntegrationMQTTListener creates a new instance of IntegrationMQTTListener
+func NewIntegrationMQTTListener(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	service *services.IntegrationMQTTService,
+) (l *IntegrationMQTTListener, routes map[string]events.EventListener) {
+	l = &IntegrationMQTTListener{
+		logger:  logger.WithService(fmt.Sprintf("%T", l)),
+		tracer:  tracer,
+		service: service,
+	}
+
+	routes = map[string]events.EventListener{
+		events.UserAccountDeleted: l.onUserAccountDeleted,
+	}
+	for _, eventType := range services.StreamEventTypes {
+		routes[eventType] = l.onEvent
+	}
+
+	return l, routes
+}
+
+// onEvent handles the events in services.StreamEventTypes
+func (listener *IntegrationMQTTListener) onEvent(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload struct {
+		UserID entities.UserID `json:"user_id"`
+	}
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.Send(ctx, payload.UserID, event); err != nil {
+		msg := fmt.Sprintf("cannot process [%s] event with ID [%s]", event.Type(), event.ID())
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (listener *IntegrationMQTTListener) onUserAccountDeleted(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload events.UserAccountDeletedPayload
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.DeleteAllForUser(ctx, payload.UserID); err != nil {
+		msg := fmt.Sprintf("cannot delete [entities.IntegrationMQTT] for user [%s] on [%s] event with ID [%s]", payload.UserID, event.Type(), event.ID())
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
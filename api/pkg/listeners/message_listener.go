@@ -42,7 +42,6 @@ func NewMessageListener(
 		events.EventTypeMessageSendExpired:           l.onMessageSendExpired,
 		events.EventTypeMessageNotificationScheduled: l.onMessageNotificationScheduled,
 		events.MessageThreadAPIDeleted:               l.onMessageThreadAPIDeleted,
-		events.MessageCallMissed:                     l.onMessageCallMissed,
 		events.UserAccountDeleted:                    l.onUserAccountDeleted,
 	}
 }
@@ -138,7 +137,9 @@ func (listener *MessageListener) OnMessagePhoneFailed(ctx context.Context, event
 		ID:           payload.ID,
 		UserID:       payload.UserID,
 		ErrorMessage: payload.ErrorMessage,
+		FailureCode:  payload.FailureCode,
 		Timestamp:    payload.Timestamp,
+		Source:       event.Source(),
 	}
 
 	if err := listener.service.HandleMessageFailed(ctx, handleParams); err != nil {
@@ -171,6 +172,7 @@ func (listener *MessageListener) onMessageNotificationFailed(ctx context.Context
 		EventName:    entities.MessageEventNameFailed,
 		Timestamp:    payload.NotificationFailedAt,
 		ErrorMessage: &payload.ErrorMessage,
+		FailureCode:  &payload.FailureCode,
 		Source:       event.Source(),
 	}
 	if _, err = listener.service.StoreEvent(ctx, message, storeParams); err != nil {
@@ -231,9 +233,10 @@ func (listener *MessageListener) onMessageSendExpiredCheck(ctx context.Context,
 	}
 
 	checkParams := services.MessageCheckExpired{
-		MessageID: payload.MessageID,
-		UserID:    payload.UserID,
-		Source:    event.Source(),
+		MessageID:          payload.MessageID,
+		UserID:             payload.UserID,
+		NotificationSentAt: payload.NotificationSentAt,
+		Source:             event.Source(),
 	}
 	if err := listener.service.CheckExpired(ctx, checkParams); err != nil {
 		msg := fmt.Sprintf("cannot check expiration for message with ID [%s] and userID [%s]", checkParams.MessageID, checkParams.UserID)
@@ -280,10 +283,11 @@ func (listener *MessageListener) onMessageNotificationScheduled(ctx context.Cont
 	}
 
 	expiredParams := services.HandleMessageParams{
-		ID:        payload.MessageID,
-		UserID:    payload.UserID,
-		Source:    event.Source(),
-		Timestamp: payload.ScheduledAt,
+		ID:            payload.MessageID,
+		UserID:        payload.UserID,
+		Source:        event.Source(),
+		Timestamp:     payload.ScheduledAt,
+		DeferredUntil: payload.DeferredUntil,
 	}
 	if err := listener.service.HandleMessageNotificationScheduled(ctx, expiredParams); err != nil {
 		msg := fmt.Sprintf("cannot handle event [%s] for ID [%s] and userID [%s]", event.Type(), expiredParams.ID, expiredParams.UserID)
@@ -312,25 +316,6 @@ func (listener *MessageListener) onMessageThreadAPIDeleted(ctx context.Context,
 	return nil
 }
 
-// onMessageThreadAPIDeleted handles the events.MessageThreadAPIDeleted event
-func (listener *MessageListener) onMessageCallMissed(ctx context.Context, event cloudevents.Event) error {
-	ctx, span := listener.tracer.Start(ctx)
-	defer span.End()
-
-	payload := new(events.MessageCallMissedPayload)
-	if err := event.DataAs(payload); err != nil {
-		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
-		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
-	}
-
-	if err := listener.service.RespondToMissedCall(ctx, event.Source(), payload); err != nil {
-		msg := fmt.Sprintf("cannot handle [%s] event with ID [%s] and userID [%s]", event.Type(), event.ID(), payload.UserID)
-		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
-	}
-
-	return nil
-}
-
 func (listener *MessageListener) onUserAccountDeleted(ctx context.Context, event cloudevents.Event) error {
 	ctx, span := listener.tracer.Start(ctx)
 	defer span.End()
@@ -37,6 +37,8 @@ func NewNotificationListener(
 		events.EventTypeMessageSendRetry:        l.onMessageSendRetry,
 		events.EventTypeMessageNotificationSend: l.onMessageNotificationSend,
 		events.PhoneHeartbeatMissed:             l.onPhoneHeartbeatMissed,
+		events.EventTypeCampaignResumed:         l.onCampaignResumed,
+		events.EventTypeCampaignCanceled:        l.onCampaignCanceled,
 		events.UserAccountDeleted:               l.onUserAccountDeleted,
 	}
 }
@@ -53,14 +55,16 @@ func (listener *PhoneNotificationListener) onMessageAPISent(ctx context.Context,
 	}
 
 	sendParams := &services.PhoneNotificationScheduleParams{
-		UserID:    payload.UserID,
-		Owner:     payload.Owner,
-		Contact:   payload.Contact,
-		Content:   payload.Content,
-		SIM:       payload.SIM,
-		Encrypted: payload.Encrypted,
-		Source:    event.Source(),
-		MessageID: payload.MessageID,
+		UserID:     payload.UserID,
+		Owner:      payload.Owner,
+		Contact:    payload.Contact,
+		Content:    payload.Content,
+		SIM:        payload.SIM,
+		Encrypted:  payload.Encrypted,
+		Source:     event.Source(),
+		MessageID:  payload.MessageID,
+		CampaignID: payload.CampaignID,
+		ExpiresAt:  payload.ExpiresAt,
 	}
 
 	if err := listener.service.Schedule(ctx, sendParams); err != nil {
@@ -83,14 +87,16 @@ func (listener *PhoneNotificationListener) onMessageSendRetry(ctx context.Contex
 	}
 
 	sendParams := &services.PhoneNotificationScheduleParams{
-		UserID:    payload.UserID,
-		Owner:     payload.Owner,
-		Contact:   payload.Contact,
-		Content:   payload.Content,
-		SIM:       payload.SIM,
-		Encrypted: payload.Encrypted,
-		Source:    event.Source(),
-		MessageID: payload.MessageID,
+		UserID:     payload.UserID,
+		Owner:      payload.Owner,
+		Contact:    payload.Contact,
+		Content:    payload.Content,
+		SIM:        payload.SIM,
+		Encrypted:  payload.Encrypted,
+		Source:     event.Source(),
+		MessageID:  payload.MessageID,
+		CampaignID: payload.CampaignID,
+		ExpiresAt:  payload.ExpiresAt,
 	}
 
 	if err := listener.service.Schedule(ctx, sendParams); err != nil {
@@ -134,10 +140,13 @@ func (listener *PhoneNotificationListener) onMessageNotificationSend(ctx context
 	scheduleParams := &services.PhoneNotificationSendParams{
 		UserID:              payload.UserID,
 		PhoneID:             payload.PhoneID,
+		Contact:             payload.Contact,
 		Source:              event.Source(),
 		ScheduledAt:         payload.ScheduledAt,
 		PhoneNotificationID: payload.NotificationID,
 		MessageID:           payload.MessageID,
+		CampaignID:          payload.CampaignID,
+		ExpiresAt:           payload.ExpiresAt,
 	}
 
 	if err := listener.service.Send(ctx, scheduleParams); err != nil {
@@ -148,6 +157,44 @@ func (listener *PhoneNotificationListener) onMessageNotificationSend(ctx context
 	return nil
 }
 
+// onCampaignResumed handles the events.EventTypeCampaignResumed event
+func (listener *PhoneNotificationListener) onCampaignResumed(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload events.CampaignResumedPayload
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.ReleaseHeld(ctx, event.Source(), payload.UserID, payload.CampaignID); err != nil {
+		msg := fmt.Sprintf("cannot release held notifications for campaign [%s] on [%s] event with ID [%s]", payload.CampaignID, event.Type(), event.ID())
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+// onCampaignCanceled handles the events.EventTypeCampaignCanceled event
+func (listener *PhoneNotificationListener) onCampaignCanceled(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload events.CampaignCanceledPayload
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.FailHeld(ctx, event.Source(), payload.UserID, payload.CampaignID); err != nil {
+		msg := fmt.Sprintf("cannot fail held notifications for campaign [%s] on [%s] event with ID [%s]", payload.CampaignID, event.Type(), event.ID())
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
 func (listener *PhoneNotificationListener) onUserAccountDeleted(ctx context.Context, event cloudevents.Event) error {
 	ctx, span := listener.tracer.Start(ctx)
 	defer span.End()
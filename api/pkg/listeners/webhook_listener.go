@@ -32,6 +32,7 @@ func NewWebhookListener(
 
 	return l, map[string]events.EventListener{
 		events.EventTypeMessagePhoneReceived:  l.OnMessagePhoneReceived,
+		events.EventTypeMessagePhoneSending:   l.onMessagePhoneSending,
 		events.EventTypeMessageSendExpired:    l.OnMessageSendExpired,
 		events.EventTypeMessagePhoneDelivered: l.OnMessagePhoneDelivered,
 		events.EventTypeMessageSendFailed:     l.OnMessageSendFailed,
@@ -39,7 +40,9 @@ func NewWebhookListener(
 		events.EventTypePhoneHeartbeatOnline:  l.onPhoneHeartbeatOnline,
 		events.EventTypePhoneHeartbeatOffline: l.onPhoneHeartbeatOffline,
 		events.MessageCallMissed:              l.onMessageCallMissed,
+		events.EventTypeCampaignCompleted:     l.onCampaignCompleted,
 		events.UserAccountDeleted:             l.onUserAccountDeleted,
+		events.EventTypeWebhookSendRetry:      l.onWebhookSendRetry,
 	}
 }
 
@@ -62,6 +65,21 @@ func (listener *WebhookListener) OnMessagePhoneReceived(ctx context.Context, eve
 	return nil
 }
 
+// onMessagePhoneSending handles the events.EventTypeMessagePhoneSending event
+func (listener *WebhookListener) onMessagePhoneSending(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload events.MessagePhoneSendingPayload
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	listener.service.SendCallback(ctx, payload.UserID, event, payload.CallbackURL)
+	return nil
+}
+
 // OnMessageSendExpired handles the events.EventTypeMessageSendExpired event
 func (listener *WebhookListener) OnMessageSendExpired(ctx context.Context, event cloudevents.Event) error {
 	ctx, span := listener.tracer.Start(ctx)
@@ -73,6 +91,8 @@ func (listener *WebhookListener) OnMessageSendExpired(ctx context.Context, event
 		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
+	listener.service.SendCallback(ctx, payload.UserID, event, payload.CallbackURL)
+
 	if err := listener.service.Send(ctx, payload.UserID, event, payload.Owner); err != nil {
 		msg := fmt.Sprintf("cannot process [%s] event with ID [%s]", event.Type(), event.ID())
 		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
@@ -92,6 +112,8 @@ func (listener *WebhookListener) OnMessageSendFailed(ctx context.Context, event
 		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
+	listener.service.SendCallback(ctx, payload.UserID, event, payload.CallbackURL)
+
 	if err := listener.service.Send(ctx, payload.UserID, event, payload.Owner); err != nil {
 		msg := fmt.Sprintf("cannot process [%s] event with ID [%s]", event.Type(), event.ID())
 		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
@@ -111,6 +133,8 @@ func (listener *WebhookListener) OnMessagePhoneSent(ctx context.Context, event c
 		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
+	listener.service.SendCallback(ctx, payload.UserID, event, payload.CallbackURL)
+
 	if err := listener.service.Send(ctx, payload.UserID, event, payload.Owner); err != nil {
 		msg := fmt.Sprintf("cannot process [%s] event with ID [%s]", event.Type(), event.ID())
 		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
@@ -130,6 +154,8 @@ func (listener *WebhookListener) OnMessagePhoneDelivered(ctx context.Context, ev
 		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
+	listener.service.SendCallback(ctx, payload.UserID, event, payload.CallbackURL)
+
 	if err := listener.service.Send(ctx, payload.UserID, event, payload.Owner); err != nil {
 		msg := fmt.Sprintf("cannot process [%s] event with ID [%s]", event.Type(), event.ID())
 		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
@@ -195,6 +221,25 @@ func (listener *WebhookListener) onMessageCallMissed(ctx context.Context, event
 	return nil
 }
 
+// onCampaignCompleted handles the events.EventTypeCampaignCompleted event
+func (listener *WebhookListener) onCampaignCompleted(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload events.CampaignCompletedPayload
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.Send(ctx, payload.UserID, event, payload.Owner); err != nil {
+		msg := fmt.Sprintf("cannot process [%s] event with ID [%s]", event.Type(), event.ID())
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
 func (listener *WebhookListener) onUserAccountDeleted(ctx context.Context, event cloudevents.Event) error {
 	ctx, span := listener.tracer.Start(ctx)
 	defer span.End()
@@ -212,3 +257,22 @@ func (listener *WebhookListener) onUserAccountDeleted(ctx context.Context, event
 
 	return nil
 }
+
+// onWebhookSendRetry handles the events.EventTypeWebhookSendRetry event
+func (listener *WebhookListener) onWebhookSendRetry(ctx context.Context, event cloudevents.Event) error {
+	ctx, span := listener.tracer.Start(ctx)
+	defer span.End()
+
+	var payload events.WebhookSendRetryPayload
+	if err := event.DataAs(&payload); err != nil {
+		msg := fmt.Sprintf("cannot decode [%s] into [%T]", event.Data(), payload)
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err := listener.service.Retry(ctx, &payload); err != nil {
+		msg := fmt.Sprintf("cannot process [%s] event with ID [%s]", event.Type(), event.ID())
+		return listener.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/gofiber/fiber/v2"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength  = 255
+)
+
+// Idempotency makes sure that a request with an Idempotency-Key header is processed only once.
+// The original response is returned when the request is repeated and requests which reuse the key with a different payload are rejected.
+func Idempotency(logger telemetry.Logger, tracer telemetry.Tracer, service *services.IdempotencyService) fiber.Handler {
+	logger = logger.WithService("middlewares.Idempotency")
+
+	return func(c *fiber.Ctx) error {
+		key := strings.TrimSpace(c.Get(idempotencyKeyHeader))
+		if key == "" {
+			return c.Next()
+		}
+
+		ctx, span := tracer.StartFromFiberCtx(c, "middlewares.Idempotency")
+		defer span.End()
+
+		ctxLogger := tracer.CtxLogger(logger, span)
+
+		authUser, ok := c.Locals(ContextKeyAuthUserID).(entities.AuthUser)
+		if !ok || authUser.IsNoop() {
+			return c.Next()
+		}
+
+		// a dry run does not store or send anything so it is not replayed when the request is sent for real with the same key
+		if isDryRun(c) {
+			return c.Next()
+		}
+
+		if len(key) > idempotencyKeyMaxLength {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"status":  "error",
+				"message": "validation errors with the idempotency key",
+				"data":    fiber.Map{idempotencyKeyHeader: []string{fmt.Sprintf("The [%s] header must not be longer than %d characters", idempotencyKeyHeader, idempotencyKeyMaxLength)}},
+			})
+		}
+
+		payload, err := idempotencyPayload(c)
+		if err != nil {
+			ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot read the payload of the request with idempotency key [%s]", key)))
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"status":  "error",
+				"message": "The request isn't properly formed",
+				"data":    err.Error(),
+			})
+		}
+
+		params := &services.IdempotencyParams{
+			UserID:  authUser.ID,
+			Key:     key,
+			Route:   c.Method() + " " + c.Path(),
+			Payload: payload,
+		}
+
+		response, err := service.Begin(ctx, params)
+		if stacktrace.GetCode(err) == services.ErrCodeIdempotencyKeyConflict {
+			ctxLogger.Warn(err)
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"status":  "error",
+				"message": "validation errors with the idempotency key",
+				"data":    fiber.Map{idempotencyKeyHeader: []string{fmt.Sprintf("The idempotency key [%s] has already been used with a different request payload", key)}},
+			})
+		}
+
+		if stacktrace.GetCode(err) == services.ErrCodeIdempotencyKeyInProgress {
+			ctxLogger.Warn(err)
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"status":  "error",
+				"message": fmt.Sprintf("A request with the idempotency key [%s] is still being processed", key),
+			})
+		}
+
+		if err != nil {
+			ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot check idempotency key [%s] for user [%s]", key, authUser.ID)))
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"status":  "error",
+				"message": "We ran into an internal error while handling the request.",
+			})
+		}
+
+		if response != nil {
+			c.Set(idempotentReplayedHeader, "true")
+			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			return c.Status(response.StatusCode).Send(response.Body)
+		}
+
+		if err = c.Next(); err != nil {
+			if releaseErr := service.Release(ctx, params); releaseErr != nil {
+				ctxLogger.Error(stacktrace.Propagate(releaseErr, fmt.Sprintf("cannot release idempotency key [%s] for user [%s]", key, authUser.ID)))
+			}
+			return err
+		}
+
+		// only successful and client error responses are stored. The key is released for other responses so that the request can be retried.
+		statusCode := c.Response().StatusCode()
+		if statusCode < fiber.StatusOK || statusCode >= fiber.StatusInternalServerError || (statusCode >= fiber.StatusMultipleChoices && statusCode < fiber.StatusBadRequest) {
+			if err = service.Release(ctx, params); err != nil {
+				ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot release idempotency key [%s] for user [%s]", key, authUser.ID)))
+			}
+			return nil
+		}
+
+		if err = service.Complete(ctx, params, statusCode, c.Response().Body()); err != nil {
+			ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot store response for idempotency key [%s] and user [%s]", key, authUser.ID)))
+		}
+
+		return nil
+	}
+}
+
+// isDryRun checks if the request only previews the result with the dry_run query parameter, form value or JSON field.
+func isDryRun(c *fiber.Ctx) bool {
+	if c.QueryBool("dry_run") || c.FormValue("dry_run") == "true" {
+		return true
+	}
+
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		return false
+	}
+
+	payload := struct {
+		DryRun bool `json:"dry_run"`
+	}{}
+	return json.Unmarshal(c.Body(), &payload) == nil && payload.DryRun
+}
+
+// idempotencyPayload returns the payload which identifies a request. The files and values of multipart forms are used because the boundary changes when a client retries the request.
+func idempotencyPayload(c *fiber.Ctx) ([]byte, error) {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return c.Body(), nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "cannot parse multipart form")
+	}
+
+	payload := new(bytes.Buffer)
+	for _, key := range sortedKeys(form.Value) {
+		payload.WriteString(fmt.Sprintf("%s=%s\n", key, strings.Join(form.Value[key], ",")))
+	}
+
+	for _, key := range sortedKeys(form.File) {
+		for _, header := range form.File[key] {
+			file, err := header.Open()
+			if err != nil {
+				return nil, stacktrace.Propagate(err, fmt.Sprintf("cannot open file [%s] in form field [%s]", header.Filename, key))
+			}
+
+			payload.WriteString(fmt.Sprintf("%s=%s\n", key, header.Filename))
+			_, err = io.Copy(payload, file)
+			_ = file.Close()
+			if err != nil {
+				return nil, stacktrace.Propagate(err, fmt.Sprintf("cannot read file [%s] in form field [%s]", header.Filename, key))
+			}
+		}
+	}
+
+	return payload.Bytes(), nil
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,158 @@
+package middlewares
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/cache"
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/gofiber/fiber/v2"
+	"github.com/hirosassa/zerodriver"
+	ttlCache "github.com/patrickmn/go-cache"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	t.Run("a repeated request is replayed", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		app, calls := testIdempotencyApp(fiber.StatusOK)
+
+		// Act
+		_, first := testIdempotencyRequest(t, app, "key-1", `{"content":"hello"}`)
+		response, second := testIdempotencyRequest(t, app, "key-1", `{"content":"hello"}`)
+
+		// Assert
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, fiber.StatusOK, response.StatusCode)
+		assert.Equal(t, "true", response.Header.Get(idempotentReplayedHeader))
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("a key reused with a different payload is rejected", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		app, calls := testIdempotencyApp(fiber.StatusOK)
+		testIdempotencyRequest(t, app, "key-1", `{"content":"hello"}`)
+
+		// Act
+		response, _ := testIdempotencyRequest(t, app, "key-1", `{"content":"bye"}`)
+
+		// Assert
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, response.StatusCode)
+	})
+
+	t.Run("requests without a key are always processed", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		app, calls := testIdempotencyApp(fiber.StatusOK)
+
+		// Act
+		testIdempotencyRequest(t, app, "", `{"content":"hello"}`)
+		response, _ := testIdempotencyRequest(t, app, "", `{"content":"hello"}`)
+
+		// Assert
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, "", response.Header.Get(idempotentReplayedHeader))
+	})
+
+	t.Run("the key is released when the request fails", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		app, calls := testIdempotencyApp(fiber.StatusInternalServerError)
+
+		// Act
+		testIdempotencyRequest(t, app, "key-1", `{"content":"hello"}`)
+		response, _ := testIdempotencyRequest(t, app, "key-1", `{"content":"hello"}`)
+
+		// Assert
+		assert.Equal(t, 2, *calls)
+		assert.Equal(t, fiber.StatusInternalServerError, response.StatusCode)
+		assert.Equal(t, "", response.Header.Get(idempotentReplayedHeader))
+	})
+
+	t.Run("client errors are replayed", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		app, calls := testIdempotencyApp(fiber.StatusUnprocessableEntity)
+
+		// Act
+		testIdempotencyRequest(t, app, "key-1", `{"content":"hello"}`)
+		response, _ := testIdempotencyRequest(t, app, "key-1", `{"content":"hello"}`)
+
+		// Assert
+		assert.Equal(t, 1, *calls)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, response.StatusCode)
+		assert.Equal(t, "true", response.Header.Get(idempotentReplayedHeader))
+	})
+
+	t.Run("a key longer than 255 characters is rejected", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		app, calls := testIdempotencyApp(fiber.StatusOK)
+
+		// Act
+		response, _ := testIdempotencyRequest(t, app, strings.Repeat("k", 256), `{"content":"hello"}`)
+
+		// Assert
+		assert.Equal(t, 0, *calls)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, response.StatusCode)
+	})
+}
+
+func testIdempotencyApp(statusCode int) (*fiber.App, *int) {
+	zl := zerolog.Nop()
+	logger := telemetry.NewZerologLogger("test", map[string]string{}, &zerodriver.Logger{Logger: &zl}, nil)
+	tracer := telemetry.NewOtelLogger("test", logger)
+	service := services.NewIdempotencyService(logger, tracer, cache.NewMemoryCache(tracer, ttlCache.New(time.Minute, time.Minute)))
+
+	calls := 0
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(ContextKeyAuthUserID, entities.AuthUser{ID: "user-1", Email: "name@example.com"})
+		return c.Next()
+	})
+	app.Use(Idempotency(logger, tracer, service))
+	app.Post("/v1/messages/send", func(c *fiber.Ctx) error {
+		calls++
+		return c.Status(statusCode).JSON(fiber.Map{"status": "success", "call": calls})
+	})
+
+	return app, &calls
+}
+
+func testIdempotencyRequest(t *testing.T, app *fiber.App, key string, body string) (*http.Response, string) {
+	request := httptest.NewRequest(fiber.MethodPost, "/v1/messages/send", strings.NewReader(body))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		request.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	content, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	return response, string(content)
+}
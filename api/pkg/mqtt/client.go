@@ -0,0 +1,137 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/palantir/stacktrace"
+)
+
+// MessageHandler is called with the payload of a message received on a subscribed topic
+type MessageHandler func(topic string, payload []byte)
+
+// Client publishes and subscribes to messages on an MQTT broker
+type Client interface {
+	// Connect opens the connection to the broker
+	Connect(ctx context.Context) error
+
+	// Publish sends the payload to a topic
+	Publish(ctx context.Context, topic string, qos byte, payload []byte) error
+
+	// Subscribe calls the handler for every message on a topic. Subscriptions are restored when the client reconnects.
+	Subscribe(ctx context.Context, topic string, qos byte, handler MessageHandler) error
+
+	// Disconnect closes the connection to the broker
+	Disconnect()
+}
+
+// Options are the parameters for connecting to an MQTT broker
+type Options struct {
+	Host     string
+	Port     uint
+	UseTLS   bool
+	ClientID string
+	Username *string
+	Password *string
+}
+
+// BrokerURL is the URL of the broker e.g. ssl://broker.hivemq.com:8883
+func (options Options) BrokerURL() string {
+	scheme := "tcp"
+	if options.UseTLS {
+		scheme = "ssl"
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, options.Host, options.Port)
+}
+
+// Factory creates a Client from Options
+type Factory func(options Options) Client
+
+type subscription struct {
+	qos     byte
+	handler MessageHandler
+}
+
+// pahoClient is the Client backed by github.com/eclipse/paho.mqtt.golang
+type pahoClient struct {
+	client        paho.Client
+	mutex         sync.Mutex
+	subscriptions map[string]subscription
+}
+
+// NewClient creates a Client which connects to the broker in Options
+func NewClient(options Options) Client {
+	client := &pahoClient{subscriptions: map[string]subscription{}}
+
+	pahoOptions := paho.NewClientOptions().
+		AddBroker(options.BrokerURL()).
+		SetClientID(options.ClientID).
+		SetConnectTimeout(10 * time.Second).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(client.onConnect)
+
+	if options.Username != nil {
+		pahoOptions.SetUsername(*options.Username)
+	}
+	if options.Password != nil {
+		pahoOptions.SetPassword(*options.Password)
+	}
+	if options.UseTLS {
+		pahoOptions.SetTLSConfig(&tls.Config{ServerName: options.Host, MinVersion: tls.VersionTLS12})
+	}
+
+	client.client = paho.NewClient(pahoOptions)
+	return client
+}
+
+func (client *pahoClient) Connect(ctx context.Context) error {
+	return client.wait(ctx, client.client.Connect(), "cannot connect to the MQTT broker")
+}
+
+func (client *pahoClient) Publish(ctx context.Context, topic string, qos byte, payload []byte) error {
+	return client.wait(ctx, client.client.Publish(topic, qos, false, payload), fmt.Sprintf("cannot publish message to topic [%s]", topic))
+}
+
+func (client *pahoClient) Subscribe(ctx context.Context, topic string, qos byte, handler MessageHandler) error {
+	client.mutex.Lock()
+	client.subscriptions[topic] = subscription{qos: qos, handler: handler}
+	client.mutex.Unlock()
+
+	return client.wait(ctx, client.client.Subscribe(topic, qos, client.callback(handler)), fmt.Sprintf("cannot subscribe to topic [%s]", topic))
+}
+
+func (client *pahoClient) Disconnect() {
+	client.client.Disconnect(250)
+}
+
+// onConnect restores the subscriptions after the client reconnects to the broker
+func (client *pahoClient) onConnect(c paho.Client) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	for topic, subscription := range client.subscriptions {
+		c.Subscribe(topic, subscription.qos, client.callback(subscription.handler))
+	}
+}
+
+func (client *pahoClient) callback(handler MessageHandler) paho.MessageHandler {
+	return func(_ paho.Client, message paho.Message) {
+		handler(message.Topic(), message.Payload())
+	}
+}
+
+func (client *pahoClient) wait(ctx context.Context, token paho.Token, msg string) error {
+	select {
+	case <-ctx.Done():
+		return stacktrace.Propagate(ctx.Err(), msg)
+	case <-token.Done():
+		if token.Error() != nil {
+			return stacktrace.Propagate(token.Error(), msg)
+		}
+		return nil
+	}
+}
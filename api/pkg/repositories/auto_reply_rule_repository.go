@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+)
+
+// AutoReplyRuleRepository loads and persists an entities.AutoReplyRule
+type AutoReplyRuleRepository interface {
+	// Store a new entities.AutoReplyRule
+	Store(ctx context.Context, rule *entities.AutoReplyRule) error
+
+	// Update an entities.AutoReplyRule
+	Update(ctx context.Context, rule *entities.AutoReplyRule) error
+
+	// Index entities.AutoReplyRule by entities.UserID
+	Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.AutoReplyRule, error)
+
+	// Load an entities.AutoReplyRule by ID
+	Load(ctx context.Context, userID entities.UserID, ruleID uuid.UUID) (*entities.AutoReplyRule, error)
+
+	// FetchEnabled fetches the enabled entities.AutoReplyRule of a phone ordered by priority
+	FetchEnabled(ctx context.Context, userID entities.UserID, owner string) ([]*entities.AutoReplyRule, error)
+
+	// ClaimCooldown records a reply to a contact. It returns false if the rule replied to the contact after the cutoff time.
+	ClaimCooldown(ctx context.Context, rule *entities.AutoReplyRule, contact string, timestamp time.Time, cutoff time.Time) (bool, error)
+
+	// ClaimOutOfOffice records an out-of-office reply to a contact. It returns false if the phone replied to the contact after the start of the out-of-office window.
+	ClaimOutOfOffice(ctx context.Context, userID entities.UserID, owner string, contact string, timestamp time.Time, since time.Time) (bool, error)
+
+	// Delete an entities.AutoReplyRule
+	Delete(ctx context.Context, userID entities.UserID, ruleID uuid.UUID) error
+
+	// DeleteAllForUser deletes all entities.AutoReplyRule and entities.OutOfOfficeCooldown for a user
+	DeleteAllForUser(ctx context.Context, userID entities.UserID) error
+}
@@ -9,8 +9,8 @@ import (
 
 // BillingUsageRepository loads and persists an entities.BillingUsage
 type BillingUsageRepository interface {
-	// RegisterSentMessage registers a message as sent
-	RegisterSentMessage(ctx context.Context, timestamp time.Time, user entities.UserID) error
+	// RegisterSentMessage registers a message as sent. The count is the number of billed messages and segments is the number of SMS segments of the message.
+	RegisterSentMessage(ctx context.Context, timestamp time.Time, user entities.UserID, count uint, segments uint) error
 
 	// RegisterReceivedMessage registers a message as received
 	RegisterReceivedMessage(ctx context.Context, timestamp time.Time, user entities.UserID) error
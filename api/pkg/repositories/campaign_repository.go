@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+)
+
+// CampaignRepository loads and persists an entities.Campaign
+type CampaignRepository interface {
+	// Store a new entities.Campaign
+	Store(ctx context.Context, campaign *entities.Campaign) error
+
+	// UpdateStatus saves the status of an entities.Campaign only if the stored status is one of the expected statuses.
+	// It returns false if the status of the campaign has been changed concurrently.
+	UpdateStatus(ctx context.Context, campaign *entities.Campaign, expected ...entities.CampaignStatus) (bool, error)
+
+	// Index entities.Campaign by entities.UserID
+	Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.Campaign, error)
+
+	// Load an entities.Campaign by ID
+	Load(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) (*entities.Campaign, error)
+
+	// Stats fetches the entities.CampaignStats of an entities.Campaign
+	Stats(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) (*entities.CampaignStats, error)
+
+	// Complete marks an active entities.Campaign without outstanding messages as completed.
+	// It returns false if the campaign is not active or it still has outstanding messages.
+	Complete(ctx context.Context, userID entities.UserID, campaignID uuid.UUID, timestamp time.Time) (bool, error)
+
+	// Reopen marks a completed entities.Campaign as active. It returns false if the campaign is not completed.
+	Reopen(ctx context.Context, userID entities.UserID, campaignID uuid.UUID, timestamp time.Time) (bool, error)
+
+	// Delete an entities.Campaign
+	Delete(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) error
+
+	// DeleteAllForUser deletes all entities.Campaign for a user
+	DeleteAllForUser(ctx context.Context, userID entities.UserID) error
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+)
+
+// ContactListRepository loads and persists an entities.ContactList and its entities.ContactListMember
+type ContactListRepository interface {
+	// Store a new entities.ContactList
+	Store(ctx context.Context, list *entities.ContactList) error
+
+	// Update an entities.ContactList
+	Update(ctx context.Context, list *entities.ContactList) error
+
+	// Index entities.ContactList by entities.UserID
+	Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.ContactList, error)
+
+	// Load an entities.ContactList by ID
+	Load(ctx context.Context, userID entities.UserID, listID uuid.UUID) (*entities.ContactList, error)
+
+	// LoadByKeyword loads an entities.ContactList by keyword
+	LoadByKeyword(ctx context.Context, userID entities.UserID, keyword string) (*entities.ContactList, error)
+
+	// Delete an entities.ContactList and its members
+	Delete(ctx context.Context, userID entities.UserID, listID uuid.UUID) error
+
+	// StoreMember adds an entities.ContactListMember. It does nothing if the contact is already a member
+	StoreMember(ctx context.Context, member *entities.ContactListMember) error
+
+	// IndexMembers fetches the entities.ContactListMember of an entities.ContactList
+	IndexMembers(ctx context.Context, userID entities.UserID, listID uuid.UUID, params IndexParams) ([]*entities.ContactListMember, error)
+
+	// FetchContacts fetches the contacts of all the entities.ContactListMember of an entities.ContactList
+	FetchContacts(ctx context.Context, userID entities.UserID, listID uuid.UUID) ([]string, error)
+
+	// DeleteMember deletes an entities.ContactListMember by ID
+	DeleteMember(ctx context.Context, userID entities.UserID, listID uuid.UUID, memberID uuid.UUID) error
+
+	// DeleteMemberByContact deletes an entities.ContactListMember by contact
+	DeleteMemberByContact(ctx context.Context, userID entities.UserID, listID uuid.UUID, contact string) error
+
+	// DeleteAllForUser deletes all entities.ContactList and entities.ContactListMember for a user
+	DeleteAllForUser(ctx context.Context, userID entities.UserID) error
+}
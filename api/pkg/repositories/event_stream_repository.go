@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// EventStreamRepository buffers the recent events of a user which are streamed to clients in realtime
+type EventStreamRepository interface {
+	// Append adds an event to the stream of a user
+	Append(ctx context.Context, userID entities.UserID, event cloudevents.Event) error
+
+	// LastID returns the ID of the newest event in the stream of a user
+	LastID(ctx context.Context, userID entities.UserID) (string, error)
+
+	// Read returns the events which were added after the event with ID lastID. It waits up to timeout for new events.
+	Read(ctx context.Context, userID entities.UserID, lastID string, timeout time.Duration) ([]*entities.StreamEvent, error)
+}
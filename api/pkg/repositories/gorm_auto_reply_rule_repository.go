@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+	"gorm.io/gorm"
+)
+
+// gormAutoReplyRuleRepository is responsible for persisting entities.AutoReplyRule
+type gormAutoReplyRuleRepository struct {
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+	db     *gorm.DB
+}
+
+// NewGormAutoReplyRuleRepository creates the GORM version of the AutoReplyRuleRepository
+func NewGormAutoReplyRuleRepository(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	db *gorm.DB,
+) AutoReplyRuleRepository {
+	return &gormAutoReplyRuleRepository{
+		logger: logger.WithService(fmt.Sprintf("%T", &gormAutoReplyRuleRepository{})),
+		tracer: tracer,
+		db:     db,
+	}
+}
+
+func (repository *gormAutoReplyRuleRepository) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.AutoReplyCooldown{}).Error; err != nil {
+			return stacktrace.Propagate(err, fmt.Sprintf("cannot delete all [%T] for user with ID [%s]", &entities.AutoReplyCooldown{}, userID))
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.AutoReplyRule{}).Error; err != nil {
+			return stacktrace.Propagate(err, fmt.Sprintf("cannot delete all [%T] for user with ID [%s]", &entities.AutoReplyRule{}, userID))
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.OutOfOfficeCooldown{}).Error; err != nil {
+			return stacktrace.Propagate(err, fmt.Sprintf("cannot delete all [%T] for user with ID [%s]", &entities.OutOfOfficeCooldown{}, userID))
+		}
+		return nil
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete all auto reply rules for user with ID [%s]", userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormAutoReplyRuleRepository) Store(ctx context.Context, rule *entities.AutoReplyRule) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Create(rule).Error; err != nil {
+		msg := fmt.Sprintf("cannot save auto reply rule with ID [%s]", rule.ID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormAutoReplyRuleRepository) Update(ctx context.Context, rule *entities.AutoReplyRule) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Save(rule).Error; err != nil {
+		msg := fmt.Sprintf("cannot update auto reply rule with ID [%s]", rule.ID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormAutoReplyRuleRepository) Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.AutoReplyRule, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := repository.db.WithContext(ctx).Where("user_id = ?", userID)
+	if len(params.Query) > 0 {
+		queryPattern := "%" + params.Query + "%"
+		query.Where(repository.db.Where("name ILIKE ?", queryPattern).Or("owner ILIKE ?", queryPattern).Or("pattern ILIKE ?", queryPattern))
+	}
+
+	rules := make([]*entities.AutoReplyRule, 0)
+	if err := query.Order("owner ASC").Order("priority ASC").Order("created_at ASC").Limit(params.Limit).Offset(params.Skip).Find(&rules).Error; err != nil {
+		msg := fmt.Sprintf("cannot fetch auto reply rules for user [%s] and params [%+#v]", userID, params)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return rules, nil
+}
+
+func (repository *gormAutoReplyRuleRepository) Load(ctx context.Context, userID entities.UserID, ruleID uuid.UUID) (*entities.AutoReplyRule, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	rule := new(entities.AutoReplyRule)
+	err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Where("id = ?", ruleID).First(rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		msg := fmt.Sprintf("auto reply rule with ID [%s] for user [%s] does not exist", ruleID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeNotFound, msg))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load auto reply rule with ID [%s] for user [%s]", ruleID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return rule, nil
+}
+
+func (repository *gormAutoReplyRuleRepository) FetchEnabled(ctx context.Context, userID entities.UserID, owner string) ([]*entities.AutoReplyRule, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	rules := make([]*entities.AutoReplyRule, 0)
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("owner = ?", owner).
+		Where("enabled = ?", true).
+		Order("priority ASC").
+		Order("created_at ASC").
+		Find(&rules).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot fetch enabled auto reply rules for user [%s] and owner [%s]", userID, owner)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return rules, nil
+}
+
+func (repository *gormAutoReplyRuleRepository) ClaimCooldown(ctx context.Context, rule *entities.AutoReplyRule, contact string, timestamp time.Time, cutoff time.Time) (bool, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := `
+INSERT INTO auto_reply_cooldowns (auto_reply_rule_id, contact, user_id, replied_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (auto_reply_rule_id, contact) DO UPDATE SET replied_at = EXCLUDED.replied_at
+WHERE auto_reply_cooldowns.replied_at <= ?
+`
+	result := repository.db.WithContext(ctx).Exec(query, rule.ID, contact, rule.UserID, timestamp, cutoff)
+	if result.Error != nil {
+		msg := fmt.Sprintf("cannot claim cooldown of auto reply rule [%s] for contact [%s]", rule.ID, contact)
+		return false, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(result.Error, msg))
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (repository *gormAutoReplyRuleRepository) ClaimOutOfOffice(ctx context.Context, userID entities.UserID, owner string, contact string, timestamp time.Time, since time.Time) (bool, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := `
+INSERT INTO out_of_office_cooldowns (user_id, owner, contact, replied_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (user_id, owner, contact) DO UPDATE SET replied_at = EXCLUDED.replied_at
+WHERE out_of_office_cooldowns.replied_at < ?
+`
+	result := repository.db.WithContext(ctx).Exec(query, userID, owner, contact, timestamp, since)
+	if result.Error != nil {
+		msg := fmt.Sprintf("cannot claim out-of-office reply of phone [%s] for contact [%s] and user [%s]", owner, contact, userID)
+		return false, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(result.Error, msg))
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (repository *gormAutoReplyRuleRepository) Delete(ctx context.Context, userID entities.UserID, ruleID uuid.UUID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Where("auto_reply_rule_id = ?", ruleID).Delete(&entities.AutoReplyCooldown{}).Error; err != nil {
+			return stacktrace.Propagate(err, fmt.Sprintf("cannot delete cooldowns of auto reply rule with ID [%s]", ruleID))
+		}
+		if err := tx.Where("user_id = ?", userID).Where("id = ?", ruleID).Delete(&entities.AutoReplyRule{}).Error; err != nil {
+			return stacktrace.Propagate(err, fmt.Sprintf("cannot delete auto reply rule with ID [%s]", ruleID))
+		}
+		return nil
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete auto reply rule with ID [%s] for user [%s]", ruleID, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
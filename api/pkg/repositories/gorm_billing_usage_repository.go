@@ -49,7 +49,7 @@ func (repository *gormBillingUsageRepository) DeleteAllForUser(ctx context.Conte
 }
 
 // RegisterSentMessage registers a message as sent
-func (repository *gormBillingUsageRepository) RegisterSentMessage(ctx context.Context, timestamp time.Time, userID entities.UserID) error {
+func (repository *gormBillingUsageRepository) RegisterSentMessage(ctx context.Context, timestamp time.Time, userID entities.UserID, count uint, segments uint) error {
 	ctx, span := repository.tracer.Start(ctx)
 	defer span.End()
 
@@ -59,10 +59,15 @@ func (repository *gormBillingUsageRepository) RegisterSentMessage(ctx context.Co
 				Model(&entities.BillingUsage{}).
 				Where("start_timestamp = ?", now.New(timestamp).BeginningOfMonth()).
 				Where("user_id = ?", userID).
-				UpdateColumn("sent_messages", gorm.Expr("sent_messages + ?", 1))
+				UpdateColumns(map[string]any{
+					"sent_messages": gorm.Expr("sent_messages + ?", count),
+					"sent_segments": gorm.Expr("sent_segments + ?", segments),
+				})
 
 			if result.Error == nil && result.RowsAffected == 0 {
-				return tx.Create(repository.createBillingUsage(userID, timestamp, 1, 0)).Error
+				usage := repository.createBillingUsage(userID, timestamp, count, 0)
+				usage.SentSegments = segments
+				return tx.Create(usage).Error
 			}
 			return result.Error
 		},
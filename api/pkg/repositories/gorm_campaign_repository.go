@@ -0,0 +1,256 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+	"gorm.io/gorm"
+)
+
+// gormCampaignRepository is responsible for persisting entities.Campaign
+type gormCampaignRepository struct {
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+	db     *gorm.DB
+}
+
+// NewGormCampaignRepository creates the GORM version of the CampaignRepository
+func NewGormCampaignRepository(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	db *gorm.DB,
+) CampaignRepository {
+	return &gormCampaignRepository{
+		logger: logger.WithService(fmt.Sprintf("%T", &gormCampaignRepository{})),
+		tracer: tracer,
+		db:     db,
+	}
+}
+
+func (repository *gormCampaignRepository) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entities.Campaign{}).Error; err != nil {
+		msg := fmt.Sprintf("cannot delete all [%T] for user with ID [%s]", &entities.Campaign{}, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormCampaignRepository) Store(ctx context.Context, campaign *entities.Campaign) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Create(campaign).Error; err != nil {
+		msg := fmt.Sprintf("cannot save campaign with ID [%s]", campaign.ID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormCampaignRepository) UpdateStatus(ctx context.Context, campaign *entities.Campaign, expected ...entities.CampaignStatus) (bool, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	result := repository.db.WithContext(ctx).
+		Model(&entities.Campaign{}).
+		Where("user_id = ?", campaign.UserID).
+		Where("id = ?", campaign.ID).
+		Where("status IN ?", expected).
+		Updates(map[string]any{
+			"status":       campaign.Status,
+			"paused_at":    campaign.PausedAt,
+			"canceled_at":  campaign.CanceledAt,
+			"completed_at": campaign.CompletedAt,
+			"updated_at":   campaign.UpdatedAt,
+		})
+	if result.Error != nil {
+		msg := fmt.Sprintf("cannot update status of campaign with ID [%s] to [%s]", campaign.ID, campaign.Status)
+		return false, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(result.Error, msg))
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (repository *gormCampaignRepository) Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.Campaign, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := repository.db.WithContext(ctx).Where("user_id = ?", userID)
+	if len(params.Query) > 0 {
+		queryPattern := "%" + params.Query + "%"
+		query.Where(repository.db.Where("name ILIKE ?", queryPattern).Or("status ILIKE ?", queryPattern))
+	}
+
+	campaigns := make([]*entities.Campaign, 0)
+	if err := query.Order("created_at DESC").Limit(params.Limit).Offset(params.Skip).Find(&campaigns).Error; err != nil {
+		msg := fmt.Sprintf("cannot fetch campaigns for user [%s] and params [%+#v]", userID, params)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return campaigns, nil
+}
+
+func (repository *gormCampaignRepository) Load(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) (*entities.Campaign, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	campaign := new(entities.Campaign)
+	err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Where("id = ?", campaignID).First(campaign).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		msg := fmt.Sprintf("campaign with ID [%s] for user [%s] does not exist", campaignID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeNotFound, msg))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load campaign with ID [%s] for user [%s]", campaignID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return campaign, nil
+}
+
+func (repository *gormCampaignRepository) Stats(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) (*entities.CampaignStats, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	var rows []struct {
+		Status entities.MessageStatus
+		Count  uint
+	}
+
+	err := repository.db.WithContext(ctx).
+		Model(&entities.Message{}).
+		Select("status, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Where("campaign_id = ?", campaignID).
+		Group("status").
+		Scan(&rows).
+		Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot count messages for campaign with ID [%s] and user [%s]", campaignID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	stats := new(entities.CampaignStats)
+	for _, row := range rows {
+		stats.Total += row.Count
+		switch row.Status {
+		case entities.MessageStatusPending:
+			stats.Pending = row.Count
+		case entities.MessageStatusScheduled:
+			stats.Scheduled = row.Count
+		case entities.MessageStatusSending:
+			stats.Sending = row.Count
+		case entities.MessageStatusSent:
+			stats.Sent = row.Count
+		case entities.MessageStatusDelivered:
+			stats.Delivered = row.Count
+		case entities.MessageStatusFailed:
+			stats.Failed = row.Count
+		case entities.MessageStatusExpired:
+			stats.Expired = row.Count
+		}
+	}
+
+	query := `
+SELECT COUNT(*) FROM messages AS replies
+WHERE replies.user_id = @userID
+  AND replies.type = @type
+  AND EXISTS (
+    SELECT 1 FROM messages AS sent
+    WHERE sent.user_id = @userID
+      AND sent.campaign_id = @campaignID
+      AND sent.owner = replies.owner
+      AND sent.contact = replies.contact
+      AND sent.created_at <= replies.created_at
+  )`
+
+	err = repository.db.WithContext(ctx).
+		Raw(query, map[string]any{"userID": userID, "campaignID": campaignID, "type": entities.MessageTypeMobileOriginated}).
+		Scan(&stats.Replies).
+		Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot count replies for campaign with ID [%s] and user [%s]", campaignID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return stats, nil
+}
+
+func (repository *gormCampaignRepository) Complete(ctx context.Context, userID entities.UserID, campaignID uuid.UUID, timestamp time.Time) (bool, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	result := repository.db.WithContext(ctx).
+		Model(&entities.Campaign{}).
+		Where("user_id = ?", userID).
+		Where("id = ?", campaignID).
+		Where("status = ?", entities.CampaignStatusActive).
+		Where(
+			"NOT EXISTS (?)",
+			repository.db.Model(&entities.Message{}).
+				Select("1").
+				Where("user_id = ?", userID).
+				Where("campaign_id = ?", campaignID).
+				Where("status IN ?", []entities.MessageStatus{entities.MessageStatusPending, entities.MessageStatusScheduled, entities.MessageStatusSending}),
+		).
+		Updates(map[string]any{
+			"status":       entities.CampaignStatusCompleted,
+			"completed_at": timestamp,
+			"updated_at":   timestamp,
+		})
+	if result.Error != nil {
+		msg := fmt.Sprintf("cannot complete campaign with ID [%s] for user [%s]", campaignID, userID)
+		return false, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(result.Error, msg))
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (repository *gormCampaignRepository) Reopen(ctx context.Context, userID entities.UserID, campaignID uuid.UUID, timestamp time.Time) (bool, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	result := repository.db.WithContext(ctx).
+		Model(&entities.Campaign{}).
+		Where("user_id = ?", userID).
+		Where("id = ?", campaignID).
+		Where("status = ?", entities.CampaignStatusCompleted).
+		Updates(map[string]any{
+			"status":       entities.CampaignStatusActive,
+			"completed_at": nil,
+			"updated_at":   timestamp,
+		})
+	if result.Error != nil {
+		msg := fmt.Sprintf("cannot reopen campaign with ID [%s] for user [%s]", campaignID, userID)
+		return false, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(result.Error, msg))
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (repository *gormCampaignRepository) Delete(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("id = ?", campaignID).
+		Delete(&entities.Campaign{}).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete campaign with ID [%s] and userID [%s]", campaignID, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
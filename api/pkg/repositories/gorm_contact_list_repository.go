@@ -0,0 +1,240 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormContactListRepository is responsible for persisting entities.ContactList
+type gormContactListRepository struct {
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+	db     *gorm.DB
+}
+
+// NewGormContactListRepository creates the GORM version of the ContactListRepository
+func NewGormContactListRepository(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	db *gorm.DB,
+) ContactListRepository {
+	return &gormContactListRepository{
+		logger: logger.WithService(fmt.Sprintf("%T", &gormContactListRepository{})),
+		tracer: tracer,
+		db:     db,
+	}
+}
+
+func (repository *gormContactListRepository) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.ContactListMember{}).Error; err != nil {
+			return stacktrace.Propagate(err, fmt.Sprintf("cannot delete all [%T] for user with ID [%s]", &entities.ContactListMember{}, userID))
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.ContactList{}).Error; err != nil {
+			return stacktrace.Propagate(err, fmt.Sprintf("cannot delete all [%T] for user with ID [%s]", &entities.ContactList{}, userID))
+		}
+		return nil
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete all contact lists for user with ID [%s]", userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormContactListRepository) Store(ctx context.Context, list *entities.ContactList) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Create(list).Error; err != nil {
+		msg := fmt.Sprintf("cannot save contact list with ID [%s]", list.ID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormContactListRepository) Update(ctx context.Context, list *entities.ContactList) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Save(list).Error; err != nil {
+		msg := fmt.Sprintf("cannot update contact list with ID [%s]", list.ID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormContactListRepository) Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.ContactList, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := repository.db.WithContext(ctx).Where("user_id = ?", userID)
+	if len(params.Query) > 0 {
+		queryPattern := "%" + params.Query + "%"
+		query.Where(repository.db.Where("name ILIKE ?", queryPattern).Or("keyword ILIKE ?", queryPattern))
+	}
+
+	lists := make([]*entities.ContactList, 0)
+	if err := query.Order("created_at DESC").Limit(params.Limit).Offset(params.Skip).Find(&lists).Error; err != nil {
+		msg := fmt.Sprintf("cannot fetch contact lists for user [%s] and params [%+#v]", userID, params)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return lists, nil
+}
+
+func (repository *gormContactListRepository) Load(ctx context.Context, userID entities.UserID, listID uuid.UUID) (*entities.ContactList, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	list := new(entities.ContactList)
+	err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Where("id = ?", listID).First(list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		msg := fmt.Sprintf("contact list with ID [%s] for user [%s] does not exist", listID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeNotFound, msg))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load contact list with ID [%s] for user [%s]", listID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return list, nil
+}
+
+func (repository *gormContactListRepository) LoadByKeyword(ctx context.Context, userID entities.UserID, keyword string) (*entities.ContactList, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	list := new(entities.ContactList)
+	err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Where("keyword = ?", keyword).First(list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		msg := fmt.Sprintf("contact list with keyword [%s] for user [%s] does not exist", keyword, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeNotFound, msg))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load contact list with keyword [%s] for user [%s]", keyword, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return list, nil
+}
+
+func (repository *gormContactListRepository) Delete(ctx context.Context, userID entities.UserID, listID uuid.UUID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Where("contact_list_id = ?", listID).Delete(&entities.ContactListMember{}).Error; err != nil {
+			return stacktrace.Propagate(err, fmt.Sprintf("cannot delete members of contact list with ID [%s]", listID))
+		}
+		if err := tx.Where("user_id = ?", userID).Where("id = ?", listID).Delete(&entities.ContactList{}).Error; err != nil {
+			return stacktrace.Propagate(err, fmt.Sprintf("cannot delete contact list with ID [%s]", listID))
+		}
+		return nil
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete contact list with ID [%s] for user [%s]", listID, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormContactListRepository) StoreMember(ctx context.Context, member *entities.ContactListMember) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(member).Error; err != nil {
+		msg := fmt.Sprintf("cannot save member [%s] of contact list with ID [%s]", member.Contact, member.ContactListID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormContactListRepository) IndexMembers(ctx context.Context, userID entities.UserID, listID uuid.UUID, params IndexParams) ([]*entities.ContactListMember, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := repository.db.WithContext(ctx).Where("user_id = ?", userID).Where("contact_list_id = ?", listID)
+	if len(params.Query) > 0 {
+		query.Where("contact ILIKE ?", "%"+params.Query+"%")
+	}
+
+	members := make([]*entities.ContactListMember, 0)
+	if err := query.Order("created_at DESC").Limit(params.Limit).Offset(params.Skip).Find(&members).Error; err != nil {
+		msg := fmt.Sprintf("cannot fetch members of contact list [%s] for user [%s] and params [%+#v]", listID, userID, params)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return members, nil
+}
+
+func (repository *gormContactListRepository) FetchContacts(ctx context.Context, userID entities.UserID, listID uuid.UUID) ([]string, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	contacts := make([]string, 0)
+	err := repository.db.WithContext(ctx).
+		Model(&entities.ContactListMember{}).
+		Where("user_id = ?", userID).
+		Where("contact_list_id = ?", listID).
+		Order("created_at ASC").
+		Pluck("contact", &contacts).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot fetch contacts of contact list [%s] for user [%s]", listID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return contacts, nil
+}
+
+func (repository *gormContactListRepository) DeleteMember(ctx context.Context, userID entities.UserID, listID uuid.UUID, memberID uuid.UUID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("contact_list_id = ?", listID).
+		Where("id = ?", memberID).
+		Delete(&entities.ContactListMember{}).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete member [%s] of contact list [%s] for user [%s]", memberID, listID, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormContactListRepository) DeleteMemberByContact(ctx context.Context, userID entities.UserID, listID uuid.UUID, contact string) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("contact_list_id = ?", listID).
+		Where("contact = ?", contact).
+		Delete(&entities.ContactListMember{}).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete contact [%s] from contact list [%s] for user [%s]", contact, listID, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+	"gorm.io/gorm"
+)
+
+// gormIntegrationMQTTRepository is responsible for persisting entities.IntegrationMQTT
+type gormIntegrationMQTTRepository struct {
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+	db     *gorm.DB
+}
+
+// NewGormIntegrationMQTTRepository creates the GORM version of the IntegrationMQTTRepository
+func NewGormIntegrationMQTTRepository(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	db *gorm.DB,
+) IntegrationMQTTRepository {
+	return &gormIntegrationMQTTRepository{
+		logger: logger.WithService(fmt.Sprintf("%T", &gormIntegrationMQTTRepository{})),
+		tracer: tracer,
+		db:     db,
+	}
+}
+
+func (repository *gormIntegrationMQTTRepository) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entities.IntegrationMQTT{}).Error; err != nil {
+		msg := fmt.Sprintf("cannot delete all [%T] for user with ID [%s]", &entities.IntegrationMQTT{}, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormIntegrationMQTTRepository) Save(ctx context.Context, integration *entities.IntegrationMQTT) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Save(integration).Error; err != nil {
+		msg := fmt.Sprintf("cannot save mqtt integration with ID [%s]", integration.ID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormIntegrationMQTTRepository) Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.IntegrationMQTT, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := repository.db.WithContext(ctx).Where("user_id = ?", userID)
+	if len(params.Query) > 0 {
+		queryPattern := "%" + params.Query + "%"
+		query.Where(repository.db.Where("name ILIKE ?", queryPattern).Or("host ILIKE ?", queryPattern))
+	}
+
+	integrations := make([]*entities.IntegrationMQTT, 0)
+	if err := query.Order("created_at DESC").Limit(params.Limit).Offset(params.Skip).Find(&integrations).Error; err != nil {
+		msg := fmt.Sprintf("cannot fetch mqtt integrations for user [%s] and params [%+#v]", userID, params)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return integrations, nil
+}
+
+func (repository *gormIntegrationMQTTRepository) LoadByEvent(ctx context.Context, userID entities.UserID, event string) ([]*entities.IntegrationMQTT, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	integrations := make([]*entities.IntegrationMQTT, 0)
+	err := repository.db.
+		WithContext(ctx).
+		Raw("SELECT * FROM integration_mqtt WHERE user_id = ? AND CAST(? as TEXT) = ANY(events)", userID, event).
+		Scan(&integrations).
+		Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot load mqtt integrations for user with ID [%s] and event [%s]", userID, event)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return integrations, nil
+}
+
+func (repository *gormIntegrationMQTTRepository) FetchHavingCommandTopic(ctx context.Context) ([]*entities.IntegrationMQTT, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	integrations := make([]*entities.IntegrationMQTT, 0)
+	err := repository.db.
+		WithContext(ctx).
+		Where("command_topic IS NOT NULL").
+		Where("command_topic != ?", "").
+		Find(&integrations).Error
+	if err != nil {
+		msg := "cannot load mqtt integrations having a valid [command_topic]"
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return integrations, nil
+}
+
+func (repository *gormIntegrationMQTTRepository) Load(ctx context.Context, userID entities.UserID, integrationID uuid.UUID) (*entities.IntegrationMQTT, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	integration := new(entities.IntegrationMQTT)
+	err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Where("id = ?", integrationID).First(integration).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		msg := fmt.Sprintf("mqtt integration with ID [%s] for user [%s] does not exist", integrationID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeNotFound, msg))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load mqtt integration with ID [%s] for user [%s]", integrationID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return integration, nil
+}
+
+func (repository *gormIntegrationMQTTRepository) Delete(ctx context.Context, userID entities.UserID, integrationID uuid.UUID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("id = ?", integrationID).
+		Delete(&entities.IntegrationMQTT{}).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete mqtt integration with ID [%s] and userID [%s]", integrationID, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm/clause"
 
@@ -130,7 +131,7 @@ func (repository *gormMessageRepository) LastMessage(ctx context.Context, userID
 	return message, nil
 }
 
-func (repository *gormMessageRepository) Search(ctx context.Context, userID entities.UserID, owners []string, types []entities.MessageType, statuses []entities.MessageStatus, params IndexParams) ([]*entities.Message, error) {
+func (repository *gormMessageRepository) Search(ctx context.Context, userID entities.UserID, owners []string, types []entities.MessageType, statuses []entities.MessageStatus, failureCodes []entities.MessageFailureCode, params IndexParams) ([]*entities.Message, error) {
 	ctx, span := repository.tracer.Start(ctx)
 	defer span.End()
 
@@ -147,6 +148,9 @@ func (repository *gormMessageRepository) Search(ctx context.Context, userID enti
 	if len(statuses) > 0 {
 		query = query.Where("status IN ?", statuses)
 	}
+	if len(failureCodes) > 0 {
+		query = query.Where("failure_code IN ?", failureCodes)
+	}
 
 	if len(params.Query) > 0 {
 		queryPattern := "%" + params.Query + "%"
@@ -176,6 +180,58 @@ func (repository *gormMessageRepository) Search(ctx context.Context, userID enti
 	return messages, nil
 }
 
+// LoadDuplicate fetches the last outgoing entities.Message with the same content sent to a contact since the timestamp
+func (repository *gormMessageRepository) LoadDuplicate(ctx context.Context, userID entities.UserID, contact string, content string, since time.Time) (*entities.Message, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	message := new(entities.Message)
+	err := repository.db.
+		WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("contact = ?", contact).
+		Where("type = ?", entities.MessageTypeMobileTerminated).
+		Where("content = ?", content).
+		Where("created_at >= ?", since).
+		Order("created_at DESC").
+		First(message).
+		Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		msg := fmt.Sprintf("no duplicate message to contact [%s] for user [%s] since [%s]", contact, userID, since)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeNotFound, msg))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load duplicate message to contact [%s] for user [%s] since [%s]", contact, userID, since)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return message, nil
+}
+
+// CountByContact counts the outgoing entities.Message sent to a contact since the timestamp
+func (repository *gormMessageRepository) CountByContact(ctx context.Context, userID entities.UserID, contact string, since time.Time) (int64, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	var count int64
+	err := repository.db.
+		WithContext(ctx).
+		Model(&entities.Message{}).
+		Where("user_id = ?", userID).
+		Where("contact = ?", contact).
+		Where("type = ?", entities.MessageTypeMobileTerminated).
+		Where("created_at >= ?", since).
+		Count(&count).
+		Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot count messages to contact [%s] for user [%s] since [%s]", contact, userID, since)
+		return 0, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return count, nil
+}
+
 // Store a new entities.Message
 func (repository *gormMessageRepository) Store(ctx context.Context, message *entities.Message) error {
 	ctx, span := repository.tracer.Start(ctx)
@@ -209,6 +265,30 @@ func (repository *gormMessageRepository) Load(ctx context.Context, userID entiti
 	return message, nil
 }
 
+// LoadPart loads the part of a split entities.Message by the ID of the parent and the part number
+func (repository *gormMessageRepository) LoadPart(ctx context.Context, userID entities.UserID, parentID uuid.UUID, partNumber uint) (*entities.Message, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	message := new(entities.Message)
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("parent_id = ?", parentID).
+		Where("part_number = ?", partNumber).
+		First(message).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		msg := fmt.Sprintf("part [%d] of message [%s] and userID [%s] does not exist", partNumber, parentID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeNotFound, msg))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load part [%d] of message [%s]", partNumber, parentID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return message, nil
+}
+
 // Update an entities.Message
 func (repository *gormMessageRepository) Update(ctx context.Context, message *entities.Message) error {
 	ctx, span := repository.tracer.Start(ctx)
@@ -235,6 +315,7 @@ func (repository *gormMessageRepository) GetOutstanding(ctx context.Context, use
 				Where("user_id = ?", userID).
 				Where("id = ?", messageID).
 				Where(repository.db.Where("status = ?", entities.MessageStatusScheduled).Or("status = ?", entities.MessageStatusPending).Or("status = ?", entities.MessageStatusExpired)).
+				Where(repository.db.Where("expires_at IS NULL").Or("expires_at > ?", time.Now().UTC())).
 				Update("status", entities.MessageStatusSending).Error
 		},
 	)
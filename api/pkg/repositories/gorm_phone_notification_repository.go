@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
@@ -65,35 +64,62 @@ func (repository *gormPhoneNotificationRepository) UpdateStatus(ctx context.Cont
 	return nil
 }
 
+// FetchHeld fetches the notifications which were held because their entities.Campaign was paused
+func (repository *gormPhoneNotificationRepository) FetchHeld(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) ([]*entities.PhoneNotification, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	notifications := make([]*entities.PhoneNotification, 0)
+	err := repository.db.
+		WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("campaign_id = ?", campaignID).
+		Where("status = ?", entities.PhoneNotificationStatusHeld).
+		Order("scheduled_at ASC").
+		Find(&notifications).
+		Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot fetch held notifications for campaign [%s] and user [%s]", campaignID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return notifications, nil
+}
+
+// FreeSlot returns the first time at or after the timestamp which is at least messagesPerMinute apart from all the notifications scheduled for a phone
+func (repository *gormPhoneNotificationRepository) FreeSlot(ctx context.Context, phoneID uuid.UUID, messagesPerMinute uint, timestamp time.Time) (time.Time, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	slot, err := repository.freeSlot(repository.db.WithContext(ctx), phoneID, messagesPerMinute, timestamp)
+	if err != nil {
+		msg := fmt.Sprintf("cannot find a free slot for phone ID [%s] after [%s]", phoneID, timestamp)
+		return timestamp, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return slot, nil
+}
+
 // Schedule a notification to be sent in the future
 func (repository *gormPhoneNotificationRepository) Schedule(ctx context.Context, messagesPerMinute uint, notification *entities.PhoneNotification) error {
 	ctx, span := repository.tracer.Start(ctx)
 	defer span.End()
 
+	notification.ScheduledAt = repository.maxTime(time.Now().UTC(), notification.ScheduledAt)
 	if messagesPerMinute == 0 {
 		return repository.insert(ctx, notification)
 	}
 
 	err := crdbgorm.ExecuteTx(ctx, repository.db, nil, func(tx *gorm.DB) error {
-		lastNotification := new(entities.PhoneNotification)
-		err := tx.WithContext(ctx).
-			Where("phone_id = ?", notification.PhoneID).
-			Order("scheduled_at desc").
-			First(lastNotification).
-			Error
-		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			msg := fmt.Sprintf("cannot fetch last notification with phone ID [%s]", notification.PhoneID)
+		// the slot is computed against all the notifications of the phone so that deferred and released notifications
+		// cannot be sent at the same time as the notifications which are not deferred
+		slot, err := repository.freeSlot(tx.WithContext(ctx), notification.PhoneID, messagesPerMinute, notification.ScheduledAt)
+		if err != nil {
+			msg := fmt.Sprintf("cannot find a free slot for phone ID [%s] after [%s]", notification.PhoneID, notification.ScheduledAt)
 			return stacktrace.Propagate(err, msg)
 		}
 
-		notification.ScheduledAt = time.Now().UTC()
-		if err == nil {
-			notification.ScheduledAt = repository.maxTime(
-				time.Now().UTC(),
-				lastNotification.ScheduledAt.Add(time.Duration(60/messagesPerMinute)*time.Second),
-			)
-		}
-
+		notification.ScheduledAt = slot
 		if err = tx.WithContext(ctx).Create(notification).Error; err != nil {
 			msg := fmt.Sprintf("cannot create new notification with id [%s] and schedule [%s]", notification.ID, notification.ScheduledAt.String())
 			return stacktrace.Propagate(err, msg)
@@ -108,6 +134,36 @@ func (repository *gormPhoneNotificationRepository) Schedule(ctx context.Context,
 	return nil
 }
 
+// freeSlot moves the timestamp after each notification of the phone which is scheduled less than an interval away until it finds a gap
+func (repository *gormPhoneNotificationRepository) freeSlot(db *gorm.DB, phoneID uuid.UUID, messagesPerMinute uint, timestamp time.Time) (time.Time, error) {
+	if messagesPerMinute == 0 {
+		return timestamp, nil
+	}
+
+	interval := time.Duration(60/messagesPerMinute) * time.Second
+
+	var scheduled []time.Time
+	err := db.Model(&entities.PhoneNotification{}).
+		Where("phone_id = ?", phoneID).
+		Where("scheduled_at > ?", timestamp.Add(-interval)).
+		Order("scheduled_at ASC").
+		Pluck("scheduled_at", &scheduled).
+		Error
+	if err != nil {
+		return timestamp, stacktrace.Propagate(err, fmt.Sprintf("cannot fetch the notifications of phone ID [%s] after [%s]", phoneID, timestamp))
+	}
+
+	slot := timestamp
+	for _, scheduledAt := range scheduled {
+		if !scheduledAt.Before(slot.Add(interval)) {
+			break
+		}
+		slot = repository.maxTime(slot, scheduledAt.Add(interval))
+	}
+
+	return slot, nil
+}
+
 func (repository *gormPhoneNotificationRepository) maxTime(a, b time.Time) time.Time {
 	if a.Unix() > b.Unix() {
 		return a
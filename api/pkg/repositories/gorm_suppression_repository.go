@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gormSuppressionRepository is responsible for persisting entities.Suppression
+type gormSuppressionRepository struct {
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+	db     *gorm.DB
+}
+
+// NewGormSuppressionRepository creates the GORM version of the SuppressionRepository
+func NewGormSuppressionRepository(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	db *gorm.DB,
+) SuppressionRepository {
+	return &gormSuppressionRepository{
+		logger: logger.WithService(fmt.Sprintf("%T", &gormSuppressionRepository{})),
+		tracer: tracer,
+		db:     db,
+	}
+}
+
+func (repository *gormSuppressionRepository) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entities.Suppression{}).Error; err != nil {
+		msg := fmt.Sprintf("cannot delete all [%T] for user with ID [%s]", &entities.Suppression{}, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormSuppressionRepository) Store(ctx context.Context, suppression *entities.Suppression) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "contact"}},
+			DoUpdates: clause.AssignmentColumns([]string{"owner", "keyword", "updated_at"}),
+		}).
+		Create(suppression).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot save suppression with ID [%s] for contact [%s]", suppression.ID, suppression.Contact)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormSuppressionRepository) Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.Suppression, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := repository.db.WithContext(ctx).Where("user_id = ?", userID)
+	if len(params.Query) > 0 {
+		queryPattern := "%" + params.Query + "%"
+		query.Where(repository.db.Where("contact ILIKE ?", queryPattern).Or("keyword ILIKE ?", queryPattern))
+	}
+
+	suppressions := make([]*entities.Suppression, 0)
+	if err := query.Order("created_at DESC").Limit(params.Limit).Offset(params.Skip).Find(&suppressions).Error; err != nil {
+		msg := fmt.Sprintf("cannot fetch suppressions for user [%s] and params [%+#v]", userID, params)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return suppressions, nil
+}
+
+func (repository *gormSuppressionRepository) LoadByContact(ctx context.Context, userID entities.UserID, contact string) (*entities.Suppression, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	suppression := new(entities.Suppression)
+	err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Where("contact = ?", contact).First(suppression).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		msg := fmt.Sprintf("suppression with contact [%s] for user [%s] does not exist", contact, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeNotFound, msg))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load suppression with contact [%s] for user [%s]", contact, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return suppression, nil
+}
+
+func (repository *gormSuppressionRepository) FilterContacts(ctx context.Context, userID entities.UserID, contacts []string) ([]string, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	result := make([]string, 0)
+	if len(contacts) == 0 {
+		return result, nil
+	}
+
+	err := repository.db.WithContext(ctx).
+		Model(&entities.Suppression{}).
+		Where("user_id = ?", userID).
+		Where("contact IN ?", contacts).
+		Pluck("contact", &result).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot filter [%d] suppressed contacts for user [%s]", len(contacts), userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return result, nil
+}
+
+func (repository *gormSuppressionRepository) Delete(ctx context.Context, userID entities.UserID, suppressionID uuid.UUID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("id = ?", suppressionID).
+		Delete(&entities.Suppression{}).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete suppression with ID [%s] for user [%s]", suppressionID, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormSuppressionRepository) DeleteByContact(ctx context.Context, userID entities.UserID, contact string) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("contact = ?", contact).
+		Delete(&entities.Suppression{}).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete suppression with contact [%s] for user [%s]", contact, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+	"gorm.io/gorm"
+)
+
+// webhookDeliveryDeleteBatchSize is the maximum number of entities.WebhookDelivery which are deleted in one query
+const webhookDeliveryDeleteBatchSize = 10_000
+
+// gormWebhookDeliveryRepository is responsible for persisting entities.WebhookDelivery
+type gormWebhookDeliveryRepository struct {
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+	db     *gorm.DB
+}
+
+// NewGormWebhookDeliveryRepository creates the GORM version of the WebhookDeliveryRepository
+func NewGormWebhookDeliveryRepository(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	db *gorm.DB,
+) WebhookDeliveryRepository {
+	return &gormWebhookDeliveryRepository{
+		logger: logger.WithService(fmt.Sprintf("%T", &gormWebhookDeliveryRepository{})),
+		tracer: tracer,
+		db:     db,
+	}
+}
+
+func (repository *gormWebhookDeliveryRepository) Store(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Create(delivery).Error; err != nil {
+		msg := fmt.Sprintf("cannot save webhook delivery with ID [%s]", delivery.ID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormWebhookDeliveryRepository) Index(ctx context.Context, userID entities.UserID, webhookID uuid.UUID, params IndexParams) ([]*entities.WebhookDelivery, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := repository.db.WithContext(ctx).Where("user_id = ?", userID).Where("webhook_id = ?", webhookID)
+	if len(params.Query) > 0 {
+		queryPattern := "%" + params.Query + "%"
+		query.Where(repository.db.Where("event_type ILIKE ?", queryPattern).Or("event_id ILIKE ?", queryPattern).Or("status ILIKE ?", queryPattern))
+	}
+
+	deliveries := make([]*entities.WebhookDelivery, 0)
+	if err := query.Order("created_at DESC").Limit(params.Limit).Offset(params.Skip).Find(&deliveries).Error; err != nil {
+		msg := fmt.Sprintf("cannot fetch webhook deliveries for user [%s], webhook [%s] and params [%+#v]", userID, webhookID, params)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return deliveries, nil
+}
+
+func (repository *gormWebhookDeliveryRepository) Load(ctx context.Context, userID entities.UserID, webhookID uuid.UUID, deliveryID uuid.UUID) (*entities.WebhookDelivery, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	delivery := new(entities.WebhookDelivery)
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("webhook_id = ?", webhookID).
+		Where("id = ?", deliveryID).
+		First(delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		msg := fmt.Sprintf("webhook delivery with ID [%s] for webhook [%s] and user [%s] does not exist", deliveryID, webhookID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeNotFound, msg))
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load webhook delivery with ID [%s] for webhook [%s] and user [%s]", deliveryID, webhookID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return delivery, nil
+}
+
+func (repository *gormWebhookDeliveryRepository) DeleteAllForWebhook(ctx context.Context, userID entities.UserID, webhookID uuid.UUID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Where("webhook_id = ?", webhookID).
+		Delete(&entities.WebhookDelivery{}).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot delete all [%T] for webhook [%s] and user [%s]", &entities.WebhookDelivery{}, webhookID, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormWebhookDeliveryRepository) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	if err := repository.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entities.WebhookDelivery{}).Error; err != nil {
+		msg := fmt.Sprintf("cannot delete all [%T] for user with ID [%s]", &entities.WebhookDelivery{}, userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormWebhookDeliveryRepository) DeleteBefore(ctx context.Context, timestamp time.Time) (int64, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	var count int64
+	for {
+		// rows are deleted in batches so that a large backlog does not lock the table for a long time
+		batch := repository.db.Model(&entities.WebhookDelivery{}).Select("id").Where("created_at < ?", timestamp).Limit(webhookDeliveryDeleteBatchSize)
+		result := repository.db.WithContext(ctx).Where("id IN (?)", batch).Delete(&entities.WebhookDelivery{})
+		if result.Error != nil {
+			msg := fmt.Sprintf("cannot delete [%T] created before [%s]", &entities.WebhookDelivery{}, timestamp)
+			return count, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(result.Error, msg))
+		}
+
+		count += result.RowsAffected
+		if result.RowsAffected < webhookDeliveryDeleteBatchSize {
+			return count, nil
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/NdoleStudio/httpsms/pkg/entities"
 	"github.com/NdoleStudio/httpsms/pkg/telemetry"
@@ -82,7 +83,7 @@ func (repository *gormWebhookRepository) LoadByEvent(ctx context.Context, userID
 
 	webhooks := make([]*entities.Webhook, 0)
 	err := repository.db.
-		Raw("SELECT * FROM webhooks WHERE user_id = ? AND CAST(? as TEXT) = ANY(events) AND CAST(? as TEXT) = ANY(phone_numbers)", userID, event, phoneNumber).
+		Raw("SELECT * FROM webhooks WHERE user_id = ? AND CAST(? as TEXT) = ANY(events) AND CAST(? as TEXT) = ANY(phone_numbers) AND status <> ?", userID, event, phoneNumber, entities.WebhookStatusDisabled).
 		Scan(&webhooks).
 		Error
 	if err != nil {
@@ -112,6 +113,52 @@ func (repository *gormWebhookRepository) Load(ctx context.Context, userID entiti
 	return webhook, nil
 }
 
+func (repository *gormWebhookRepository) RecordSuccess(ctx context.Context, webhook *entities.Webhook, timestamp time.Time) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	err := repository.db.WithContext(ctx).
+		Model(&entities.Webhook{}).
+		Where("user_id = ?", webhook.UserID).
+		Where("id = ?", webhook.ID).
+		UpdateColumns(map[string]any{
+			"consecutive_failures": 0,
+			"last_success_at":      timestamp,
+		}).Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot record success of webhook with ID [%s] for user [%s]", webhook.ID, webhook.UserID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *gormWebhookRepository) RecordFailure(ctx context.Context, webhook *entities.Webhook, timestamp time.Time) (bool, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	query := `
+UPDATE webhooks SET
+	consecutive_failures = consecutive_failures + 1,
+	last_failure_at = ?,
+	status = CASE WHEN failure_threshold > 0 AND consecutive_failures + 1 >= failure_threshold THEN ? ELSE status END,
+	disabled_at = CASE WHEN failure_threshold > 0 AND consecutive_failures + 1 >= failure_threshold THEN ? ELSE disabled_at END
+WHERE user_id = ? AND id = ? AND status <> ?
+RETURNING status
+`
+	var statuses []entities.WebhookStatus
+	err := repository.db.WithContext(ctx).
+		Raw(query, timestamp, entities.WebhookStatusDisabled, timestamp, webhook.UserID, webhook.ID, entities.WebhookStatusDisabled).
+		Scan(&statuses).
+		Error
+	if err != nil {
+		msg := fmt.Sprintf("cannot record failure of webhook with ID [%s] for user [%s]", webhook.ID, webhook.UserID)
+		return false, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return len(statuses) == 1 && statuses[0] == entities.WebhookStatusDisabled, nil
+}
+
 func (repository *gormWebhookRepository) Delete(ctx context.Context, userID entities.UserID, webhookID uuid.UUID) error {
 	ctx, span := repository.tracer.Start(ctx)
 	defer span.End()
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/google/uuid"
+)
+
+// IntegrationMQTTRepository loads and persists an entities.IntegrationMQTT
+type IntegrationMQTTRepository interface {
+	// Save Upsert a new entities.IntegrationMQTT
+	Save(ctx context.Context, integration *entities.IntegrationMQTT) error
+
+	// Index entities.IntegrationMQTT by entities.UserID
+	Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.IntegrationMQTT, error)
+
+	// LoadByEvent loads the entities.IntegrationMQTT of a user which are subscribed to an event
+	LoadByEvent(ctx context.Context, userID entities.UserID, event string) ([]*entities.IntegrationMQTT, error)
+
+	// FetchHavingCommandTopic loads the entities.IntegrationMQTT of all users which have a command topic.
+	FetchHavingCommandTopic(ctx context.Context) ([]*entities.IntegrationMQTT, error)
+
+	// Load an entities.IntegrationMQTT by ID.
+	Load(ctx context.Context, userID entities.UserID, integrationID uuid.UUID) (*entities.IntegrationMQTT, error)
+
+	// Delete an entities.IntegrationMQTT
+	Delete(ctx context.Context, userID entities.UserID, integrationID uuid.UUID) error
+
+	// DeleteAllForUser deletes all entities.IntegrationMQTT for a user
+	DeleteAllForUser(ctx context.Context, userID entities.UserID) error
+}
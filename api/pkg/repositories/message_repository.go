@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/NdoleStudio/httpsms/pkg/entities"
 	"github.com/google/uuid"
@@ -18,6 +19,9 @@ type MessageRepository interface {
 	// Load an entities.Message by ID
 	Load(ctx context.Context, userID entities.UserID, messageID uuid.UUID) (*entities.Message, error)
 
+	// LoadPart loads the part of a split entities.Message by the ID of the parent and the part number
+	LoadPart(ctx context.Context, userID entities.UserID, parentID uuid.UUID, partNumber uint) (*entities.Message, error)
+
 	// Index entities.Message between 2 phone numbers
 	Index(ctx context.Context, userID entities.UserID, owner string, contact string, params IndexParams) (*[]entities.Message, error)
 
@@ -25,7 +29,13 @@ type MessageRepository interface {
 	LastMessage(ctx context.Context, userID entities.UserID, owner string, contact string) (*entities.Message, error)
 
 	// Search entities.Message for a user
-	Search(ctx context.Context, userID entities.UserID, owners []string, types []entities.MessageType, statuses []entities.MessageStatus, params IndexParams) ([]*entities.Message, error)
+	Search(ctx context.Context, userID entities.UserID, owners []string, types []entities.MessageType, statuses []entities.MessageStatus, failureCodes []entities.MessageFailureCode, params IndexParams) ([]*entities.Message, error)
+
+	// LoadDuplicate fetches the last outgoing entities.Message with the same content sent to a contact since the timestamp
+	LoadDuplicate(ctx context.Context, userID entities.UserID, contact string, content string, since time.Time) (*entities.Message, error)
+
+	// CountByContact counts the outgoing entities.Message sent to a contact since the timestamp
+	CountByContact(ctx context.Context, userID entities.UserID, contact string, since time.Time) (int64, error)
 
 	// GetOutstanding fetches an entities.Message which is outstanding
 	GetOutstanding(ctx context.Context, userID entities.UserID, messageID uuid.UUID) (*entities.Message, error)
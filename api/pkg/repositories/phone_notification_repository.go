@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -13,9 +14,15 @@ type PhoneNotificationRepository interface {
 	// Schedule a new entities.PhoneNotification
 	Schedule(ctx context.Context, messagesPerMinute uint, notification *entities.PhoneNotification) error
 
+	// FreeSlot returns the first time at or after the timestamp which is at least messagesPerMinute apart from all the notifications scheduled for a phone
+	FreeSlot(ctx context.Context, phoneID uuid.UUID, messagesPerMinute uint, timestamp time.Time) (time.Time, error)
+
 	// UpdateStatus of a notification
 	UpdateStatus(ctx context.Context, notificationID uuid.UUID, status entities.PhoneNotificationStatus) error
 
+	// FetchHeld fetches the entities.PhoneNotification which were held for an entities.Campaign
+	FetchHeld(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) ([]*entities.PhoneNotification, error)
+
 	// DeleteAllForUser deletes all entities.PhoneNotification for a user
 	DeleteAllForUser(ctx context.Context, userID entities.UserID) error
 }
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/palantir/stacktrace"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// eventStreamMaxLength is the approximate number of events which are kept in the stream of a user
+	eventStreamMaxLength = 1000
+
+	// eventStreamRetention is how long the stream of a user is kept after the last event was added
+	eventStreamRetention = time.Hour
+
+	// eventStreamReadCount is the maximum number of events returned by a single read
+	eventStreamReadCount = 100
+)
+
+// redisEventStreamRepository stores the event stream of a user in a redis stream
+type redisEventStreamRepository struct {
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+	client *redis.Client
+}
+
+// NewRedisEventStreamRepository creates the redis version of the EventStreamRepository
+func NewRedisEventStreamRepository(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	client *redis.Client,
+) EventStreamRepository {
+	return &redisEventStreamRepository{
+		logger: logger.WithService(fmt.Sprintf("%T", &redisEventStreamRepository{})),
+		tracer: tracer,
+		client: client,
+	}
+}
+
+func (repository *redisEventStreamRepository) Append(ctx context.Context, userID entities.UserID, event cloudevents.Event) error {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	content, err := event.MarshalJSON()
+	if err != nil {
+		msg := fmt.Sprintf("cannot marshal [%s] event with ID [%s] into JSON", event.Type(), event.ID())
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	_, err = repository.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: repository.key(userID),
+			MaxLen: eventStreamMaxLength,
+			Approx: true,
+			Values: map[string]any{"type": event.Type(), "event": string(content)},
+		})
+		pipe.Expire(ctx, repository.key(userID), eventStreamRetention)
+		return nil
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot add [%s] event with ID [%s] to the stream of user [%s]", event.Type(), event.ID(), userID)
+		return repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (repository *redisEventStreamRepository) LastID(ctx context.Context, userID entities.UserID) (string, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	messages, err := repository.client.XRevRangeN(ctx, repository.key(userID), "+", "-", 1).Result()
+	if err != nil {
+		msg := fmt.Sprintf("cannot fetch the last event in the stream of user [%s]", userID)
+		return "", repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if len(messages) == 0 {
+		return "0-0", nil
+	}
+
+	return messages[0].ID, nil
+}
+
+func (repository *redisEventStreamRepository) Read(ctx context.Context, userID entities.UserID, lastID string, timeout time.Duration) ([]*entities.StreamEvent, error) {
+	ctx, span := repository.tracer.Start(ctx)
+	defer span.End()
+
+	streams, err := repository.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{repository.key(userID), lastID},
+		Count:   eventStreamReadCount,
+		Block:   timeout,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return []*entities.StreamEvent{}, nil
+	}
+	if err != nil {
+		msg := fmt.Sprintf("cannot read events after [%s] in the stream of user [%s]", lastID, userID)
+		return nil, repository.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	result := make([]*entities.StreamEvent, 0, eventStreamReadCount)
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			eventType, _ := message.Values["type"].(string)
+			content, _ := message.Values["event"].(string)
+			result = append(result, &entities.StreamEvent{
+				ID:    message.ID,
+				Type:  eventType,
+				Event: []byte(content),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func (repository *redisEventStreamRepository) key(userID entities.UserID) string {
+	return fmt.Sprintf("event-stream:%s", userID)
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+)
+
+// SlackRepository loads and persists an entities.Slack
+type SlackRepository interface {
+	// Save Upsert a new entities.Slack
+	Save(ctx context.Context, slack *entities.Slack) error
+
+	// Index entities.Slack by entities.UserID
+	Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.Slack, error)
+
+	// FetchHavingIncomingChannel loads Slacks for a user that has an incoming channel ID set.
+	FetchHavingIncomingChannel(ctx context.Context, userID entities.UserID) ([]*entities.Slack, error)
+
+	// Load loads a Slack by ID.
+	Load(ctx context.Context, userID entities.UserID, slackID uuid.UUID) (*entities.Slack, error)
+
+	// FindByTeamID loads a Slack by the teamID.
+	FindByTeamID(ctx context.Context, teamID string) (*entities.Slack, error)
+
+	// Delete an entities.Slack
+	Delete(ctx context.Context, userID entities.UserID, slackID uuid.UUID) error
+
+	// DeleteAllForUser deletes all entities.Slack for a user
+	DeleteAllForUser(ctx context.Context, userID entities.UserID) error
+}
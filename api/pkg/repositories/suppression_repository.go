@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+)
+
+// SuppressionRepository loads and persists an entities.Suppression
+type SuppressionRepository interface {
+	// Store a new entities.Suppression. It updates the existing entities.Suppression if the contact is already suppressed
+	Store(ctx context.Context, suppression *entities.Suppression) error
+
+	// Index entities.Suppression by entities.UserID
+	Index(ctx context.Context, userID entities.UserID, params IndexParams) ([]*entities.Suppression, error)
+
+	// LoadByContact loads an entities.Suppression by contact
+	LoadByContact(ctx context.Context, userID entities.UserID, contact string) (*entities.Suppression, error)
+
+	// FilterContacts returns the contacts which are suppressed
+	FilterContacts(ctx context.Context, userID entities.UserID, contacts []string) ([]string, error)
+
+	// Delete an entities.Suppression by ID
+	Delete(ctx context.Context, userID entities.UserID, suppressionID uuid.UUID) error
+
+	// DeleteByContact deletes an entities.Suppression by contact
+	DeleteByContact(ctx context.Context, userID entities.UserID, contact string) error
+
+	// DeleteAllForUser deletes all entities.Suppression for a user
+	DeleteAllForUser(ctx context.Context, userID entities.UserID) error
+}
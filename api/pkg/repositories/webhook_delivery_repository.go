@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+)
+
+// WebhookDeliveryRepository loads and persists an entities.WebhookDelivery
+type WebhookDeliveryRepository interface {
+	// Store a new entities.WebhookDelivery
+	Store(ctx context.Context, delivery *entities.WebhookDelivery) error
+
+	// Index entities.WebhookDelivery of an entities.Webhook
+	Index(ctx context.Context, userID entities.UserID, webhookID uuid.UUID, params IndexParams) ([]*entities.WebhookDelivery, error)
+
+	// Load an entities.WebhookDelivery by ID
+	Load(ctx context.Context, userID entities.UserID, webhookID uuid.UUID, deliveryID uuid.UUID) (*entities.WebhookDelivery, error)
+
+	// DeleteAllForWebhook deletes all entities.WebhookDelivery of an entities.Webhook
+	DeleteAllForWebhook(ctx context.Context, userID entities.UserID, webhookID uuid.UUID) error
+
+	// DeleteAllForUser deletes all entities.WebhookDelivery for a user
+	DeleteAllForUser(ctx context.Context, userID entities.UserID) error
+
+	// DeleteBefore deletes all entities.WebhookDelivery which were created before the timestamp and returns the number of deleted rows
+	DeleteBefore(ctx context.Context, timestamp time.Time) (int64, error)
+}
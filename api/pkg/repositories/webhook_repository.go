@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -22,6 +23,13 @@ type WebhookRepository interface {
 	// Load loads a webhook by ID.
 	Load(ctx context.Context, userID entities.UserID, webhookID uuid.UUID) (*entities.Webhook, error)
 
+	// RecordSuccess resets the consecutive failures of an entities.Webhook after an event was delivered
+	RecordSuccess(ctx context.Context, webhook *entities.Webhook, timestamp time.Time) error
+
+	// RecordFailure increments the consecutive failures of an entities.Webhook and disables it when the failure threshold is reached.
+	// It returns true only for the call which disabled the webhook.
+	RecordFailure(ctx context.Context, webhook *entities.Webhook, timestamp time.Time) (bool, error)
+
 	// Delete an entities.Webhook
 	Delete(ctx context.Context, userID entities.UserID, webhookID uuid.UUID) error
 
@@ -0,0 +1,72 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+)
+
+// AutoReplyRuleStore is the payload for creating a new entities.AutoReplyRule
+type AutoReplyRuleStore struct {
+	request
+	Owner string `json:"owner" example:"+18005550199"`
+	Name  string `json:"name" example:"Opening hours"`
+
+	// Priority determines the order in which the rules are evaluated. Rules with a lower priority are evaluated first.
+	Priority uint `json:"priority" example:"1"`
+
+	// MatchType is one of "keyword", "regex" or "any"
+	MatchType string `json:"match_type" example:"keyword"`
+	Pattern   string `json:"pattern" example:"HOURS" validate:"optional"`
+
+	// Contacts limits the rule to messages from these contacts. The rule applies to all contacts when it is empty.
+	Contacts []string `json:"contacts" example:"+18005550100" validate:"optional"`
+
+	// StartTime and EndTime limit the rule to a daily time window in the timezone of the user
+	StartTime string `json:"start_time" example:"18:00" validate:"optional"`
+	EndTime   string `json:"end_time" example:"08:00" validate:"optional"`
+
+	// CooldownSeconds is the minimum duration in seconds between replies of this rule to the same contact. It must be at least 60 seconds and the default is 3600 seconds.
+	CooldownSeconds uint `json:"cooldown_seconds" example:"3600" validate:"optional"`
+
+	// Reply supports the {{contact}}, {{owner}} and {{content}} placeholders
+	Reply   string `json:"reply" example:"We are open from 08:00 to 18:00, Monday to Friday."`
+	Enabled bool   `json:"enabled" example:"true"`
+}
+
+// Sanitize sets defaults to AutoReplyRuleStore
+func (input *AutoReplyRuleStore) Sanitize() AutoReplyRuleStore {
+	input.Owner = input.sanitizeAddress(input.Owner)
+	input.Name = strings.TrimSpace(input.Name)
+	input.MatchType = strings.ToLower(strings.TrimSpace(input.MatchType))
+	input.Pattern = strings.TrimSpace(input.Pattern)
+	if input.Contacts != nil {
+		input.Contacts = input.removeStringDuplicates(input.sanitizeAddresses(input.Contacts))
+	}
+	input.StartTime = strings.TrimSpace(input.StartTime)
+	input.EndTime = strings.TrimSpace(input.EndTime)
+	input.Reply = strings.TrimSpace(input.Reply)
+	if input.CooldownSeconds == 0 {
+		input.CooldownSeconds = entities.AutoReplyDefaultCooldownSeconds
+	}
+	return *input
+}
+
+// ToStoreParams converts AutoReplyRuleStore to services.AutoReplyRuleStoreParams
+func (input *AutoReplyRuleStore) ToStoreParams(user entities.AuthUser) *services.AutoReplyRuleStoreParams {
+	return &services.AutoReplyRuleStoreParams{
+		UserID:          user.ID,
+		Owner:           input.Owner,
+		Name:            input.Name,
+		Priority:        input.Priority,
+		MatchType:       entities.AutoReplyMatchType(input.MatchType),
+		Pattern:         input.Pattern,
+		Contacts:        input.Contacts,
+		StartTime:       input.sanitizeStringPointer(input.StartTime),
+		EndTime:         input.sanitizeStringPointer(input.EndTime),
+		CooldownSeconds: input.CooldownSeconds,
+		Reply:           input.Reply,
+		Enabled:         input.Enabled,
+	}
+}
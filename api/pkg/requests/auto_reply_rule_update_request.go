@@ -0,0 +1,27 @@
+package requests
+
+import (
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/google/uuid"
+)
+
+// AutoReplyRuleUpdate is the payload for updating an entities.AutoReplyRule
+type AutoReplyRuleUpdate struct {
+	AutoReplyRuleStore
+	AutoReplyRuleID string `json:"autoReplyRuleID" swaggerignore:"true"` // used internally for validation
+}
+
+// Sanitize sets defaults to AutoReplyRuleUpdate
+func (input *AutoReplyRuleUpdate) Sanitize() AutoReplyRuleUpdate {
+	input.AutoReplyRuleStore.Sanitize()
+	return *input
+}
+
+// ToUpdateParams converts AutoReplyRuleUpdate to services.AutoReplyRuleUpdateParams
+func (input *AutoReplyRuleUpdate) ToUpdateParams(user entities.AuthUser) *services.AutoReplyRuleUpdateParams {
+	return &services.AutoReplyRuleUpdateParams{
+		AutoReplyRuleStoreParams: *input.ToStoreParams(user),
+		AutoReplyRuleID:          uuid.MustParse(input.AutoReplyRuleID),
+	}
+}
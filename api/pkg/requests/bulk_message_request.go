@@ -18,6 +18,7 @@ type BulkMessage struct {
 	ToPhoneNumber   string     `csv:"ToPhoneNumber"`
 	Content         string     `csv:"Content"`
 	SendTime        *time.Time `csv:"SendTime(optional)"`
+	CallbackURL     string     `csv:"CallbackURL(optional)"`
 }
 
 // Sanitize sets defaults to BulkMessage
@@ -25,16 +26,19 @@ func (input *BulkMessage) Sanitize() *BulkMessage {
 	input.ToPhoneNumber = input.sanitizeAddress(input.ToPhoneNumber)
 	input.Content = strings.TrimSpace(input.Content)
 	input.FromPhoneNumber = input.sanitizeAddress(input.FromPhoneNumber)
+	input.CallbackURL = strings.TrimSpace(input.CallbackURL)
 	return input
 }
 
 // ToMessageSendParams converts BulkMessage to services.MessageSendParams
-func (input *BulkMessage) ToMessageSendParams(userID entities.UserID, requestID uuid.UUID, source string) services.MessageSendParams {
+func (input *BulkMessage) ToMessageSendParams(userID entities.UserID, requestID uuid.UUID, campaignID *uuid.UUID, source string) services.MessageSendParams {
 	from, _ := phonenumbers.Parse(input.FromPhoneNumber, phonenumbers.UNKNOWN_REGION)
 	return services.MessageSendParams{
 		Source:            source,
 		Owner:             from,
 		RequestID:         input.sanitizeStringPointer(fmt.Sprintf("bulk-%s", requestID.String())),
+		CallbackURL:       input.sanitizeStringPointer(input.CallbackURL),
+		CampaignID:        campaignID,
 		UserID:            userID,
 		SendAt:            input.SendTime,
 		RequestReceivedAt: time.Now().UTC(),
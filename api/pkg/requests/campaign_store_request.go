@@ -0,0 +1,28 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+)
+
+// CampaignStore is the payload for creating a new entities.Campaign
+type CampaignStore struct {
+	request
+	Name string `json:"name" example:"Black Friday Promotion"`
+}
+
+// Sanitize sets defaults to CampaignStore
+func (input *CampaignStore) Sanitize() CampaignStore {
+	input.Name = strings.TrimSpace(input.Name)
+	return *input
+}
+
+// ToStoreParams converts CampaignStore to services.CampaignStoreParams
+func (input *CampaignStore) ToStoreParams(user entities.AuthUser) *services.CampaignStoreParams {
+	return &services.CampaignStoreParams{
+		UserID: user.ID,
+		Name:   input.Name,
+	}
+}
@@ -0,0 +1,13 @@
+package requests
+
+// ContactListMemberStore is the payload for adding a contact to an entities.ContactList
+type ContactListMemberStore struct {
+	request
+	Contact string `json:"contact" example:"+18005550100"`
+}
+
+// Sanitize sets defaults to ContactListMemberStore
+func (input *ContactListMemberStore) Sanitize() ContactListMemberStore {
+	input.Contact = input.sanitizeAddress(input.Contact)
+	return *input
+}
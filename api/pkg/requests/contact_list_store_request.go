@@ -0,0 +1,40 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+)
+
+// ContactListStore is the payload for creating a new entities.ContactList
+type ContactListStore struct {
+	request
+	Name string `json:"name" example:"Weather Alerts"`
+
+	// Keyword is used by contacts to join or leave the list e.g. "JOIN ALERTS" or "LEAVE ALERTS"
+	Keyword string `json:"keyword" example:"ALERTS"`
+
+	JoinReply  string `json:"join_reply" example:"You have joined the weather alerts. Reply LEAVE ALERTS to leave." validate:"optional"`
+	LeaveReply string `json:"leave_reply" example:"You have left the weather alerts." validate:"optional"`
+}
+
+// Sanitize sets defaults to ContactListStore
+func (input *ContactListStore) Sanitize() ContactListStore {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Keyword = entities.NormalizeKeyword(input.Keyword)
+	input.JoinReply = strings.TrimSpace(input.JoinReply)
+	input.LeaveReply = strings.TrimSpace(input.LeaveReply)
+	return *input
+}
+
+// ToStoreParams converts ContactListStore to services.ContactListStoreParams
+func (input *ContactListStore) ToStoreParams(user entities.AuthUser) *services.ContactListStoreParams {
+	return &services.ContactListStoreParams{
+		UserID:     user.ID,
+		Name:       input.Name,
+		Keyword:    input.Keyword,
+		JoinReply:  input.sanitizeStringPointer(input.JoinReply),
+		LeaveReply: input.sanitizeStringPointer(input.LeaveReply),
+	}
+}
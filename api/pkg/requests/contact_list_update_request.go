@@ -0,0 +1,27 @@
+package requests
+
+import (
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/google/uuid"
+)
+
+// ContactListUpdate is the payload for updating an entities.ContactList
+type ContactListUpdate struct {
+	ContactListStore
+	ContactListID string `json:"contactListID" swaggerignore:"true"` // used internally for validation
+}
+
+// Sanitize sets defaults to ContactListUpdate
+func (input *ContactListUpdate) Sanitize() ContactListUpdate {
+	input.ContactListStore.Sanitize()
+	return *input
+}
+
+// ToUpdateParams converts ContactListUpdate to services.ContactListUpdateParams
+func (input *ContactListUpdate) ToUpdateParams(user entities.AuthUser) *services.ContactListUpdateParams {
+	return &services.ContactListUpdateParams{
+		ContactListStoreParams: *input.ToStoreParams(user),
+		ContactListID:          uuid.MustParse(input.ContactListID),
+	}
+}
@@ -0,0 +1,41 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+)
+
+// EventStream is the payload for streaming the events of a user in realtime
+type EventStream struct {
+	request
+	// EventTypes is a comma separated list of the events which are streamed e.g. message.phone.received,message.phone.delivered
+	EventTypes string `json:"event_types" query:"event_types"`
+	// LastEventID resumes the stream after the event with this ID. It can also be set with the Last-Event-ID header.
+	LastEventID string `json:"last_event_id" query:"last_event_id"`
+}
+
+// Sanitize sets defaults to EventStream
+func (input *EventStream) Sanitize() EventStream {
+	input.EventTypes = strings.Join(input.GetEventTypes(), ",")
+	input.LastEventID = strings.TrimSpace(input.LastEventID)
+	return *input
+}
+
+// ToReadParams converts EventStream to services.EventStreamReadParams
+func (input *EventStream) ToReadParams(userID entities.UserID, cursor string) *services.EventStreamReadParams {
+	return &services.EventStreamReadParams{
+		UserID:     userID,
+		Cursor:     cursor,
+		EventTypes: input.GetEventTypes(),
+	}
+}
+
+// GetEventTypes returns the event types which are streamed. All the supported events are streamed when it is empty.
+func (input *EventStream) GetEventTypes() []string {
+	if strings.TrimSpace(input.EventTypes) == "" {
+		return nil
+	}
+	return input.sanitizeStrings(strings.Split(input.EventTypes, ","))
+}
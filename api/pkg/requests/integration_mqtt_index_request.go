@@ -0,0 +1,37 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+)
+
+// IntegrationMQTTIndex is the payload for fetching entities.IntegrationMQTT of a user
+type IntegrationMQTTIndex struct {
+	request
+	Skip  string `json:"skip" query:"skip"`
+	Query string `json:"query" query:"query"`
+	Limit string `json:"limit" query:"limit"`
+}
+
+// Sanitize sets defaults to IntegrationMQTTIndex
+func (input *IntegrationMQTTIndex) Sanitize() IntegrationMQTTIndex {
+	if strings.TrimSpace(input.Limit) == "" {
+		input.Limit = "1"
+	}
+	input.Query = strings.TrimSpace(input.Query)
+	input.Skip = strings.TrimSpace(input.Skip)
+	if input.Skip == "" {
+		input.Skip = "0"
+	}
+	return *input
+}
+
+// ToIndexParams converts IntegrationMQTTIndex to repositories.IndexParams
+func (input *IntegrationMQTTIndex) ToIndexParams() repositories.IndexParams {
+	return repositories.IndexParams{
+		Skip:  input.getInt(input.Skip),
+		Query: input.Query,
+		Limit: input.getInt(input.Limit),
+	}
+}
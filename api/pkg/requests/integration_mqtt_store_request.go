@@ -0,0 +1,73 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+)
+
+// IntegrationMQTTStore is the payload for creating a new entities.IntegrationMQTT
+type IntegrationMQTTStore struct {
+	request
+	Name string `json:"name" example:"Home Assistant"`
+	// Host is the hostname of the MQTT broker without the scheme
+	Host string `json:"host" example:"broker.hivemq.com"`
+	// Port of the MQTT broker. It defaults to 8883 when use_tls is true and 1883 otherwise
+	Port     uint   `json:"port" example:"8883"`
+	UseTLS   bool   `json:"use_tls" example:"true"`
+	Username string `json:"username" example:"httpsms"`
+	// Password is not returned in responses. It is not changed when it is empty on update
+	Password string `json:"password" example:"secret"`
+	// TopicTemplate is the topic of each event. It can contain the {user_id}, {owner}, {contact} and {event_type} placeholders
+	TopicTemplate string `json:"topic_template" example:"httpsms/{owner}/{event_type}"`
+	// QoS is the MQTT quality of service level. It can be 0, 1 or 2
+	QoS    uint8    `json:"qos" example:"1"`
+	Events []string `json:"events" example:"message.phone.received"`
+	// CommandTopic is an optional topic on which messages with the payload of POST /v1/messages/send are sent as SMS
+	CommandTopic string `json:"command_topic" example:"httpsms/commands/send"`
+	// SharedSubscription subscribes to the command topic with an MQTT 5 shared subscription. When it is false, the plain
+	// topic is used and a command with the same payload is sent only once per minute.
+	SharedSubscription bool `json:"shared_subscription" example:"false"`
+}
+
+// Sanitize sets defaults to IntegrationMQTTStore
+func (input *IntegrationMQTTStore) Sanitize() IntegrationMQTTStore {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Host = strings.TrimSpace(input.Host)
+	input.Username = strings.TrimSpace(input.Username)
+	input.TopicTemplate = strings.TrimSpace(input.TopicTemplate)
+	input.CommandTopic = strings.TrimSpace(input.CommandTopic)
+	input.Events = input.removeStringDuplicates(input.Events)
+
+	if input.TopicTemplate == "" {
+		input.TopicTemplate = "httpsms/{owner}/{event_type}"
+	}
+
+	if input.Port == 0 && input.UseTLS {
+		input.Port = 8883
+	}
+	if input.Port == 0 {
+		input.Port = 1883
+	}
+
+	return *input
+}
+
+// ToStoreParams converts IntegrationMQTTStore to services.IntegrationMQTTStoreParams
+func (input *IntegrationMQTTStore) ToStoreParams(user entities.AuthUser) *services.IntegrationMQTTStoreParams {
+	return &services.IntegrationMQTTStoreParams{
+		UserID:             user.ID,
+		Name:               input.Name,
+		Host:               input.Host,
+		Port:               input.Port,
+		UseTLS:             input.UseTLS,
+		Username:           input.sanitizeStringPointer(input.Username),
+		Password:           input.sanitizeStringPointer(input.Password),
+		TopicTemplate:      input.TopicTemplate,
+		QoS:                input.QoS,
+		Events:             input.Events,
+		CommandTopic:       input.sanitizeStringPointer(input.CommandTopic),
+		SharedSubscription: input.SharedSubscription,
+	}
+}
@@ -0,0 +1,27 @@
+package requests
+
+import (
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/google/uuid"
+)
+
+// IntegrationMQTTUpdate is the payload for updating an entities.IntegrationMQTT
+type IntegrationMQTTUpdate struct {
+	IntegrationMQTTStore
+	IntegrationID string `json:"integrationID" swaggerignore:"true"` // used internally for validation
+}
+
+// Sanitize sets defaults to IntegrationMQTTUpdate
+func (input *IntegrationMQTTUpdate) Sanitize() IntegrationMQTTUpdate {
+	input.IntegrationMQTTStore.Sanitize()
+	return *input
+}
+
+// ToUpdateParams converts IntegrationMQTTUpdate to services.IntegrationMQTTUpdateParams
+func (input *IntegrationMQTTUpdate) ToUpdateParams(user entities.AuthUser) *services.IntegrationMQTTUpdateParams {
+	return &services.IntegrationMQTTUpdateParams{
+		IntegrationMQTTStoreParams: *input.ToStoreParams(user),
+		IntegrationID:              uuid.MustParse(input.IntegrationID),
+	}
+}
@@ -1,6 +1,7 @@
 package requests
 
 import (
+	"strings"
 	"time"
 
 	"github.com/NdoleStudio/httpsms/pkg/entities"
@@ -22,6 +23,29 @@ type MessageBulkSend struct {
 
 	// RequestID is an optional parameter used to track a request from the client's perspective
 	RequestID string `json:"request_id" example:"153554b5-ae44-44a0-8f4f-7bbac5657ad4" validate:"optional"`
+
+	// CallbackURL is an optional parameter used to receive the sending, sent, delivered, failed and expired events of each message. The events are signed with your callback signing key.
+	CallbackURL string `json:"callback_url" example:"https://example.com/sms/status" validate:"optional"`
+
+	// CampaignID is an optional parameter used to add the messages to an existing campaign
+	CampaignID string `json:"campaign_id" example:"a9f6bc56-0ec9-4b0b-9f6a-4d7f4d1b6c8e" validate:"optional"`
+
+	// ContactListID is an optional parameter used to send the message to all the members of a contact list
+	ContactListID string `json:"contact_list_id" example:"5e2b1f7c-3c8f-4a8e-9d3c-2f1f7d1b6c8e" validate:"optional"`
+
+	// NormalizeGSM7 is an optional parameter used to replace characters like smart quotes with their GSM-7 equivalents. It defaults to the setting of the phone.
+	NormalizeGSM7 *bool `json:"normalize_gsm7" example:"true" validate:"optional"`
+
+	// ExpiresAt is an optional parameter used to set the time after which the messages will not be sent. It defaults to the message expiration of the phone.
+	ExpiresAt *time.Time `json:"expires_at" example:"2022-06-05T14:36:09.527976+03:00" validate:"optional"`
+
+	// TTL is an optional parameter used to set the number of seconds after the request when the messages will not be sent. It is an alternative to expires_at.
+	TTL uint `json:"ttl" example:"120" validate:"optional"`
+
+	// RetryPolicy is an optional parameter used to determine when and how the messages are sent again after they fail or expire. It defaults to the retry policy of the phone.
+	RetryPolicy *entities.RetryPolicy `json:"retry_policy" validate:"optional"`
+	// DryRun is an optional parameter used to validate the request and preview the messages without storing or sending them. It can also be set with the dry_run query parameter.
+	DryRun bool `json:"dry_run" example:"false" validate:"optional"`
 }
 
 // Sanitize sets defaults to MessageReceive
@@ -32,13 +56,24 @@ func (input *MessageBulkSend) Sanitize() MessageBulkSend {
 	}
 	input.To = to
 	input.From = input.sanitizeAddress(input.From)
+	input.CallbackURL = strings.TrimSpace(input.CallbackURL)
+	input.CampaignID = strings.TrimSpace(input.CampaignID)
+	input.ContactListID = strings.TrimSpace(input.ContactListID)
+	input.RetryPolicy = input.sanitizeRetryPolicy(input.RetryPolicy)
 	return *input
 }
 
+// AddContacts adds the contacts to the recipients of the message without duplicates
+func (input *MessageBulkSend) AddContacts(contacts []string) {
+	input.To = input.removeStringDuplicates(append(input.To, contacts...))
+}
+
 // ToMessageSendParams converts MessageSend to services.MessageSendParams
 func (input *MessageBulkSend) ToMessageSendParams(userID entities.UserID, source string) []services.MessageSendParams {
 	from, _ := phonenumbers.Parse(input.From, phonenumbers.UNKNOWN_REGION)
 
+	expiresAt := input.getExpiresAt(input.ExpiresAt, input.TTL, nil)
+
 	var result []services.MessageSendParams
 	for _, to := range input.To {
 		result = append(result, services.MessageSendParams{
@@ -46,10 +81,15 @@ func (input *MessageBulkSend) ToMessageSendParams(userID entities.UserID, source
 			Owner:             from,
 			Encrypted:         input.Encrypted,
 			RequestID:         input.sanitizeStringPointer(input.RequestID),
+			CallbackURL:       input.sanitizeStringPointer(input.CallbackURL),
+			CampaignID:        input.sanitizeUUIDPointer(input.CampaignID),
 			UserID:            userID,
 			RequestReceivedAt: time.Now().UTC(),
 			Contact:           to,
 			Content:           input.Content,
+			NormalizeGSM7:     input.NormalizeGSM7,
+			ExpiresAt:         expiresAt,
+			RetryPolicy:       input.RetryPolicy,
 		})
 	}
 
@@ -17,6 +17,7 @@ type MessageSearch struct {
 	Owners         []string `json:"owners" query:"owners"`
 	Types          []string `json:"types" query:"types"`
 	Statuses       []string `json:"statuses" query:"statuses"`
+	FailureCodes   []string `json:"failure_codes" query:"failure_codes"`
 	Query          string   `json:"query" query:"query"`
 	SortBy         string   `json:"sort_by" query:"sort_by"`
 	SortDescending bool     `json:"sort_descending" query:"sort_descending"`
@@ -54,6 +55,11 @@ func (input *MessageSearch) ToSearchParams(userID entities.UserID) *services.Mes
 		statuses = append(statuses, entities.MessageStatus(s))
 	}
 
+	var failureCodes []entities.MessageFailureCode
+	for _, code := range input.FailureCodes {
+		failureCodes = append(failureCodes, entities.MessageFailureCode(code))
+	}
+
 	return &services.MessageSearchParams{
 		IndexParams: repositories.IndexParams{
 			Skip:           input.getInt(input.Skip),
@@ -62,9 +68,10 @@ func (input *MessageSearch) ToSearchParams(userID entities.UserID) *services.Mes
 			SortDescending: input.SortDescending,
 			Limit:          input.getInt(input.Limit),
 		},
-		UserID:   userID,
-		Owners:   input.Owners,
-		Types:    types,
-		Statuses: statuses,
+		UserID:       userID,
+		Owners:       input.Owners,
+		Types:        types,
+		Statuses:     statuses,
+		FailureCodes: failureCodes,
 	}
 }
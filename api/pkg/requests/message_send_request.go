@@ -22,15 +22,34 @@ type MessageSend struct {
 	Encrypted bool `json:"encrypted" example:"false"`
 	// RequestID is an optional parameter used to track a request from the client's perspective
 	RequestID string `json:"request_id" example:"153554b5-ae44-44a0-8f4f-7bbac5657ad4" validate:"optional"`
+	// CallbackURL is an optional parameter used to receive the sending, sent, delivered, failed and expired events of the message. The events are signed with your callback signing key.
+	CallbackURL string `json:"callback_url" example:"https://example.com/sms/status" validate:"optional"`
 	// SendAt is an optional parameter used to schedule a message to be sent at a later time
 	SendAt *time.Time `json:"send_at" example:"2022-06-05T14:26:09.527976+03:00" validate:"optional"`
+	// CampaignID is an optional parameter used to add the message to an existing campaign
+	CampaignID string `json:"campaign_id" example:"a9f6bc56-0ec9-4b0b-9f6a-4d7f4d1b6c8e" validate:"optional"`
+	// NormalizeGSM7 is an optional parameter used to replace characters like smart quotes with their GSM-7 equivalents. It defaults to the setting of the phone.
+	NormalizeGSM7 *bool `json:"normalize_gsm7" example:"true" validate:"optional"`
+	// Split is an optional parameter used to split long content into multiple linked messages with "(1/3)" style prefixes which are sent in order
+	Split bool `json:"split" example:"false" validate:"optional"`
+	// ExpiresAt is an optional parameter used to set the time after which the message will not be sent. It defaults to the message expiration of the phone.
+	ExpiresAt *time.Time `json:"expires_at" example:"2022-06-05T14:36:09.527976+03:00" validate:"optional"`
+	// TTL is an optional parameter used to set the number of seconds after the send time when the message will not be sent. It is an alternative to expires_at.
+	TTL uint `json:"ttl" example:"120" validate:"optional"`
+	// RetryPolicy is an optional parameter used to determine when and how the message is sent again after it fails or expires. It defaults to the retry policy of the phone.
+	RetryPolicy *entities.RetryPolicy `json:"retry_policy" validate:"optional"`
+	// DryRun is an optional parameter used to validate the request and preview the messages without storing or sending them. It can also be set with the dry_run query parameter.
+	DryRun bool `json:"dry_run" example:"false" validate:"optional"`
 }
 
 // Sanitize sets defaults to MessageReceive
 func (input *MessageSend) Sanitize() MessageSend {
 	input.To = input.sanitizeAddress(input.To)
 	input.RequestID = strings.TrimSpace(input.RequestID)
+	input.CallbackURL = strings.TrimSpace(input.CallbackURL)
+	input.CampaignID = strings.TrimSpace(input.CampaignID)
 	input.From = input.sanitizeAddress(input.From)
+	input.RetryPolicy = input.sanitizeRetryPolicy(input.RetryPolicy)
 	return *input
 }
 
@@ -42,10 +61,15 @@ func (input *MessageSend) ToMessageSendParams(userID entities.UserID, source str
 		Owner:             from,
 		Encrypted:         input.Encrypted,
 		RequestID:         input.sanitizeStringPointer(input.RequestID),
+		CallbackURL:       input.sanitizeStringPointer(input.CallbackURL),
+		CampaignID:        input.sanitizeUUIDPointer(input.CampaignID),
 		UserID:            userID,
 		SendAt:            input.SendAt,
 		RequestReceivedAt: time.Now().UTC(),
 		Contact:           input.sanitizeAddress(input.To),
 		Content:           input.Content,
+		NormalizeGSM7:     input.NormalizeGSM7,
+		ExpiresAt:         input.getExpiresAt(input.ExpiresAt, input.TTL, input.SendAt),
+		RetryPolicy:       input.RetryPolicy,
 	}
 }
@@ -26,6 +26,38 @@ type PhoneUpsert struct {
 
 	MissedCallAutoReply *string `json:"missed_call_auto_reply" example:"e.g. This phone cannot receive calls. Please send an SMS instead."`
 
+	// OptOutKeywords add the contact to the suppression list. An empty list disables opt-out handling.
+	OptOutKeywords []string `json:"opt_out_keywords" example:"STOP,UNSUBSCRIBE"`
+
+	// OptInKeywords remove the contact from the suppression list. An empty list disables opt-in handling.
+	OptInKeywords []string `json:"opt_in_keywords" example:"START"`
+
+	OptOutReply *string `json:"opt_out_reply" example:"You have been unsubscribed. Reply START to subscribe again."`
+
+	OptInReply *string `json:"opt_in_reply" example:"You have been subscribed again. Reply STOP to unsubscribe."`
+
+	// BusinessHours are the weekly time windows in your timezone when the phone is in the office. An empty list removes the business hours.
+	BusinessHours []entities.BusinessHours `json:"business_hours"`
+
+	// Holidays are dates in the format YYYY-MM-DD when the phone is out of the office for the whole day
+	Holidays []string `json:"holidays" example:"2024-12-25"`
+
+	// NormalizeGSM7 replaces characters like smart quotes with their GSM-7 equivalents by default when sending messages
+	NormalizeGSM7 *bool `json:"normalize_gsm7" example:"true"`
+
+	// RetryPolicy determines when and how messages are sent again after they fail or expire. Use a max_attempts of 0 to remove the retry policy.
+	RetryPolicy *entities.RetryPolicy `json:"retry_policy"`
+
+	// QuietHoursStart and QuietHoursEnd are a daily time window e.g. 21:00 to 08:00 when outgoing messages are deferred. Use empty strings to remove the quiet hours.
+	QuietHoursStart *string `json:"quiet_hours_start" example:"21:00"`
+	QuietHoursEnd   *string `json:"quiet_hours_end" example:"08:00"`
+
+	// QuietHoursRecipientTimezone evaluates the quiet hours in the timezone of the recipient inferred from the phone number instead of your timezone
+	QuietHoursRecipientTimezone *bool `json:"quiet_hours_recipient_timezone" example:"false"`
+
+	// OutOfOfficeReply is sent once per out-of-office window to contacts who send a message or call outside business hours
+	OutOfOfficeReply *string `json:"out_of_office_reply" example:"We are closed right now. Our business hours are Monday to Friday from 09:00 to 17:00."`
+
 	// SIM is the SIM slot of the phone in case the phone has more than 1 SIM slot
 	SIM string `json:"sim" example:"SIM1"`
 }
@@ -38,6 +70,32 @@ func (input *PhoneUpsert) Sanitize() PhoneUpsert {
 	if input.MissedCallAutoReply != nil {
 		input.MissedCallAutoReply = input.sanitizeStringPointer(*input.MissedCallAutoReply)
 	}
+	if input.OptOutReply != nil {
+		input.OptOutReply = input.sanitizeStringPointer(*input.OptOutReply)
+	}
+	if input.OptInReply != nil {
+		input.OptInReply = input.sanitizeStringPointer(*input.OptInReply)
+	}
+	if input.OutOfOfficeReply != nil {
+		input.OutOfOfficeReply = input.sanitizeStringPointer(*input.OutOfOfficeReply)
+	}
+	for index, hours := range input.BusinessHours {
+		input.BusinessHours[index] = entities.BusinessHours{
+			Day:       strings.ToLower(strings.TrimSpace(hours.Day)),
+			StartTime: strings.TrimSpace(hours.StartTime),
+			EndTime:   strings.TrimSpace(hours.EndTime),
+		}
+	}
+	input.Holidays = input.sanitizeDates(input.Holidays)
+	if input.QuietHoursStart != nil {
+		*input.QuietHoursStart = strings.TrimSpace(*input.QuietHoursStart)
+	}
+	if input.QuietHoursEnd != nil {
+		*input.QuietHoursEnd = strings.TrimSpace(*input.QuietHoursEnd)
+	}
+	input.RetryPolicy = input.sanitizeRetryPolicy(input.RetryPolicy)
+	input.OptOutKeywords = input.sanitizeKeywords(input.OptOutKeywords)
+	input.OptInKeywords = input.sanitizeKeywords(input.OptInKeywords)
 	return *input
 }
 
@@ -70,14 +128,26 @@ func (input *PhoneUpsert) ToUpsertParams(user entities.AuthUser, source string)
 	}
 
 	return &services.PhoneUpsertParams{
-		Source:                    source,
-		PhoneNumber:               phone,
-		MessagesPerMinute:         messagesPerMinute,
-		MissedCallAutoReply:       input.MissedCallAutoReply,
-		MessageExpirationDuration: timeout,
-		MaxSendAttempts:           maxSendAttempts,
-		FcmToken:                  fcmToken,
-		UserID:                    user.ID,
-		SIM:                       entities.SIM(input.SIM),
+		Source:                      source,
+		PhoneNumber:                 phone,
+		MessagesPerMinute:           messagesPerMinute,
+		MissedCallAutoReply:         input.MissedCallAutoReply,
+		OptOutKeywords:              input.OptOutKeywords,
+		OptInKeywords:               input.OptInKeywords,
+		OptOutReply:                 input.OptOutReply,
+		OptInReply:                  input.OptInReply,
+		BusinessHours:               input.BusinessHours,
+		Holidays:                    input.Holidays,
+		OutOfOfficeReply:            input.OutOfOfficeReply,
+		NormalizeGSM7:               input.NormalizeGSM7,
+		RetryPolicy:                 input.RetryPolicy,
+		QuietHoursStart:             input.QuietHoursStart,
+		QuietHoursEnd:               input.QuietHoursEnd,
+		QuietHoursRecipientTimezone: input.QuietHoursRecipientTimezone,
+		MessageExpirationDuration:   timeout,
+		MaxSendAttempts:             maxSendAttempts,
+		FcmToken:                    fcmToken,
+		UserID:                      user.ID,
+		SIM:                         entities.SIM(input.SIM),
 	}
 }
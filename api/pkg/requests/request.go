@@ -4,9 +4,11 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/google/uuid"
 
 	"github.com/nyaruka/phonenumbers"
 )
@@ -94,6 +96,14 @@ func (input *request) sanitizeStringPointer(value string) *string {
 	return &value
 }
 
+func (input *request) sanitizeUUIDPointer(value string) *uuid.UUID {
+	id, err := uuid.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
 func (input *request) removeStringDuplicates(values []string) []string {
 	cache := map[string]struct{}{}
 	for _, value := range values {
@@ -108,6 +118,106 @@ func (input *request) removeStringDuplicates(values []string) []string {
 	return result
 }
 
+// sanitizeKeywords normalizes keywords and removes duplicates. A nil value is kept as nil so that it can be ignored.
+func (input *request) sanitizeKeywords(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	cache := map[string]struct{}{}
+	for _, value := range values {
+		keyword := entities.NormalizeKeyword(value)
+		if _, ok := cache[keyword]; ok || keyword == "" {
+			continue
+		}
+		cache[keyword] = struct{}{}
+		result = append(result, keyword)
+	}
+
+	return result
+}
+
+// sanitizeStrings trims values and removes empty values and duplicates. A nil value is kept as nil so that it can be ignored.
+func (input *request) sanitizeStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	cache := map[string]struct{}{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if _, ok := cache[value]; ok || value == "" {
+			continue
+		}
+		cache[value] = struct{}{}
+		result = append(result, value)
+	}
+
+	return result
+}
+
+// sanitizeDates trims dates and removes duplicates. A nil value is kept as nil so that it can be ignored.
+func (input *request) sanitizeDates(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	cache := map[string]struct{}{}
+	for _, value := range values {
+		date := strings.TrimSpace(value)
+		if _, ok := cache[date]; ok || date == "" {
+			continue
+		}
+		cache[date] = struct{}{}
+		result = append(result, date)
+	}
+
+	return result
+}
+
+// sanitizeRetryPolicy normalizes the retry reasons and removes duplicates. A nil value is kept as nil so that it can be ignored.
+func (input *request) sanitizeRetryPolicy(policy *entities.RetryPolicy) *entities.RetryPolicy {
+	if policy == nil {
+		return nil
+	}
+
+	reasons := make([]string, 0, len(policy.RetryOn))
+	cache := map[string]struct{}{}
+	for _, value := range policy.RetryOn {
+		reason := strings.ToUpper(strings.TrimSpace(value))
+		if _, ok := cache[reason]; ok || reason == "" {
+			continue
+		}
+		cache[reason] = struct{}{}
+		reasons = append(reasons, reason)
+	}
+	policy.RetryOn = reasons
+
+	if policy.BackoffMultiplier == 0 {
+		policy.BackoffMultiplier = 1
+	}
+
+	return policy
+}
+
+// getExpiresAt returns the expiry of a message from either the expiry time or the TTL in seconds after the send time
+func (input *request) getExpiresAt(expiresAt *time.Time, ttl uint, sendAt *time.Time) *time.Time {
+	if expiresAt != nil || ttl == 0 {
+		return expiresAt
+	}
+
+	timestamp := time.Now().UTC()
+	if sendAt != nil && sendAt.After(timestamp) {
+		timestamp = *sendAt
+	}
+
+	result := timestamp.Add(time.Duration(ttl) * time.Second)
+	return &result
+}
+
 func (input *request) sanitizeMessageID(value string) string {
 	id := strings.Builder{}
 	for _, char := range value {
@@ -0,0 +1,26 @@
+package requests
+
+import (
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+)
+
+// SuppressionStore is the payload for adding a contact to the suppression list
+type SuppressionStore struct {
+	request
+	Contact string `json:"contact" example:"+18005550100"`
+}
+
+// Sanitize sets defaults to SuppressionStore
+func (input *SuppressionStore) Sanitize() SuppressionStore {
+	input.Contact = input.sanitizeAddress(input.Contact)
+	return *input
+}
+
+// ToStoreParams converts SuppressionStore to services.SuppressionStoreParams
+func (input *SuppressionStore) ToStoreParams(user entities.AuthUser) *services.SuppressionStoreParams {
+	return &services.SuppressionStoreParams{
+		UserID:  user.ID,
+		Contact: input.Contact,
+	}
+}
@@ -0,0 +1,30 @@
+package requests
+
+import (
+	"strings"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/services"
+)
+
+// UserCallbackSigningKeyRotate is the payload for rotating the key which signs the requests to the callback URL of messages
+type UserCallbackSigningKeyRotate struct {
+	request
+	SigningKey string `json:"signing_key" example:"Kq9v67SLqSbWQvckzzmK8D6rvd7NywSEkdMJtuxKyEkYnCYDGW8NwQp7mxKaSZ72X"`
+	// OverlapSeconds is the duration in which requests are signed with both the new and the previous signing key
+	OverlapSeconds uint `json:"overlap_seconds" example:"86400"`
+}
+
+// Sanitize sets defaults to UserCallbackSigningKeyRotate
+func (input *UserCallbackSigningKeyRotate) Sanitize() UserCallbackSigningKeyRotate {
+	input.SigningKey = strings.TrimSpace(input.SigningKey)
+	return *input
+}
+
+// ToRotateParams converts UserCallbackSigningKeyRotate to services.UserCallbackSigningKeyRotateParams
+func (input *UserCallbackSigningKeyRotate) ToRotateParams() *services.UserCallbackSigningKeyRotateParams {
+	return &services.UserCallbackSigningKeyRotateParams{
+		SigningKey: input.SigningKey,
+		Overlap:    time.Duration(input.OverlapSeconds) * time.Second,
+	}
+}
@@ -0,0 +1,41 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+)
+
+// UserMessageLimitsUpdate is the payload for updating the duplicate message protection and contact rate limit of a user
+type UserMessageLimitsUpdate struct {
+	request
+	DuplicateMessageWindowSeconds uint   `json:"duplicate_message_window_seconds" example:"60"`
+	DuplicateMessageAction        string `json:"duplicate_message_action" example:"block"`
+	ContactRateLimit              uint   `json:"contact_rate_limit" example:"10"`
+	ContactRateLimitWindowSeconds uint   `json:"contact_rate_limit_window_seconds" example:"3600"`
+}
+
+// Sanitize sets defaults to UserMessageLimitsUpdate
+func (input *UserMessageLimitsUpdate) Sanitize() UserMessageLimitsUpdate {
+	input.DuplicateMessageAction = strings.ToLower(strings.TrimSpace(input.DuplicateMessageAction))
+	if input.DuplicateMessageAction == "" {
+		input.DuplicateMessageAction = string(entities.DuplicateMessageActionBlock)
+	}
+
+	if input.ContactRateLimitWindowSeconds == 0 {
+		input.ContactRateLimitWindowSeconds = 3600
+	}
+
+	return *input
+}
+
+// ToUserMessageLimitsUpdateParams converts UserMessageLimitsUpdate to services.UserMessageLimitsUpdateParams
+func (input *UserMessageLimitsUpdate) ToUserMessageLimitsUpdateParams() *services.UserMessageLimitsUpdateParams {
+	return &services.UserMessageLimitsUpdateParams{
+		DuplicateMessageWindowSeconds: input.DuplicateMessageWindowSeconds,
+		DuplicateMessageAction:        entities.DuplicateMessageAction(input.DuplicateMessageAction),
+		ContactRateLimit:              input.ContactRateLimit,
+		ContactRateLimitWindowSeconds: input.ContactRateLimitWindowSeconds,
+	}
+}
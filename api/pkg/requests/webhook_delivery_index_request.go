@@ -0,0 +1,39 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+)
+
+// WebhookDeliveryIndex is the payload for fetching entities.WebhookDelivery of a webhook
+type WebhookDeliveryIndex struct {
+	request
+	WebhookID string `json:"webhookID" swaggerignore:"true"` // used internally for validation
+	Skip      string `json:"skip" query:"skip"`
+	Query     string `json:"query" query:"query"`
+	Limit     string `json:"limit" query:"limit"`
+}
+
+// Sanitize sets defaults to WebhookDeliveryIndex
+func (input *WebhookDeliveryIndex) Sanitize() WebhookDeliveryIndex {
+	if strings.TrimSpace(input.Limit) == "" {
+		input.Limit = "20"
+	}
+	input.WebhookID = strings.TrimSpace(input.WebhookID)
+	input.Query = strings.TrimSpace(input.Query)
+	input.Skip = strings.TrimSpace(input.Skip)
+	if input.Skip == "" {
+		input.Skip = "0"
+	}
+	return *input
+}
+
+// ToIndexParams converts WebhookDeliveryIndex to repositories.IndexParams
+func (input *WebhookDeliveryIndex) ToIndexParams() repositories.IndexParams {
+	return repositories.IndexParams{
+		Skip:  input.getInt(input.Skip),
+		Query: input.Query,
+		Limit: input.getInt(input.Limit),
+	}
+}
@@ -0,0 +1,36 @@
+package requests
+
+import (
+	"strings"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/google/uuid"
+)
+
+// WebhookSigningKeyRotate is the payload for rotating the signing key of an entities.Webhook
+type WebhookSigningKeyRotate struct {
+	request
+	WebhookID  string `json:"webhookID" swaggerignore:"true"` // used internally for validation
+	SigningKey string `json:"signing_key" example:"Kq9v67SLqSbWQvckzzmK8D6rvd7NywSEkdMJtuxKyEkYnCYDGW8NwQp7mxKaSZ72X"`
+	// OverlapSeconds is the duration in which requests are signed with both the new and the previous signing key
+	OverlapSeconds uint `json:"overlap_seconds" example:"86400"`
+}
+
+// Sanitize sets defaults to WebhookSigningKeyRotate
+func (input *WebhookSigningKeyRotate) Sanitize() WebhookSigningKeyRotate {
+	input.WebhookID = strings.TrimSpace(input.WebhookID)
+	input.SigningKey = strings.TrimSpace(input.SigningKey)
+	return *input
+}
+
+// ToRotateParams converts WebhookSigningKeyRotate to services.WebhookSigningKeyRotateParams
+func (input *WebhookSigningKeyRotate) ToRotateParams(user entities.AuthUser) *services.WebhookSigningKeyRotateParams {
+	return &services.WebhookSigningKeyRotateParams{
+		UserID:     user.ID,
+		WebhookID:  uuid.MustParse(input.WebhookID),
+		SigningKey: input.SigningKey,
+		Overlap:    time.Duration(input.OverlapSeconds) * time.Second,
+	}
+}
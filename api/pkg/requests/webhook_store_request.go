@@ -1,6 +1,7 @@
 package requests
 
 import (
+	"net/http"
 	"strings"
 
 	"github.com/NdoleStudio/httpsms/pkg/entities"
@@ -10,18 +11,52 @@ import (
 // WebhookStore is the payload for creating a new entities.Webhook
 type WebhookStore struct {
 	request
-	SigningKey   string   `json:"signing_key"`
-	URL          string   `json:"url"`
-	PhoneNumbers []string `json:"phone_numbers" example:"+18005550100,+18005550100"`
-	Events       []string `json:"events"`
+	SigningKey string `json:"signing_key"`
+	// SignatureScheme is the method used to authenticate requests to the webhook. It can be "jwt" or "hmac-sha256"
+	SignatureScheme string `json:"signature_scheme" example:"hmac-sha256"`
+	// FailureThreshold is the number of consecutive failed events after which the webhook is disabled
+	FailureThreshold uint `json:"failure_threshold" example:"20"`
+	// PayloadFormat is the format of the request body. It can be "cloudevent", "discord", "slack", "teams", "form" or "template"
+	PayloadFormat string `json:"payload_format" example:"cloudevent"`
+	// PayloadTemplate is the Go text/template which is rendered when the payload format is "template" e.g. {"text": "{{ .Data.content }}"}
+	PayloadTemplate string `json:"payload_template" example:"{\"text\": \"{{ .Data.content }}\"}"`
+	// HTTPMethod is the method of the request. It can be "POST", "PUT" or "PATCH"
+	HTTPMethod string `json:"http_method" example:"POST"`
+	// Headers are custom HTTP headers which are added to each request
+	Headers map[string]string `json:"headers" swaggertype:"object,string" example:"X-Api-Key:secret"`
+	// Filter restricts the events which are sent to the webhook using the contact, content, SIM, message type or encrypted flag of the event
+	Filter       *entities.WebhookFilter `json:"filter" validate:"optional"`
+	URL          string                  `json:"url"`
+	PhoneNumbers []string                `json:"phone_numbers" example:"+18005550100,+18005550100"`
+	Events       []string                `json:"events"`
 }
 
 // Sanitize sets defaults to WebhookStore
 func (input *WebhookStore) Sanitize() WebhookStore {
 	input.URL = input.sanitizeURL(input.URL)
 	input.SigningKey = strings.TrimSpace(input.SigningKey)
+	input.SignatureScheme = strings.ToLower(strings.TrimSpace(input.SignatureScheme))
+	input.PayloadFormat = strings.ToLower(strings.TrimSpace(input.PayloadFormat))
+	input.HTTPMethod = strings.ToUpper(strings.TrimSpace(input.HTTPMethod))
+
+	if input.Headers != nil {
+		headers := make(map[string]string, len(input.Headers))
+		for key, value := range input.Headers {
+			headers[http.CanonicalHeaderKey(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+		input.Headers = headers
+	}
 	input.Events = input.removeStringDuplicates(input.Events)
 
+	if input.Filter != nil {
+		input.Filter.ContactPrefixes = input.sanitizeStrings(input.Filter.ContactPrefixes)
+		input.Filter.ContentKeywords = input.sanitizeStrings(input.Filter.ContentKeywords)
+		if input.Filter.SIM != nil {
+			sim := entities.SIM(strings.ToUpper(strings.TrimSpace(input.Filter.SIM.String())))
+			input.Filter.SIM = &sim
+		}
+	}
+
 	var phoneNumbers []string
 	for _, address := range input.PhoneNumbers {
 		phoneNumbers = append(phoneNumbers, input.sanitizeAddress(address))
@@ -32,11 +67,38 @@ func (input *WebhookStore) Sanitize() WebhookStore {
 
 // ToStoreParams converts WebhookStore to services.WebhookStoreParams
 func (input *WebhookStore) ToStoreParams(user entities.AuthUser) *services.WebhookStoreParams {
+	scheme := entities.WebhookSignatureSchemeJWT
+	if input.SignatureScheme != "" {
+		scheme = entities.WebhookSignatureScheme(input.SignatureScheme)
+	}
+
+	format := entities.WebhookPayloadFormatCloudEvent
+	if input.PayloadFormat != "" {
+		format = entities.WebhookPayloadFormat(input.PayloadFormat)
+	}
+
+	method := http.MethodPost
+	if input.HTTPMethod != "" {
+		method = input.HTTPMethod
+	}
+
+	threshold := input.FailureThreshold
+	if threshold == 0 {
+		threshold = entities.WebhookDefaultFailureThreshold
+	}
+
 	return &services.WebhookStoreParams{
-		UserID:       user.ID,
-		SigningKey:   input.SigningKey,
-		URL:          input.URL,
-		PhoneNumbers: input.PhoneNumbers,
-		Events:       input.Events,
+		UserID:           user.ID,
+		SigningKey:       input.SigningKey,
+		SignatureScheme:  scheme,
+		FailureThreshold: threshold,
+		PayloadFormat:    format,
+		PayloadTemplate:  input.sanitizeStringPointer(input.PayloadTemplate),
+		HTTPMethod:       method,
+		Headers:          input.Headers,
+		Filter:           input.Filter,
+		URL:              input.URL,
+		PhoneNumbers:     input.PhoneNumbers,
+		Events:           input.Events,
 	}
 }
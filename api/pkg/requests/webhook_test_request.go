@@ -0,0 +1,34 @@
+package requests
+
+import (
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/google/uuid"
+)
+
+// WebhookTest is the payload for sending a test event to an entities.Webhook
+type WebhookTest struct {
+	request
+	WebhookID string `json:"webhookID" swaggerignore:"true"` // used internally for validation
+	// EventType is the type of the synthetic event which is sent to the webhook
+	EventType string `json:"event_type" example:"message.phone.received"`
+}
+
+// Sanitize sets defaults to WebhookTest
+func (input *WebhookTest) Sanitize() WebhookTest {
+	input.WebhookID = strings.TrimSpace(input.WebhookID)
+	input.EventType = strings.TrimSpace(input.EventType)
+	return *input
+}
+
+// ToTestParams converts WebhookTest to services.WebhookTestParams
+func (input *WebhookTest) ToTestParams(user entities.AuthUser, source string) *services.WebhookTestParams {
+	return &services.WebhookTestParams{
+		UserID:    user.ID,
+		WebhookID: uuid.MustParse(input.WebhookID),
+		EventType: input.EventType,
+		Source:    source,
+	}
+}
@@ -21,11 +21,18 @@ func (input *WebhookUpdate) Sanitize() WebhookUpdate {
 // ToUpdateParams converts WebhookUpdate to services.WebhookUpdateParams
 func (input *WebhookUpdate) ToUpdateParams(user entities.AuthUser) *services.WebhookUpdateParams {
 	return &services.WebhookUpdateParams{
-		UserID:       user.ID,
-		WebhookID:    uuid.MustParse(input.WebhookID),
-		SigningKey:   input.SigningKey,
-		URL:          input.URL,
-		PhoneNumbers: input.PhoneNumbers,
-		Events:       input.Events,
+		UserID:           user.ID,
+		WebhookID:        uuid.MustParse(input.WebhookID),
+		SigningKey:       input.SigningKey,
+		SignatureScheme:  entities.WebhookSignatureScheme(input.SignatureScheme),
+		FailureThreshold: input.FailureThreshold,
+		PayloadFormat:    entities.WebhookPayloadFormat(input.PayloadFormat),
+		PayloadTemplate:  input.sanitizeStringPointer(input.PayloadTemplate),
+		HTTPMethod:       input.HTTPMethod,
+		Headers:          input.Headers,
+		Filter:           input.Filter,
+		URL:              input.URL,
+		PhoneNumbers:     input.PhoneNumbers,
+		Events:           input.Events,
 	}
 }
@@ -0,0 +1,15 @@
+package responses
+
+import "github.com/NdoleStudio/httpsms/pkg/entities"
+
+// AutoReplyRuleResponse is the payload containing entities.AutoReplyRule
+type AutoReplyRuleResponse struct {
+	response
+	Data entities.AutoReplyRule `json:"data"`
+}
+
+// AutoReplyRulesResponse is the payload containing []entities.AutoReplyRule
+type AutoReplyRulesResponse struct {
+	response
+	Data []entities.AutoReplyRule `json:"data"`
+}
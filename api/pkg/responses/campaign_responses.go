@@ -0,0 +1,21 @@
+package responses
+
+import "github.com/NdoleStudio/httpsms/pkg/entities"
+
+// CampaignResponse is the payload containing entities.Campaign
+type CampaignResponse struct {
+	response
+	Data entities.Campaign `json:"data"`
+}
+
+// CampaignsResponse is the payload containing []entities.Campaign
+type CampaignsResponse struct {
+	response
+	Data []entities.Campaign `json:"data"`
+}
+
+// CampaignStatsResponse is the payload containing entities.CampaignStats
+type CampaignStatsResponse struct {
+	response
+	Data entities.CampaignStats `json:"data"`
+}
@@ -0,0 +1,27 @@
+package responses
+
+import "github.com/NdoleStudio/httpsms/pkg/entities"
+
+// ContactListResponse is the payload containing entities.ContactList
+type ContactListResponse struct {
+	response
+	Data entities.ContactList `json:"data"`
+}
+
+// ContactListsResponse is the payload containing []entities.ContactList
+type ContactListsResponse struct {
+	response
+	Data []entities.ContactList `json:"data"`
+}
+
+// ContactListMemberResponse is the payload containing entities.ContactListMember
+type ContactListMemberResponse struct {
+	response
+	Data entities.ContactListMember `json:"data"`
+}
+
+// ContactListMembersResponse is the payload containing []entities.ContactListMember
+type ContactListMembersResponse struct {
+	response
+	Data []entities.ContactListMember `json:"data"`
+}
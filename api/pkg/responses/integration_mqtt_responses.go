@@ -0,0 +1,15 @@
+package responses
+
+import "github.com/NdoleStudio/httpsms/pkg/entities"
+
+// IntegrationMQTTResponse is the payload containing entities.IntegrationMQTT
+type IntegrationMQTTResponse struct {
+	response
+	Data entities.IntegrationMQTT `json:"data"`
+}
+
+// IntegrationMQTTsResponse is the payload containing []entities.IntegrationMQTT
+type IntegrationMQTTsResponse struct {
+	response
+	Data []entities.IntegrationMQTT `json:"data"`
+}
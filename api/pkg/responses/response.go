@@ -31,6 +31,19 @@ type UnprocessableEntity struct {
 	Data    map[string][]string `json:"data"`
 }
 
+// TooManyRequests is the response with status code is 429
+type TooManyRequests struct {
+	Status  string              `json:"status" example:"error"`
+	Message string              `json:"message" example:"too many messages sent to the contact"`
+	Data    map[string][]string `json:"data"`
+}
+
+// Conflict is the response with status code is 409
+type Conflict struct {
+	Status  string `json:"status" example:"error"`
+	Message string `json:"message" example:"A request with the idempotency key [a8f5f167] is still being processed"`
+}
+
 // Unauthorized is the response with status code is 403
 type Unauthorized struct {
 	Status  string `json:"status" example:"error"`
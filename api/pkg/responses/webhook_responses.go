@@ -13,3 +13,15 @@ type WebhooksResponse struct {
 	response
 	Data []entities.Webhook `json:"data"`
 }
+
+// WebhookDeliveryResponse is the payload containing entities.WebhookDelivery
+type WebhookDeliveryResponse struct {
+	response
+	Data entities.WebhookDelivery `json:"data"`
+}
+
+// WebhookDeliveriesResponse is the payload containing []entities.WebhookDelivery
+type WebhookDeliveriesResponse struct {
+	response
+	Data []entities.WebhookDelivery `json:"data"`
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/palantir/stacktrace"
+)
+
+// AutoReplyService is responsible for managing entities.AutoReplyRule
+type AutoReplyService struct {
+	service
+	logger         telemetry.Logger
+	tracer         telemetry.Tracer
+	repository     repositories.AutoReplyRuleRepository
+	userRepository repositories.UserRepository
+	phoneService   *PhoneService
+	messageService *MessageService
+}
+
+// NewAutoReplyService creates a new AutoReplyService
+func NewAutoReplyService(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	repository repositories.AutoReplyRuleRepository,
+	userRepository repositories.UserRepository,
+	phoneService *PhoneService,
+	messageService *MessageService,
+) (s *AutoReplyService) {
+	return &AutoReplyService{
+		logger:         logger.WithService(fmt.Sprintf("%T", s)),
+		tracer:         tracer,
+		repository:     repository,
+		userRepository: userRepository,
+		phoneService:   phoneService,
+		messageService: messageService,
+	}
+}
+
+// DeleteAllForUser deletes all entities.AutoReplyRule for an entities.UserID.
+func (service *AutoReplyService) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.DeleteAllForUser(ctx, userID); err != nil {
+		msg := fmt.Sprintf("could not delete all [entities.AutoReplyRule] for user with ID [%s]", userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted all [entities.AutoReplyRule] for user with ID [%s]", userID))
+	return nil
+}
+
+// Index fetches the entities.AutoReplyRule for an entities.UserID
+func (service *AutoReplyService) Index(ctx context.Context, userID entities.UserID, params repositories.IndexParams) ([]*entities.AutoReplyRule, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	rules, err := service.repository.Index(ctx, userID, params)
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch auto reply rules with params [%+#v]", params)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("fetched [%d] auto reply rules with prams [%+#v]", len(rules), params))
+	return rules, nil
+}
+
+// Load an entities.AutoReplyRule by ID
+func (service *AutoReplyService) Load(ctx context.Context, userID entities.UserID, ruleID uuid.UUID) (*entities.AutoReplyRule, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	rule, err := service.repository.Load(ctx, userID, ruleID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load auto reply rule with ID [%s] for user [%s]", ruleID, userID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	return rule, nil
+}
+
+// AutoReplyRuleStoreParams are parameters for creating a new entities.AutoReplyRule
+type AutoReplyRuleStoreParams struct {
+	UserID          entities.UserID
+	Owner           string
+	Name            string
+	Priority        uint
+	MatchType       entities.AutoReplyMatchType
+	Pattern         string
+	Contacts        pq.StringArray
+	StartTime       *string
+	EndTime         *string
+	CooldownSeconds uint
+	Reply           string
+	Enabled         bool
+}
+
+// Store a new entities.AutoReplyRule
+func (service *AutoReplyService) Store(ctx context.Context, params *AutoReplyRuleStoreParams) (*entities.AutoReplyRule, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	rule := &entities.AutoReplyRule{
+		ID:        uuid.New(),
+		UserID:    params.UserID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := service.repository.Store(ctx, service.update(rule, params)); err != nil {
+		msg := fmt.Sprintf("cannot store auto reply rule with id [%s]", rule.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("auto reply rule saved with id [%s] in the [%T]", rule.ID, service.repository))
+	return rule, nil
+}
+
+// AutoReplyRuleUpdateParams are parameters for updating an entities.AutoReplyRule
+type AutoReplyRuleUpdateParams struct {
+	AutoReplyRuleStoreParams
+	AutoReplyRuleID uuid.UUID
+}
+
+// Update an entities.AutoReplyRule
+func (service *AutoReplyService) Update(ctx context.Context, params *AutoReplyRuleUpdateParams) (*entities.AutoReplyRule, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	rule, err := service.repository.Load(ctx, params.UserID, params.AutoReplyRuleID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load auto reply rule with ID [%s] for user [%s]", params.AutoReplyRuleID, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	if err = service.repository.Update(ctx, service.update(rule, &params.AutoReplyRuleStoreParams)); err != nil {
+		msg := fmt.Sprintf("cannot update auto reply rule with id [%s]", rule.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("auto reply rule updated with id [%s] in the [%T]", rule.ID, service.repository))
+	return rule, nil
+}
+
+// Delete an entities.AutoReplyRule
+func (service *AutoReplyService) Delete(ctx context.Context, userID entities.UserID, ruleID uuid.UUID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.Delete(ctx, userID, ruleID); err != nil {
+		msg := fmt.Sprintf("cannot delete auto reply rule with ID [%s] for user [%s]", ruleID, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted auto reply rule with ID [%s] for user [%s]", ruleID, userID))
+	return nil
+}
+
+// HandleMessageReceived evaluates the entities.AutoReplyRule of the phone and sends a reply with the first matching rule.
+// The out-of-office reply of the phone is sent when no rule matches a message received outside business hours.
+func (service *AutoReplyService) HandleMessageReceived(ctx context.Context, source string, payload *events.MessagePhoneReceivedPayload) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if payload.Encrypted {
+		ctxLogger.Info(fmt.Sprintf("message [%s] for user [%s] is encrypted and cannot be matched with an auto reply rule", payload.MessageID, payload.UserID))
+		return nil
+	}
+
+	if _, _, ok := entities.ParseContactListCommand(payload.Content); ok {
+		return nil
+	}
+
+	phone, err := service.phoneService.Load(ctx, payload.UserID, payload.Owner)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load phone with owner [%s] for user [%s] when handling message [%s]", payload.Owner, payload.UserID, payload.MessageID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if phone.IsOptOutKeyword(payload.Content) || phone.IsOptInKeyword(payload.Content) {
+		return nil
+	}
+
+	rules, err := service.repository.FetchEnabled(ctx, payload.UserID, payload.Owner)
+	if err != nil {
+		msg := fmt.Sprintf("cannot fetch auto reply rules for owner [%s] and user [%s]", payload.Owner, payload.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if len(rules) == 0 && !phone.HasOutOfOfficeReply() {
+		return nil
+	}
+
+	user, err := service.userRepository.Load(ctx, payload.UserID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load user [%s] when handling message [%s]", payload.UserID, payload.MessageID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	timestamp := payload.Timestamp.In(user.Location())
+	for _, rule := range rules {
+		if rule.Matches(payload.Contact, payload.Content, timestamp) {
+			return service.reply(ctx, source, rule, payload)
+		}
+	}
+
+	if phone.HasOutOfOfficeReply() && phone.IsOutOfOffice(timestamp) {
+		return service.replyOutOfOffice(ctx, source, phone, payload.Contact, payload.MessageID, timestamp)
+	}
+
+	ctxLogger.Info(fmt.Sprintf("no auto reply rule out of [%d] matches message [%s] for user [%s]", len(rules), payload.MessageID, payload.UserID))
+	return nil
+}
+
+// HandleMissedCall sends the out-of-office reply for a missed call outside business hours and the missed call auto reply otherwise
+func (service *AutoReplyService) HandleMissedCall(ctx context.Context, source string, payload *events.MessageCallMissedPayload) error {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	phone, err := service.phoneService.Load(ctx, payload.UserID, payload.Owner)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load phone with owner [%s] for user [%s] when handling missed call [%s]", payload.Owner, payload.UserID, payload.MessageID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if phone.HasOutOfOfficeReply() {
+		user, err := service.userRepository.Load(ctx, payload.UserID)
+		if err != nil {
+			msg := fmt.Sprintf("cannot load user [%s] when handling missed call [%s]", payload.UserID, payload.MessageID)
+			return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+
+		if timestamp := payload.Timestamp.In(user.Location()); phone.IsOutOfOffice(timestamp) {
+			return service.replyOutOfOffice(ctx, source, phone, payload.Contact, payload.MessageID, timestamp)
+		}
+	}
+
+	if err = service.messageService.RespondToMissedCall(ctx, source, payload); err != nil {
+		msg := fmt.Sprintf("cannot respond to missed call [%s] for user [%s]", payload.MessageID, payload.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+// replyOutOfOffice sends the out-of-office reply of the phone at most once per contact in an out-of-office window
+func (service *AutoReplyService) replyOutOfOffice(ctx context.Context, source string, phone *entities.Phone, contact string, messageID uuid.UUID, timestamp time.Time) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	since := phone.OutOfOfficeSince(timestamp)
+	claimed, err := service.repository.ClaimOutOfOffice(ctx, phone.UserID, phone.PhoneNumber, contact, timestamp, since)
+	if err != nil {
+		msg := fmt.Sprintf("cannot claim out-of-office reply of phone [%s] for contact [%s]", phone.PhoneNumber, contact)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if !claimed {
+		ctxLogger.Info(fmt.Sprintf("phone [%s] already sent an out-of-office reply to contact [%s] since [%s]", phone.PhoneNumber, contact, since))
+		return nil
+	}
+
+	_, err = service.messageService.SendSystemMessage(ctx, SystemMessageParams{
+		UserID:    phone.UserID,
+		Owner:     phone.PhoneNumber,
+		Contact:   contact,
+		Content:   *phone.OutOfOfficeReply,
+		Source:    source,
+		RequestID: fmt.Sprintf("out-of-office-%s", messageID),
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot send out-of-office reply to [%s] for message [%s]", contact, messageID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (service *AutoReplyService) reply(ctx context.Context, source string, rule *entities.AutoReplyRule, payload *events.MessagePhoneReceivedPayload) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	now := time.Now().UTC()
+	claimed, err := service.repository.ClaimCooldown(ctx, rule, payload.Contact, now, now.Add(-rule.Cooldown()))
+	if err != nil {
+		msg := fmt.Sprintf("cannot claim cooldown of auto reply rule [%s] for contact [%s]", rule.ID, payload.Contact)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if !claimed {
+		ctxLogger.Info(fmt.Sprintf("auto reply rule [%s] already replied to contact [%s] in the last [%s]", rule.ID, payload.Contact, rule.Cooldown()))
+		return nil
+	}
+
+	_, err = service.messageService.SendSystemMessage(ctx, SystemMessageParams{
+		UserID:    payload.UserID,
+		Owner:     payload.Owner,
+		Contact:   payload.Contact,
+		Content:   rule.RenderReply(payload.Owner, payload.Contact, payload.Content),
+		Source:    source,
+		RequestID: fmt.Sprintf("auto-reply-%s-%s", rule.ID, payload.MessageID),
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot send auto reply with rule [%s] to [%s] for message [%s]", rule.ID, payload.Contact, payload.MessageID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (service *AutoReplyService) update(rule *entities.AutoReplyRule, params *AutoReplyRuleStoreParams) *entities.AutoReplyRule {
+	rule.Owner = params.Owner
+	rule.Name = params.Name
+	rule.Priority = params.Priority
+	rule.MatchType = params.MatchType
+	rule.Pattern = params.Pattern
+	rule.Contacts = params.Contacts
+	rule.StartTime = params.StartTime
+	rule.EndTime = params.EndTime
+	rule.CooldownSeconds = params.CooldownSeconds
+	rule.Reply = params.Reply
+	rule.Enabled = params.Enabled
+	rule.UpdatedAt = time.Now().UTC()
+	return rule
+}
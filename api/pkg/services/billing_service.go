@@ -25,6 +25,7 @@ type BillingService struct {
 	mailer                 emails.Mailer
 	userRepository         repositories.UserRepository
 	billingUsageRepository repositories.BillingUsageRepository
+	billBySegment          bool
 }
 
 // NewBillingService creates a new BillingService
@@ -36,6 +37,7 @@ func NewBillingService(
 	emailFactory emails.UserEmailFactory,
 	usageRepository repositories.BillingUsageRepository,
 	userRepository repositories.UserRepository,
+	billBySegment bool,
 ) (s *BillingService) {
 	return &BillingService{
 		logger:                 logger.WithService(fmt.Sprintf("%T", s)),
@@ -45,6 +47,7 @@ func NewBillingService(
 		mailer:                 mailer,
 		userRepository:         userRepository,
 		billingUsageRepository: usageRepository,
+		billBySegment:          billBySegment,
 	}
 }
 
@@ -79,6 +82,19 @@ func (service *BillingService) IsEntitled(ctx context.Context, userID entities.U
 	return service.IsEntitledWithCount(ctx, userID, 1)
 }
 
+// IsEntitledToSend checks if a user can send messages with the number of segments of each message.
+// The segments are counted instead of the messages when billing by segment.
+func (service *BillingService) IsEntitledToSend(ctx context.Context, userID entities.UserID, segments ...uint) *string {
+	count := uint(len(segments))
+	if service.billBySegment {
+		count = 0
+		for _, value := range segments {
+			count += max(value, 1)
+		}
+	}
+	return service.IsEntitledWithCount(ctx, userID, count)
+}
+
 func (service *BillingService) handleLimitExceeded(ctx context.Context, user *entities.User) *string {
 	ctx, span := service.tracer.Start(ctx)
 	defer span.End()
@@ -136,14 +152,21 @@ func (service *BillingService) GetUsageHistory(ctx context.Context, userID entit
 	return service.billingUsageRepository.GetHistory(ctx, userID, params)
 }
 
-// RegisterSentMessage records the billing usage for a sent message
-func (service *BillingService) RegisterSentMessage(ctx context.Context, messageID uuid.UUID, timestamp time.Time, userID entities.UserID) error {
+// RegisterSentMessage records the billing usage for a sent message.
+// Each SMS segment is billed as a separate message when billing by segment is enabled.
+func (service *BillingService) RegisterSentMessage(ctx context.Context, messageID uuid.UUID, timestamp time.Time, userID entities.UserID, segments uint) error {
 	ctx, span := service.tracer.Start(ctx)
 	defer span.End()
 
 	ctxLogger := service.tracer.CtxLogger(service.logger, span)
 
-	if err := service.billingUsageRepository.RegisterSentMessage(ctx, timestamp, userID); err != nil {
+	segments = max(segments, 1)
+	count := uint(1)
+	if service.billBySegment {
+		count = segments
+	}
+
+	if err := service.billingUsageRepository.RegisterSentMessage(ctx, timestamp, userID, count, segments); err != nil {
 		msg := fmt.Sprintf("could not register [sent] message with ID [%s] for user with ID [%s]", messageID, userID)
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
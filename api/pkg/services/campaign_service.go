@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+// CampaignService is responsible for managing entities.Campaign
+type CampaignService struct {
+	service
+	logger          telemetry.Logger
+	tracer          telemetry.Tracer
+	eventDispatcher *EventDispatcher
+	repository      repositories.CampaignRepository
+}
+
+// NewCampaignService creates a new CampaignService
+func NewCampaignService(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	repository repositories.CampaignRepository,
+	eventDispatcher *EventDispatcher,
+) (s *CampaignService) {
+	return &CampaignService{
+		logger:          logger.WithService(fmt.Sprintf("%T", s)),
+		tracer:          tracer,
+		repository:      repository,
+		eventDispatcher: eventDispatcher,
+	}
+}
+
+// DeleteAllForUser deletes all entities.Campaign for an entities.UserID.
+func (service *CampaignService) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.DeleteAllForUser(ctx, userID); err != nil {
+		msg := fmt.Sprintf("could not delete all [entities.Campaign] for user with ID [%s]", userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted all [entities.Campaign] for user with ID [%s]", userID))
+	return nil
+}
+
+// Index fetches the entities.Campaign for an entities.UserID
+func (service *CampaignService) Index(ctx context.Context, userID entities.UserID, params repositories.IndexParams) ([]*entities.Campaign, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	campaigns, err := service.repository.Index(ctx, userID, params)
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch campaigns with params [%+#v]", params)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("fetched [%d] campaigns with prams [%+#v]", len(campaigns), params))
+	return campaigns, nil
+}
+
+// Load an entities.Campaign by ID
+func (service *CampaignService) Load(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) (*entities.Campaign, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	campaign, err := service.repository.Load(ctx, userID, campaignID)
+	if err != nil {
+		msg := fmt.Sprintf("could not load campaign with ID [%s] for user [%s]", campaignID, userID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	return campaign, nil
+}
+
+// Stats fetches the entities.CampaignStats of an entities.Campaign
+func (service *CampaignService) Stats(ctx context.Context, campaign *entities.Campaign) (*entities.CampaignStats, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	stats, err := service.repository.Stats(ctx, campaign.UserID, campaign.ID)
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch stats for campaign with ID [%s] and user [%s]", campaign.ID, campaign.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return stats, nil
+}
+
+// CampaignStoreParams are parameters for creating a new entities.Campaign
+type CampaignStoreParams struct {
+	UserID entities.UserID
+	Name   string
+}
+
+// Store a new entities.Campaign
+func (service *CampaignService) Store(ctx context.Context, params *CampaignStoreParams) (*entities.Campaign, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	campaign := &entities.Campaign{
+		ID:        uuid.New(),
+		UserID:    params.UserID,
+		Name:      params.Name,
+		Status:    entities.CampaignStatusActive,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	if err := service.repository.Store(ctx, campaign); err != nil {
+		msg := fmt.Sprintf("cannot store campaign with id [%s]", campaign.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("campaign saved with id [%s] in the [%T]", campaign.ID, service.repository))
+	return campaign, nil
+}
+
+// Pause an entities.Campaign so that messages which have not been sent to the phone are held
+func (service *CampaignService) Pause(ctx context.Context, campaign *entities.Campaign) (*entities.Campaign, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.updateStatus(ctx, campaign.Pause(time.Now().UTC()), entities.CampaignStatusActive); err != nil {
+		msg := fmt.Sprintf("cannot pause campaign with id [%s]", campaign.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("campaign with id [%s] has been paused for user [%s]", campaign.ID, campaign.UserID))
+	return campaign, nil
+}
+
+// Resume a paused entities.Campaign and release the held messages
+func (service *CampaignService) Resume(ctx context.Context, source string, campaign *entities.Campaign) (*entities.Campaign, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.updateStatus(ctx, campaign.Resume(time.Now().UTC()), entities.CampaignStatusPaused); err != nil {
+		msg := fmt.Sprintf("cannot resume campaign with id [%s]", campaign.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	event, err := service.createEvent(events.EventTypeCampaignResumed, source, &events.CampaignResumedPayload{
+		CampaignID: campaign.ID,
+		UserID:     campaign.UserID,
+		Timestamp:  campaign.UpdatedAt,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot create [%s] event for campaign [%s]", events.EventTypeCampaignResumed, campaign.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err = service.eventDispatcher.Dispatch(ctx, event); err != nil {
+		msg := fmt.Sprintf("cannot dispatch [%s] event for campaign [%s]", event.Type(), campaign.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("campaign with id [%s] has been resumed for user [%s]", campaign.ID, campaign.UserID))
+	return campaign, nil
+}
+
+// Cancel an entities.Campaign so that messages which have not been sent to the phone are failed
+func (service *CampaignService) Cancel(ctx context.Context, source string, campaign *entities.Campaign) (*entities.Campaign, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.updateStatus(ctx, campaign.Cancel(time.Now().UTC()), entities.CampaignStatusActive, entities.CampaignStatusPaused); err != nil {
+		msg := fmt.Sprintf("cannot cancel campaign with id [%s]", campaign.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	event, err := service.createEvent(events.EventTypeCampaignCanceled, source, &events.CampaignCanceledPayload{
+		CampaignID: campaign.ID,
+		UserID:     campaign.UserID,
+		Timestamp:  *campaign.CanceledAt,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot create [%s] event for campaign [%s]", events.EventTypeCampaignCanceled, campaign.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err = service.eventDispatcher.Dispatch(ctx, event); err != nil {
+		msg := fmt.Sprintf("cannot dispatch [%s] event for campaign [%s]", event.Type(), campaign.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("campaign with id [%s] has been canceled for user [%s]", campaign.ID, campaign.UserID))
+	return campaign, nil
+}
+
+// updateStatus saves the new status of an entities.Campaign if it still has one of the expected statuses
+func (service *CampaignService) updateStatus(ctx context.Context, campaign *entities.Campaign, expected ...entities.CampaignStatus) error {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	updated, err := service.repository.UpdateStatus(ctx, campaign, expected...)
+	if err != nil {
+		msg := fmt.Sprintf("cannot update status of campaign with id [%s] to [%s]", campaign.ID, campaign.Status)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if !updated {
+		msg := fmt.Sprintf("the status of campaign with id [%s] is no longer one of %v", campaign.ID, expected)
+		return service.tracer.WrapErrorSpan(span, stacktrace.NewErrorWithCode(ErrCodeCampaignStatusChanged, msg))
+	}
+
+	return nil
+}
+
+// Delete an entities.Campaign
+func (service *CampaignService) Delete(ctx context.Context, userID entities.UserID, campaignID uuid.UUID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.Delete(ctx, userID, campaignID); err != nil {
+		msg := fmt.Sprintf("cannot delete campaign with id [%s] and user [%s]", campaignID, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted campaign with id [%s] and user id [%s]", campaignID, userID))
+	return nil
+}
+
+// HandleMessageUpdated completes an entities.Campaign when all its messages have been processed
+func (service *CampaignService) HandleMessageUpdated(ctx context.Context, source string, payload *events.CampaignMessageUpdatedPayload) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	campaign, err := service.repository.Load(ctx, payload.UserID, payload.CampaignID)
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		ctxLogger.Info(fmt.Sprintf("campaign with ID [%s] has been deleted for user [%s]", payload.CampaignID, payload.UserID))
+		return nil
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load campaign with ID [%s] for user [%s]", payload.CampaignID, payload.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if !campaign.IsActive() {
+		ctxLogger.Info(fmt.Sprintf("campaign with ID [%s] has status [%s] and cannot be completed", campaign.ID, campaign.Status))
+		return nil
+	}
+
+	stats, err := service.Stats(ctx, campaign)
+	if err != nil {
+		return service.tracer.WrapErrorSpan(span, err)
+	}
+
+	if stats.Outstanding() > 0 {
+		ctxLogger.Info(fmt.Sprintf("campaign with ID [%s] still has [%d] outstanding messages", campaign.ID, stats.Outstanding()))
+		return nil
+	}
+
+	completedAt := time.Now().UTC()
+	completed, err := service.repository.Complete(ctx, campaign.UserID, campaign.ID, completedAt)
+	if err != nil {
+		msg := fmt.Sprintf("cannot complete campaign with ID [%s] for user [%s]", campaign.ID, campaign.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if !completed {
+		ctxLogger.Info(fmt.Sprintf("campaign with ID [%s] has already been completed or new messages were added to it", campaign.ID))
+		return nil
+	}
+
+	event, err := service.createEvent(events.EventTypeCampaignCompleted, source, &events.CampaignCompletedPayload{
+		CampaignID:  campaign.ID,
+		UserID:      campaign.UserID,
+		Owner:       payload.Owner,
+		Name:        campaign.Name,
+		Stats:       *stats,
+		CompletedAt: completedAt,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot create [%s] event for campaign [%s]", events.EventTypeCampaignCompleted, campaign.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err = service.eventDispatcher.Dispatch(ctx, event); err != nil {
+		msg := fmt.Sprintf("cannot dispatch [%s] event for campaign [%s]", event.Type(), campaign.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("campaign with ID [%s] has been completed for user [%s]", campaign.ID, campaign.UserID))
+	return nil
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+// ContactListService is responsible for managing entities.ContactList
+type ContactListService struct {
+	service
+	logger             telemetry.Logger
+	tracer             telemetry.Tracer
+	repository         repositories.ContactListRepository
+	suppressionService *SuppressionService
+	messageService     *MessageService
+}
+
+// NewContactListService creates a new ContactListService
+func NewContactListService(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	repository repositories.ContactListRepository,
+	suppressionService *SuppressionService,
+	messageService *MessageService,
+) (s *ContactListService) {
+	return &ContactListService{
+		logger:             logger.WithService(fmt.Sprintf("%T", s)),
+		tracer:             tracer,
+		repository:         repository,
+		suppressionService: suppressionService,
+		messageService:     messageService,
+	}
+}
+
+// DeleteAllForUser deletes all entities.ContactList for an entities.UserID.
+func (service *ContactListService) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.DeleteAllForUser(ctx, userID); err != nil {
+		msg := fmt.Sprintf("could not delete all [entities.ContactList] for user with ID [%s]", userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted all [entities.ContactList] for user with ID [%s]", userID))
+	return nil
+}
+
+// Index fetches the entities.ContactList for an entities.UserID
+func (service *ContactListService) Index(ctx context.Context, userID entities.UserID, params repositories.IndexParams) ([]*entities.ContactList, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	lists, err := service.repository.Index(ctx, userID, params)
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch contact lists with params [%+#v]", params)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("fetched [%d] contact lists with prams [%+#v]", len(lists), params))
+	return lists, nil
+}
+
+// Load an entities.ContactList by ID
+func (service *ContactListService) Load(ctx context.Context, userID entities.UserID, listID uuid.UUID) (*entities.ContactList, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	list, err := service.repository.Load(ctx, userID, listID)
+	if err != nil {
+		msg := fmt.Sprintf("could not load contact list with ID [%s] for user [%s]", listID, userID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	return list, nil
+}
+
+// LoadByKeyword loads an entities.ContactList by keyword
+func (service *ContactListService) LoadByKeyword(ctx context.Context, userID entities.UserID, keyword string) (*entities.ContactList, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	list, err := service.repository.LoadByKeyword(ctx, userID, keyword)
+	if err != nil {
+		msg := fmt.Sprintf("could not load contact list with keyword [%s] for user [%s]", keyword, userID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	return list, nil
+}
+
+// ContactListStoreParams are parameters for creating a new entities.ContactList
+type ContactListStoreParams struct {
+	UserID     entities.UserID
+	Name       string
+	Keyword    string
+	JoinReply  *string
+	LeaveReply *string
+}
+
+// Store a new entities.ContactList
+func (service *ContactListService) Store(ctx context.Context, params *ContactListStoreParams) (*entities.ContactList, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	list := &entities.ContactList{
+		ID:         uuid.New(),
+		UserID:     params.UserID,
+		Name:       params.Name,
+		Keyword:    params.Keyword,
+		JoinReply:  params.JoinReply,
+		LeaveReply: params.LeaveReply,
+		CreatedAt:  time.Now().UTC(),
+		UpdatedAt:  time.Now().UTC(),
+	}
+
+	if err := service.repository.Store(ctx, list); err != nil {
+		msg := fmt.Sprintf("cannot store contact list with id [%s]", list.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("contact list saved with id [%s] in the [%T]", list.ID, service.repository))
+	return list, nil
+}
+
+// ContactListUpdateParams are parameters for updating an entities.ContactList
+type ContactListUpdateParams struct {
+	ContactListStoreParams
+	ContactListID uuid.UUID
+}
+
+// Update an entities.ContactList
+func (service *ContactListService) Update(ctx context.Context, params *ContactListUpdateParams) (*entities.ContactList, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	list, err := service.repository.Load(ctx, params.UserID, params.ContactListID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load contact list with ID [%s] for user [%s]", params.ContactListID, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	list.Name = params.Name
+	list.Keyword = params.Keyword
+	list.JoinReply = params.JoinReply
+	list.LeaveReply = params.LeaveReply
+	list.UpdatedAt = time.Now().UTC()
+
+	if err = service.repository.Update(ctx, list); err != nil {
+		msg := fmt.Sprintf("cannot update contact list with id [%s]", list.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("contact list updated with id [%s] in the [%T]", list.ID, service.repository))
+	return list, nil
+}
+
+// Delete an entities.ContactList and its members
+func (service *ContactListService) Delete(ctx context.Context, userID entities.UserID, listID uuid.UUID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.Delete(ctx, userID, listID); err != nil {
+		msg := fmt.Sprintf("cannot delete contact list with ID [%s] for user [%s]", listID, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted contact list with ID [%s] for user [%s]", listID, userID))
+	return nil
+}
+
+// IndexMembers fetches the entities.ContactListMember of an entities.ContactList
+func (service *ContactListService) IndexMembers(ctx context.Context, list *entities.ContactList, params repositories.IndexParams) ([]*entities.ContactListMember, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	members, err := service.repository.IndexMembers(ctx, list.UserID, list.ID, params)
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch members of contact list [%s] with params [%+#v]", list.ID, params)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return members, nil
+}
+
+// AddMember adds a contact to an entities.ContactList
+func (service *ContactListService) AddMember(ctx context.Context, list *entities.ContactList, contact string, owner *string) (*entities.ContactListMember, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	member := &entities.ContactListMember{
+		ID:            uuid.New(),
+		ContactListID: list.ID,
+		UserID:        list.UserID,
+		Contact:       contact,
+		Owner:         owner,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	if err := service.repository.StoreMember(ctx, member); err != nil {
+		msg := fmt.Sprintf("cannot add contact [%s] to contact list [%s]", contact, list.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("contact [%s] added to contact list [%s] for user [%s]", contact, list.ID, list.UserID))
+	return member, nil
+}
+
+// DeleteMember removes an entities.ContactListMember from an entities.ContactList
+func (service *ContactListService) DeleteMember(ctx context.Context, list *entities.ContactList, memberID uuid.UUID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.DeleteMember(ctx, list.UserID, list.ID, memberID); err != nil {
+		msg := fmt.Sprintf("cannot delete member [%s] of contact list [%s]", memberID, list.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted member [%s] of contact list [%s] for user [%s]", memberID, list.ID, list.UserID))
+	return nil
+}
+
+// Contacts fetches the contacts of an entities.ContactList which are not on the suppression list
+func (service *ContactListService) Contacts(ctx context.Context, userID entities.UserID, listID uuid.UUID) ([]string, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	contacts, err := service.repository.FetchContacts(ctx, userID, listID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot fetch contacts of contact list [%s] for user [%s]", listID, userID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	suppressed, err := service.suppressionService.FilterSuppressed(ctx, userID, contacts)
+	if err != nil {
+		msg := fmt.Sprintf("cannot filter suppressed contacts of contact list [%s] for user [%s]", listID, userID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	excluded := map[string]struct{}{}
+	for _, contact := range suppressed {
+		excluded[contact] = struct{}{}
+	}
+
+	result := make([]string, 0, len(contacts))
+	for _, contact := range contacts {
+		if _, ok := excluded[contact]; !ok {
+			result = append(result, contact)
+		}
+	}
+
+	ctxLogger.Info(fmt.Sprintf("fetched [%d] contacts of contact list [%s] excluding [%d] suppressed contacts", len(result), listID, len(suppressed)))
+	return result, nil
+}
+
+// HandleMessageReceived adds or removes the contact from an entities.ContactList when an inbound message is a keyword like "JOIN ALERTS"
+func (service *ContactListService) HandleMessageReceived(ctx context.Context, source string, payload *events.MessagePhoneReceivedPayload) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if payload.Encrypted {
+		return nil
+	}
+
+	action, keyword, ok := entities.ParseContactListCommand(payload.Content)
+	if !ok {
+		return nil
+	}
+
+	list, err := service.repository.LoadByKeyword(ctx, payload.UserID, keyword)
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		ctxLogger.Info(fmt.Sprintf("no contact list with keyword [%s] for user [%s] and message [%s]", keyword, payload.UserID, payload.MessageID))
+		return nil
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load contact list with keyword [%s] for user [%s] and message [%s]", keyword, payload.UserID, payload.MessageID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if action == entities.ContactListActionLeave {
+		if err = service.repository.DeleteMemberByContact(ctx, list.UserID, list.ID, payload.Contact); err != nil {
+			msg := fmt.Sprintf("cannot remove contact [%s] from contact list [%s] for message [%s]", payload.Contact, list.ID, payload.MessageID)
+			return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+		ctxLogger.Info(fmt.Sprintf("contact [%s] left contact list [%s] with message [%s]", payload.Contact, list.ID, payload.MessageID))
+		return service.sendConfirmation(ctx, source, list.LeaveReply, fmt.Sprintf("contact-list-leave-%s", payload.MessageID), payload)
+	}
+
+	if _, err = service.AddMember(ctx, list, payload.Contact, &payload.Owner); err != nil {
+		msg := fmt.Sprintf("cannot add contact [%s] to contact list [%s] for message [%s]", payload.Contact, list.ID, payload.MessageID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return service.sendConfirmation(ctx, source, list.JoinReply, fmt.Sprintf("contact-list-join-%s", payload.MessageID), payload)
+}
+
+func (service *ContactListService) sendConfirmation(ctx context.Context, source string, reply *string, requestID string, payload *events.MessagePhoneReceivedPayload) error {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	if reply == nil {
+		return nil
+	}
+
+	_, err := service.messageService.SendSystemMessage(ctx, SystemMessageParams{
+		UserID:    payload.UserID,
+		Owner:     payload.Owner,
+		Contact:   payload.Contact,
+		Content:   *reply,
+		Source:    source,
+		RequestID: requestID,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot send confirmation reply from [%s] to [%s] for message [%s] and user [%s]", payload.Owner, payload.Contact, payload.MessageID, payload.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/palantir/stacktrace"
+)
+
+// StreamEventTypes are the events which are sent to the realtime event stream of a user
+var StreamEventTypes = []string{
+	events.EventTypeMessagePhoneReceived,
+	events.EventTypeMessagePhoneSending,
+	events.EventTypeMessagePhoneSent,
+	events.EventTypeMessagePhoneDelivered,
+	events.EventTypeMessageSendFailed,
+	events.EventTypeMessageSendExpired,
+	events.MessageCallMissed,
+	events.EventTypePhoneHeartbeatOnline,
+	events.EventTypePhoneHeartbeatOffline,
+}
+
+const (
+	// EventStreamMaxConnections is the maximum number of open event streams on an instance of the API.
+	// Every open stream blocks a redis connection so it must be less than the size of the redis connection pool.
+	EventStreamMaxConnections = 900
+
+	// eventStreamMaxConnectionsPerUser is the maximum number of open event streams of a user on an instance of the API
+	eventStreamMaxConnectionsPerUser = 5
+)
+
+// ErrCodeEventStreamUserLimit is thrown when a user has too many open event streams
+const ErrCodeEventStreamUserLimit = stacktrace.ErrorCode(1008)
+
+// ErrCodeEventStreamServerLimit is thrown when the API instance has too many open event streams
+const ErrCodeEventStreamServerLimit = stacktrace.ErrorCode(1009)
+
+// EventStreamService streams the events of a user to clients in realtime
+type EventStreamService struct {
+	service
+	logger      telemetry.Logger
+	tracer      telemetry.Tracer
+	repository  repositories.EventStreamRepository
+	mutex       sync.Mutex
+	connections map[entities.UserID]int
+	total       int
+}
+
+// NewEventStreamService creates a new EventStreamService
+func NewEventStreamService(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	repository repositories.EventStreamRepository,
+) (s *EventStreamService) {
+	return &EventStreamService{
+		logger:      logger.WithService(fmt.Sprintf("%T", s)),
+		tracer:      tracer,
+		repository:  repository,
+		connections: map[entities.UserID]int{},
+	}
+}
+
+// Connect reserves an event stream connection for a user. The returned function must be called when the stream is closed.
+func (service *EventStreamService) Connect(userID entities.UserID) (func(), error) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if service.total >= EventStreamMaxConnections {
+		msg := fmt.Sprintf("cannot open an event stream for user [%s] because there are [%d] open streams", userID, service.total)
+		return nil, stacktrace.NewErrorWithCode(ErrCodeEventStreamServerLimit, msg)
+	}
+
+	if service.connections[userID] >= eventStreamMaxConnectionsPerUser {
+		msg := fmt.Sprintf("cannot open an event stream for user [%s] because the user has [%d] open streams", userID, service.connections[userID])
+		return nil, stacktrace.NewErrorWithCode(ErrCodeEventStreamUserLimit, msg)
+	}
+
+	service.total++
+	service.connections[userID]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			service.mutex.Lock()
+			defer service.mutex.Unlock()
+
+			service.total--
+			if service.connections[userID]--; service.connections[userID] <= 0 {
+				delete(service.connections, userID)
+			}
+		})
+	}, nil
+}
+
+// Publish adds an event to the stream of a user
+func (service *EventStreamService) Publish(ctx context.Context, userID entities.UserID, event cloudevents.Event) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.Append(ctx, userID, event); err != nil {
+		msg := fmt.Sprintf("cannot publish [%s] event with ID [%s] to the stream of user [%s]", event.Type(), event.ID(), userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("published [%s] event with ID [%s] to the stream of user [%s]", event.Type(), event.ID(), userID))
+	return nil
+}
+
+// Cursor returns the ID after which events are read. New clients start after the newest event in the stream.
+func (service *EventStreamService) Cursor(ctx context.Context, userID entities.UserID, lastEventID string) (string, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	if lastEventID != "" {
+		return lastEventID, nil
+	}
+
+	cursor, err := service.repository.LastID(ctx, userID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot get the cursor of the event stream for user [%s]", userID)
+		return "", service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return cursor, nil
+}
+
+// EventStreamReadParams are parameters for reading the event stream of a user
+type EventStreamReadParams struct {
+	UserID     entities.UserID
+	Cursor     string
+	EventTypes []string
+	Timeout    time.Duration
+}
+
+// Read waits for the events which were published after the cursor. It returns the events matching the event
+// types and the cursor for the next read.
+func (service *EventStreamService) Read(ctx context.Context, params *EventStreamReadParams) ([]*entities.StreamEvent, string, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	streamEvents, err := service.repository.Read(ctx, params.UserID, params.Cursor, params.Timeout)
+	if err != nil {
+		msg := fmt.Sprintf("cannot read the event stream of user [%s] after [%s]", params.UserID, params.Cursor)
+		return nil, params.Cursor, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if len(streamEvents) == 0 {
+		return streamEvents, params.Cursor, nil
+	}
+
+	cursor := streamEvents[len(streamEvents)-1].ID
+	if len(params.EventTypes) == 0 {
+		return streamEvents, cursor, nil
+	}
+
+	result := make([]*entities.StreamEvent, 0, len(streamEvents))
+	for _, event := range streamEvents {
+		if slices.Contains(params.EventTypes, event.Type) {
+			result = append(result, event)
+		}
+	}
+
+	return result, cursor, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/cache"
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/palantir/stacktrace"
+)
+
+// ErrCodeIdempotencyKeyConflict is thrown when an idempotency key is reused with a different payload
+const ErrCodeIdempotencyKeyConflict = stacktrace.ErrorCode(1002)
+
+// ErrCodeIdempotencyKeyInProgress is thrown when an idempotency key is reused while the original request is still being processed
+const ErrCodeIdempotencyKeyInProgress = stacktrace.ErrorCode(1003)
+
+// idempotencyKeyRetention is the duration for which the response of a request with an idempotency key is stored
+const idempotencyKeyRetention = 24 * time.Hour
+
+// idempotencyKeyLockTimeout is the duration for which an idempotency key is locked while the original request is processed
+const idempotencyKeyLockTimeout = 2 * time.Minute
+
+// IdempotencyService makes sure that requests with the same Idempotency-Key header are processed only once
+type IdempotencyService struct {
+	service
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+	cache  cache.Cache
+}
+
+// NewIdempotencyService creates a new IdempotencyService
+func NewIdempotencyService(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	cache cache.Cache,
+) (s *IdempotencyService) {
+	return &IdempotencyService{
+		logger: logger.WithService(fmt.Sprintf("%T", s)),
+		tracer: tracer,
+		cache:  cache,
+	}
+}
+
+// IdempotentResponse is the response which is returned again when a request is repeated with the same idempotency key
+type IdempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	StatusCode  int    `json:"status_code"`
+	Body        []byte `json:"body"`
+}
+
+// IdempotencyParams are the parameters of a request with an idempotency key
+type IdempotencyParams struct {
+	UserID  entities.UserID
+	Key     string
+	Route   string
+	Payload []byte
+}
+
+// Begin locks the idempotency key for a new request. It returns the stored response when the request has already been processed.
+// The lock is taken atomically so that only one of several concurrent requests with the same key is processed.
+func (service *IdempotencyService) Begin(ctx context.Context, params *IdempotencyParams) (*IdempotentResponse, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	fingerprint := service.fingerprint(params)
+	lock, err := json.Marshal(&IdempotentResponse{Fingerprint: fingerprint})
+	if err != nil {
+		msg := fmt.Sprintf("cannot marshal lock for idempotency key [%s]", params.Key)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	locked, err := service.cache.Add(ctx, service.cacheKey(params), string(lock), idempotencyKeyLockTimeout)
+	if err != nil {
+		msg := fmt.Sprintf("cannot lock idempotency key [%s] for user [%s]", params.Key, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if locked {
+		return nil, nil
+	}
+
+	value, err := service.cache.Get(ctx, service.cacheKey(params))
+	if err != nil {
+		// the key expired or was released after the lock failed so the client can retry the request
+		msg := fmt.Sprintf("the request with idempotency key [%s] is still being processed", params.Key)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, ErrCodeIdempotencyKeyInProgress, msg))
+	}
+
+	response := new(IdempotentResponse)
+	if err = json.Unmarshal([]byte(value), response); err != nil {
+		msg := fmt.Sprintf("cannot unmarshal [%s] into [%T] for idempotency key [%s]", value, response, params.Key)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if response.Fingerprint != fingerprint {
+		msg := fmt.Sprintf("the idempotency key [%s] was used with a different payload for the route [%s]", params.Key, params.Route)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.NewErrorWithCode(ErrCodeIdempotencyKeyConflict, msg))
+	}
+
+	if !response.Completed {
+		msg := fmt.Sprintf("the request with idempotency key [%s] is still being processed", params.Key)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.NewErrorWithCode(ErrCodeIdempotencyKeyInProgress, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("replaying response with status [%d] for idempotency key [%s] and user [%s]", response.StatusCode, params.Key, params.UserID))
+	return response, nil
+}
+
+// Complete stores the response of a request so that it is returned when the request is repeated with the same idempotency key.
+func (service *IdempotencyService) Complete(ctx context.Context, params *IdempotencyParams, statusCode int, body []byte) error {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	response := &IdempotentResponse{
+		Fingerprint: service.fingerprint(params),
+		Completed:   true,
+		StatusCode:  statusCode,
+		Body:        body,
+	}
+
+	if err := service.store(ctx, params, response, idempotencyKeyRetention); err != nil {
+		msg := fmt.Sprintf("cannot store response for idempotency key [%s] and user [%s]", params.Key, params.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+// Release removes the lock of an idempotency key when the request failed so that it can be retried immediately.
+func (service *IdempotencyService) Release(ctx context.Context, params *IdempotencyParams) error {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	if err := service.cache.Delete(ctx, service.cacheKey(params)); err != nil {
+		msg := fmt.Sprintf("cannot release idempotency key [%s] for user [%s]", params.Key, params.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (service *IdempotencyService) store(ctx context.Context, params *IdempotencyParams, response *IdempotentResponse, ttl time.Duration) error {
+	value, err := json.Marshal(response)
+	if err != nil {
+		return stacktrace.Propagate(err, fmt.Sprintf("cannot marshal [%T] for idempotency key [%s]", response, params.Key))
+	}
+	return service.cache.Set(ctx, service.cacheKey(params), string(value), ttl)
+}
+
+func (service *IdempotencyService) cacheKey(params *IdempotencyParams) string {
+	return fmt.Sprintf("idempotency.%s.%s", params.UserID, params.Key)
+}
+
+func (service *IdempotencyService) fingerprint(params *IdempotencyParams) string {
+	hash := sha256.New()
+	hash.Write([]byte(params.Route))
+	hash.Write(params.Payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/cache"
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/hirosassa/zerodriver"
+	"github.com/palantir/stacktrace"
+	ttlCache "github.com/patrickmn/go-cache"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	t.Run("a new idempotency key is locked", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		service := testIdempotencyService()
+		params := testIdempotencyParams("user-1", `{"content":"hello"}`)
+
+		// Act
+		response, err := service.Begin(context.Background(), params)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Nil(t, response)
+	})
+
+	t.Run("a key which is being processed is in progress", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		service := testIdempotencyService()
+		params := testIdempotencyParams("user-1", `{"content":"hello"}`)
+		_, _ = service.Begin(context.Background(), params)
+
+		// Act
+		response, err := service.Begin(context.Background(), params)
+
+		// Assert
+		assert.Nil(t, response)
+		assert.Equal(t, ErrCodeIdempotencyKeyInProgress, stacktrace.GetCode(err))
+	})
+
+	t.Run("a key which is reused with a different payload is a conflict", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		service := testIdempotencyService()
+		_, _ = service.Begin(context.Background(), testIdempotencyParams("user-1", `{"content":"hello"}`))
+
+		// Act
+		response, err := service.Begin(context.Background(), testIdempotencyParams("user-1", `{"content":"bye"}`))
+
+		// Assert
+		assert.Nil(t, response)
+		assert.Equal(t, ErrCodeIdempotencyKeyConflict, stacktrace.GetCode(err))
+	})
+
+	t.Run("a key which is reused on a different route is a conflict", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		service := testIdempotencyService()
+		params := testIdempotencyParams("user-1", `{"content":"hello"}`)
+		_, _ = service.Begin(context.Background(), params)
+
+		other := testIdempotencyParams("user-1", `{"content":"hello"}`)
+		other.Route = "POST /v1/messages/bulk-send"
+
+		// Act
+		_, err := service.Begin(context.Background(), other)
+
+		// Assert
+		assert.Equal(t, ErrCodeIdempotencyKeyConflict, stacktrace.GetCode(err))
+	})
+
+	t.Run("the same key is independent for each user", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		service := testIdempotencyService()
+		_, _ = service.Begin(context.Background(), testIdempotencyParams("user-1", `{"content":"hello"}`))
+
+		// Act
+		response, err := service.Begin(context.Background(), testIdempotencyParams("user-2", `{"content":"bye"}`))
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Nil(t, response)
+	})
+
+	t.Run("only one of several concurrent requests is processed", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		service := testIdempotencyService()
+		params := testIdempotencyParams("user-1", `{"content":"hello"}`)
+
+		var locked, inProgress atomic.Int32
+		var wg sync.WaitGroup
+
+		// Act
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := service.Begin(context.Background(), params)
+				if err == nil {
+					locked.Add(1)
+				}
+				if stacktrace.GetCode(err) == ErrCodeIdempotencyKeyInProgress {
+					inProgress.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		// Assert
+		assert.Equal(t, int32(1), locked.Load())
+		assert.Equal(t, int32(9), inProgress.Load())
+	})
+}
+
+func TestIdempotencyService_Complete(t *testing.T) {
+	t.Run("the stored response is replayed", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		service := testIdempotencyService()
+		params := testIdempotencyParams("user-1", `{"content":"hello"}`)
+		_, _ = service.Begin(context.Background(), params)
+
+		// Act
+		err := service.Complete(context.Background(), params, 200, []byte(`{"status":"success"}`))
+		response, beginErr := service.Begin(context.Background(), params)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Nil(t, beginErr)
+		assert.NotNil(t, response)
+		assert.True(t, response.Completed)
+		assert.Equal(t, 200, response.StatusCode)
+		assert.Equal(t, []byte(`{"status":"success"}`), response.Body)
+	})
+
+	t.Run("a completed key is a conflict with a different payload", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		service := testIdempotencyService()
+		params := testIdempotencyParams("user-1", `{"content":"hello"}`)
+		_, _ = service.Begin(context.Background(), params)
+		_ = service.Complete(context.Background(), params, 200, []byte(`{"status":"success"}`))
+
+		// Act
+		response, err := service.Begin(context.Background(), testIdempotencyParams("user-1", `{"content":"bye"}`))
+
+		// Assert
+		assert.Nil(t, response)
+		assert.Equal(t, ErrCodeIdempotencyKeyConflict, stacktrace.GetCode(err))
+	})
+}
+
+func TestIdempotencyService_Release(t *testing.T) {
+	t.Run("a released key can be locked again", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		service := testIdempotencyService()
+		params := testIdempotencyParams("user-1", `{"content":"hello"}`)
+		_, _ = service.Begin(context.Background(), params)
+
+		// Act
+		err := service.Release(context.Background(), params)
+		response, beginErr := service.Begin(context.Background(), params)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Nil(t, beginErr)
+		assert.Nil(t, response)
+	})
+}
+
+func testIdempotencyService() *IdempotencyService {
+	zl := zerolog.Nop()
+	logger := telemetry.NewZerologLogger("test", map[string]string{}, &zerodriver.Logger{Logger: &zl}, nil)
+	tracer := telemetry.NewOtelLogger("test", logger)
+	return NewIdempotencyService(logger, tracer, cache.NewMemoryCache(tracer, ttlCache.New(time.Minute, time.Minute)))
+}
+
+func testIdempotencyParams(userID string, payload string) *IdempotencyParams {
+	return &IdempotencyParams{
+		UserID:  entities.UserID(userID),
+		Key:     "8f2b7c1e-5a4d-4b6e-9c3f-1d2e3f4a5b6c",
+		Route:   "POST /v1/messages/send",
+		Payload: []byte(payload),
+	}
+}
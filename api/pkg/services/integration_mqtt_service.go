@@ -0,0 +1,473 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/cache"
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/mqtt"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// mqttTimeout is the maximum time for connecting to a broker or publishing a message
+	mqttTimeout = 10 * time.Second
+
+	// mqttCommandTimeout is the maximum time for processing a message received on a command topic
+	mqttCommandTimeout = 30 * time.Second
+
+	// mqttCommandDeduplicationTTL is how long a command is remembered when every API instance receives the messages on the
+	// command topic because the integration does not use a shared subscription
+	mqttCommandDeduplicationTTL = time.Minute
+)
+
+// MQTTTopicPlaceholders are the values which can be used in the topic template of an entities.IntegrationMQTT
+var MQTTTopicPlaceholders = []string{"{user_id}", "{owner}", "{contact}", "{event_type}"}
+
+// IntegrationMQTTCommandHandler sends the message in the payload received on the command topic of an entities.IntegrationMQTT
+type IntegrationMQTTCommandHandler func(ctx context.Context, integration *entities.IntegrationMQTT, payload []byte) error
+
+type mqttConnection struct {
+	client    mqtt.Client
+	updatedAt time.Time
+}
+
+// IntegrationMQTTService publishes events to the MQTT brokers of users
+type IntegrationMQTTService struct {
+	service
+	logger      telemetry.Logger
+	tracer      telemetry.Tracer
+	factory     mqtt.Factory
+	repository  repositories.IntegrationMQTTRepository
+	cache       cache.Cache
+	mutex       sync.Mutex
+	connections map[uuid.UUID]*mqttConnection
+	onCommand   IntegrationMQTTCommandHandler
+}
+
+// NewIntegrationMQTTService creates a new IntegrationMQTTService
+func NewIntegrationMQTTService(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	factory mqtt.Factory,
+	repository repositories.IntegrationMQTTRepository,
+	cache cache.Cache,
+) (s *IntegrationMQTTService) {
+	return &IntegrationMQTTService{
+		logger:      logger.WithService(fmt.Sprintf("%T", s)),
+		tracer:      tracer,
+		factory:     factory,
+		repository:  repository,
+		cache:       cache,
+		connections: map[uuid.UUID]*mqttConnection{},
+	}
+}
+
+// Index fetches the entities.IntegrationMQTT for an entities.UserID
+func (service *IntegrationMQTTService) Index(ctx context.Context, userID entities.UserID, params repositories.IndexParams) ([]*entities.IntegrationMQTT, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	integrations, err := service.repository.Index(ctx, userID, params)
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch mqtt integrations with params [%+#v]", params)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("fetched [%d] mqtt integrations with prams [%+#v]", len(integrations), params))
+	return integrations, nil
+}
+
+// IntegrationMQTTStoreParams are parameters for creating a new entities.IntegrationMQTT
+type IntegrationMQTTStoreParams struct {
+	UserID             entities.UserID
+	Name               string
+	Host               string
+	Port               uint
+	UseTLS             bool
+	Username           *string
+	Password           *string
+	TopicTemplate      string
+	QoS                uint8
+	Events             []string
+	CommandTopic       *string
+	SharedSubscription bool
+}
+
+// Store a new entities.IntegrationMQTT
+func (service *IntegrationMQTTService) Store(ctx context.Context, params *IntegrationMQTTStoreParams) (*entities.IntegrationMQTT, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	integration := &entities.IntegrationMQTT{
+		ID:                 uuid.New(),
+		UserID:             params.UserID,
+		Name:               params.Name,
+		Host:               params.Host,
+		Port:               params.Port,
+		UseTLS:             params.UseTLS,
+		Username:           params.Username,
+		Password:           params.Password,
+		TopicTemplate:      params.TopicTemplate,
+		QoS:                params.QoS,
+		Events:             params.Events,
+		CommandTopic:       params.CommandTopic,
+		SharedSubscription: params.SharedSubscription,
+		CreatedAt:          time.Now().UTC(),
+		UpdatedAt:          time.Now().UTC(),
+	}
+
+	if err := service.repository.Save(ctx, integration); err != nil {
+		msg := fmt.Sprintf("cannot save mqtt integration with id [%s]", integration.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("mqtt integration saved with id [%s] in the [%T]", integration.ID, service.repository))
+	go service.subscribe(context.WithoutCancel(ctx), integration)
+	return integration, nil
+}
+
+// IntegrationMQTTUpdateParams are parameters for updating an entities.IntegrationMQTT
+type IntegrationMQTTUpdateParams struct {
+	IntegrationMQTTStoreParams
+	IntegrationID uuid.UUID
+}
+
+// Update an entities.IntegrationMQTT. The password is not changed when params.Password is nil.
+func (service *IntegrationMQTTService) Update(ctx context.Context, params *IntegrationMQTTUpdateParams) (*entities.IntegrationMQTT, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	integration, err := service.repository.Load(ctx, params.UserID, params.IntegrationID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load mqtt integration with userID [%s] and integrationID [%s]", params.UserID, params.IntegrationID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	integration.Name = params.Name
+	integration.Host = params.Host
+	integration.Port = params.Port
+	integration.UseTLS = params.UseTLS
+	integration.Username = params.Username
+	integration.TopicTemplate = params.TopicTemplate
+	integration.QoS = params.QoS
+	integration.Events = params.Events
+	integration.CommandTopic = params.CommandTopic
+	integration.SharedSubscription = params.SharedSubscription
+	integration.UpdatedAt = time.Now().UTC()
+	if params.Password != nil {
+		integration.Password = params.Password
+	}
+
+	if err = service.repository.Save(ctx, integration); err != nil {
+		msg := fmt.Sprintf("cannot save mqtt integration with id [%s] after update", integration.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("mqtt integration updated with id [%s] in the [%T]", integration.ID, service.repository))
+	service.disconnect(integration.ID)
+	go service.subscribe(context.WithoutCancel(ctx), integration)
+	return integration, nil
+}
+
+// Delete an entities.IntegrationMQTT
+func (service *IntegrationMQTTService) Delete(ctx context.Context, userID entities.UserID, integrationID uuid.UUID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if _, err := service.repository.Load(ctx, userID, integrationID); err != nil {
+		msg := fmt.Sprintf("cannot load mqtt integration with userID [%s] and integrationID [%s]", userID, integrationID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	if err := service.repository.Delete(ctx, userID, integrationID); err != nil {
+		msg := fmt.Sprintf("cannot delete mqtt integration with id [%s] and user id [%s]", integrationID, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	service.disconnect(integrationID)
+	ctxLogger.Info(fmt.Sprintf("deleted mqtt integration with id [%s] and user id [%s]", integrationID, userID))
+	return nil
+}
+
+// DeleteAllForUser deletes all entities.IntegrationMQTT for an entities.UserID.
+func (service *IntegrationMQTTService) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	integrations, err := service.repository.Index(ctx, userID, repositories.IndexParams{Skip: 0, Limit: 100})
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch [entities.IntegrationMQTT] for user with ID [%s]", userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err = service.repository.DeleteAllForUser(ctx, userID); err != nil {
+		msg := fmt.Sprintf("could not delete all [entities.IntegrationMQTT] for user with ID [%s]", userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	for _, integration := range integrations {
+		service.disconnect(integration.ID)
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted all [entities.IntegrationMQTT] for user with ID [%s]", userID))
+	return nil
+}
+
+// Subscribe connects to the brokers of all entities.IntegrationMQTT having a command topic and calls the handler
+// with the messages received on the command topic.
+func (service *IntegrationMQTTService) Subscribe(ctx context.Context, handler IntegrationMQTTCommandHandler) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	service.mutex.Lock()
+	service.onCommand = handler
+	service.mutex.Unlock()
+
+	integrations, err := service.repository.FetchHavingCommandTopic(ctx)
+	if err != nil {
+		msg := "cannot fetch mqtt integrations having a command topic"
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	for _, integration := range integrations {
+		service.subscribe(ctx, integration)
+	}
+
+	ctxLogger.Info(fmt.Sprintf("subscribed to the command topics of [%d] mqtt integrations", len(integrations)))
+	return nil
+}
+
+// Send publishes an event to the MQTT brokers of a user which are subscribed to the event
+func (service *IntegrationMQTTService) Send(ctx context.Context, userID entities.UserID, event cloudevents.Event) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	integrations, err := service.repository.LoadByEvent(ctx, userID, event.Type())
+	if err != nil {
+		msg := fmt.Sprintf("cannot load mqtt integrations for user [%s] and event [%s]", userID, event.Type())
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if len(integrations) == 0 {
+		ctxLogger.Info(fmt.Sprintf("user [%s] has no mqtt integration for event [%s]", userID, event.Type()))
+		return nil
+	}
+
+	for _, integration := range integrations {
+		service.publish(ctx, event, integration)
+	}
+
+	return nil
+}
+
+// Topic replaces the placeholders in the topic template of an entities.IntegrationMQTT with the values of an event
+func (service *IntegrationMQTTService) Topic(template string, event cloudevents.Event) string {
+	payload := struct {
+		UserID  string `json:"user_id"`
+		Owner   string `json:"owner"`
+		Contact string `json:"contact"`
+	}{}
+	_ = event.DataAs(&payload)
+
+	return strings.NewReplacer(
+		"{user_id}", service.topicLevel(payload.UserID),
+		"{owner}", service.topicLevel(payload.Owner),
+		"{contact}", service.topicLevel(payload.Contact),
+		"{event_type}", service.topicLevel(event.Type()),
+	).Replace(template)
+}
+
+// topicLevel removes the characters which are not allowed in a topic level e.g. the "+" of a phone number
+func (service *IntegrationMQTTService) topicLevel(value string) string {
+	value = strings.NewReplacer("+", "", "#", "", "/", "").Replace(value)
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+func (service *IntegrationMQTTService) publish(ctx context.Context, event cloudevents.Event, integration *entities.IntegrationMQTT) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, mqttTimeout)
+	defer cancel()
+
+	payload, err := event.MarshalJSON()
+	if err != nil {
+		msg := fmt.Sprintf("cannot marshal [%s] event with ID [%s] for mqtt integration [%s]", event.Type(), event.ID(), integration.ID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return
+	}
+
+	client, err := service.connection(ctx, integration)
+	if err != nil {
+		msg := fmt.Sprintf("cannot connect to mqtt broker [%s] for integration [%s] and user [%s]", integration.Host, integration.ID, integration.UserID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return
+	}
+
+	topic := service.Topic(integration.TopicTemplate, event)
+	if err = client.Publish(ctx, topic, integration.QoS, payload); err != nil {
+		msg := fmt.Sprintf("cannot publish [%s] event with ID [%s] to topic [%s] for mqtt integration [%s]", event.Type(), event.ID(), topic, integration.ID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		service.disconnect(integration.ID)
+		return
+	}
+
+	ctxLogger.Info(fmt.Sprintf("published [%s] event with ID [%s] to topic [%s] for mqtt integration [%s]", event.Type(), event.ID(), topic, integration.ID))
+}
+
+// subscribe connects to the broker of an entities.IntegrationMQTT so that messages on the command topic are received
+func (service *IntegrationMQTTService) subscribe(ctx context.Context, integration *entities.IntegrationMQTT) {
+	if !integration.HasCommandTopic() {
+		return
+	}
+
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, mqttTimeout)
+	defer cancel()
+
+	if _, err := service.connection(ctx, integration); err != nil {
+		msg := fmt.Sprintf("cannot subscribe to command topic [%s] of mqtt integration [%s]", *integration.CommandTopic, integration.ID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+	}
+}
+
+// connection returns a client which is connected to the broker of an entities.IntegrationMQTT. Connections are reused
+// until the integration is updated.
+func (service *IntegrationMQTTService) connection(ctx context.Context, integration *entities.IntegrationMQTT) (mqtt.Client, error) {
+	service.mutex.Lock()
+	existing, ok := service.connections[integration.ID]
+	onCommand := service.onCommand
+	service.mutex.Unlock()
+
+	if ok && existing.updatedAt.Equal(integration.UpdatedAt) {
+		return existing.client, nil
+	}
+
+	client := service.factory(mqtt.Options{
+		Host:     integration.Host,
+		Port:     integration.Port,
+		UseTLS:   integration.UseTLS,
+		ClientID: "httpsms-" + strings.ReplaceAll(uuid.NewString(), "-", "")[:15],
+		Username: integration.Username,
+		Password: integration.Password,
+	})
+
+	if err := client.Connect(ctx); err != nil {
+		return nil, stacktrace.Propagate(err, fmt.Sprintf("cannot connect to broker [%s:%d]", integration.Host, integration.Port))
+	}
+
+	if integration.HasCommandTopic() && onCommand != nil {
+		topic := integration.SubscriptionTopic()
+		if err := client.Subscribe(ctx, topic, integration.QoS, service.commandHandler(integration, *integration.CommandTopic, onCommand)); err != nil {
+			client.Disconnect()
+			return nil, stacktrace.Propagate(err, fmt.Sprintf("cannot subscribe to topic [%s] on broker [%s:%d]", topic, integration.Host, integration.Port))
+		}
+	}
+
+	service.mutex.Lock()
+	if previous, ok := service.connections[integration.ID]; ok {
+		previous.client.Disconnect()
+	}
+	service.connections[integration.ID] = &mqttConnection{client: client, updatedAt: integration.UpdatedAt}
+	service.mutex.Unlock()
+
+	return client, nil
+}
+
+// commandHandler processes the messages received on the command topic of an entities.IntegrationMQTT. The integration is
+// loaded again for every message because it could have been updated or deleted on another API instance.
+func (service *IntegrationMQTTService) commandHandler(subscribed *entities.IntegrationMQTT, commandTopic string, onCommand IntegrationMQTTCommandHandler) mqtt.MessageHandler {
+	return func(topic string, payload []byte) {
+		ctx, cancel := context.WithTimeout(context.Background(), mqttCommandTimeout)
+		defer cancel()
+
+		ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+		defer span.End()
+
+		integration, err := service.repository.Load(ctx, subscribed.UserID, subscribed.ID)
+		if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+			ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("mqtt integration [%s] was deleted so the message on topic [%s] is ignored", subscribed.ID, topic)))
+			go service.disconnectStale(subscribed)
+			return
+		}
+
+		if err != nil {
+			msg := fmt.Sprintf("cannot load mqtt integration [%s] for message on topic [%s]", subscribed.ID, topic)
+			ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+			return
+		}
+
+		if !integration.UpdatedAt.Equal(subscribed.UpdatedAt) {
+			go service.resubscribe(subscribed, integration)
+		}
+
+		if !integration.HasCommandTopic() || *integration.CommandTopic != commandTopic {
+			ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("mqtt integration [%s] is no longer subscribed to the topic [%s] so the message is ignored", integration.ID, topic)))
+			return
+		}
+
+		if !integration.SharedSubscription {
+			// every API instance is subscribed to the plain topic so the command is processed by the first instance which adds it to the cache
+			added, err := service.cache.Add(ctx, fmt.Sprintf("mqtt-command:%s:%x", integration.ID, sha256.Sum256(payload)), topic, mqttCommandDeduplicationTTL)
+			if err != nil {
+				msg := fmt.Sprintf("cannot deduplicate message on topic [%s] for mqtt integration [%s]", topic, integration.ID)
+				ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+				return
+			}
+			if !added {
+				ctxLogger.Info(fmt.Sprintf("message on topic [%s] for mqtt integration [%s] has already been processed", topic, integration.ID))
+				return
+			}
+		}
+
+		if err = onCommand(ctx, integration, payload); err != nil {
+			msg := fmt.Sprintf("cannot process message [%s] on topic [%s] for mqtt integration [%s]", payload, topic, integration.ID)
+			ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+			return
+		}
+
+		ctxLogger.Info(fmt.Sprintf("processed message on topic [%s] for mqtt integration [%s]", topic, integration.ID))
+	}
+}
+
+// resubscribe replaces the connection of an outdated entities.IntegrationMQTT with a connection using the latest settings
+func (service *IntegrationMQTTService) resubscribe(outdated *entities.IntegrationMQTT, integration *entities.IntegrationMQTT) {
+	service.disconnectStale(outdated)
+	service.subscribe(context.Background(), integration)
+}
+
+// disconnectStale closes the connection of an entities.IntegrationMQTT only if it was opened with the outdated settings
+func (service *IntegrationMQTTService) disconnectStale(outdated *entities.IntegrationMQTT) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if connection, ok := service.connections[outdated.ID]; ok && connection.updatedAt.Equal(outdated.UpdatedAt) {
+		connection.client.Disconnect()
+		delete(service.connections, outdated.ID)
+	}
+}
+
+func (service *IntegrationMQTTService) disconnect(integrationID uuid.UUID) {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if connection, ok := service.connections[integrationID]; ok {
+		connection.client.Disconnect()
+		delete(service.connections, integrationID)
+	}
+}
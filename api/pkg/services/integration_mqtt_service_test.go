@@ -0,0 +1,354 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/cache"
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/mqtt"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
+	"github.com/hirosassa/zerodriver"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/palantir/stacktrace"
+	ttlCache "github.com/patrickmn/go-cache"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationMQTTService_Send(t *testing.T) {
+	t.Run("event is published to the topic of the integration", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		broker := newTestMQTTBroker(t)
+		received := broker.subscribe(t, "httpsms/#")
+		integration := testMQTTIntegration(broker, nil, false)
+		service := testIntegrationMQTTService(t, newFakeIntegrationMQTTRepository(integration), testMQTTCache())
+		event := testMQTTEvent(t)
+
+		// Act
+		err := service.Send(context.Background(), integration.UserID, event)
+
+		// Assert
+		assert.Nil(t, err)
+		select {
+		case packet := <-received:
+			assert.Equal(t, "httpsms/18005550199/message.phone.received", packet.TopicName)
+			assert.Equal(t, byte(1), packet.FixedHeader.Qos)
+
+			published := cloudevents.NewEvent()
+			assert.Nil(t, published.UnmarshalJSON(packet.Payload))
+			assert.Equal(t, event.ID(), published.ID())
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "the event was not published to the broker")
+		}
+	})
+
+	t.Run("event is not published when the user has no integration", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		broker := newTestMQTTBroker(t)
+		received := broker.subscribe(t, "#")
+		service := testIntegrationMQTTService(t, newFakeIntegrationMQTTRepository(), testMQTTCache())
+
+		// Act
+		err := service.Send(context.Background(), "user-id", testMQTTEvent(t))
+
+		// Assert
+		assert.Nil(t, err)
+		select {
+		case packet := <-received:
+			assert.Fail(t, "unexpected message on topic "+packet.TopicName)
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+}
+
+func TestIntegrationMQTTService_Subscribe(t *testing.T) {
+	t.Run("message on the command topic is passed to the handler", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		commandTopic := "httpsms/commands/send"
+		broker := newTestMQTTBroker(t)
+		integration := testMQTTIntegration(broker, &commandTopic, false)
+		service := testIntegrationMQTTService(t, newFakeIntegrationMQTTRepository(integration), testMQTTCache())
+
+		received := make(chan []byte, 1)
+		var receivedIntegration *entities.IntegrationMQTT
+		handler := func(ctx context.Context, integration *entities.IntegrationMQTT, payload []byte) error {
+			receivedIntegration = integration
+			received <- payload
+			return nil
+		}
+
+		// Act
+		err := service.Subscribe(context.Background(), handler)
+		broker.publish(t, commandTopic, `{"content":"hello"}`)
+
+		// Assert
+		assert.Nil(t, err)
+		select {
+		case payload := <-received:
+			assert.Equal(t, `{"content":"hello"}`, string(payload))
+			assert.Equal(t, integration.ID, receivedIntegration.ID)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "the message on the command topic was not received")
+		}
+	})
+
+	t.Run("message on the command topic is processed once by multiple instances", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		for _, shared := range []bool{false, true} {
+			// Arrange
+			commandTopic := "httpsms/commands/" + strconv.FormatBool(shared)
+			broker := newTestMQTTBroker(t)
+			integration := testMQTTIntegration(broker, &commandTopic, shared)
+			repository := newFakeIntegrationMQTTRepository(integration)
+			store := testMQTTCache()
+
+			var calls atomic.Int32
+			handler := func(ctx context.Context, integration *entities.IntegrationMQTT, payload []byte) error {
+				calls.Add(1)
+				return nil
+			}
+
+			// Act
+			for i := 0; i < 3; i++ {
+				assert.Nil(t, testIntegrationMQTTService(t, repository, store).Subscribe(context.Background(), handler))
+			}
+			broker.publish(t, commandTopic, `{"content":"hello"}`)
+
+			// Assert
+			assert.Eventually(t, func() bool { return calls.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+			time.Sleep(200 * time.Millisecond)
+			assert.Equal(t, int32(1), calls.Load(), "shared subscription [%t]", shared)
+		}
+	})
+
+	t.Run("message on the command topic is ignored when the integration is deleted", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		commandTopic := "httpsms/commands/send"
+		broker := newTestMQTTBroker(t)
+		integration := testMQTTIntegration(broker, &commandTopic, false)
+		repository := newFakeIntegrationMQTTRepository(integration)
+		service := testIntegrationMQTTService(t, repository, testMQTTCache())
+
+		var calls atomic.Int32
+		handler := func(ctx context.Context, integration *entities.IntegrationMQTT, payload []byte) error {
+			calls.Add(1)
+			return nil
+		}
+
+		// Act
+		err := service.Subscribe(context.Background(), handler)
+		_ = repository.Delete(context.Background(), integration.UserID, integration.ID)
+		broker.publish(t, commandTopic, `{"content":"hello"}`)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Eventually(t, func() bool { return !service.isConnected(integration.ID) }, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+}
+
+func testIntegrationMQTTService(t *testing.T, repository repositories.IntegrationMQTTRepository, store cache.Cache) *IntegrationMQTTService {
+	zl := zerolog.Nop()
+	logger := telemetry.NewZerologLogger("test", map[string]string{}, &zerodriver.Logger{Logger: &zl}, nil)
+	service := NewIntegrationMQTTService(logger, telemetry.NewOtelLogger("test", logger), mqtt.NewClient, repository, store)
+
+	t.Cleanup(func() {
+		service.mutex.Lock()
+		defer service.mutex.Unlock()
+		for _, connection := range service.connections {
+			connection.client.Disconnect()
+		}
+	})
+
+	return service
+}
+
+func (service *IntegrationMQTTService) isConnected(integrationID uuid.UUID) bool {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	_, ok := service.connections[integrationID]
+	return ok
+}
+
+func testMQTTCache() cache.Cache {
+	zl := zerolog.Nop()
+	logger := telemetry.NewZerologLogger("test", map[string]string{}, &zerodriver.Logger{Logger: &zl}, nil)
+	return cache.NewMemoryCache(telemetry.NewOtelLogger("test", logger), ttlCache.New(time.Minute, time.Minute))
+}
+
+func testMQTTIntegration(broker *testMQTTBroker, commandTopic *string, shared bool) *entities.IntegrationMQTT {
+	return &entities.IntegrationMQTT{
+		ID:                 uuid.New(),
+		UserID:             "user-id",
+		Name:               "Home Assistant",
+		Host:               broker.host,
+		Port:               broker.port,
+		TopicTemplate:      "httpsms/{owner}/{event_type}",
+		QoS:                1,
+		Events:             []string{events.EventTypeMessagePhoneReceived},
+		CommandTopic:       commandTopic,
+		SharedSubscription: shared,
+		UpdatedAt:          time.Now().UTC(),
+	}
+}
+
+func testMQTTEvent(t *testing.T) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID(uuid.NewString())
+	event.SetSource("/v1/messages/receive")
+	event.SetType(events.EventTypeMessagePhoneReceived)
+	assert.Nil(t, event.SetData(cloudevents.ApplicationJSON, map[string]string{
+		"user_id": "user-id",
+		"owner":   "+18005550199",
+		"contact": "+18005550100",
+	}))
+	return event
+}
+
+// testMQTTBroker is an embedded MQTT broker listening on a random local port
+type testMQTTBroker struct {
+	server *mochi.Server
+	host   string
+	port   uint
+}
+
+func newTestMQTTBroker(t *testing.T) *testMQTTBroker {
+	server := mochi.New(&mochi.Options{InlineClient: true, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	assert.Nil(t, server.AddHook(new(auth.AllowHook), nil))
+
+	listener := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	assert.Nil(t, server.AddListener(listener))
+	assert.Nil(t, server.Serve())
+	t.Cleanup(func() { _ = server.Close() })
+
+	host, port, err := net.SplitHostPort(listener.Address())
+	assert.Nil(t, err)
+	value, err := strconv.ParseUint(port, 10, 16)
+	assert.Nil(t, err)
+
+	return &testMQTTBroker{server: server, host: host, port: uint(value)}
+}
+
+// subscribe returns the messages published by the clients of the broker on the topic filter
+func (broker *testMQTTBroker) subscribe(t *testing.T, filter string) <-chan packets.Packet {
+	received := make(chan packets.Packet, 10)
+	assert.Nil(t, broker.server.Subscribe(filter, 1, func(_ *mochi.Client, _ packets.Subscription, packet packets.Packet) {
+		received <- packet
+	}))
+	return received
+}
+
+func (broker *testMQTTBroker) publish(t *testing.T, topic string, payload string) {
+	assert.Nil(t, broker.server.Publish(topic, []byte(payload), false, 1))
+}
+
+// fakeIntegrationMQTTRepository stores entities.IntegrationMQTT in memory
+type fakeIntegrationMQTTRepository struct {
+	mutex        sync.Mutex
+	integrations map[uuid.UUID]*entities.IntegrationMQTT
+}
+
+func newFakeIntegrationMQTTRepository(integrations ...*entities.IntegrationMQTT) *fakeIntegrationMQTTRepository {
+	repository := &fakeIntegrationMQTTRepository{integrations: map[uuid.UUID]*entities.IntegrationMQTT{}}
+	for _, integration := range integrations {
+		repository.integrations[integration.ID] = integration
+	}
+	return repository
+}
+
+func (repository *fakeIntegrationMQTTRepository) Save(_ context.Context, integration *entities.IntegrationMQTT) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	repository.integrations[integration.ID] = integration
+	return nil
+}
+
+func (repository *fakeIntegrationMQTTRepository) Index(_ context.Context, userID entities.UserID, _ repositories.IndexParams) ([]*entities.IntegrationMQTT, error) {
+	return repository.filter(func(integration *entities.IntegrationMQTT) bool {
+		return integration.UserID == userID
+	}), nil
+}
+
+func (repository *fakeIntegrationMQTTRepository) LoadByEvent(_ context.Context, userID entities.UserID, event string) ([]*entities.IntegrationMQTT, error) {
+	return repository.filter(func(integration *entities.IntegrationMQTT) bool {
+		for _, value := range integration.Events {
+			if value == event && integration.UserID == userID {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (repository *fakeIntegrationMQTTRepository) FetchHavingCommandTopic(_ context.Context) ([]*entities.IntegrationMQTT, error) {
+	return repository.filter(func(integration *entities.IntegrationMQTT) bool {
+		return integration.HasCommandTopic()
+	}), nil
+}
+
+func (repository *fakeIntegrationMQTTRepository) Load(_ context.Context, userID entities.UserID, integrationID uuid.UUID) (*entities.IntegrationMQTT, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	integration, ok := repository.integrations[integrationID]
+	if !ok || integration.UserID != userID {
+		return nil, stacktrace.NewErrorWithCode(repositories.ErrCodeNotFound, "integration not found")
+	}
+	return integration, nil
+}
+
+func (repository *fakeIntegrationMQTTRepository) Delete(_ context.Context, _ entities.UserID, integrationID uuid.UUID) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+	delete(repository.integrations, integrationID)
+	return nil
+}
+
+func (repository *fakeIntegrationMQTTRepository) DeleteAllForUser(_ context.Context, userID entities.UserID) error {
+	for _, integration := range repository.filter(func(integration *entities.IntegrationMQTT) bool { return integration.UserID == userID }) {
+		_ = repository.Delete(context.Background(), userID, integration.ID)
+	}
+	return nil
+}
+
+func (repository *fakeIntegrationMQTTRepository) filter(predicate func(integration *entities.IntegrationMQTT) bool) []*entities.IntegrationMQTT {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	var result []*entities.IntegrationMQTT
+	for _, integration := range repository.integrations {
+		if predicate(integration) {
+			result = append(result, integration)
+		}
+	}
+	return result
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
 
 	"github.com/nyaruka/phonenumbers"
 
+	"github.com/NdoleStudio/httpsms/pkg/cache"
 	"github.com/NdoleStudio/httpsms/pkg/events"
 	"github.com/NdoleStudio/httpsms/pkg/repositories"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
@@ -23,11 +25,16 @@ import (
 // MessageService is handles message requests
 type MessageService struct {
 	service
-	logger          telemetry.Logger
-	tracer          telemetry.Tracer
-	eventDispatcher *EventDispatcher
-	phoneService    *PhoneService
-	repository      repositories.MessageRepository
+	logger                telemetry.Logger
+	tracer                telemetry.Tracer
+	eventDispatcher       *EventDispatcher
+	phoneService          *PhoneService
+	repository            repositories.MessageRepository
+	suppressionRepository repositories.SuppressionRepository
+	userRepository        repositories.UserRepository
+	notificationService   *PhoneNotificationService
+	campaignRepository    repositories.CampaignRepository
+	cache                 cache.Cache
 }
 
 // NewMessageService creates a new MessageService
@@ -37,13 +44,23 @@ func NewMessageService(
 	repository repositories.MessageRepository,
 	eventDispatcher *EventDispatcher,
 	phoneService *PhoneService,
+	suppressionRepository repositories.SuppressionRepository,
+	userRepository repositories.UserRepository,
+	notificationService *PhoneNotificationService,
+	campaignRepository repositories.CampaignRepository,
+	cache cache.Cache,
 ) (s *MessageService) {
 	return &MessageService{
-		logger:          logger.WithService(fmt.Sprintf("%T", s)),
-		tracer:          tracer,
-		repository:      repository,
-		phoneService:    phoneService,
-		eventDispatcher: eventDispatcher,
+		logger:                logger.WithService(fmt.Sprintf("%T", s)),
+		tracer:                tracer,
+		repository:            repository,
+		phoneService:          phoneService,
+		suppressionRepository: suppressionRepository,
+		userRepository:        userRepository,
+		notificationService:   notificationService,
+		campaignRepository:    campaignRepository,
+		cache:                 cache,
+		eventDispatcher:       eventDispatcher,
 	}
 }
 
@@ -69,14 +86,16 @@ func (service *MessageService) GetOutstanding(ctx context.Context, params Messag
 	}
 
 	event, err := service.createMessagePhoneSendingEvent(params.Source, events.MessagePhoneSendingPayload{
-		ID:        message.ID,
-		Owner:     message.Owner,
-		Contact:   message.Contact,
-		Timestamp: params.Timestamp,
-		Encrypted: message.Encrypted,
-		UserID:    message.UserID,
-		Content:   message.Content,
-		SIM:       message.SIM,
+		ID:          message.ID,
+		Owner:       message.Owner,
+		Contact:     message.Contact,
+		Timestamp:   params.Timestamp,
+		Encrypted:   message.Encrypted,
+		UserID:      message.UserID,
+		RequestID:   message.RequestID,
+		CallbackURL: message.CallbackURL,
+		Content:     message.Content,
+		SIM:         message.SIM,
 	})
 	if err != nil {
 		msg := fmt.Sprintf("cannot create [%T] for message with ID [%s]", event, message.ID)
@@ -191,28 +210,66 @@ func (service *MessageService) RespondToMissedCall(ctx context.Context, source s
 		return nil
 	}
 
-	requestID := fmt.Sprintf("missed-call-%s", payload.MessageID)
-	owner, _ := phonenumbers.Parse(payload.Owner, phonenumbers.UNKNOWN_REGION)
-	message, err := service.SendMessage(ctx, MessageSendParams{
-		Owner:             owner,
-		Contact:           payload.Contact,
-		Encrypted:         false,
-		Content:           *phone.MissedCallAutoReply,
-		Source:            source,
-		SendAt:            nil,
-		RequestID:         &requestID,
-		UserID:            payload.UserID,
-		RequestReceivedAt: time.Now().UTC(),
+	_, err = service.SendSystemMessage(ctx, SystemMessageParams{
+		UserID:    payload.UserID,
+		Owner:     payload.Owner,
+		Contact:   payload.Contact,
+		Content:   *phone.MissedCallAutoReply,
+		Source:    source,
+		RequestID: fmt.Sprintf("missed-call-%s", payload.MessageID),
 	})
 	if err != nil {
 		msg := fmt.Sprintf("cannot send auto response message for owner [%s] for user with ID [%s] when handling missed phone call message [%s]", payload.Owner, payload.UserID, payload.MessageID)
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
-	ctxLogger.Info(fmt.Sprintf("created response message with ID [%s] for missed call event [%s] for user [%s]", message.ID, payload.MessageID, message.UserID))
 	return nil
 }
 
+// SystemMessageParams are parameters for sending a message which httpSMS sends on behalf of the user in response to a contact
+type SystemMessageParams struct {
+	UserID               entities.UserID
+	Owner                string
+	Contact              string
+	Content              string
+	Source               string
+	RequestID            string
+	SkipSuppressionCheck bool
+}
+
+// SendSystemMessage sends a message which httpSMS sends on behalf of the user e.g. an auto reply. The contact limits are
+// not applied and nil is returned without an error when the contact is suppressed.
+func (service *MessageService) SendSystemMessage(ctx context.Context, params SystemMessageParams) (*entities.Message, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	owner, _ := phonenumbers.Parse(params.Owner, phonenumbers.UNKNOWN_REGION)
+	message, err := service.SendMessage(ctx, MessageSendParams{
+		Owner:                owner,
+		Contact:              params.Contact,
+		Encrypted:            false,
+		Content:              params.Content,
+		Source:               params.Source,
+		RequestID:            &params.RequestID,
+		UserID:               params.UserID,
+		SkipSuppressionCheck: params.SkipSuppressionCheck,
+		SkipContactLimits:    true,
+		RequestReceivedAt:    time.Now().UTC(),
+	})
+	if stacktrace.GetCode(err) == ErrCodeContactSuppressed {
+		ctxLogger.Info(fmt.Sprintf("contact [%s] is suppressed so the message with request ID [%s] is not sent for user [%s]", params.Contact, params.RequestID, params.UserID))
+		return nil, nil
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot send message with request ID [%s] from [%s] to [%s] for user [%s]", params.RequestID, params.Owner, params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("created message with ID [%s] and request ID [%s] for user [%s]", message.ID, params.RequestID, params.UserID))
+	return message, nil
+}
+
 // MessageGetParams parameters for sending a new message
 type MessageGetParams struct {
 	repositories.IndexParams
@@ -259,6 +316,9 @@ type MessageStoreEventParams struct {
 	Timestamp    time.Time
 	ErrorMessage *string
 	Source       string
+
+	// FailureCode is set for server errors. The code is inferred from the ErrorMessage reported by the phone when it is nil.
+	FailureCode *entities.MessageFailureCode
 }
 
 // StoreEvent handles event generated by a mobile phone
@@ -341,15 +401,19 @@ func (service *MessageService) handleMessageSentEvent(ctx context.Context, param
 	defer span.End()
 
 	event, err := service.createMessagePhoneSentEvent(params.Source, events.MessagePhoneSentPayload{
-		ID:        message.ID,
-		Owner:     message.Owner,
-		UserID:    message.UserID,
-		RequestID: message.RequestID,
-		Timestamp: params.Timestamp,
-		Contact:   message.Contact,
-		Encrypted: message.Encrypted,
-		Content:   message.Content,
-		SIM:       message.SIM,
+		ID:          message.ID,
+		Owner:       message.Owner,
+		UserID:      message.UserID,
+		RequestID:   message.RequestID,
+		CallbackURL: message.CallbackURL,
+		Timestamp:   params.Timestamp,
+		Contact:     message.Contact,
+		Encrypted:   message.Encrypted,
+		Content:     message.Content,
+		SIM:         message.SIM,
+		Encoding:    message.Encoding,
+		Segments:    message.Segments,
+		ParentID:    message.ParentID,
 	})
 	if err != nil {
 		msg := fmt.Sprintf("cannot create event [%s] for message [%s]", events.EventTypeMessagePhoneSent, message.ID)
@@ -368,15 +432,19 @@ func (service *MessageService) handleMessageDeliveredEvent(ctx context.Context,
 	defer span.End()
 
 	event, err := service.createMessagePhoneDeliveredEvent(params.Source, events.MessagePhoneDeliveredPayload{
-		ID:        message.ID,
-		Owner:     message.Owner,
-		UserID:    message.UserID,
-		RequestID: message.RequestID,
-		Timestamp: params.Timestamp,
-		Encrypted: message.Encrypted,
-		Contact:   message.Contact,
-		Content:   message.Content,
-		SIM:       message.SIM,
+		ID:          message.ID,
+		Owner:       message.Owner,
+		UserID:      message.UserID,
+		RequestID:   message.RequestID,
+		CallbackURL: message.CallbackURL,
+		Timestamp:   params.Timestamp,
+		Encrypted:   message.Encrypted,
+		Contact:     message.Contact,
+		Content:     message.Content,
+		SIM:         message.SIM,
+		Encoding:    message.Encoding,
+		Segments:    message.Segments,
+		ParentID:    message.ParentID,
 	})
 	if err != nil {
 		msg := fmt.Sprintf("cannot create event [%s] for message [%s]", events.EventTypeMessagePhoneSent, message.ID)
@@ -399,17 +467,35 @@ func (service *MessageService) handleMessageFailedEvent(ctx context.Context, par
 		errorMessage = *params.ErrorMessage
 	}
 
+	failureCode := entities.ClassifyFailure(errorMessage)
+	if params.FailureCode != nil && params.FailureCode.IsValid() {
+		failureCode = *params.FailureCode
+	}
+
+	if message.CanBeRetried(failureCode) {
+		if err := service.repository.Update(ctx, message.RetryAfterFailure(params.Timestamp, failureCode, errorMessage)); err != nil {
+			msg := fmt.Sprintf("cannot update failed message with id [%s] for retry", message.ID)
+			return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+		return service.retryMessage(ctx, params.Source, message, failureCode)
+	}
+
 	event, err := service.createMessageSendFailedEvent(params.Source, events.MessageSendFailedPayload{
 		ID:           message.ID,
 		Owner:        message.Owner,
 		ErrorMessage: errorMessage,
+		FailureCode:  failureCode,
 		Timestamp:    params.Timestamp,
 		Encrypted:    message.Encrypted,
 		Contact:      message.Contact,
 		RequestID:    message.RequestID,
+		CallbackURL:  message.CallbackURL,
 		UserID:       message.UserID,
 		Content:      message.Content,
 		SIM:          message.SIM,
+		Encoding:     message.Encoding,
+		Segments:     message.Segments,
+		ParentID:     message.ParentID,
 	})
 	if err != nil {
 		msg := fmt.Sprintf("cannot create event [%s] for message [%s]", events.EventTypeMessageSendFailed, message.ID)
@@ -432,8 +518,47 @@ type MessageSendParams struct {
 	Source            string
 	SendAt            *time.Time
 	RequestID         *string
+	CampaignID        *uuid.UUID
 	UserID            entities.UserID
 	RequestReceivedAt time.Time
+
+	// CallbackURL receives the sending, sent, delivered, failed and expired events of the message
+	CallbackURL *string
+
+	// SkipSuppressionCheck is used for the confirmation reply sent to a contact which has just opted out
+	SkipSuppressionCheck bool
+
+	// User is the owner of the message. It is loaded when it is nil so that a request which sends many messages can load it once.
+	User *entities.User
+
+	// SkipContactLimits is used for replies sent by the system e.g. opt-out confirmations and auto replies which must not be rejected as duplicates or rate limited
+	SkipContactLimits bool
+
+	// NormalizeGSM7 replaces characters with GSM-7 equivalents. The default of the phone is used when it is nil.
+	NormalizeGSM7 *bool
+
+	// ExpiresAt is the time after which the message will not be sent. The expiration of the phone is used when it is nil.
+	ExpiresAt *time.Time
+
+	// RetryPolicy determines when and how the message is sent again after it fails or expires. The policy of the phone is used when it is nil.
+	RetryPolicy *entities.RetryPolicy
+}
+
+// Segments estimates the number of SMS segments of the message
+func (params MessageSendParams) Segments() uint {
+	_, segments := entities.EstimateSegments(params.Content, params.Encrypted)
+	return segments
+}
+
+// PartSegments returns the number of segments of each part when the message is split with SendSplitMessage
+func (params MessageSendParams) PartSegments() []uint {
+	parts := entities.SplitMessage(params.Content)
+	segments := make([]uint, 0, len(parts))
+	for _, part := range parts {
+		_, count := entities.EstimateSegments(part, params.Encrypted)
+		segments = append(segments, count)
+	}
+	return segments
 }
 
 // SendMessage a new message
@@ -441,36 +566,324 @@ func (service *MessageService) SendMessage(ctx context.Context, params MessageSe
 	ctx, span := service.tracer.Start(ctx)
 	defer span.End()
 
+	content, err := service.prepareContent(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot send message to contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	duplicate, release, err := service.reserveContactLimits(ctx, params, content.content, 1)
+	if err != nil {
+		msg := fmt.Sprintf("cannot send message to contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+	defer release()
+
+	if duplicate != nil {
+		return duplicate, nil
+	}
+
+	message, err := service.sendMessagePart(ctx, params, content, messagePart{})
+	if err != nil {
+		msg := fmt.Sprintf("cannot send message to contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return message, nil
+}
+
+// SendSplitMessage splits long content at word boundaries into multiple linked messages which are sent in order on the same phone and SIM
+func (service *MessageService) SendSplitMessage(ctx context.Context, params MessageSendParams) ([]*entities.Message, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	content, err := service.prepareContent(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot send split message to contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	parts := entities.SplitMessage(content.content)
+
+	duplicate, release, err := service.reserveContactLimits(ctx, params, parts[0], len(parts))
+	if err != nil {
+		msg := fmt.Sprintf("cannot send split message to contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+	defer release()
+
+	if duplicate != nil {
+		return []*entities.Message{duplicate}, nil
+	}
+
+	if len(parts) == 1 {
+		message, err := service.sendMessagePart(ctx, params, content, messagePart{})
+		if err != nil {
+			msg := fmt.Sprintf("cannot send message to contact [%s] for user [%s]", params.Contact, params.UserID)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+		return []*entities.Message{message}, nil
+	}
+
+	parentID := uuid.New()
+	messages := make([]*entities.Message, 0, len(parts))
+	for index, part := range parts {
+		partContent := *content
+		partContent.content = part
+		if index > 0 {
+			// the normalizations are reported once on the first part
+			partContent.normalizations = nil
+		}
+
+		message, err := service.sendMessagePart(ctx, params, &partContent, messagePart{
+			parentID: &parentID,
+			number:   uint(index + 1),
+			count:    uint(len(parts)),
+		})
+		if err != nil {
+			msg := fmt.Sprintf("cannot send part [%d/%d] of message [%s] to contact [%s] for user [%s]", index+1, len(parts), parentID, params.Contact, params.UserID)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+		messages = append(messages, message)
+	}
+
+	ctxLogger.Info(fmt.Sprintf("split message [%s] into [%d] parts for contact [%s] and user [%s]", parentID, len(parts), params.Contact, params.UserID))
+	return messages, nil
+}
+
+// PreviewMessage runs a dry run of sending a message. All the checks of SendMessage and SendSplitMessage are performed but nothing is stored or sent.
+// The position is the number of messages which will be sent by the same phone before this message.
+func (service *MessageService) PreviewMessage(ctx context.Context, params MessageSendParams, split bool, position uint) (*entities.MessagePreview, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	content, err := service.prepareContent(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot preview message to contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	encoding, segments := entities.EstimateSegments(content.content, params.Encrypted)
+	preview := &entities.MessagePreview{
+		Owner:           phonenumbers.Format(params.Owner, phonenumbers.E164),
+		Contact:         params.Contact,
+		Content:         content.content,
+		SIM:             content.sim,
+		Encoding:        encoding,
+		Segments:        segments,
+		Normalizations:  content.normalizations,
+		MaxSendAttempts: content.sendAttempts,
+		ExpiresAt:       params.ExpiresAt,
+	}
+
+	if split {
+		preview.Parts = entities.SplitMessage(content.content)
+		if len(preview.Parts) > 1 {
+			preview.Segments = uint(len(preview.Parts))
+		}
+	}
+
+	first, count := content.content, 1
+	if len(preview.Parts) > 0 {
+		first, count = preview.Parts[0], len(preview.Parts)
+	}
+
+	user, err := service.loadUser(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot preview message to contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	duplicate, err := service.checkContactLimits(ctx, params, user, first, count)
+	if err != nil {
+		msg := fmt.Sprintf("cannot preview message to contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	if duplicate != nil {
+		preview.DuplicateOf = &duplicate.ID
+	}
+
+	preview.EstimatedSendTime, err = service.notificationService.EstimateScheduledAt(ctx, &PhoneNotificationEstimateParams{
+		UserID:   params.UserID,
+		Owner:    preview.Owner,
+		Contact:  params.Contact,
+		SendAt:   params.SendAt,
+		Position: position,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot estimate the send time of the message to contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return preview, nil
+}
+
+// PreviewMessages runs a dry run of sending messages in bulk. The reason why a message cannot be sent is set on the entities.MessagePreview of the message.
+func (service *MessageService) PreviewMessages(ctx context.Context, params []MessageSendParams) ([]*entities.MessagePreview, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	params, err := service.withUsers(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load the users to preview [%d] messages", len(params))
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	positions := map[string]uint{}
+	previews := make([]*entities.MessagePreview, 0, len(params))
+	for _, message := range params {
+		owner := phonenumbers.Format(message.Owner, phonenumbers.E164)
+
+		preview, err := service.PreviewMessage(ctx, message, false, positions[owner])
+		if reason := service.previewError(err, message.Contact); reason != nil {
+			previews = append(previews, &entities.MessagePreview{Owner: owner, Contact: message.Contact, Content: message.Content, Error: reason})
+			continue
+		}
+
+		if err != nil {
+			msg := fmt.Sprintf("cannot preview message to contact [%s] for user [%s]", message.Contact, message.UserID)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+
+		if preview.DuplicateOf == nil {
+			positions[owner]++
+		}
+		previews = append(previews, preview)
+	}
+
+	return previews, nil
+}
+
+// previewError returns the reason why a message cannot be sent to the contact when the error is caused by the contact
+func (service *MessageService) previewError(err error, contact string) *string {
+	var reason string
+	switch stacktrace.GetCode(err) {
+	case ErrCodeContactSuppressed:
+		reason = fmt.Sprintf("the contact [%s] opted out of receiving messages and is on your suppression list", contact)
+	case ErrCodeDuplicateMessage:
+		reason = fmt.Sprintf("the same message was recently sent to the contact [%s]", contact)
+	case ErrCodeContactRateLimited:
+		reason = fmt.Sprintf("too many messages were recently sent to the contact [%s]", contact)
+	default:
+		return nil
+	}
+	return &reason
+}
+
+// messagePartTTL is how long the dispatch of the next part of a split message is remembered so that it is sent only once
+const messagePartTTL = 7 * 24 * time.Hour
+
+// messagePart is the position of a message in the parts of a split message
+type messagePart struct {
+	parentID *uuid.UUID
+	number   uint
+	count    uint
+}
+
+// isFirst checks if the part is sent immediately. The other parts are sent by sendNextPart after the previous part is sent
+func (part messagePart) isFirst() bool {
+	return part.number <= 1
+}
+
+// messageContent is the content of a message after applying the settings of the phone
+type messageContent struct {
+	content        string
+	normalizations []entities.MessageNormalization
+	sendAttempts   uint
+	sim            entities.SIM
+	retryPolicy    *entities.RetryPolicy
+}
+
+func (service *MessageService) prepareContent(ctx context.Context, params MessageSendParams) (*messageContent, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	if !params.SkipSuppressionCheck {
+		if err := service.checkSuppression(ctx, params.UserID, params.Contact); err != nil {
+			msg := fmt.Sprintf("cannot send message to contact [%s] for user [%s]", params.Contact, params.UserID)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+		}
+	}
+
+	phone := service.phoneSettings(ctx, params.UserID, phonenumbers.Format(params.Owner, phonenumbers.E164))
+
+	normalize := phone.NormalizeGSM7
+	if params.NormalizeGSM7 != nil {
+		normalize = *params.NormalizeGSM7
+	}
+
+	retryPolicy := phone.RetryPolicy
+	if params.RetryPolicy != nil {
+		retryPolicy = params.RetryPolicy
+	}
+
+	content := &messageContent{
+		content:      params.Content,
+		sendAttempts: phone.MaxSendAttemptsSanitized(),
+		sim:          phone.SIM,
+		retryPolicy:  retryPolicy,
+	}
+	if retryPolicy != nil {
+		content.sendAttempts = retryPolicy.MaxAttempts
+	}
+
+	if normalize && !params.Encrypted {
+		content.content, content.normalizations = entities.NormalizeGSM7(params.Content)
+	}
+
+	return content, nil
+}
+
+func (service *MessageService) sendMessagePart(ctx context.Context, params MessageSendParams, content *messageContent, part messagePart) (*entities.Message, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
 	ctxLogger := service.tracer.CtxLogger(service.logger, span)
 
-	sendAttempts, sim := service.phoneSettings(ctx, params.UserID, phonenumbers.Format(params.Owner, phonenumbers.E164))
+	encoding, segments := entities.EstimateSegments(content.content, params.Encrypted)
 
 	eventPayload := events.MessageAPISentPayload{
 		MessageID:         uuid.New(),
 		UserID:            params.UserID,
 		Encrypted:         params.Encrypted,
-		MaxSendAttempts:   sendAttempts,
+		MaxSendAttempts:   content.sendAttempts,
 		RequestID:         params.RequestID,
+		CallbackURL:       params.CallbackURL,
+		CampaignID:        params.CampaignID,
 		Owner:             phonenumbers.Format(params.Owner, phonenumbers.E164),
 		Contact:           params.Contact,
 		RequestReceivedAt: params.RequestReceivedAt,
-		Content:           params.Content,
+		Content:           content.content,
 		ScheduledSendTime: params.SendAt,
-		SIM:               sim,
+		SIM:               content.sim,
+		Encoding:          encoding,
+		Segments:          segments,
+		Normalizations:    content.normalizations,
+		ParentID:          part.parentID,
+		PartNumber:        part.number,
+		PartCount:         part.count,
+		ExpiresAt:         params.ExpiresAt,
+		RetryPolicy:       content.retryPolicy,
 	}
 
-	event, err := service.createMessageAPISentEvent(params.Source, eventPayload)
+	message, err := service.storeSentMessage(ctx, eventPayload)
 	if err != nil {
-		msg := fmt.Sprintf("cannot create %T from payload with message id [%s]", event, eventPayload.MessageID)
+		msg := fmt.Sprintf("cannot store message with id [%s]", eventPayload.MessageID)
 		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
-	ctxLogger.Info(fmt.Sprintf("created event [%s] with id [%s] and message id [%s] and user [%s]", event.Type(), event.ID(), eventPayload.MessageID, eventPayload.UserID))
 
-	message, err := service.storeSentMessage(ctx, eventPayload)
+	if !part.isFirst() {
+		ctxLogger.Info(fmt.Sprintf("part [%d/%d] of message [%s] with ID [%s] will be sent after part [%d]", part.number, part.count, *part.parentID, message.ID, part.number-1))
+		return message, nil
+	}
+
+	event, err := service.createMessageAPISentEvent(params.Source, eventPayload)
 	if err != nil {
-		msg := fmt.Sprintf("cannot store message with id [%s]", eventPayload.MessageID)
+		msg := fmt.Sprintf("cannot create %T from payload with message id [%s]", event, eventPayload.MessageID)
 		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
+	ctxLogger.Info(fmt.Sprintf("created event [%s] with id [%s] and message id [%s] and user [%s]", event.Type(), event.ID(), eventPayload.MessageID, eventPayload.UserID))
 
 	timeout := service.getSendDelay(ctxLogger, eventPayload, params.SendAt)
 	if _, err = service.eventDispatcher.DispatchWithTimeout(ctx, event, timeout); err != nil {
@@ -580,10 +993,11 @@ func (service *MessageService) storeReceivedMessage(ctx context.Context, params
 
 // HandleMessageParams are parameters for handling a message event
 type HandleMessageParams struct {
-	ID        uuid.UUID
-	Source    string
-	UserID    entities.UserID
-	Timestamp time.Time
+	ID            uuid.UUID
+	Source        string
+	UserID        entities.UserID
+	Timestamp     time.Time
+	DeferredUntil *time.Time
 }
 
 // HandleMessageSending handles when a message is being sent
@@ -642,7 +1056,13 @@ func (service *MessageService) HandleMessageSent(ctx context.Context, params Han
 	}
 
 	ctxLogger.Info(fmt.Sprintf("message with id [%s] has been updated to status [%s]", message.ID, message.Status))
-	return nil
+
+	if err = service.sendNextPart(ctx, params.Source, message); err != nil {
+		msg := fmt.Sprintf("cannot send the part after message with ID [%s]", message.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return service.dispatchCampaignMessageUpdated(ctx, params.Source, message)
 }
 
 // HandleMessageFailedParams are parameters for handling a failed message event
@@ -650,7 +1070,9 @@ type HandleMessageFailedParams struct {
 	ID           uuid.UUID
 	UserID       entities.UserID
 	ErrorMessage string
+	FailureCode  entities.MessageFailureCode
 	Timestamp    time.Time
+	Source       string
 }
 
 // HandleMessageFailed handles when a message could not be sent by a mobile phone
@@ -671,13 +1093,19 @@ func (service *MessageService) HandleMessageFailed(ctx context.Context, params H
 		return service.tracer.WrapErrorSpan(span, stacktrace.NewError(msg))
 	}
 
-	if err = service.repository.Update(ctx, message.Failed(params.Timestamp, params.ErrorMessage)); err != nil {
+	if err = service.repository.Update(ctx, message.Failed(params.Timestamp, params.FailureCode, params.ErrorMessage)); err != nil {
 		msg := fmt.Sprintf("cannot update message with id [%s] as sent", message.ID)
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
 	ctxLogger.Info(fmt.Sprintf("message with id [%s] has been updated to status [%s]", message.ID, message.Status))
-	return nil
+
+	if err = service.sendNextPart(ctx, params.Source, message); err != nil {
+		msg := fmt.Sprintf("cannot send the part after message with ID [%s]", message.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return service.dispatchCampaignMessageUpdated(ctx, params.Source, message)
 }
 
 // HandleMessageDelivered handles when a message is has been delivered by a mobile phone
@@ -705,7 +1133,13 @@ func (service *MessageService) HandleMessageDelivered(ctx context.Context, param
 	}
 
 	ctxLogger.Info(fmt.Sprintf("message with id [%s] has been updated to status [%s]", message.ID, message.Status))
-	return nil
+
+	if err = service.sendNextPart(ctx, params.Source, message); err != nil {
+		msg := fmt.Sprintf("cannot send the part after message with ID [%s]", message.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return service.dispatchCampaignMessageUpdated(ctx, params.Source, message)
 }
 
 // HandleMessageNotificationScheduled handles the event when the notification of a message has been scheduled
@@ -725,7 +1159,7 @@ func (service *MessageService) HandleMessageNotificationScheduled(ctx context.Co
 		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("received scheduled event for message with id [%s] message has status [%s]", message.ID, message.Status)))
 	}
 
-	if err = service.repository.Update(ctx, message.NotificationScheduled(params.Timestamp)); err != nil {
+	if err = service.repository.Update(ctx, message.NotificationScheduled(params.Timestamp, params.DeferredUntil)); err != nil {
 		msg := fmt.Sprintf("cannot update message with id [%s] as expired", message.ID)
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
@@ -774,38 +1208,69 @@ func (service *MessageService) HandleMessageExpired(ctx context.Context, params
 		return service.tracer.WrapErrorSpan(span, stacktrace.NewError(msg))
 	}
 
-	if err = service.repository.Update(ctx, message.Expired(params.Timestamp)); err != nil {
+	failureCode := message.ExpiryFailureCode()
+	if err = service.repository.Update(ctx, message.Expired(params.Timestamp, failureCode)); err != nil {
 		msg := fmt.Sprintf("cannot update message with id [%s] as expired", message.ID)
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
-	ctxLogger.Info(fmt.Sprintf("message with id [%s] has been updated to status [%s]", message.ID, message.Status))
+	ctxLogger.Info(fmt.Sprintf("message with id [%s] has been updated to status [%s] with failure code [%s]", message.ID, message.Status, failureCode))
 
-	if !message.CanBeRescheduled() {
-		return nil
+	if !message.CanBeRetried(failureCode) {
+		if err = service.sendNextPart(ctx, params.Source, message); err != nil {
+			msg := fmt.Sprintf("cannot send the part after message with ID [%s]", message.ID)
+			return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+		return service.dispatchCampaignMessageUpdated(ctx, params.Source, message)
+	}
+
+	if err = service.retryMessage(ctx, params.Source, message, failureCode); err != nil {
+		msg := fmt.Sprintf("cannot retry expired message with ID [%s]", message.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+// retryMessage schedules the next attempt of sending a message using the backoff delay of its entities.RetryPolicy
+func (service *MessageService) retryMessage(ctx context.Context, source string, message *entities.Message, failureCode entities.MessageFailureCode) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	delay := message.RetryPolicySanitized().Delay(message.SendAttemptCount)
+	nextAttemptAt := time.Now().UTC().Add(delay)
+
+	if err := service.repository.Update(ctx, message.ScheduleRetry(nextAttemptAt)); err != nil {
+		msg := fmt.Sprintf("cannot update message with id [%s] with next attempt at [%s]", message.ID, nextAttemptAt)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
-	event, err := service.createMessageSendRetryEvent(params.Source, &events.MessageSendRetryPayload{
-		MessageID: message.ID,
-		Timestamp: time.Now().UTC(),
-		Contact:   message.Contact,
-		Owner:     message.Owner,
-		Encrypted: message.Encrypted,
-		UserID:    message.UserID,
-		Content:   message.Content,
-		SIM:       message.SIM,
+	event, err := service.createMessageSendRetryEvent(source, &events.MessageSendRetryPayload{
+		MessageID:     message.ID,
+		CampaignID:    message.CampaignID,
+		Timestamp:     time.Now().UTC(),
+		Contact:       message.Contact,
+		Owner:         message.Owner,
+		Encrypted:     message.Encrypted,
+		UserID:        message.UserID,
+		Content:       message.Content,
+		SIM:           message.SIM,
+		ExpiresAt:     message.ExpiresAt,
+		Attempt:       message.SendAttemptCount + 1,
+		NextAttemptAt: nextAttemptAt,
+		FailureCode:   failureCode,
 	})
 	if err != nil {
-		msg := fmt.Sprintf("cannot create [%s] event for expired message with ID [%s]", events.EventTypeMessageSendRetry, message.ID)
+		msg := fmt.Sprintf("cannot create [%s] event for message with ID [%s]", events.EventTypeMessageSendRetry, message.ID)
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
-	if err = service.eventDispatcher.Dispatch(ctx, event); err != nil {
+	if _, err = service.eventDispatcher.DispatchWithTimeout(ctx, event, delay); err != nil {
 		msg := fmt.Sprintf("cannot dispatch [%s] event for message with ID [%s]", event.Type(), message.ID)
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
-	ctxLogger.Info(fmt.Sprintf("retried sending message with ID [%s]", message.ID))
+	ctxLogger.Info(fmt.Sprintf("scheduled attempt [%d] of message with ID [%s] at [%s] with SIM [%s] because of [%s]", message.SendAttemptCount+1, message.ID, nextAttemptAt, message.SIM, failureCode))
 	return nil
 }
 
@@ -832,9 +1297,10 @@ func (service *MessageService) ScheduleExpirationCheck(ctx context.Context, para
 	}
 
 	event, err := service.createMessageSendExpiredCheckEvent(params.Source, &events.MessageSendExpiredCheckPayload{
-		MessageID:   params.MessageID,
-		ScheduledAt: params.NotificationSentAt.Add(params.MessageExpirationDuration),
-		UserID:      params.UserID,
+		MessageID:          params.MessageID,
+		ScheduledAt:        params.NotificationSentAt.Add(params.MessageExpirationDuration),
+		NotificationSentAt: params.NotificationSentAt,
+		UserID:             params.UserID,
 	})
 	if err != nil {
 		msg := fmt.Sprintf("cannot create event [%s] for message with id [%s]", events.EventTypeMessageSendExpiredCheck, params.MessageID)
@@ -852,9 +1318,10 @@ func (service *MessageService) ScheduleExpirationCheck(ctx context.Context, para
 
 // MessageCheckExpired are parameters for checking if a message is expired
 type MessageCheckExpired struct {
-	MessageID uuid.UUID
-	UserID    entities.UserID
-	Source    string
+	MessageID          uuid.UUID
+	UserID             entities.UserID
+	NotificationSentAt time.Time
+	Source             string
 }
 
 // CheckExpired checks if a message has expired
@@ -880,18 +1347,28 @@ func (service *MessageService) CheckExpired(ctx context.Context, params MessageC
 		return nil
 	}
 
+	if message.NextAttemptAt != nil && !params.NotificationSentAt.IsZero() && message.NextAttemptAt.After(params.NotificationSentAt) {
+		ctxLogger.Info(fmt.Sprintf("message with ID [%s] has been retried at [%s] after the notification was sent at [%s]", message.ID, message.NextAttemptAt, params.NotificationSentAt))
+		return nil
+	}
+
 	event, err := service.createMessageSendExpiredEvent(params.Source, events.MessageSendExpiredPayload{
 		MessageID:        message.ID,
 		Owner:            message.Owner,
 		Contact:          message.Contact,
 		Encrypted:        message.Encrypted,
 		RequestID:        message.RequestID,
-		IsFinal:          message.SendAttemptCount == message.MaxSendAttempts,
+		CallbackURL:      message.CallbackURL,
+		IsFinal:          !message.CanBeRescheduled(),
+		FailureCode:      message.ExpiryFailureCode(),
 		SendAttemptCount: message.SendAttemptCount,
 		UserID:           message.UserID,
 		Timestamp:        time.Now().UTC(),
 		Content:          message.Content,
 		SIM:              message.SIM,
+		Encoding:         message.Encoding,
+		Segments:         message.Segments,
+		ParentID:         message.ParentID,
 	})
 	if err != nil {
 		msg := fmt.Sprintf("cannot create event [%s] for message with id [%s]", events.EventTypeMessageSendExpired, params.MessageID)
@@ -914,6 +1391,9 @@ type MessageSearchParams struct {
 	Owners   []string
 	Types    []entities.MessageType
 	Statuses []entities.MessageStatus
+
+	// FailureCodes filters the messages by their entities.MessageFailureCode
+	FailureCodes []entities.MessageFailureCode
 }
 
 // SearchMessages fetches all the messages for a user
@@ -923,7 +1403,7 @@ func (service *MessageService) SearchMessages(ctx context.Context, params *Messa
 
 	ctxLogger := service.tracer.CtxLogger(service.logger, span)
 
-	messages, err := service.repository.Search(ctx, params.UserID, params.Owners, params.Types, params.Statuses, params.IndexParams)
+	messages, err := service.repository.Search(ctx, params.UserID, params.Owners, params.Types, params.Statuses, params.FailureCodes, params.IndexParams)
 	if err != nil {
 		msg := fmt.Sprintf("could not search messages with parms [%+#v]", params)
 		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
@@ -933,7 +1413,322 @@ func (service *MessageService) SearchMessages(ctx context.Context, params *Messa
 	return messages, nil
 }
 
-func (service *MessageService) phoneSettings(ctx context.Context, userID entities.UserID, owner string) (uint, entities.SIM) {
+// sendNextPart sends the part of a split message which comes after the message so that the phone sends the parts in order
+func (service *MessageService) sendNextPart(ctx context.Context, source string, message *entities.Message) error {
+	if message.ParentID == nil || message.PartNumber >= message.PartCount {
+		return nil
+	}
+
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	next, err := service.repository.LoadPart(ctx, message.UserID, *message.ParentID, message.PartNumber+1)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load part [%d/%d] of message [%s] for user [%s]", message.PartNumber+1, message.PartCount, *message.ParentID, message.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if !next.IsPending() {
+		ctxLogger.Info(fmt.Sprintf("part [%d/%d] of message [%s] with ID [%s] has already been sent with status [%s]", next.PartNumber, next.PartCount, *next.ParentID, next.ID, next.Status))
+		return nil
+	}
+
+	// the previous part can be sent and delivered so the lock makes sure that the next part is dispatched only once
+	added, err := service.cache.Add(ctx, fmt.Sprintf("message-part:%s", next.ID), message.ID.String(), messagePartTTL)
+	if err != nil {
+		msg := fmt.Sprintf("cannot lock part [%d/%d] of message [%s] with ID [%s]", next.PartNumber, next.PartCount, *next.ParentID, next.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+	if !added {
+		ctxLogger.Info(fmt.Sprintf("part [%d/%d] of message [%s] with ID [%s] has already been dispatched", next.PartNumber, next.PartCount, *next.ParentID, next.ID))
+		return nil
+	}
+
+	event, err := service.createMessageAPISentEvent(source, events.MessageAPISentPayload{
+		MessageID:         next.ID,
+		UserID:            next.UserID,
+		Encrypted:         next.Encrypted,
+		MaxSendAttempts:   next.MaxSendAttempts,
+		RequestID:         next.RequestID,
+		CallbackURL:       next.CallbackURL,
+		CampaignID:        next.CampaignID,
+		Owner:             next.Owner,
+		Contact:           next.Contact,
+		RequestReceivedAt: next.RequestReceivedAt,
+		Content:           next.Content,
+		ScheduledSendTime: next.ScheduledSendTime,
+		SIM:               next.SIM,
+		Encoding:          next.Encoding,
+		Segments:          next.Segments,
+		Normalizations:    next.Normalizations,
+		ParentID:          next.ParentID,
+		PartNumber:        next.PartNumber,
+		PartCount:         next.PartCount,
+		ExpiresAt:         next.ExpiresAt,
+		RetryPolicy:       next.RetryPolicy,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot create [%s] event for message with ID [%s]", events.EventTypeMessageAPISent, next.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err = service.eventDispatcher.Dispatch(ctx, event); err != nil {
+		msg := fmt.Sprintf("cannot dispatch [%s] event for message with ID [%s]", event.Type(), next.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("dispatched part [%d/%d] of message [%s] with ID [%s] after message [%s] with status [%s]", next.PartNumber, next.PartCount, *next.ParentID, next.ID, message.ID, message.Status))
+	return nil
+}
+
+func (service *MessageService) dispatchCampaignMessageUpdated(ctx context.Context, source string, message *entities.Message) error {
+	if message.CampaignID == nil {
+		return nil
+	}
+
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	event, err := service.createEvent(events.EventTypeCampaignMessageUpdated, source, &events.CampaignMessageUpdatedPayload{
+		CampaignID: *message.CampaignID,
+		MessageID:  message.ID,
+		UserID:     message.UserID,
+		Owner:      message.Owner,
+		Status:     message.Status,
+		Timestamp:  message.UpdatedAt,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot create [%s] event for message with ID [%s]", events.EventTypeCampaignMessageUpdated, message.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if err = service.eventDispatcher.Dispatch(ctx, event); err != nil {
+		msg := fmt.Sprintf("cannot dispatch [%s] event for message with ID [%s]", event.Type(), message.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+func (service *MessageService) checkSuppression(ctx context.Context, userID entities.UserID, contact string) error {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	suppression, err := service.suppressionRepository.LoadByContact(ctx, userID, contact)
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return nil
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot check if contact [%s] is suppressed for user [%s]", contact, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	msg := fmt.Sprintf("the contact [%s] opted out of receiving messages on [%s]", contact, suppression.CreatedAt.Format(time.RFC3339))
+	return service.tracer.WrapErrorSpan(span, stacktrace.NewErrorWithCode(ErrCodeContactSuppressed, msg))
+}
+
+// contactLimitsLockTTL is the maximum duration for which the contact limits of a contact are locked while a message is sent
+const contactLimitsLockTTL = 30 * time.Second
+
+// contactLimitsLockTimeout is the maximum duration to wait for the lock of the contact limits of a contact
+const contactLimitsLockTimeout = 10 * time.Second
+
+// contactLimitsLockInterval is the delay between attempts to acquire the lock of the contact limits of a contact
+const contactLimitsLockInterval = 50 * time.Millisecond
+
+// SendMessages sends multiple messages concurrently. The users are loaded once for all the messages.
+// The message at the index of a message which cannot be sent is nil.
+func (service *MessageService) SendMessages(ctx context.Context, params []MessageSendParams) ([]*entities.Message, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	params, err := service.withUsers(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load the users to send [%d] messages", len(params))
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	wg := sync.WaitGroup{}
+	messages := make([]*entities.Message, len(params))
+	for index, message := range params {
+		wg.Add(1)
+		go func(message MessageSendParams, index int) {
+			defer wg.Done()
+
+			response, err := service.SendMessage(ctx, message)
+			if err != nil {
+				msg := fmt.Sprintf("cannot send message [%d] to contact [%s] for user [%s]", index, message.Contact, message.UserID)
+				ctxLogger.Error(stacktrace.Propagate(err, msg))
+			}
+			messages[index] = response
+		}(message, index)
+	}
+
+	wg.Wait()
+	return messages, nil
+}
+
+// withUsers sets the user of each message so that the user is loaded once for all the messages
+func (service *MessageService) withUsers(ctx context.Context, params []MessageSendParams) ([]MessageSendParams, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	users := map[entities.UserID]*entities.User{}
+	result := make([]MessageSendParams, 0, len(params))
+	for _, message := range params {
+		if message.User == nil && users[message.UserID] == nil {
+			user, err := service.loadUser(ctx, message)
+			if err != nil {
+				return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, fmt.Sprintf("cannot load user [%s]", message.UserID)))
+			}
+			users[message.UserID] = user
+		}
+
+		if message.User == nil {
+			message.User = users[message.UserID]
+		}
+		result = append(result, message)
+	}
+
+	return result, nil
+}
+
+// loadUser returns the user of the message from the params or from the database
+func (service *MessageService) loadUser(ctx context.Context, params MessageSendParams) (*entities.User, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	if params.User != nil {
+		return params.User, nil
+	}
+
+	user, err := service.userRepository.Load(ctx, params.UserID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load user [%s] to send a message to contact [%s]", params.UserID, params.Contact)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return user, nil
+}
+
+// reserveContactLimits checks the contact limits while holding a lock on the contact so that concurrent requests cannot
+// exceed the limits. The returned function releases the lock and it must be called after the messages are stored.
+func (service *MessageService) reserveContactLimits(ctx context.Context, params MessageSendParams, content string, messages int) (*entities.Message, func(), error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	if params.SkipContactLimits {
+		return nil, func() {}, nil
+	}
+
+	user, err := service.loadUser(ctx, params)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load user [%s] to check the limits for contact [%s]", params.UserID, params.Contact)
+		return nil, nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if user.DuplicateMessageWindowSeconds == 0 && user.ContactRateLimit == 0 {
+		return nil, func() {}, nil
+	}
+
+	release, err := service.lockContact(ctx, params.UserID, params.Contact)
+	if err != nil {
+		msg := fmt.Sprintf("cannot lock the limits of contact [%s] for user [%s]", params.Contact, params.UserID)
+		return nil, nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	duplicate, err := service.checkContactLimits(ctx, params, user, content, messages)
+	if err != nil || duplicate != nil {
+		release()
+		return duplicate, func() {}, err
+	}
+
+	return nil, release, nil
+}
+
+// lockContact acquires the lock of the contact limits of a contact and waits while another request holds it
+func (service *MessageService) lockContact(ctx context.Context, userID entities.UserID, contact string) (func(), error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	key := fmt.Sprintf("contact-limits:%s:%s", userID, contact)
+	deadline := time.Now().Add(contactLimitsLockTimeout)
+	for {
+		added, err := service.cache.Add(ctx, key, time.Now().UTC().Format(time.RFC3339Nano), contactLimitsLockTTL)
+		if err != nil {
+			msg := fmt.Sprintf("cannot add the lock [%s] to the cache", key)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+
+		if added {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			msg := fmt.Sprintf("cannot acquire the lock [%s] after [%s]", key, contactLimitsLockTimeout)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.NewError(msg))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(ctx.Err(), fmt.Sprintf("cannot acquire the lock [%s]", key)))
+		case <-time.After(contactLimitsLockInterval):
+		}
+	}
+
+	return func() {
+		if err := service.cache.Delete(context.WithoutCancel(ctx), key); err != nil {
+			ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot release the lock [%s]", key)))
+		}
+	}, nil
+}
+
+// checkContactLimits enforces the duplicate message window and the contact rate limit of the user for sending a number of messages.
+// The original message is returned when a duplicate message is collapsed.
+func (service *MessageService) checkContactLimits(ctx context.Context, params MessageSendParams, user *entities.User, content string, messages int) (*entities.Message, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if params.SkipContactLimits {
+		return nil, nil
+	}
+
+	if user.DuplicateMessageWindowSeconds > 0 {
+		duplicate, err := service.repository.LoadDuplicate(ctx, params.UserID, params.Contact, content, time.Now().UTC().Add(-user.DuplicateMessageWindow()))
+		if err != nil && stacktrace.GetCode(err) != repositories.ErrCodeNotFound {
+			msg := fmt.Sprintf("cannot check for duplicate messages to contact [%s] for user [%s]", params.Contact, params.UserID)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+
+		if err == nil && user.DuplicateMessageAction == entities.DuplicateMessageActionCollapse {
+			ctxLogger.Info(fmt.Sprintf("collapsed duplicate message to contact [%s] into message [%s] for user [%s]", params.Contact, duplicate.ID, params.UserID))
+			return duplicate, nil
+		}
+
+		if err == nil {
+			msg := fmt.Sprintf("the same message [%s] was sent to the contact [%s] less than [%s] ago", duplicate.ID, params.Contact, user.DuplicateMessageWindow())
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.NewErrorWithCode(ErrCodeDuplicateMessage, msg))
+		}
+	}
+
+	if user.ContactRateLimit > 0 {
+		count, err := service.repository.CountByContact(ctx, params.UserID, params.Contact, time.Now().UTC().Add(-user.ContactRateLimitWindow()))
+		if err != nil {
+			msg := fmt.Sprintf("cannot count messages to contact [%s] for user [%s]", params.Contact, params.UserID)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+
+		if count+int64(messages) > int64(user.ContactRateLimit) {
+			msg := fmt.Sprintf("[%d] messages were sent to the contact [%s] in the last [%s] and sending [%d] more exceeds the limit of [%d]", count, params.Contact, user.ContactRateLimitWindow(), messages, user.ContactRateLimit)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.NewErrorWithCode(ErrCodeContactRateLimited, msg))
+		}
+	}
+
+	return nil, nil
+}
+
+func (service *MessageService) phoneSettings(ctx context.Context, userID entities.UserID, owner string) *entities.Phone {
 	ctx, span := service.tracer.Start(ctx)
 	defer span.End()
 
@@ -943,10 +1738,10 @@ func (service *MessageService) phoneSettings(ctx context.Context, userID entitie
 	if err != nil {
 		msg := fmt.Sprintf("cannot load phone for userID [%s] and owner [%s]. using default max send attempt of 2", userID, owner)
 		ctxLogger.Error(stacktrace.Propagate(err, msg))
-		return 2, entities.SIM1
+		return &entities.Phone{MaxSendAttempts: 2, SIM: entities.SIM1}
 	}
 
-	return phone.MaxSendAttemptsSanitized(), phone.SIM
+	return phone
 }
 
 // storeSentMessage a new message
@@ -968,6 +1763,8 @@ func (service *MessageService) storeSentMessage(ctx context.Context, payload eve
 		UserID:            payload.UserID,
 		Content:           payload.Content,
 		RequestID:         payload.RequestID,
+		CallbackURL:       payload.CallbackURL,
+		CampaignID:        payload.CampaignID,
 		SIM:               payload.SIM,
 		Encrypted:         payload.Encrypted,
 		ScheduledSendTime: payload.ScheduledSendTime,
@@ -978,6 +1775,14 @@ func (service *MessageService) storeSentMessage(ctx context.Context, payload eve
 		UpdatedAt:         time.Now().UTC(),
 		MaxSendAttempts:   payload.MaxSendAttempts,
 		OrderTimestamp:    timestamp,
+		Encoding:          payload.Encoding,
+		Segments:          payload.Segments,
+		Normalizations:    payload.Normalizations,
+		ParentID:          payload.ParentID,
+		PartNumber:        payload.PartNumber,
+		PartCount:         payload.PartCount,
+		ExpiresAt:         payload.ExpiresAt,
+		RetryPolicy:       payload.RetryPolicy,
 	}
 
 	if err := service.repository.Store(ctx, message); err != nil {
@@ -986,6 +1791,20 @@ func (service *MessageService) storeSentMessage(ctx context.Context, payload eve
 	}
 
 	ctxLogger.Info(fmt.Sprintf("message saved with id [%s]", payload.MessageID))
+
+	if message.CampaignID != nil {
+		// the message is stored before reopening so that a concurrent completion of the campaign either sees the message or is reopened
+		reopened, err := service.campaignRepository.Reopen(ctx, message.UserID, *message.CampaignID, time.Now().UTC())
+		if err != nil {
+			msg := fmt.Sprintf("cannot reopen campaign [%s] for message with id [%s]", *message.CampaignID, message.ID)
+			return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+
+		if reopened {
+			ctxLogger.Info(fmt.Sprintf("reopened completed campaign [%s] for message with id [%s]", *message.CampaignID, message.ID))
+		}
+	}
+
 	return message, nil
 }
 
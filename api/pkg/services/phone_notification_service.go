@@ -14,6 +14,7 @@ import (
 	"github.com/NdoleStudio/httpsms/pkg/repositories"
 	"github.com/NdoleStudio/httpsms/pkg/telemetry"
 	"github.com/google/uuid"
+	"github.com/nyaruka/phonenumbers"
 	"github.com/palantir/stacktrace"
 )
 
@@ -24,6 +25,8 @@ type PhoneNotificationService struct {
 	tracer                      telemetry.Tracer
 	phoneNotificationRepository repositories.PhoneNotificationRepository
 	phoneRepository             repositories.PhoneRepository
+	campaignRepository          repositories.CampaignRepository
+	userRepository              repositories.UserRepository
 	messagingClient             *messaging.Client
 	eventDispatcher             *EventDispatcher
 }
@@ -35,6 +38,8 @@ func NewNotificationService(
 	messagingClient *messaging.Client,
 	phoneRepository repositories.PhoneRepository,
 	phoneNotificationRepository repositories.PhoneNotificationRepository,
+	campaignRepository repositories.CampaignRepository,
+	userRepository repositories.UserRepository,
 	dispatcher *EventDispatcher,
 ) (s *PhoneNotificationService) {
 	return &PhoneNotificationService{
@@ -43,6 +48,8 @@ func NewNotificationService(
 		messagingClient:             messagingClient,
 		phoneNotificationRepository: phoneNotificationRepository,
 		phoneRepository:             phoneRepository,
+		campaignRepository:          campaignRepository,
+		userRepository:              userRepository,
 		eventDispatcher:             dispatcher,
 	}
 }
@@ -101,9 +108,12 @@ type PhoneNotificationSendParams struct {
 	UserID              entities.UserID
 	PhoneID             uuid.UUID
 	PhoneNotificationID uuid.UUID
+	Contact             string
 	Source              string
 	ScheduledAt         time.Time
 	MessageID           uuid.UUID
+	CampaignID          *uuid.UUID
+	ExpiresAt           *time.Time
 }
 
 // Send sends a message when a message is sent
@@ -114,15 +124,48 @@ func (service *PhoneNotificationService) Send(ctx context.Context, params *Phone
 	phone, err := service.phoneRepository.LoadByID(ctx, params.UserID, params.PhoneID)
 	if err != nil {
 		msg := fmt.Sprintf("cannot load phone with userID [%s] and phoneID [%s]", params.UserID, params.PhoneID)
-		return service.handleNotificationFailed(ctx, errors.New(msg), params)
+		return service.handleNotificationFailed(ctx, entities.MessageFailureCodePhoneNotFound, errors.New(msg), params)
 	}
 
 	if phone.FcmToken == nil {
 		msg := fmt.Sprintf("phone with id [%s] has no FCM token", phone.ID)
-		return service.handleNotificationFailed(ctx, errors.New(msg), params)
+		return service.handleNotificationFailed(ctx, entities.MessageFailureCodeFcmTokenInvalid, errors.New(msg), params)
 	}
 
-	ttl := phone.MessageExpirationDuration()
+	campaign, err := service.loadCampaign(ctx, params.UserID, params.CampaignID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load campaign for notification [%s]", params.PhoneNotificationID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if campaign != nil && campaign.IsPaused() {
+		ctxLogger.Info(fmt.Sprintf("holding notification [%s] for message [%s] because campaign [%s] is paused", params.PhoneNotificationID, params.MessageID, campaign.ID))
+		service.updateStatus(ctx, params.PhoneNotificationID, entities.PhoneNotificationStatusHeld)
+		return nil
+	}
+
+	if campaign != nil && campaign.IsCanceled() {
+		msg := fmt.Sprintf("the message was not sent because the campaign [%s] has been canceled", campaign.Name)
+		return service.handleNotificationFailed(ctx, entities.MessageFailureCodeCampaignCanceled, errors.New(msg), params)
+	}
+
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now().UTC()) {
+		msg := fmt.Sprintf("the message was not sent because it expired at [%s]", params.ExpiresAt.Format(time.RFC3339))
+		return service.handleNotificationFailed(ctx, entities.MessageFailureCodeExpired, errors.New(msg), params)
+	}
+
+	deferredUntil, err := service.quietHoursEndAt(ctx, phone, params.Contact, time.Now().UTC())
+	if err != nil {
+		msg := fmt.Sprintf("cannot check the quiet hours of phone [%s] for notification [%s]", phone.ID, params.PhoneNotificationID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if deferredUntil != nil {
+		// the notification can be due in the quiet hours when it was delayed by the queue of the phone, a paused campaign or a retry
+		return service.reschedule(ctx, phone, params)
+	}
+
+	ttl := service.messageExpirationDuration(phone, params.ExpiresAt)
 	result, err := service.messagingClient.Send(ctx, &messaging.Message{
 		Data: map[string]string{
 			"KEY_MESSAGE_ID": params.MessageID.String(),
@@ -136,7 +179,7 @@ func (service *PhoneNotificationService) Send(ctx context.Context, params *Phone
 	if err != nil {
 		ctxLogger.Warn(stacktrace.Propagate(err, "cannot send FCM to phone"))
 		msg := fmt.Sprintf("cannot send notification for to your phone [%s]. Reinstall the httpSMS app on your Android phone.", phone.PhoneNumber)
-		return service.handleNotificationFailed(ctx, errors.New(msg), params)
+		return service.handleNotificationFailed(ctx, entities.MessageFailureCodeFcmTokenInvalid, errors.New(msg), params)
 	}
 
 	return service.handleNotificationSent(ctx, phone, result, params)
@@ -144,14 +187,16 @@ func (service *PhoneNotificationService) Send(ctx context.Context, params *Phone
 
 // PhoneNotificationScheduleParams are parameters for sending a notification
 type PhoneNotificationScheduleParams struct {
-	UserID    entities.UserID
-	Owner     string
-	Source    string
-	Encrypted bool
-	Contact   string
-	Content   string
-	SIM       entities.SIM
-	MessageID uuid.UUID
+	UserID     entities.UserID
+	Owner      string
+	Source     string
+	Encrypted  bool
+	Contact    string
+	Content    string
+	SIM        entities.SIM
+	MessageID  uuid.UUID
+	CampaignID *uuid.UUID
+	ExpiresAt  *time.Time
 }
 
 // Schedule a notification to be sent to a phone
@@ -170,15 +215,18 @@ func (service *PhoneNotificationService) Schedule(ctx context.Context, params *P
 	notification := &entities.PhoneNotification{
 		ID:          uuid.New(),
 		MessageID:   params.MessageID,
+		CampaignID:  params.CampaignID,
 		UserID:      params.UserID,
 		PhoneID:     phone.ID,
+		Contact:     params.Contact,
 		Status:      entities.PhoneNotificationStatusPending,
 		ScheduledAt: time.Now().UTC(),
+		ExpiresAt:   params.ExpiresAt,
 		CreatedAt:   time.Now().UTC(),
 		UpdatedAt:   time.Now().UTC(),
 	}
 
-	if err = service.phoneNotificationRepository.Schedule(ctx, phone.MessagesPerMinute, notification); err != nil {
+	if err = service.schedule(ctx, phone, notification); err != nil {
 		msg := fmt.Sprintf("cannot schedule notification for message [%s] to phone [%s]", params.MessageID, phone.ID)
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
@@ -195,13 +243,268 @@ func (service *PhoneNotificationService) Schedule(ctx context.Context, params *P
 	return nil
 }
 
+// PhoneNotificationEstimateParams are parameters for estimating when a message will be sent to a phone
+type PhoneNotificationEstimateParams struct {
+	UserID  entities.UserID
+	Owner   string
+	Contact string
+	SendAt  *time.Time
+
+	// Position is the number of messages which will be scheduled on the phone before this message
+	Position uint
+}
+
+// EstimateScheduledAt estimates when a notification will be sent to the phone using the quiet hours and the queue of the phone without scheduling it
+func (service *PhoneNotificationService) EstimateScheduledAt(ctx context.Context, params *PhoneNotificationEstimateParams) (time.Time, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	scheduledAt := time.Now().UTC()
+	if params.SendAt != nil && params.SendAt.After(scheduledAt) {
+		scheduledAt = params.SendAt.UTC()
+	}
+
+	phone, err := service.phoneRepository.Load(ctx, params.UserID, params.Owner)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load phone with userID [%s] and phone [%s]", params.UserID, params.Owner)
+		return scheduledAt, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	deferredUntil, err := service.quietHoursEndAt(ctx, phone, params.Contact, scheduledAt)
+	if err != nil {
+		msg := fmt.Sprintf("cannot check the quiet hours of phone [%s] for contact [%s]", phone.ID, params.Contact)
+		return scheduledAt, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if deferredUntil != nil {
+		scheduledAt = *deferredUntil
+	}
+
+	if phone.MessagesPerMinute == 0 {
+		return scheduledAt, nil
+	}
+
+	scheduledAt, err = service.phoneNotificationRepository.FreeSlot(ctx, phone.ID, phone.MessagesPerMinute, scheduledAt)
+	if err != nil {
+		msg := fmt.Sprintf("cannot find a free slot in the notifications of phone [%s]", phone.ID)
+		return scheduledAt, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	interval := time.Duration(60/phone.MessagesPerMinute) * time.Second
+	return scheduledAt.Add(time.Duration(params.Position) * interval), nil
+}
+
+// ReleaseHeld schedules the notifications which were held while an entities.Campaign was paused
+func (service *PhoneNotificationService) ReleaseHeld(ctx context.Context, source string, userID entities.UserID, campaignID uuid.UUID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	notifications, err := service.phoneNotificationRepository.FetchHeld(ctx, userID, campaignID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot fetch held notifications for campaign [%s] and user [%s]", campaignID, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	for _, held := range notifications {
+		phone, err := service.phoneRepository.LoadByID(ctx, held.UserID, held.PhoneID)
+		if err != nil {
+			msg := fmt.Sprintf("cannot load phone with userID [%s] and phoneID [%s]", held.UserID, held.PhoneID)
+			return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+
+		notification := &entities.PhoneNotification{
+			ID:          uuid.New(),
+			MessageID:   held.MessageID,
+			CampaignID:  held.CampaignID,
+			UserID:      held.UserID,
+			PhoneID:     held.PhoneID,
+			Contact:     held.Contact,
+			Status:      entities.PhoneNotificationStatusPending,
+			ScheduledAt: time.Now().UTC(),
+			ExpiresAt:   held.ExpiresAt,
+			CreatedAt:   time.Now().UTC(),
+			UpdatedAt:   time.Now().UTC(),
+		}
+
+		if err = service.schedule(ctx, phone, notification); err != nil {
+			msg := fmt.Sprintf("cannot schedule notification for message [%s] to phone [%s]", held.MessageID, phone.ID)
+			return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+
+		service.updateStatus(ctx, held.ID, entities.PhoneNotificationStatusReleased)
+
+		if err = service.dispatchMessageNotificationSend(ctx, source, notification); err != nil {
+			return service.tracer.WrapErrorSpan(span, err)
+		}
+	}
+
+	ctxLogger.Info(fmt.Sprintf("released [%d] held notifications for campaign [%s] and user [%s]", len(notifications), campaignID, userID))
+	return nil
+}
+
+// FailHeld fails the notifications which were held when an entities.Campaign is canceled
+func (service *PhoneNotificationService) FailHeld(ctx context.Context, source string, userID entities.UserID, campaignID uuid.UUID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	notifications, err := service.phoneNotificationRepository.FetchHeld(ctx, userID, campaignID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot fetch held notifications for campaign [%s] and user [%s]", campaignID, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	for _, held := range notifications {
+		params := &PhoneNotificationSendParams{
+			UserID:              held.UserID,
+			PhoneID:             held.PhoneID,
+			PhoneNotificationID: held.ID,
+			Source:              source,
+			ScheduledAt:         held.ScheduledAt,
+			MessageID:           held.MessageID,
+			CampaignID:          held.CampaignID,
+			ExpiresAt:           held.ExpiresAt,
+		}
+		if err = service.handleNotificationFailed(ctx, entities.MessageFailureCodeCampaignCanceled, errors.New("the message was not sent because the campaign has been canceled"), params); err != nil {
+			return service.tracer.WrapErrorSpan(span, err)
+		}
+	}
+
+	ctxLogger.Info(fmt.Sprintf("failed [%d] held notifications for campaign [%s] and user [%s]", len(notifications), campaignID, userID))
+	return nil
+}
+
+// reschedule replaces a notification which is due in the quiet hours of the phone with a notification after the quiet hours
+func (service *PhoneNotificationService) reschedule(ctx context.Context, phone *entities.Phone, params *PhoneNotificationSendParams) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	notification := &entities.PhoneNotification{
+		ID:          uuid.New(),
+		MessageID:   params.MessageID,
+		CampaignID:  params.CampaignID,
+		UserID:      params.UserID,
+		PhoneID:     phone.ID,
+		Contact:     params.Contact,
+		Status:      entities.PhoneNotificationStatusPending,
+		ScheduledAt: time.Now().UTC(),
+		ExpiresAt:   params.ExpiresAt,
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+	}
+
+	if err := service.schedule(ctx, phone, notification); err != nil {
+		msg := fmt.Sprintf("cannot reschedule notification [%s] for message [%s] to phone [%s]", params.PhoneNotificationID, params.MessageID, phone.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	service.updateStatus(ctx, params.PhoneNotificationID, entities.PhoneNotificationStatusDeferred)
+
+	if err := service.dispatchMessageNotificationSend(ctx, params.Source, notification); err != nil {
+		return service.tracer.WrapErrorSpan(span, err)
+	}
+
+	ctxLogger.Info(fmt.Sprintf("rescheduled notification [%s] for message [%s] as [%s] at [%s]", params.PhoneNotificationID, params.MessageID, notification.ID, notification.ScheduledAt))
+	return nil
+}
+
+// schedule defers the notification until the end of the quiet hours of the phone and stores it in the next free slot of the phone
+func (service *PhoneNotificationService) schedule(ctx context.Context, phone *entities.Phone, notification *entities.PhoneNotification) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	deferredUntil, err := service.quietHoursEndAt(ctx, phone, notification.Contact, notification.ScheduledAt)
+	if err != nil {
+		msg := fmt.Sprintf("cannot check the quiet hours of phone [%s] for message [%s]", phone.ID, notification.MessageID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if deferredUntil != nil {
+		ctxLogger.Info(fmt.Sprintf("message with id [%s] is deferred until [%s] because of the quiet hours of phone [%s]", notification.MessageID, deferredUntil, phone.ID))
+		notification.DeferredUntil = deferredUntil
+		notification.ScheduledAt = *deferredUntil
+	}
+
+	if err = service.phoneNotificationRepository.Schedule(ctx, phone.MessagesPerMinute, notification); err != nil {
+		msg := fmt.Sprintf("cannot store notification for message [%s] to phone [%s]", notification.MessageID, phone.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+// quietHoursEndAt returns the end of the quiet hours of the phone when a message to the contact must be deferred.
+// When the recipient timezone is used and the phone number has more than one timezone, the message is deferred until the quiet hours end in all of them.
+func (service *PhoneNotificationService) quietHoursEndAt(ctx context.Context, phone *entities.Phone, contact string, timestamp time.Time) (*time.Time, error) {
+	if !phone.HasQuietHours() {
+		return nil, nil
+	}
+
+	locations := service.recipientLocations(phone, contact)
+	if len(locations) == 0 {
+		user, err := service.userRepository.Load(ctx, phone.UserID)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, fmt.Sprintf("cannot load user with ID [%s]", phone.UserID))
+		}
+		locations = append(locations, user.Location())
+	}
+
+	var result *time.Time
+	for _, location := range locations {
+		if end := phone.QuietHoursEndAt(timestamp.In(location)); end != nil && (result == nil || end.After(*result)) {
+			utc := end.UTC()
+			result = &utc
+		}
+	}
+	return result, nil
+}
+
+// recipientLocations returns the timezones of the contact phone number when the quiet hours use the timezone of the recipient
+func (service *PhoneNotificationService) recipientLocations(phone *entities.Phone, contact string) []*time.Location {
+	if !phone.QuietHoursRecipientTimezone {
+		return nil
+	}
+
+	number, err := phonenumbers.Parse(contact, phonenumbers.UNKNOWN_REGION)
+	if err != nil {
+		return nil
+	}
+
+	timezones, err := phonenumbers.GetTimezonesForNumber(number)
+	if err != nil {
+		return nil
+	}
+
+	var locations []*time.Location
+	for _, timezone := range timezones {
+		if location, err := time.LoadLocation(timezone); err == nil {
+			locations = append(locations, location)
+		}
+	}
+	return locations
+}
+
+func (service *PhoneNotificationService) loadCampaign(ctx context.Context, userID entities.UserID, campaignID *uuid.UUID) (*entities.Campaign, error) {
+	if campaignID == nil {
+		return nil, nil
+	}
+
+	campaign, err := service.campaignRepository.Load(ctx, userID, *campaignID)
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return nil, nil
+	}
+	return campaign, err
+}
+
 func (service *PhoneNotificationService) dispatchMessageNotificationSend(ctx context.Context, source string, notification *entities.PhoneNotification) error {
 	event, err := service.createMessageNotificationSendEvent(source, &events.MessageNotificationSendPayload{
 		MessageID:      notification.MessageID,
+		CampaignID:     notification.CampaignID,
 		UserID:         notification.UserID,
 		PhoneID:        notification.PhoneID,
+		Contact:        notification.Contact,
 		ScheduledAt:    notification.ScheduledAt,
 		NotificationID: notification.ID,
+		ExpiresAt:      notification.ExpiresAt,
 	})
 	if err != nil {
 		return stacktrace.Propagate(err, fmt.Sprintf("cannot create [%s] event for notification [%s]", events.EventTypeMessageNotificationSend, notification.ID))
@@ -225,6 +528,7 @@ func (service *PhoneNotificationService) dispatchMessageNotificationScheduled(ct
 		PhoneID:        notification.PhoneID,
 		ScheduledAt:    notification.ScheduledAt,
 		NotificationID: notification.ID,
+		DeferredUntil:  notification.DeferredUntil,
 	})
 	if err != nil {
 		return stacktrace.Propagate(err, fmt.Sprintf("cannot create [%s] event for notification [%s]", events.EventTypeMessageNotificationScheduled, notification.ID))
@@ -236,7 +540,7 @@ func (service *PhoneNotificationService) dispatchMessageNotificationScheduled(ct
 	return nil
 }
 
-func (service *PhoneNotificationService) handleNotificationFailed(ctx context.Context, err error, params *PhoneNotificationSendParams) error {
+func (service *PhoneNotificationService) handleNotificationFailed(ctx context.Context, failureCode entities.MessageFailureCode, err error, params *PhoneNotificationSendParams) error {
 	ctx, span := service.tracer.Start(ctx)
 	defer span.End()
 
@@ -245,7 +549,7 @@ func (service *PhoneNotificationService) handleNotificationFailed(ctx context.Co
 	msg := fmt.Sprintf("cannot send notification for message [%s] to phone [%s]", params.MessageID, params.PhoneNotificationID)
 	ctxLogger.Warn(stacktrace.Propagate(err, msg))
 
-	event, err := service.createMessageNotificationFailedEvent(params.Source, err.Error(), params)
+	event, err := service.createMessageNotificationFailedEvent(params.Source, failureCode, err.Error(), params)
 	if err != nil {
 		return stacktrace.Propagate(err, fmt.Sprintf("cannot create [%s] event for notification [%s]", events.EventTypeMessageNotificationFailed, params.PhoneNotificationID))
 	}
@@ -279,6 +583,14 @@ func (service *PhoneNotificationService) handleNotificationSent(ctx context.Cont
 	return nil
 }
 
+// messageExpirationDuration returns the time left until the message expires using the expiration of the phone when the message has no expiry
+func (service *PhoneNotificationService) messageExpirationDuration(phone *entities.Phone, expiresAt *time.Time) time.Duration {
+	if expiresAt == nil {
+		return phone.MessageExpirationDuration()
+	}
+	return expiresAt.Sub(time.Now().UTC())
+}
+
 func (service *PhoneNotificationService) createMessageNotificationScheduledEvent(source string, payload *events.MessageNotificationScheduledPayload) (cloudevents.Event, error) {
 	return service.createEvent(events.EventTypeMessageNotificationScheduled, source, payload)
 }
@@ -300,7 +612,7 @@ func (service *PhoneNotificationService) createMessageNotificationSentEvent(sour
 		UserID:                    params.UserID,
 		PhoneID:                   params.PhoneID,
 		ScheduledAt:               params.ScheduledAt,
-		MessageExpirationDuration: phone.MessageExpirationDuration(),
+		MessageExpirationDuration: service.messageExpirationDuration(phone, params.ExpiresAt),
 		FcmMessageID:              fcmMessageID,
 		NotificationSentAt:        time.Now().UTC(),
 		NotificationID:            params.PhoneNotificationID,
@@ -314,7 +626,7 @@ func (service *PhoneNotificationService) createMessageNotificationSentEvent(sour
 	return event, nil
 }
 
-func (service *PhoneNotificationService) createMessageNotificationFailedEvent(source string, errorMessage string, params *PhoneNotificationSendParams) (cloudevents.Event, error) {
+func (service *PhoneNotificationService) createMessageNotificationFailedEvent(source string, failureCode entities.MessageFailureCode, errorMessage string, params *PhoneNotificationSendParams) (cloudevents.Event, error) {
 	event := cloudevents.NewEvent()
 
 	event.SetSource(source)
@@ -327,6 +639,7 @@ func (service *PhoneNotificationService) createMessageNotificationFailedEvent(so
 		UserID:               params.UserID,
 		PhoneID:              params.PhoneID,
 		ErrorMessage:         errorMessage,
+		FailureCode:          failureCode,
 		NotificationFailedAt: time.Now().UTC(),
 		NotificationID:       params.PhoneNotificationID,
 	}
@@ -83,16 +83,28 @@ func (service *PhoneService) Load(ctx context.Context, userID entities.UserID, o
 
 // PhoneUpsertParams are parameters for creating a new entities.Phone
 type PhoneUpsertParams struct {
-	PhoneNumber               *phonenumbers.PhoneNumber
-	FcmToken                  *string
-	MessagesPerMinute         *uint
-	MaxSendAttempts           *uint
-	WebhookURL                *string
-	MessageExpirationDuration *time.Duration
-	MissedCallAutoReply       *string
-	SIM                       entities.SIM
-	Source                    string
-	UserID                    entities.UserID
+	PhoneNumber                 *phonenumbers.PhoneNumber
+	FcmToken                    *string
+	MessagesPerMinute           *uint
+	MaxSendAttempts             *uint
+	WebhookURL                  *string
+	MessageExpirationDuration   *time.Duration
+	MissedCallAutoReply         *string
+	OptOutKeywords              []string
+	OptInKeywords               []string
+	OptOutReply                 *string
+	OptInReply                  *string
+	BusinessHours               []entities.BusinessHours
+	Holidays                    []string
+	OutOfOfficeReply            *string
+	NormalizeGSM7               *bool
+	RetryPolicy                 *entities.RetryPolicy
+	QuietHoursStart             *string
+	QuietHoursEnd               *string
+	QuietHoursRecipientTimezone *bool
+	SIM                         entities.SIM
+	Source                      string
+	UserID                      entities.UserID
 }
 
 // Upsert a new entities.Phone
@@ -199,6 +211,8 @@ func (service *PhoneService) createPhone(ctx context.Context, params *PhoneUpser
 		MaxSendAttempts:          2,
 		SIM:                      params.SIM,
 		MissedCallAutoReply:      nil,
+		OptOutKeywords:           entities.DefaultOptOutKeywords,
+		OptInKeywords:            entities.DefaultOptInKeywords,
 		PhoneNumber:              phonenumbers.Format(params.PhoneNumber, phonenumbers.E164),
 		CreatedAt:                time.Now().UTC(),
 		UpdatedAt:                time.Now().UTC(),
@@ -241,6 +255,57 @@ func (service *PhoneService) update(phone *entities.Phone, params *PhoneUpsertPa
 		phone.MissedCallAutoReply = params.MissedCallAutoReply
 	}
 
+	if params.OptOutKeywords != nil {
+		phone.OptOutKeywords = params.OptOutKeywords
+	}
+
+	if params.OptInKeywords != nil {
+		phone.OptInKeywords = params.OptInKeywords
+	}
+
+	if params.OptOutReply != nil {
+		phone.OptOutReply = params.OptOutReply
+	}
+
+	if params.OptInReply != nil {
+		phone.OptInReply = params.OptInReply
+	}
+
+	if params.BusinessHours != nil {
+		phone.BusinessHours = params.BusinessHours
+	}
+
+	if params.Holidays != nil {
+		phone.Holidays = params.Holidays
+	}
+
+	if params.OutOfOfficeReply != nil {
+		phone.OutOfOfficeReply = params.OutOfOfficeReply
+	}
+
+	if params.NormalizeGSM7 != nil {
+		phone.NormalizeGSM7 = *params.NormalizeGSM7
+	}
+
+	if params.RetryPolicy != nil {
+		phone.RetryPolicy = params.RetryPolicy
+		if params.RetryPolicy.MaxAttempts == 0 {
+			phone.RetryPolicy = nil
+		}
+	}
+
+	if params.QuietHoursStart != nil {
+		phone.QuietHoursStart = params.QuietHoursStart
+	}
+
+	if params.QuietHoursEnd != nil {
+		phone.QuietHoursEnd = params.QuietHoursEnd
+	}
+
+	if params.QuietHoursRecipientTimezone != nil {
+		phone.QuietHoursRecipientTimezone = *params.QuietHoursRecipientTimezone
+	}
+
 	phone.SIM = params.SIM
 
 	return phone
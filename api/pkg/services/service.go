@@ -13,6 +13,21 @@ import (
 	"github.com/palantir/stacktrace"
 )
 
+// ErrCodeContactSuppressed is thrown when sending a message to a contact which is on the suppression list
+const ErrCodeContactSuppressed = stacktrace.ErrorCode(1001)
+
+// ErrCodeDuplicateMessage is thrown when the same content is sent to the same contact within the duplicate message window of the user
+const ErrCodeDuplicateMessage = stacktrace.ErrorCode(1004)
+
+// ErrCodeContactRateLimited is thrown when the number of messages sent to a contact exceeds the contact rate limit of the user
+const ErrCodeContactRateLimited = stacktrace.ErrorCode(1005)
+
+// ErrCodeWebhookPingFailed is thrown when a webhook does not accept a test ping
+const ErrCodeWebhookPingFailed = stacktrace.ErrorCode(1006)
+
+// ErrCodeCampaignStatusChanged is thrown when the status of a campaign was changed concurrently
+const ErrCodeCampaignStatusChanged = stacktrace.ErrorCode(1007)
+
 type service struct{}
 
 func (service *service) createEvent(eventType string, source string, payload any) (cloudevents.Event, error) {
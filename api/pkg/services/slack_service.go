@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/slack"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+const (
+	// SlackActionReply is the action ID of the "Reply" button on a received message
+	SlackActionReply = "reply"
+
+	// SlackViewReply is the callback ID of the modal used to reply to a received message
+	SlackViewReply = "reply"
+
+	// SlackInputContent is the block and action ID of the content input in the reply modal
+	SlackInputContent = "content"
+)
+
+// SlackReply contains the phone numbers of a received message which is replied from slack
+type SlackReply struct {
+	Owner     string `json:"owner"`
+	Contact   string `json:"contact"`
+	ChannelID string `json:"channel_id,omitempty"`
+	ThreadTS  string `json:"thread_ts,omitempty"`
+}
+
+// SlackService is responsible for handling slack integrations
+type SlackService struct {
+	service
+	logger     telemetry.Logger
+	tracer     telemetry.Tracer
+	client     *slack.Client
+	repository repositories.SlackRepository
+}
+
+// NewSlackService creates a new SlackService
+func NewSlackService(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	client *slack.Client,
+	repository repositories.SlackRepository,
+) (s *SlackService) {
+	return &SlackService{
+		logger:     logger.WithService(fmt.Sprintf("%T", s)),
+		tracer:     tracer,
+		client:     client,
+		repository: repository,
+	}
+}
+
+// GetByTeamID fetches the entities.Slack by the ID of the slack workspace
+func (service *SlackService) GetByTeamID(ctx context.Context, teamID string) (*entities.Slack, error) {
+	ctx, span, _ := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+	return service.repository.FindByTeamID(ctx, teamID)
+}
+
+// DeleteAllForUser deletes all entities.Slack for an entities.UserID.
+func (service *SlackService) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.DeleteAllForUser(ctx, userID); err != nil {
+		msg := fmt.Sprintf("could not delete all [entities.Slack] for user with ID [%s]", userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted all [entities.Slack] for user with ID [%s]", userID))
+	return nil
+}
+
+// Index fetches the entities.Slack for an entities.UserID
+func (service *SlackService) Index(ctx context.Context, userID entities.UserID, params repositories.IndexParams) ([]*entities.Slack, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	slackIntegrations, err := service.repository.Index(ctx, userID, params)
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch slack integrations with params [%+#v]", params)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("fetched [%d] slack integrations with prams [%+#v]", len(slackIntegrations), params))
+	return slackIntegrations, nil
+}
+
+// Delete an entities.Slack
+func (service *SlackService) Delete(ctx context.Context, userID entities.UserID, slackID uuid.UUID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if _, err := service.repository.Load(ctx, userID, slackID); err != nil {
+		msg := fmt.Sprintf("cannot load slack integration with userID [%s] and slackID [%s]", userID, slackID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	if err := service.repository.Delete(ctx, userID, slackID); err != nil {
+		msg := fmt.Sprintf("cannot delete slack integration with id [%s] and user id [%s]", slackID, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted slack integration with id [%s] and user id [%s]", slackID, userID))
+	return nil
+}
+
+// SlackStoreParams are parameters for creating a new entities.Slack
+type SlackStoreParams struct {
+	UserID            entities.UserID
+	Name              string
+	BotToken          string
+	IncomingChannelID string
+}
+
+// Store a new entities.Slack
+func (service *SlackService) Store(ctx context.Context, params *SlackStoreParams) (*entities.Slack, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	teamID, err := service.getTeamID(ctx, params.BotToken)
+	if err != nil {
+		msg := fmt.Sprintf("cannot get the slack workspace of the bot token for user [%s]", params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	slackIntegration := &entities.Slack{
+		ID:                uuid.New(),
+		UserID:            params.UserID,
+		Name:              params.Name,
+		TeamID:            teamID,
+		IncomingChannelID: params.IncomingChannelID,
+		BotToken:          params.BotToken,
+		CreatedAt:         time.Now().UTC(),
+		UpdatedAt:         time.Now().UTC(),
+	}
+
+	if err = service.repository.Save(ctx, slackIntegration); err != nil {
+		msg := fmt.Sprintf("cannot save slack integration with id [%s]", slackIntegration.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("slack integration saved with id [%s] in the [%T]", slackIntegration.ID, service.repository))
+	return slackIntegration, nil
+}
+
+// SlackUpdateParams are parameters for updating an entities.Slack
+type SlackUpdateParams struct {
+	UserID            entities.UserID
+	Name              string
+	BotToken          string
+	IncomingChannelID string
+	SlackID           uuid.UUID
+}
+
+// Update an entities.Slack
+func (service *SlackService) Update(ctx context.Context, params *SlackUpdateParams) (*entities.Slack, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	slackIntegration, err := service.repository.Load(ctx, params.UserID, params.SlackID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load slack integration with userID [%s] and slackID [%s]", params.UserID, params.SlackID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	teamID, err := service.getTeamID(ctx, params.BotToken)
+	if err != nil {
+		msg := fmt.Sprintf("cannot get the slack workspace of the bot token for user [%s]", params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	slackIntegration.Name = params.Name
+	slackIntegration.TeamID = teamID
+	slackIntegration.BotToken = params.BotToken
+	slackIntegration.IncomingChannelID = params.IncomingChannelID
+	slackIntegration.UpdatedAt = time.Now().UTC()
+
+	if err = service.repository.Save(ctx, slackIntegration); err != nil {
+		msg := fmt.Sprintf("cannot save slack integration with id [%s] after update", slackIntegration.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("slack integration updated with id [%s] in the [%T]", slackIntegration.ID, service.repository))
+	return slackIntegration, nil
+}
+
+func (service *SlackService) getTeamID(ctx context.Context, botToken string) (string, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	result, _, err := service.client.Auth.Test(ctx, botToken)
+	if err != nil {
+		return "", service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, "cannot test the slack bot token"))
+	}
+
+	return result.TeamID, nil
+}
+
+// HandleMessageReceived sends an incoming SMS to a slack channel
+func (service *SlackService) HandleMessageReceived(ctx context.Context, userID entities.UserID, event cloudevents.Event) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	slackIntegrations, err := service.repository.FetchHavingIncomingChannel(ctx, userID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load slack integrations for user with ID [%s]", userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	if len(slackIntegrations) == 0 {
+		ctxLogger.Info(fmt.Sprintf("user [%s] has no slack integration for event [%s]", userID, event.Type()))
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for _, slackIntegration := range slackIntegrations {
+		wg.Add(1)
+		go func(integration *entities.Slack) {
+			defer wg.Done()
+			service.sendMessage(ctx, event, integration)
+		}(slackIntegration)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// OpenReplyModal opens the modal for replying to a received message
+func (service *SlackService) OpenReplyModal(ctx context.Context, integration *entities.Slack, triggerID string, reply *SlackReply) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	metadata, err := json.Marshal(reply)
+	if err != nil {
+		msg := fmt.Sprintf("cannot marshal [%T] for slack integration [%s]", reply, integration.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	view := fiber.Map{
+		"type":             "modal",
+		"callback_id":      SlackViewReply,
+		"private_metadata": string(metadata),
+		"title":            fiber.Map{"type": "plain_text", "text": "Reply via httpSMS"},
+		"submit":           fiber.Map{"type": "plain_text", "text": "Send"},
+		"close":            fiber.Map{"type": "plain_text", "text": "Cancel"},
+		"blocks": []fiber.Map{
+			{
+				"type": "section",
+				"fields": []fiber.Map{
+					{"type": "mrkdwn", "text": "*From:*\n" + service.getFormattedNumber(ctxLogger, reply.Owner)},
+					{"type": "mrkdwn", "text": "*To:*\n" + service.getFormattedNumber(ctxLogger, reply.Contact)},
+				},
+			},
+			{
+				"type":     "input",
+				"block_id": SlackInputContent,
+				"label":    fiber.Map{"type": "plain_text", "text": "Message"},
+				"element": fiber.Map{
+					"type":       "plain_text_input",
+					"action_id":  SlackInputContent,
+					"multiline":  true,
+					"max_length": 1024,
+				},
+			},
+		},
+	}
+
+	if _, _, err = service.client.Views.Open(ctx, integration.BotToken, triggerID, view); err != nil {
+		msg := fmt.Sprintf("cannot open reply modal in slack workspace [%s] for integration [%s]", integration.TeamID, integration.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
+
+// SendReplyConfirmation adds a reply in the thread of the received message after the SMS reply is sent
+func (service *SlackService) SendReplyConfirmation(ctx context.Context, integration *entities.Slack, reply *SlackReply, message *entities.Message) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if reply.ChannelID == "" {
+		return
+	}
+
+	payload := fiber.Map{
+		"channel":   reply.ChannelID,
+		"thread_ts": reply.ThreadTS,
+		"text":      "✔ sending sms",
+		"blocks": []fiber.Map{
+			{
+				"type": "section",
+				"text": fiber.Map{"type": "mrkdwn", "text": "*✔ sending sms*"},
+			},
+			{
+				"type": "section",
+				"text": fiber.Map{"type": "plain_text", "text": message.Content},
+			},
+			{
+				"type":     "context",
+				"elements": []fiber.Map{{"type": "mrkdwn", "text": "MessageID: " + message.ID.String()}},
+			},
+		},
+	}
+
+	if _, _, err := service.client.Chat.PostMessage(ctx, integration.BotToken, payload); err != nil {
+		msg := fmt.Sprintf("cannot send reply confirmation to slack channel [%s] for message [%s]", reply.ChannelID, message.ID)
+		ctxLogger.Warn(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+	}
+}
+
+func (service *SlackService) sendMessage(ctx context.Context, event cloudevents.Event, integration *entities.Slack) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	payload := new(events.MessagePhoneReceivedPayload)
+	if err := event.DataAs(payload); err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot unmarshal event [%s] with ID [%s] into [%T]", event.Type(), event.ID(), payload)))
+		return
+	}
+
+	request, err := service.createSlackMessage(ctxLogger, integration, payload)
+	if err != nil {
+		msg := fmt.Sprintf("cannot create slack message for [%s] event with ID [%s]", event.Type(), event.ID())
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return
+	}
+
+	message, _, err := service.client.Chat.PostMessage(ctx, integration.BotToken, request)
+	if err != nil {
+		msg := fmt.Sprintf("cannot send [%s] event to slack channel [%s] for user [%s]", event.Type(), integration.IncomingChannelID, integration.UserID)
+		ctxLogger.Warn(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return
+	}
+
+	ctxLogger.Info(fmt.Sprintf("sent slack message [%v] to channel [%s] for [%s] event with ID [%s]", message["ts"], integration.IncomingChannelID, event.Type(), event.ID()))
+}
+
+func (service *SlackService) createSlackMessage(ctxLogger telemetry.Logger, integration *entities.Slack, payload *events.MessagePhoneReceivedPayload) (fiber.Map, error) {
+	reply, err := json.Marshal(&SlackReply{Owner: payload.Owner, Contact: payload.Contact})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, fmt.Sprintf("cannot marshal [%T] for message [%s]", reply, payload.MessageID))
+	}
+
+	content := payload.Content
+	if content == "" {
+		content = "(empty message)"
+	}
+
+	return fiber.Map{
+		"channel": integration.IncomingChannelID,
+		"text":    "✉ new message received",
+		"blocks": []fiber.Map{
+			{
+				"type": "section",
+				"text": fiber.Map{"type": "mrkdwn", "text": "*✉ new message received*"},
+			},
+			{
+				"type": "section",
+				"fields": []fiber.Map{
+					{"type": "mrkdwn", "text": "*From:*\n" + service.getFormattedNumber(ctxLogger, payload.Contact)},
+					{"type": "mrkdwn", "text": "*To:*\n" + service.getFormattedNumber(ctxLogger, payload.Owner)},
+				},
+			},
+			{
+				"type": "section",
+				"text": fiber.Map{"type": "plain_text", "text": content},
+			},
+			{
+				"type":     "context",
+				"elements": []fiber.Map{{"type": "mrkdwn", "text": "MessageID: " + payload.MessageID.String()}},
+			},
+			{
+				"type": "actions",
+				"elements": []fiber.Map{
+					{
+						"type":      "button",
+						"action_id": SlackActionReply,
+						"text":      fiber.Map{"type": "plain_text", "text": "Reply"},
+						"value":     string(reply),
+					},
+				},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
+)
+
+// SuppressionService is responsible for managing entities.Suppression
+type SuppressionService struct {
+	service
+	logger         telemetry.Logger
+	tracer         telemetry.Tracer
+	repository     repositories.SuppressionRepository
+	phoneService   *PhoneService
+	messageService *MessageService
+}
+
+// NewSuppressionService creates a new SuppressionService
+func NewSuppressionService(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	repository repositories.SuppressionRepository,
+	phoneService *PhoneService,
+	messageService *MessageService,
+) (s *SuppressionService) {
+	return &SuppressionService{
+		logger:         logger.WithService(fmt.Sprintf("%T", s)),
+		tracer:         tracer,
+		repository:     repository,
+		phoneService:   phoneService,
+		messageService: messageService,
+	}
+}
+
+// DeleteAllForUser deletes all entities.Suppression for an entities.UserID.
+func (service *SuppressionService) DeleteAllForUser(ctx context.Context, userID entities.UserID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.DeleteAllForUser(ctx, userID); err != nil {
+		msg := fmt.Sprintf("could not delete all [entities.Suppression] for user with ID [%s]", userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted all [entities.Suppression] for user with ID [%s]", userID))
+	return nil
+}
+
+// Index fetches the entities.Suppression for an entities.UserID
+func (service *SuppressionService) Index(ctx context.Context, userID entities.UserID, params repositories.IndexParams) ([]*entities.Suppression, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	suppressions, err := service.repository.Index(ctx, userID, params)
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch suppressions with params [%+#v]", params)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("fetched [%d] suppressions with prams [%+#v]", len(suppressions), params))
+	return suppressions, nil
+}
+
+// IsSuppressed checks if a contact is on the suppression list of a user
+func (service *SuppressionService) IsSuppressed(ctx context.Context, userID entities.UserID, contact string) (bool, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	_, err := service.repository.LoadByContact(ctx, userID, contact)
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return false, nil
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("could not load suppression for contact [%s] and user [%s]", contact, userID)
+		return false, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return true, nil
+}
+
+// FilterSuppressed returns the contacts which are on the suppression list of a user
+func (service *SuppressionService) FilterSuppressed(ctx context.Context, userID entities.UserID, contacts []string) ([]string, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	suppressed, err := service.repository.FilterContacts(ctx, userID, contacts)
+	if err != nil {
+		msg := fmt.Sprintf("could not filter [%d] suppressed contacts for user [%s]", len(contacts), userID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return suppressed, nil
+}
+
+// SuppressionStoreParams are parameters for creating a new entities.Suppression
+type SuppressionStoreParams struct {
+	UserID  entities.UserID
+	Contact string
+	Owner   *string
+	Keyword *string
+}
+
+// Store a new entities.Suppression
+func (service *SuppressionService) Store(ctx context.Context, params *SuppressionStoreParams) (*entities.Suppression, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	suppression := &entities.Suppression{
+		ID:        uuid.New(),
+		UserID:    params.UserID,
+		Contact:   params.Contact,
+		Owner:     params.Owner,
+		Keyword:   params.Keyword,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	if err := service.repository.Store(ctx, suppression); err != nil {
+		msg := fmt.Sprintf("cannot store suppression for contact [%s] and user [%s]", params.Contact, params.UserID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("contact [%s] added to the suppression list of user [%s]", suppression.Contact, suppression.UserID))
+	return suppression, nil
+}
+
+// Delete an entities.Suppression
+func (service *SuppressionService) Delete(ctx context.Context, userID entities.UserID, suppressionID uuid.UUID) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.Delete(ctx, userID, suppressionID); err != nil {
+		msg := fmt.Sprintf("cannot delete suppression with ID [%s] for user [%s]", suppressionID, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("deleted suppression with ID [%s] for user [%s]", suppressionID, userID))
+	return nil
+}
+
+// HandleMessageReceived updates the suppression list when an inbound message is an opt-out or opt-in keyword
+func (service *SuppressionService) HandleMessageReceived(ctx context.Context, source string, payload *events.MessagePhoneReceivedPayload) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if payload.Encrypted {
+		ctxLogger.Info(fmt.Sprintf("message [%s] for user [%s] is encrypted and cannot be matched with a keyword", payload.MessageID, payload.UserID))
+		return nil
+	}
+
+	phone, err := service.phoneService.Load(ctx, payload.UserID, payload.Owner)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load phone with owner [%s] for user [%s] when handling message [%s]", payload.Owner, payload.UserID, payload.MessageID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if phone.IsOptOutKeyword(payload.Content) {
+		return service.optOut(ctx, source, phone, payload)
+	}
+
+	if phone.IsOptInKeyword(payload.Content) {
+		return service.optIn(ctx, source, phone, payload)
+	}
+
+	return nil
+}
+
+func (service *SuppressionService) optOut(ctx context.Context, source string, phone *entities.Phone, payload *events.MessagePhoneReceivedPayload) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	keyword := entities.NormalizeKeyword(payload.Content)
+	_, err := service.Store(ctx, &SuppressionStoreParams{
+		UserID:  payload.UserID,
+		Contact: payload.Contact,
+		Owner:   &payload.Owner,
+		Keyword: &keyword,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot opt-out contact [%s] for message [%s] and user [%s]", payload.Contact, payload.MessageID, payload.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("contact [%s] opted out with keyword [%s] in message [%s] for user [%s]", payload.Contact, keyword, payload.MessageID, payload.UserID))
+	return service.sendConfirmation(ctx, source, phone.OptOutReply, fmt.Sprintf("opt-out-%s", payload.MessageID), payload)
+}
+
+func (service *SuppressionService) optIn(ctx context.Context, source string, phone *entities.Phone, payload *events.MessagePhoneReceivedPayload) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.repository.DeleteByContact(ctx, payload.UserID, payload.Contact); err != nil {
+		msg := fmt.Sprintf("cannot opt-in contact [%s] for message [%s] and user [%s]", payload.Contact, payload.MessageID, payload.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("contact [%s] opted in with message [%s] for user [%s]", payload.Contact, payload.MessageID, payload.UserID))
+	return service.sendConfirmation(ctx, source, phone.OptInReply, fmt.Sprintf("opt-in-%s", payload.MessageID), payload)
+}
+
+func (service *SuppressionService) sendConfirmation(ctx context.Context, source string, reply *string, requestID string, payload *events.MessagePhoneReceivedPayload) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if reply == nil {
+		ctxLogger.Info(fmt.Sprintf("no confirmation reply set for phone [%s] for message [%s] with user [%s]", payload.Owner, payload.MessageID, payload.UserID))
+		return nil
+	}
+
+	_, err := service.messageService.SendSystemMessage(ctx, SystemMessageParams{
+		UserID:               payload.UserID,
+		Owner:                payload.Owner,
+		Contact:              payload.Contact,
+		Content:              *reply,
+		Source:               source,
+		RequestID:            requestID,
+		SkipSuppressionCheck: true,
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot send confirmation reply from [%s] to [%s] for message [%s] and user [%s]", payload.Owner, payload.Contact, payload.MessageID, payload.UserID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	return nil
+}
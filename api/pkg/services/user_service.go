@@ -159,6 +159,76 @@ func (service *UserService) UpdateNotificationSettings(ctx context.Context, user
 	return user, nil
 }
 
+// UserMessageLimitsUpdateParams are parameters for updating the message limits of a user
+type UserMessageLimitsUpdateParams struct {
+	DuplicateMessageWindowSeconds uint
+	DuplicateMessageAction        entities.DuplicateMessageAction
+	ContactRateLimit              uint
+	ContactRateLimitWindowSeconds uint
+}
+
+// UpdateMessageLimits sets the duplicate message protection and the contact rate limit for an entities.User
+func (service *UserService) UpdateMessageLimits(ctx context.Context, userID entities.UserID, params *UserMessageLimitsUpdateParams) (*entities.User, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	user, err := service.repository.Load(ctx, userID)
+	if err != nil {
+		msg := fmt.Sprintf("could not load [%T] with ID [%s]", user, userID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	user.DuplicateMessageWindowSeconds = params.DuplicateMessageWindowSeconds
+	user.DuplicateMessageAction = params.DuplicateMessageAction
+	user.ContactRateLimit = params.ContactRateLimit
+	user.ContactRateLimitWindowSeconds = params.ContactRateLimitWindowSeconds
+
+	if err = service.repository.Update(ctx, user); err != nil {
+		msg := fmt.Sprintf("cannot save user with id [%s] in [%T]", user.ID, service.repository)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("updated message limits for [%T] with ID [%s] in the [%T]", user, user.ID, service.repository))
+	return user, nil
+}
+
+// UserCallbackSigningKeyRotateParams are parameters for rotating the callback signing key of an entities.User
+type UserCallbackSigningKeyRotateParams struct {
+	SigningKey string
+	Overlap    time.Duration
+}
+
+// RotateCallbackSigningKey replaces the key which signs the requests to the callback URL of messages.
+// Requests are signed with both the new and the previous key until the overlap period expires.
+func (service *UserService) RotateCallbackSigningKey(ctx context.Context, userID entities.UserID, params *UserCallbackSigningKeyRotateParams) (*entities.User, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	user, err := service.repository.Load(ctx, userID)
+	if err != nil {
+		msg := fmt.Sprintf("could not load [%T] with ID [%s]", user, userID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	user.PreviousCallbackSigningKey = nil
+	user.PreviousCallbackSigningKeyExpiresAt = nil
+	if params.Overlap > 0 && user.CallbackSigningKey != nil {
+		expiresAt := time.Now().UTC().Add(params.Overlap)
+		user.PreviousCallbackSigningKey = user.CallbackSigningKey
+		user.PreviousCallbackSigningKeyExpiresAt = &expiresAt
+	}
+
+	user.CallbackSigningKey = &params.SigningKey
+
+	if err = service.repository.Update(ctx, user); err != nil {
+		msg := fmt.Sprintf("cannot save user with id [%s] in [%T]", user.ID, service.repository)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("rotated the callback signing key for [%T] with ID [%s] with an overlap of [%s]", user, user.ID, params.Overlap))
+	return user, nil
+}
+
 // RotateAPIKey for an entities.User
 func (service *UserService) RotateAPIKey(ctx context.Context, source string, userID entities.UserID) (*entities.User, error) {
 	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/palantir/stacktrace"
+)
+
+// webhookTemplateFuncs are the functions which can be used in the payload template of a webhook
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(value any) (string, error) {
+		content, err := json.Marshal(value)
+		return string(content), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// WebhookTemplateData is the data which is available in the payload template of a webhook e.g. {{ .Data.content }}
+type WebhookTemplateData struct {
+	ID     string
+	Type   string
+	Source string
+	Time   time.Time
+	Data   map[string]any
+}
+
+// ParseWebhookTemplate checks that the payload template of a webhook is a valid Go text/template
+func ParseWebhookTemplate(payloadTemplate string) (*template.Template, error) {
+	return template.New("webhook").Funcs(webhookTemplateFuncs).Option("missingkey=zero").Parse(payloadTemplate)
+}
+
+// webhookFact is a labelled value of an event which is displayed in chat messages
+type webhookFact struct {
+	Name  string
+	Value string
+}
+
+// webhookFactLabels are the fields of an event payload which are displayed in chat messages in the order in which they are displayed
+var webhookFactLabels = []struct {
+	key   string
+	label string
+}{
+	{"contact", "Contact"},
+	{"owner", "Phone Number"},
+	{"content", "Content"},
+	{"name", "Campaign"},
+	{"error_message", "Error"},
+	{"failure_code", "Failure Code"},
+	{"message_id", "Message ID"},
+	{"id", "Message ID"},
+	{"campaign_id", "Campaign ID"},
+	{"phone_id", "Phone ID"},
+}
+
+func (service *WebhookService) getPayload(ctxLogger telemetry.Logger, event cloudevents.Event, webhook *entities.Webhook) ([]byte, string, error) {
+	switch service.getPayloadFormat(webhook) {
+	case entities.WebhookPayloadFormatDiscord:
+		return service.getJSONPayload(service.getDiscordPayload(ctxLogger, event))
+	case entities.WebhookPayloadFormatSlack:
+		return service.getJSONPayload(service.getSlackPayload(ctxLogger, event))
+	case entities.WebhookPayloadFormatTeams:
+		return service.getJSONPayload(service.getTeamsPayload(ctxLogger, event))
+	case entities.WebhookPayloadFormatForm:
+		return service.getFormPayload(event)
+	case entities.WebhookPayloadFormatTemplate:
+		return service.getTemplatePayload(event, webhook)
+	default:
+		return service.getJSONPayload(event)
+	}
+}
+
+// getPayloadFormat returns the format of the webhook. Discord URLs which were created before payload formats existed use the discord format.
+func (service *WebhookService) getPayloadFormat(webhook *entities.Webhook) entities.WebhookPayloadFormat {
+	if webhook.PayloadFormat != "" && webhook.PayloadFormat != entities.WebhookPayloadFormatCloudEvent {
+		return webhook.PayloadFormat
+	}
+
+	if strings.HasPrefix(webhook.URL, "https://discord.com/api/webhooks/") {
+		return entities.WebhookPayloadFormatDiscord
+	}
+
+	return entities.WebhookPayloadFormatCloudEvent
+}
+
+func (service *WebhookService) getJSONPayload(payload any) ([]byte, string, error) {
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", stacktrace.Propagate(err, fmt.Sprintf("cannot marshal [%T] into JSON", payload))
+	}
+	return content, fiber.MIMEApplicationJSON, nil
+}
+
+func (service *WebhookService) getFormPayload(event cloudevents.Event) ([]byte, string, error) {
+	data, err := service.getEventData(event)
+	if err != nil {
+		return nil, "", stacktrace.Propagate(err, fmt.Sprintf("cannot decode data of event [%s]", event.ID()))
+	}
+
+	values := url.Values{}
+	values.Set("id", event.ID())
+	values.Set("type", event.Type())
+	values.Set("source", event.Source())
+	values.Set("time", event.Time().Format(time.RFC3339Nano))
+
+	for key, value := range data {
+		switch value.(type) {
+		case map[string]any, []any:
+			content, err := json.Marshal(value)
+			if err != nil {
+				return nil, "", stacktrace.Propagate(err, fmt.Sprintf("cannot marshal field [%s] of event [%s]", key, event.ID()))
+			}
+			values.Set(key, string(content))
+		case nil:
+			values.Set(key, "")
+		default:
+			values.Set(key, fmt.Sprint(value))
+		}
+	}
+
+	return []byte(values.Encode()), fiber.MIMEApplicationForm, nil
+}
+
+func (service *WebhookService) getTemplatePayload(event cloudevents.Event, webhook *entities.Webhook) ([]byte, string, error) {
+	if webhook.PayloadTemplate == nil {
+		return nil, "", stacktrace.NewError(fmt.Sprintf("webhook [%s] has no payload template", webhook.ID))
+	}
+
+	tmpl, err := ParseWebhookTemplate(*webhook.PayloadTemplate)
+	if err != nil {
+		return nil, "", stacktrace.Propagate(err, fmt.Sprintf("cannot parse payload template of webhook [%s]", webhook.ID))
+	}
+
+	data, err := service.getEventData(event)
+	if err != nil {
+		return nil, "", stacktrace.Propagate(err, fmt.Sprintf("cannot decode data of event [%s]", event.ID()))
+	}
+
+	payload := new(bytes.Buffer)
+	err = tmpl.Execute(payload, &WebhookTemplateData{
+		ID:     event.ID(),
+		Type:   event.Type(),
+		Source: event.Source(),
+		Time:   event.Time(),
+		Data:   data,
+	})
+	if err != nil {
+		return nil, "", stacktrace.Propagate(err, fmt.Sprintf("cannot execute payload template of webhook [%s] for event [%s]", webhook.ID, event.ID()))
+	}
+
+	return payload.Bytes(), fiber.MIMEApplicationJSON, nil
+}
+
+func (service *WebhookService) getEventData(event cloudevents.Event) (map[string]any, error) {
+	data := map[string]any{}
+	if len(event.Data()) == 0 {
+		return data, nil
+	}
+	if err := event.DataAs(&data); err != nil {
+		return nil, stacktrace.Propagate(err, fmt.Sprintf("cannot unmarshal data of event [%s] into [%T]", event.ID(), data))
+	}
+	return data, nil
+}
+
+// getSummary returns a short description of the event which is used as the title of chat messages
+func (service *WebhookService) getSummary(event cloudevents.Event) string {
+	switch event.Type() {
+	case events.EventTypeMessagePhoneReceived:
+		return "✉ new message received"
+	case events.EventTypeMessagePhoneSent:
+		return "📤 message sent"
+	case events.EventTypeMessagePhoneDelivered:
+		return "✅ message delivered"
+	case events.EventTypeMessageSendFailed:
+		return "❌ message failed"
+	case events.EventTypeMessageSendExpired:
+		return "⌛ message expired"
+	case events.EventTypePhoneHeartbeatOnline:
+		return "🟢 phone is online"
+	case events.EventTypePhoneHeartbeatOffline:
+		return "🔴 phone is offline"
+	case events.MessageCallMissed:
+		return "📞 missed call"
+	case events.EventTypeCampaignCompleted:
+		return "🏁 campaign completed"
+	case events.EventTypeWebhookPing:
+		return "👋 webhook ping from httpSMS"
+	default:
+		return event.Type()
+	}
+}
+
+// getFacts returns the labelled fields of the event which are displayed in chat messages
+func (service *WebhookService) getFacts(ctxLogger telemetry.Logger, event cloudevents.Event) []webhookFact {
+	data, err := service.getEventData(event)
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot get facts of event [%s] with ID [%s]", event.Type(), event.ID())))
+		return nil
+	}
+
+	facts := make([]webhookFact, 0, len(webhookFactLabels))
+	added := map[string]bool{}
+	for _, field := range webhookFactLabels {
+		value, ok := data[field.key].(string)
+		if !ok || value == "" || added[field.label] {
+			continue
+		}
+		if field.key == "contact" || field.key == "owner" {
+			value = service.getFormattedNumber(ctxLogger, value)
+		}
+		facts = append(facts, webhookFact{Name: field.label, Value: value})
+		added[field.label] = true
+	}
+
+	if stats, ok := data["stats"].(map[string]any); ok {
+		keys := make([]string, 0, len(stats))
+		for key := range stats {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			facts = append(facts, webhookFact{Name: strings.ToUpper(key[:1]) + key[1:], Value: fmt.Sprint(stats[key])})
+		}
+	}
+
+	return facts
+}
+
+func (service *WebhookService) getDiscordPayload(ctxLogger telemetry.Logger, event cloudevents.Event) any {
+	if event.Type() == events.EventTypeMessagePhoneReceived {
+		if payload := service.getDiscordMessageReceivedPayload(ctxLogger, event); payload != nil {
+			return payload
+		}
+	}
+
+	fields := make([]fiber.Map, 0)
+	for _, fact := range service.getFacts(ctxLogger, event) {
+		fields = append(fields, fiber.Map{
+			"name":   fact.Name + ":",
+			"value":  fact.Value,
+			"inline": fact.Name != "Content",
+		})
+	}
+
+	return map[string]any{
+		"avatar_url": "https://httpsms.com/avatar.png",
+		"username":   "httpsms.com",
+		"content":    service.getSummary(event),
+		"embeds": []fiber.Map{
+			{
+				"fields": fields,
+			},
+		},
+	}
+}
+
+func (service *WebhookService) getDiscordMessageReceivedPayload(ctxLogger telemetry.Logger, event cloudevents.Event) any {
+	payload := new(events.MessagePhoneReceivedPayload)
+
+	err := event.DataAs(payload)
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot unmarshal event [%s] with ID [%s] into [%T]", event.Type(), event.ID(), payload)))
+		return nil
+	}
+
+	return map[string]any{
+		"avatar_url": "https://httpsms.com/avatar.png",
+		"username":   "httpsms.com",
+		"content":    "✉ new message received",
+		"embeds": []fiber.Map{
+			{
+				"fields": []fiber.Map{
+					{
+						"name":   "From:",
+						"value":  service.getFormattedNumber(ctxLogger, payload.Contact),
+						"inline": true,
+					},
+					{
+						"name":   "To:",
+						"value":  service.getFormattedNumber(ctxLogger, payload.Owner),
+						"inline": true,
+					},
+					{
+						"name":  "Content:",
+						"value": payload.Content,
+					},
+					{
+						"name":  "MessageID:",
+						"value": payload.MessageID,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (service *WebhookService) getSlackPayload(ctxLogger telemetry.Logger, event cloudevents.Event) any {
+	summary := service.getSummary(event)
+	blocks := []fiber.Map{
+		{
+			"type": "section",
+			"text": fiber.Map{"type": "mrkdwn", "text": fmt.Sprintf("*%s*", summary)},
+		},
+	}
+
+	fields := make([]fiber.Map, 0)
+	for _, fact := range service.getFacts(ctxLogger, event) {
+		fields = append(fields, fiber.Map{"type": "mrkdwn", "text": fmt.Sprintf("*%s:*\n%s", fact.Name, fact.Value)})
+	}
+
+	// slack allows a maximum of 10 fields in a section block
+	for len(fields) > 0 {
+		count := min(len(fields), 10)
+		blocks = append(blocks, fiber.Map{"type": "section", "fields": fields[:count]})
+		fields = fields[count:]
+	}
+
+	return fiber.Map{
+		"text":   summary,
+		"blocks": blocks,
+	}
+}
+
+func (service *WebhookService) getTeamsPayload(ctxLogger telemetry.Logger, event cloudevents.Event) any {
+	facts := make([]fiber.Map, 0)
+	for _, fact := range service.getFacts(ctxLogger, event) {
+		facts = append(facts, fiber.Map{"title": fact.Name, "value": fact.Value})
+	}
+
+	return fiber.Map{
+		"type": "message",
+		"attachments": []fiber.Map{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": fiber.Map{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body": []fiber.Map{
+						{
+							"type":   "TextBlock",
+							"text":   service.getSummary(event),
+							"weight": "Bolder",
+							"size":   "Medium",
+							"wrap":   true,
+						},
+						{
+							"type":  "FactSet",
+							"facts": facts,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -3,22 +3,21 @@ package services
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 
-	"github.com/gofiber/fiber/v2"
-
 	"github.com/NdoleStudio/httpsms/pkg/events"
 
 	"github.com/NdoleStudio/httpsms/pkg/entities"
 	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/signature"
 	"github.com/NdoleStudio/httpsms/pkg/telemetry"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/golang-jwt/jwt"
@@ -27,14 +26,40 @@ import (
 	"github.com/palantir/stacktrace"
 )
 
+// webhookTimeout is the maximum duration of a request to a webhook
+const webhookTimeout = 10 * time.Second
+
+// webhookMaxAttempts is the maximum number of times an event is sent to a webhook including the first attempt
+const webhookMaxAttempts = 5
+
+// webhookRetryDelay is the delay before the first retry. The delay is multiplied by webhookRetryBackoffMultiplier after each retry.
+const webhookRetryDelay = 30 * time.Second
+
+// webhookRetryBackoffMultiplier multiplies the delay between retries of a webhook event
+const webhookRetryBackoffMultiplier = 4
+
+// webhookMaxRetryAfter is the longest delay of a retry which is requested by a webhook with the Retry-After header
+const webhookMaxRetryAfter = time.Hour
+
+// webhookDeliveryRetention is the duration for which an entities.WebhookDelivery is kept
+const webhookDeliveryRetention = 30 * 24 * time.Hour
+
+// webhookDeliveryCleanupInterval is the interval between deleting the expired entities.WebhookDelivery
+const webhookDeliveryCleanupInterval = time.Hour
+
+// webhookResponseExcerptLength is the maximum number of bytes of the webhook response which are stored in an entities.WebhookDelivery
+const webhookResponseExcerptLength = 1024
+
 // WebhookService is responsible for handling webhooks
 type WebhookService struct {
 	service
-	logger     telemetry.Logger
-	tracer     telemetry.Tracer
-	client     *http.Client
-	repository repositories.WebhookRepository
-	dispatcher *EventDispatcher
+	logger             telemetry.Logger
+	tracer             telemetry.Tracer
+	client             *http.Client
+	repository         repositories.WebhookRepository
+	deliveryRepository repositories.WebhookDeliveryRepository
+	userRepository     repositories.UserRepository
+	dispatcher         *EventDispatcher
 }
 
 // NewWebhookService creates a new WebhookService
@@ -43,14 +68,18 @@ func NewWebhookService(
 	tracer telemetry.Tracer,
 	client *http.Client,
 	repository repositories.WebhookRepository,
+	deliveryRepository repositories.WebhookDeliveryRepository,
+	userRepository repositories.UserRepository,
 	dispatcher *EventDispatcher,
 ) (s *WebhookService) {
 	return &WebhookService{
-		logger:     logger.WithService(fmt.Sprintf("%T", s)),
-		tracer:     tracer,
-		client:     client,
-		dispatcher: dispatcher,
-		repository: repository,
+		logger:             logger.WithService(fmt.Sprintf("%T", s)),
+		tracer:             tracer,
+		client:             client,
+		dispatcher:         dispatcher,
+		repository:         repository,
+		deliveryRepository: deliveryRepository,
+		userRepository:     userRepository,
 	}
 }
 
@@ -64,6 +93,11 @@ func (service *WebhookService) DeleteAllForUser(ctx context.Context, userID enti
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
+	if err := service.deliveryRepository.DeleteAllForUser(ctx, userID); err != nil {
+		msg := fmt.Sprintf("could not delete all [entities.WebhookDelivery] for user with ID [%s]", userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
 	ctxLogger.Info(fmt.Sprintf("deleted all [entities.Webhook] for user with ID [%s]", userID))
 	return nil
 }
@@ -102,17 +136,29 @@ func (service *WebhookService) Delete(ctx context.Context, userID entities.UserI
 		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
+	if err := service.deliveryRepository.DeleteAllForWebhook(ctx, userID, webhookID); err != nil {
+		msg := fmt.Sprintf("cannot delete deliveries of webhook with id [%s] and user id [%s]", webhookID, userID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
 	ctxLogger.Info(fmt.Sprintf("deleted webhook with id [%s] and user id [%s]", webhookID, userID))
 	return nil
 }
 
 // WebhookStoreParams are parameters for creating a new entities.Webhook
 type WebhookStoreParams struct {
-	UserID       entities.UserID
-	SigningKey   string
-	URL          string
-	PhoneNumbers pq.StringArray
-	Events       pq.StringArray
+	UserID           entities.UserID
+	SigningKey       string
+	SignatureScheme  entities.WebhookSignatureScheme
+	FailureThreshold uint
+	PayloadFormat    entities.WebhookPayloadFormat
+	PayloadTemplate  *string
+	HTTPMethod       string
+	Headers          map[string]string
+	Filter           *entities.WebhookFilter
+	URL              string
+	PhoneNumbers     pq.StringArray
+	Events           pq.StringArray
 }
 
 // Store a new entities.Webhook
@@ -123,14 +169,22 @@ func (service *WebhookService) Store(ctx context.Context, params *WebhookStorePa
 	ctxLogger := service.tracer.CtxLogger(service.logger, span)
 
 	webhook := &entities.Webhook{
-		ID:           uuid.New(),
-		UserID:       params.UserID,
-		URL:          params.URL,
-		PhoneNumbers: params.PhoneNumbers,
-		SigningKey:   params.SigningKey,
-		Events:       params.Events,
-		CreatedAt:    time.Now().UTC(),
-		UpdatedAt:    time.Now().UTC(),
+		ID:               uuid.New(),
+		UserID:           params.UserID,
+		URL:              params.URL,
+		PhoneNumbers:     params.PhoneNumbers,
+		SigningKey:       params.SigningKey,
+		SignatureScheme:  params.SignatureScheme,
+		Status:           entities.WebhookStatusActive,
+		FailureThreshold: params.FailureThreshold,
+		PayloadFormat:    params.PayloadFormat,
+		PayloadTemplate:  params.PayloadTemplate,
+		HTTPMethod:       params.HTTPMethod,
+		Headers:          params.Headers,
+		Filter:           params.Filter,
+		Events:           params.Events,
+		CreatedAt:        time.Now().UTC(),
+		UpdatedAt:        time.Now().UTC(),
 	}
 
 	if err := service.repository.Save(ctx, webhook); err != nil {
@@ -144,12 +198,19 @@ func (service *WebhookService) Store(ctx context.Context, params *WebhookStorePa
 
 // WebhookUpdateParams are parameters for updating an entities.Webhook
 type WebhookUpdateParams struct {
-	UserID       entities.UserID
-	SigningKey   string
-	URL          string
-	Events       pq.StringArray
-	PhoneNumbers pq.StringArray
-	WebhookID    uuid.UUID
+	UserID           entities.UserID
+	SigningKey       string
+	SignatureScheme  entities.WebhookSignatureScheme
+	FailureThreshold uint
+	PayloadFormat    entities.WebhookPayloadFormat
+	PayloadTemplate  *string
+	HTTPMethod       string
+	Headers          map[string]string
+	Filter           *entities.WebhookFilter
+	URL              string
+	Events           pq.StringArray
+	PhoneNumbers     pq.StringArray
+	WebhookID        uuid.UUID
 }
 
 // Update an entities.Webhook
@@ -167,6 +228,30 @@ func (service *WebhookService) Update(ctx context.Context, params *WebhookUpdate
 	webhook.SigningKey = params.SigningKey
 	webhook.Events = params.Events
 	webhook.PhoneNumbers = params.PhoneNumbers
+	if params.SignatureScheme != "" {
+		webhook.SignatureScheme = params.SignatureScheme
+	}
+	if params.FailureThreshold > 0 {
+		webhook.FailureThreshold = params.FailureThreshold
+	}
+	if params.PayloadFormat != "" {
+		webhook.PayloadFormat = params.PayloadFormat
+	}
+	if params.PayloadTemplate != nil {
+		webhook.PayloadTemplate = params.PayloadTemplate
+	}
+	if params.HTTPMethod != "" {
+		webhook.HTTPMethod = params.HTTPMethod
+	}
+	if params.Headers != nil {
+		webhook.Headers = params.Headers
+	}
+	if params.Filter != nil {
+		webhook.Filter = params.Filter
+		if params.Filter.IsEmpty() {
+			webhook.Filter = nil
+		}
+	}
 
 	if err = service.repository.Save(ctx, webhook); err != nil {
 		msg := fmt.Sprintf("cannot save webhook with id [%s] after update", webhook.ID)
@@ -177,6 +262,290 @@ func (service *WebhookService) Update(ctx context.Context, params *WebhookUpdate
 	return webhook, nil
 }
 
+// Enable sends a test ping to a disabled entities.Webhook and activates it again when the ping is successful.
+// The entities.WebhookDelivery of the ping is returned when the ping fails.
+func (service *WebhookService) Enable(ctx context.Context, source string, userID entities.UserID, webhookID uuid.UUID) (*entities.Webhook, *entities.WebhookDelivery, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	webhook, err := service.repository.Load(ctx, userID, webhookID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load webhook with userID [%s] and webhookID [%s]", userID, webhookID)
+		return nil, nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	delivery, err := service.ping(ctx, source, webhook)
+	if err != nil {
+		msg := fmt.Sprintf("cannot ping webhook [%s] for user [%s]", webhookID, userID)
+		return nil, nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if delivery.Status != entities.WebhookDeliveryStatusSucceeded {
+		msg := fmt.Sprintf("the test ping to webhook [%s] failed with error [%s]", webhook.URL, service.getDeliveryErrorMessage(delivery))
+		return nil, delivery, service.tracer.WrapErrorSpan(span, stacktrace.NewErrorWithCode(ErrCodeWebhookPingFailed, msg))
+	}
+
+	webhook.Status = entities.WebhookStatusActive
+	webhook.ConsecutiveFailures = 0
+	webhook.DisabledAt = nil
+	webhook.LastSuccessAt = &delivery.CreatedAt
+	webhook.UpdatedAt = time.Now().UTC()
+
+	if err = service.repository.Save(ctx, webhook); err != nil {
+		msg := fmt.Sprintf("cannot save webhook with id [%s] after enabling it", webhook.ID)
+		return nil, nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("enabled webhook [%s] for user [%s]", webhook.ID, webhook.UserID))
+	return webhook, delivery, nil
+}
+
+// ping sends a single events.EventTypeWebhookPing event to the webhook without retries
+func (service *WebhookService) ping(ctx context.Context, source string, webhook *entities.Webhook) (*entities.WebhookDelivery, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	event, err := service.createEvent(events.EventTypeWebhookPing, source, &events.WebhookPingPayload{
+		WebhookID: webhook.ID,
+		UserID:    webhook.UserID,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		msg := fmt.Sprintf("cannot create [%s] event for webhook [%s]", events.EventTypeWebhookPing, webhook.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	delivery, err := service.sendOnce(ctx, event, "", webhook)
+	if err != nil {
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, fmt.Sprintf("cannot ping webhook [%s]", webhook.ID)))
+	}
+
+	return delivery, nil
+}
+
+// WebhookTestParams are parameters for sending a test event to an entities.Webhook
+type WebhookTestParams struct {
+	UserID    entities.UserID
+	WebhookID uuid.UUID
+	EventType string
+	Source    string
+}
+
+// Test sends a synthetic event to an entities.Webhook so that the user can check that the webhook works.
+// The test is sent once without retries and it does not change the health of the webhook.
+func (service *WebhookService) Test(ctx context.Context, params *WebhookTestParams) (*entities.WebhookDelivery, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	webhook, err := service.repository.Load(ctx, params.UserID, params.WebhookID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load webhook with userID [%s] and webhookID [%s]", params.UserID, params.WebhookID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	owner := service.getSampleOwner(webhook)
+	event, err := service.createEvent(params.EventType, params.Source, service.getSamplePayload(params.EventType, webhook.UserID, owner))
+	if err != nil {
+		msg := fmt.Sprintf("cannot create test [%s] event for webhook [%s]", params.EventType, webhook.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	delivery, err := service.sendOnce(ctx, event, owner, webhook)
+	if err != nil {
+		msg := fmt.Sprintf("cannot send test [%s] event to webhook [%s]", params.EventType, webhook.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("sent test [%s] event to webhook [%s] with status [%s]", event.Type(), webhook.ID, delivery.Status))
+	return delivery, nil
+}
+
+// sendOnce sends an event to the webhook without retries and stores the delivery
+func (service *WebhookService) sendOnce(ctx context.Context, event cloudevents.Event, owner string, webhook *entities.Webhook) (*entities.WebhookDelivery, error) {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	delivery, _ := service.deliver(ctx, event, owner, webhook, webhookAttempt{number: 1})
+	if delivery == nil {
+		msg := fmt.Sprintf("cannot send [%s] event with ID [%s] to webhook [%s]", event.Type(), event.ID(), webhook.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.NewError(msg))
+	}
+
+	if delivery.Status != entities.WebhookDeliveryStatusSucceeded {
+		delivery.Status = entities.WebhookDeliveryStatusFailed
+		delivery.NextAttemptAt = nil
+	}
+
+	service.storeDelivery(ctx, delivery)
+	return delivery, nil
+}
+
+func (service *WebhookService) getSampleOwner(webhook *entities.Webhook) string {
+	if len(webhook.PhoneNumbers) > 0 {
+		return webhook.PhoneNumbers[0]
+	}
+	return "+18005550199"
+}
+
+// getSamplePayload returns a realistic payload for an event type which is sent to a webhook
+func (service *WebhookService) getSamplePayload(eventType string, userID entities.UserID, owner string) any {
+	contact := "+18005550100"
+	content := "This is a test message from httpSMS"
+	timestamp := time.Now().UTC()
+
+	switch eventType {
+	case events.EventTypeMessagePhoneReceived:
+		return &events.MessagePhoneReceivedPayload{
+			MessageID: uuid.New(),
+			UserID:    userID,
+			Owner:     owner,
+			Contact:   contact,
+			Timestamp: timestamp,
+			Content:   content,
+			SIM:       entities.SIM1,
+		}
+	case events.EventTypeMessagePhoneSent:
+		return &events.MessagePhoneSentPayload{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Owner:     owner,
+			Contact:   contact,
+			Timestamp: timestamp,
+			Content:   content,
+			SIM:       entities.SIM1,
+			Encoding:  entities.MessageEncodingGSM7,
+			Segments:  1,
+		}
+	case events.EventTypeMessagePhoneDelivered:
+		return &events.MessagePhoneDeliveredPayload{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Owner:     owner,
+			Contact:   contact,
+			Timestamp: timestamp,
+			Content:   content,
+			SIM:       entities.SIM1,
+			Encoding:  entities.MessageEncodingGSM7,
+			Segments:  1,
+		}
+	case events.EventTypeMessageSendFailed:
+		return &events.MessageSendFailedPayload{
+			ID:           uuid.New(),
+			ErrorMessage: "NO_SERVICE",
+			FailureCode:  entities.MessageFailureCodeNoService,
+			UserID:       userID,
+			Owner:        owner,
+			Contact:      contact,
+			Timestamp:    timestamp,
+			Content:      content,
+			SIM:          entities.SIM1,
+			Encoding:     entities.MessageEncodingGSM7,
+			Segments:     1,
+		}
+	case events.EventTypeMessageSendExpired:
+		return &events.MessageSendExpiredPayload{
+			MessageID:        uuid.New(),
+			Owner:            owner,
+			SendAttemptCount: 2,
+			IsFinal:          true,
+			Contact:          contact,
+			UserID:           userID,
+			Timestamp:        timestamp,
+			Content:          content,
+			SIM:              entities.SIM1,
+			Encoding:         entities.MessageEncodingGSM7,
+			Segments:         1,
+			FailureCode:      entities.MessageFailureCodeExpired,
+		}
+	case events.EventTypePhoneHeartbeatOnline:
+		return &events.PhoneHeartbeatOnlinePayload{
+			PhoneID:                uuid.New(),
+			UserID:                 userID,
+			LastHeartbeatTimestamp: timestamp.Add(-15 * time.Minute),
+			Timestamp:              timestamp,
+			MonitorID:              uuid.New(),
+			Owner:                  owner,
+		}
+	case events.EventTypePhoneHeartbeatOffline:
+		return &events.PhoneHeartbeatOfflinePayload{
+			PhoneID:                uuid.New(),
+			UserID:                 userID,
+			LastHeartbeatTimestamp: timestamp.Add(-time.Hour),
+			Timestamp:              timestamp,
+			MonitorID:              uuid.New(),
+			Owner:                  owner,
+		}
+	case events.MessageCallMissed:
+		return &events.MessageCallMissedPayload{
+			MessageID: uuid.New(),
+			UserID:    userID,
+			Owner:     owner,
+			Contact:   contact,
+			Timestamp: timestamp,
+			SIM:       entities.SIM1,
+		}
+	case events.EventTypeCampaignCompleted:
+		return &events.CampaignCompletedPayload{
+			CampaignID: uuid.New(),
+			UserID:     userID,
+			Owner:      owner,
+			Name:       "Test Campaign",
+			Stats: entities.CampaignStats{
+				Total:     100,
+				Sent:      5,
+				Delivered: 90,
+				Failed:    3,
+				Expired:   2,
+				Replies:   7,
+			},
+			CompletedAt: timestamp,
+		}
+	default:
+		return &events.WebhookPingPayload{UserID: userID, Timestamp: timestamp}
+	}
+}
+
+// WebhookSigningKeyRotateParams are parameters for rotating the signing key of an entities.Webhook
+type WebhookSigningKeyRotateParams struct {
+	UserID     entities.UserID
+	WebhookID  uuid.UUID
+	SigningKey string
+	Overlap    time.Duration
+}
+
+// RotateSigningKey replaces the signing key of an entities.Webhook.
+// Requests are signed with both the new and the previous key until the overlap period expires.
+func (service *WebhookService) RotateSigningKey(ctx context.Context, params *WebhookSigningKeyRotateParams) (*entities.Webhook, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	webhook, err := service.repository.Load(ctx, params.UserID, params.WebhookID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load webhook with userID [%s] and webhookID [%s]", params.UserID, params.WebhookID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	webhook.PreviousSigningKey = nil
+	webhook.PreviousSigningKeyExpiresAt = nil
+	if params.Overlap > 0 && strings.TrimSpace(webhook.SigningKey) != "" {
+		previousSigningKey := webhook.SigningKey
+		expiresAt := time.Now().UTC().Add(params.Overlap)
+		webhook.PreviousSigningKey = &previousSigningKey
+		webhook.PreviousSigningKeyExpiresAt = &expiresAt
+	}
+
+	webhook.SigningKey = params.SigningKey
+	webhook.UpdatedAt = time.Now().UTC()
+
+	if err = service.repository.Save(ctx, webhook); err != nil {
+		msg := fmt.Sprintf("cannot save webhook with id [%s] after rotating the signing key", webhook.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("rotated signing key of webhook [%s] with an overlap of [%s]", webhook.ID, params.Overlap))
+	return webhook, nil
+}
+
 // Send an event to a subscribed webhook
 func (service *WebhookService) Send(ctx context.Context, userID entities.UserID, event cloudevents.Event, phoneNumber string) error {
 	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
@@ -188,6 +557,7 @@ func (service *WebhookService) Send(ctx context.Context, userID entities.UserID,
 		return service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
 	}
 
+	webhooks = service.filterWebhooks(ctxLogger, event, webhooks)
 	if len(webhooks) == 0 {
 		ctxLogger.Info(fmt.Sprintf("user [%s] has no webhook subscription to event [%s]", userID, event.Type()))
 		return nil
@@ -198,7 +568,7 @@ func (service *WebhookService) Send(ctx context.Context, userID entities.UserID,
 		wg.Add(1)
 		go func(webhook *entities.Webhook) {
 			defer wg.Done()
-			service.sendNotification(ctx, event, phoneNumber, webhook)
+			service.sendNotification(ctx, event, phoneNumber, webhook, webhookAttempt{number: 1})
 		}(webhook)
 	}
 	wg.Wait()
@@ -206,25 +576,270 @@ func (service *WebhookService) Send(ctx context.Context, userID entities.UserID,
 	return nil
 }
 
-func (service *WebhookService) sendNotification(ctx context.Context, event cloudevents.Event, owner string, webhook *entities.Webhook) {
+// SendCallback sends an event of a message to the callback URL of the message. The request is signed with the
+// WebhookSignatureSchemeHMAC scheme using the callback signing key of the user and failed requests are retried like webhooks.
+func (service *WebhookService) SendCallback(ctx context.Context, userID entities.UserID, event cloudevents.Event, callbackURL *string) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if callbackURL == nil || strings.TrimSpace(*callbackURL) == "" {
+		return
+	}
+
+	callback, err := service.loadCallback(ctx, userID, *callbackURL)
+	if err != nil {
+		msg := fmt.Sprintf("cannot send [%s] event with ID [%s] to callback URL [%s]", event.Type(), event.ID(), *callbackURL)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return
+	}
+
+	service.sendNotification(ctx, event, "", callback, webhookAttempt{number: 1})
+}
+
+// loadCallback creates the entities.Webhook which sends events to the callback URL of a message
+func (service *WebhookService) loadCallback(ctx context.Context, userID entities.UserID, callbackURL string) (*entities.Webhook, error) {
+	user, err := service.userRepository.Load(ctx, userID)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, fmt.Sprintf("cannot load user [%s] for callback URL [%s]", userID, callbackURL))
+	}
+	return user.CallbackWebhook(callbackURL), nil
+}
+
+// filterWebhooks removes the webhooks with an entities.WebhookFilter which does not match the event
+func (service *WebhookService) filterWebhooks(ctxLogger telemetry.Logger, event cloudevents.Event, webhooks []*entities.Webhook) []*entities.Webhook {
+	var subject *entities.WebhookFilterSubject
+	result := make([]*entities.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.Filter.IsEmpty() {
+			result = append(result, webhook)
+			continue
+		}
+
+		if subject == nil {
+			subject = service.getFilterSubject(ctxLogger, event)
+		}
+
+		if !webhook.Filter.Matches(*subject) {
+			ctxLogger.Info(fmt.Sprintf("event [%s] with ID [%s] does not match the filter of webhook [%s] for user [%s]", event.Type(), event.ID(), webhook.ID, webhook.UserID))
+			continue
+		}
+		result = append(result, webhook)
+	}
+	return result
+}
+
+// getFilterSubject returns the fields of the event which are used by an entities.WebhookFilter
+func (service *WebhookService) getFilterSubject(ctxLogger telemetry.Logger, event cloudevents.Event) *entities.WebhookFilterSubject {
+	subject := new(entities.WebhookFilterSubject)
+
+	data, err := service.getEventData(event)
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot get filter subject of event [%s] with ID [%s]", event.Type(), event.ID())))
+		return subject
+	}
+
+	if value, ok := data["contact"].(string); ok {
+		subject.Contact = &value
+	}
+	if value, ok := data["content"].(string); ok {
+		subject.Content = &value
+	}
+	if value, ok := data["sim"].(string); ok && value != "" {
+		sim := entities.SIM(value)
+		subject.SIM = &sim
+	}
+	if value, ok := data["encrypted"].(bool); ok {
+		subject.Encrypted = &value
+	}
+
+	switch {
+	case event.Type() == events.EventTypeMessagePhoneReceived:
+		subject.MessageType = service.messageType(entities.MessageTypeMobileOriginated)
+	case event.Type() == events.MessageCallMissed:
+		subject.MessageType = service.messageType(entities.MessageTypeCallMissed)
+	case strings.HasPrefix(event.Type(), "message.") && subject.Contact != nil:
+		subject.MessageType = service.messageType(entities.MessageTypeMobileTerminated)
+	}
+
+	return subject
+}
+
+func (service *WebhookService) messageType(value string) *entities.MessageType {
+	messageType := entities.MessageType(value)
+	return &messageType
+}
+
+// IndexDeliveries fetches the entities.WebhookDelivery of an entities.Webhook
+func (service *WebhookService) IndexDeliveries(ctx context.Context, userID entities.UserID, webhookID uuid.UUID, params repositories.IndexParams) ([]*entities.WebhookDelivery, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if _, err := service.repository.Load(ctx, userID, webhookID); err != nil {
+		msg := fmt.Sprintf("cannot load webhook with userID [%s] and webhookID [%s]", userID, webhookID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	deliveries, err := service.deliveryRepository.Index(ctx, userID, webhookID, params)
+	if err != nil {
+		msg := fmt.Sprintf("could not fetch deliveries of webhook [%s] with params [%+#v]", webhookID, params)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("fetched [%d] deliveries of webhook [%s] with params [%+#v]", len(deliveries), webhookID, params))
+	return deliveries, nil
+}
+
+// Redeliver sends the event of an entities.WebhookDelivery to the webhook again
+func (service *WebhookService) Redeliver(ctx context.Context, userID entities.UserID, webhookID uuid.UUID, deliveryID uuid.UUID) (*entities.WebhookDelivery, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	webhook, err := service.repository.Load(ctx, userID, webhookID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load webhook with userID [%s] and webhookID [%s]", userID, webhookID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	delivery, err := service.deliveryRepository.Load(ctx, userID, webhookID, deliveryID)
+	if err != nil {
+		msg := fmt.Sprintf("cannot load delivery with ID [%s] for webhook [%s]", deliveryID, webhookID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.PropagateWithCode(err, stacktrace.GetCode(err), msg))
+	}
+
+	event := cloudevents.NewEvent()
+	if err = event.UnmarshalJSON([]byte(delivery.Event)); err != nil {
+		msg := fmt.Sprintf("cannot unmarshal event of webhook delivery [%s]", delivery.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	redelivery := service.sendNotification(ctx, event, delivery.Owner, webhook, webhookAttempt{number: 1, redeliveryOf: &delivery.ID})
+	if redelivery == nil {
+		msg := fmt.Sprintf("cannot redeliver [%s] event with ID [%s] to webhook [%s]", event.Type(), event.ID(), webhook.ID)
+		return nil, service.tracer.WrapErrorSpan(span, stacktrace.NewError(msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("redelivered webhook delivery [%s] as [%s] with status [%s]", delivery.ID, redelivery.ID, redelivery.Status))
+	return redelivery, nil
+}
+
+// Retry sends an event to a webhook again after a failed attempt
+func (service *WebhookService) Retry(ctx context.Context, payload *events.WebhookSendRetryPayload) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if payload.CallbackURL != nil {
+		callback, err := service.loadCallback(ctx, payload.UserID, *payload.CallbackURL)
+		if err != nil {
+			msg := fmt.Sprintf("cannot retry attempt [%d] to callback URL [%s]", payload.Attempt, *payload.CallbackURL)
+			return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+		}
+		return service.retry(ctx, callback, payload)
+	}
+
+	webhook, err := service.repository.Load(ctx, payload.UserID, payload.WebhookID)
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		ctxLogger.Info(fmt.Sprintf("webhook [%s] for user [%s] has been deleted so attempt [%d] is skipped", payload.WebhookID, payload.UserID, payload.Attempt))
+		return nil
+	}
+
+	if err != nil {
+		msg := fmt.Sprintf("cannot load webhook with userID [%s] and webhookID [%s]", payload.UserID, payload.WebhookID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if webhook.IsDisabled() {
+		ctxLogger.Info(fmt.Sprintf("webhook [%s] for user [%s] is disabled so attempt [%d] is skipped", payload.WebhookID, payload.UserID, payload.Attempt))
+		return nil
+	}
+
+	return service.retry(ctx, webhook, payload)
+}
+
+// retry sends the event of a webhook attempt again
+func (service *WebhookService) retry(ctx context.Context, webhook *entities.Webhook, payload *events.WebhookSendRetryPayload) error {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	event := cloudevents.NewEvent()
+	if err := event.UnmarshalJSON([]byte(payload.Event)); err != nil {
+		msg := fmt.Sprintf("cannot unmarshal event for attempt [%d] to [%s]", payload.Attempt, webhook.URL)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	service.sendNotification(ctx, event, payload.Owner, webhook, webhookAttempt{number: payload.Attempt, redeliveryOf: payload.RedeliveryOf})
+	return nil
+}
+
+// webhookAttempt identifies an attempt of sending an event to a webhook
+type webhookAttempt struct {
+	number       uint
+	redeliveryOf *uuid.UUID
+}
+
+func (service *WebhookService) sendNotification(ctx context.Context, event cloudevents.Event, owner string, webhook *entities.Webhook, attempt webhookAttempt) *entities.WebhookDelivery {
+	ctx, span := service.tracer.Start(ctx)
+	defer span.End()
+
+	delivery, retriable := service.deliver(ctx, event, owner, webhook, attempt)
+	if delivery == nil {
+		return nil
+	}
+
+	if delivery.Status != entities.WebhookDeliveryStatusSucceeded {
+		return service.handleDeliveryFailed(ctx, event, webhook, delivery, retriable)
+	}
+
+	service.storeDelivery(ctx, delivery)
+	if !delivery.IsCallback() {
+		service.recordSuccess(ctx, webhook)
+	}
+	return delivery
+}
+
+// deliver sends a single request to the webhook. It returns the entities.WebhookDelivery and whether the request can be retried when it failed.
+func (service *WebhookService) deliver(ctx context.Context, event cloudevents.Event, owner string, webhook *entities.Webhook, attempt webhookAttempt) (*entities.WebhookDelivery, bool) {
 	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
 	defer span.End()
 
-	requestCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	eventJSON, err := event.MarshalJSON()
+	if err != nil {
+		msg := fmt.Sprintf("cannot marshal [%s] event with ID [%s]", event.Type(), event.ID())
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return nil, false
+	}
+
+	requestCtx, cancel := context.WithTimeout(ctx, webhookTimeout)
 	defer cancel()
 
-	request, err := service.createRequest(requestCtx, event, webhook)
+	request, payload, err := service.createRequest(requestCtx, event, webhook)
 	if err != nil {
 		msg := fmt.Sprintf("cannot create [%s] event to webhook [%s] for user [%s]", event.Type(), webhook.URL, webhook.UserID)
 		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
-		return
+		return nil, false
+	}
+
+	delivery := &entities.WebhookDelivery{
+		ID:           uuid.New(),
+		WebhookID:    webhook.ID,
+		UserID:       webhook.UserID,
+		EventID:      event.ID(),
+		EventType:    event.Type(),
+		Owner:        owner,
+		URL:          webhook.URL,
+		Attempt:      attempt.number,
+		RequestBody:  string(payload),
+		RedeliveryOf: attempt.redeliveryOf,
+		Event:        string(eventJSON),
+		CreatedAt:    time.Now().UTC(),
 	}
 
+	start := time.Now()
 	response, err := service.client.Do(request)
+	delivery.LatencyMilliseconds = time.Since(start).Milliseconds()
 	if err != nil {
-		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot send [%s] event to webhook [%s] for user [%s]", event.Type(), webhook.URL, webhook.UserID)))
-		service.handleWebhookSendFailed(ctx, event, webhook, owner, err, nil)
-		return
+		ctxLogger.Warn(stacktrace.Propagate(err, fmt.Sprintf("cannot send [%s] event to webhook [%s] for user [%s] on attempt [%d]", event.Type(), webhook.URL, webhook.UserID, attempt.number)))
+		delivery.ErrorMessage = service.getErrorMessage(err)
+		return delivery, true
 	}
 
 	defer func() {
@@ -234,92 +849,288 @@ func (service *WebhookService) sendNotification(ctx context.Context, event cloud
 		}
 	}()
 
+	delivery.ResponseStatusCode = &response.StatusCode
+	if body, err := io.ReadAll(io.LimitReader(response.Body, webhookResponseExcerptLength)); err == nil && len(body) > 0 {
+		excerpt := string(body)
+		delivery.ResponseBody = &excerpt
+	}
+
 	if response.StatusCode >= 400 {
-		ctxLogger.Info(fmt.Sprintf("cannot send [%s] event to webhook [%s] for user [%s] with response code [%d]", event.Type(), webhook.URL, webhook.UserID, response.StatusCode))
-		service.handleWebhookSendFailed(ctx, event, webhook, owner, stacktrace.NewError(http.StatusText(response.StatusCode)), response)
-		return
+		ctxLogger.Info(fmt.Sprintf("cannot send [%s] event to webhook [%s] for user [%s] with response code [%d] on attempt [%d]", event.Type(), webhook.URL, webhook.UserID, response.StatusCode, attempt.number))
+		errorMessage := http.StatusText(response.StatusCode)
+		delivery.ErrorMessage = &errorMessage
+		delivery.NextAttemptAt = service.getRetryAfter(response)
+		return delivery, response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
 	}
 
+	delivery.Status = entities.WebhookDeliveryStatusSucceeded
 	ctxLogger.Info(fmt.Sprintf("sent webhook to url [%s] for event [%s] with ID [%s] and response code [%d]", webhook.URL, event.Type(), event.ID(), response.StatusCode))
+	return delivery, false
+}
+
+// handleDeliveryFailed schedules the next attempt of a failed delivery or reports the failure when the event cannot be retried
+func (service *WebhookService) handleDeliveryFailed(ctx context.Context, event cloudevents.Event, webhook *entities.Webhook, delivery *entities.WebhookDelivery, retriable bool) *entities.WebhookDelivery {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if retriable && delivery.Attempt < webhookMaxAttempts {
+		delay := service.getRetryDelay(delivery.Attempt)
+		if delivery.NextAttemptAt != nil {
+			delay = max(delay, min(time.Until(*delivery.NextAttemptAt), webhookMaxRetryAfter))
+		}
+		nextAttemptAt := time.Now().UTC().Add(delay)
+
+		delivery.Status = entities.WebhookDeliveryStatusRetrying
+		delivery.NextAttemptAt = &nextAttemptAt
+		service.storeDelivery(ctx, delivery)
+
+		err := service.dispatchRetry(ctx, event.Source(), delivery, delay)
+		if err == nil {
+			return delivery
+		}
+
+		msg := fmt.Sprintf("cannot schedule attempt [%d] of [%s] event with ID [%s] to webhook [%s]", delivery.Attempt+1, event.Type(), event.ID(), webhook.ID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+	}
+
+	delivery.Status = entities.WebhookDeliveryStatusFailed
+	delivery.NextAttemptAt = nil
+	service.storeDelivery(ctx, delivery)
+
+	if delivery.IsCallback() {
+		ctxLogger.Warn(stacktrace.NewError(fmt.Sprintf("cannot send [%s] event with ID [%s] to callback URL [%s] for user [%s]: %s", event.Type(), event.ID(), delivery.URL, delivery.UserID, service.getDeliveryErrorMessage(delivery))))
+		return delivery
+	}
+
+	if !service.recordFailure(ctx, event, webhook, delivery) {
+		service.handleWebhookSendFailed(ctx, event, webhook, delivery)
+	}
+	return delivery
+}
+
+// recordSuccess resets the consecutive failures of the webhook. The webhook is only updated when it has failed
+// before or when the last success is stale so that every event does not cause a write.
+func (service *WebhookService) recordSuccess(ctx context.Context, webhook *entities.Webhook) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	timestamp := time.Now().UTC()
+	if webhook.ConsecutiveFailures == 0 && webhook.LastSuccessAt != nil && timestamp.Sub(*webhook.LastSuccessAt) < time.Minute {
+		return
+	}
+
+	if err := service.repository.RecordSuccess(ctx, webhook, timestamp); err != nil {
+		msg := fmt.Sprintf("cannot record success of webhook [%s] for user [%s]", webhook.ID, webhook.UserID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+	}
+}
+
+// recordFailure increments the consecutive failures of the webhook and notifies the user once when the webhook is disabled.
+// It returns true when the webhook was disabled by this failure.
+func (service *WebhookService) recordFailure(ctx context.Context, event cloudevents.Event, webhook *entities.Webhook, delivery *entities.WebhookDelivery) bool {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	timestamp := time.Now().UTC()
+	disabled, err := service.repository.RecordFailure(ctx, webhook, timestamp)
+	if err != nil {
+		msg := fmt.Sprintf("cannot record failure of webhook [%s] for user [%s]", webhook.ID, webhook.UserID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return false
+	}
+
+	if !disabled {
+		return false
+	}
+
+	ctxLogger.Info(fmt.Sprintf("disabled webhook [%s] for user [%s] after [%d] consecutive failures", webhook.ID, webhook.UserID, webhook.FailureThreshold))
+
+	payload := &events.WebhookDisabledPayload{
+		WebhookID:           webhook.ID,
+		WebhookURL:          webhook.URL,
+		UserID:              webhook.UserID,
+		Owner:               delivery.Owner,
+		ConsecutiveFailures: webhook.FailureThreshold,
+		EventType:           event.Type(),
+		ErrorMessage:        service.getDeliveryErrorMessage(delivery),
+		DisabledAt:          timestamp,
+	}
+
+	disabledEvent, err := service.createEvent(events.EventTypeWebhookDisabled, event.Source(), payload)
+	if err != nil {
+		msg := fmt.Sprintf("cannot create event [%s] for webhook [%s]", events.EventTypeWebhookDisabled, webhook.ID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		return true
+	}
+
+	if err = service.dispatcher.Dispatch(ctx, disabledEvent); err != nil {
+		msg := fmt.Sprintf("cannot dispatch event [%s] for webhook [%s]", disabledEvent.Type(), webhook.ID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+	}
+	return true
+}
+
+func (service *WebhookService) getDeliveryErrorMessage(delivery *entities.WebhookDelivery) string {
+	if delivery.ResponseBody != nil {
+		return *delivery.ResponseBody
+	}
+	if delivery.ErrorMessage != nil {
+		return *delivery.ErrorMessage
+	}
+	return ""
 }
 
-func (service *WebhookService) createRequest(ctx context.Context, event cloudevents.Event, webhook *entities.Webhook) (*http.Request, error) {
+func (service *WebhookService) dispatchRetry(ctx context.Context, source string, delivery *entities.WebhookDelivery, delay time.Duration) error {
 	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
 	defer span.End()
 
-	payload, err := json.Marshal(service.getPayload(ctxLogger, event, webhook))
+	payload := &events.WebhookSendRetryPayload{
+		WebhookID:    delivery.WebhookID,
+		UserID:       delivery.UserID,
+		Owner:        delivery.Owner,
+		Event:        delivery.Event,
+		Attempt:      delivery.Attempt + 1,
+		RedeliveryOf: delivery.RedeliveryOf,
+	}
+	if delivery.IsCallback() {
+		payload.CallbackURL = &delivery.URL
+	}
+
+	event, err := service.createEvent(events.EventTypeWebhookSendRetry, source, payload)
 	if err != nil {
-		msg := fmt.Sprintf("cannot marshal payload for user [%s] and webhook [%s] for event [%s]", webhook.UserID, webhook.ID, event.ID())
-		return nil, stacktrace.Propagate(err, msg)
+		msg := fmt.Sprintf("cannot create event [%s] for webhook delivery [%s]", events.EventTypeWebhookSendRetry, delivery.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	if _, err = service.dispatcher.DispatchWithTimeout(ctx, event, delay); err != nil {
+		msg := fmt.Sprintf("cannot dispatch event [%s] for webhook delivery [%s]", event.Type(), delivery.ID)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
+	}
+
+	ctxLogger.Info(fmt.Sprintf("scheduled attempt [%d] of webhook delivery [%s] in [%s]", payload.Attempt, delivery.ID, delay))
+	return nil
+}
+
+func (service *WebhookService) storeDelivery(ctx context.Context, delivery *entities.WebhookDelivery) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	if err := service.deliveryRepository.Store(ctx, delivery); err != nil {
+		msg := fmt.Sprintf("cannot store delivery [%s] of [%s] event with ID [%s] for webhook [%s]", delivery.ID, delivery.EventType, delivery.EventID, delivery.WebhookID)
+		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+	}
+}
+
+// getRetryDelay returns the delay before the next attempt when an event has already been sent the given number of times
+func (service *WebhookService) getRetryDelay(attempts uint) time.Duration {
+	delay := webhookRetryDelay
+	for i := uint(1); i < attempts; i++ {
+		delay *= webhookRetryBackoffMultiplier
+	}
+	return delay
+}
+
+// getRetryAfter returns the time of the next attempt from the Retry-After header which contains either a number of seconds or an HTTP date
+func (service *WebhookService) getRetryAfter(response *http.Response) *time.Time {
+	value := strings.TrimSpace(response.Header.Get("Retry-After"))
+	if value == "" {
+		return nil
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		timestamp := time.Now().UTC().Add(time.Duration(seconds) * time.Second)
+		return &timestamp
+	}
+
+	if timestamp, err := http.ParseTime(value); err == nil {
+		timestamp = timestamp.UTC()
+		return &timestamp
 	}
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	return nil
+}
+
+// DeleteExpiredDeliveries deletes the entities.WebhookDelivery which are older than the retention period
+func (service *WebhookService) DeleteExpiredDeliveries(ctx context.Context) error {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	timestamp := time.Now().UTC().Add(-webhookDeliveryRetention)
+	count, err := service.deliveryRepository.DeleteBefore(ctx, timestamp)
 	if err != nil {
-		msg := fmt.Sprintf("cannot create request for user [%s] and webhook [%s] for event [%s]", webhook.UserID, webhook.ID, event.ID())
-		return nil, stacktrace.Propagate(err, msg)
+		msg := fmt.Sprintf("cannot delete webhook deliveries created before [%s]", timestamp)
+		return service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg))
 	}
 
-	request.Header.Add("X-Event-Type", event.Type())
-	request.Header.Set("Content-Type", "application/json")
+	ctxLogger.Info(fmt.Sprintf("deleted [%d] webhook deliveries created before [%s]", count, timestamp))
+	return nil
+}
 
-	if strings.TrimSpace(webhook.SigningKey) != "" {
-		token, err := service.getAuthToken(webhook)
-		if err != nil {
-			msg := fmt.Sprintf("cannot generate auth token for user [%s] and webhook [%s]", webhook.UserID, webhook.ID)
-			return nil, stacktrace.Propagate(err, msg)
+// CleanupDeliveries deletes the expired entities.WebhookDelivery periodically until the context is canceled
+func (service *WebhookService) CleanupDeliveries(ctx context.Context) {
+	ticker := time.NewTicker(webhookDeliveryCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := service.DeleteExpiredDeliveries(ctx); err != nil {
+			service.logger.Error(stacktrace.Propagate(err, "cannot delete the expired webhook deliveries"))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
+}
 
-	return request, nil
+func (service *WebhookService) getErrorMessage(err error) *string {
+	message := err.Error()
+	if errors.Is(err, context.DeadlineExceeded) {
+		message = fmt.Sprintf("TIMEOUT after %s", webhookTimeout)
+	}
+	return &message
 }
 
-func (service *WebhookService) getPayload(ctxLogger telemetry.Logger, event cloudevents.Event, webhook *entities.Webhook) any {
-	if event.Type() != events.EventTypeMessagePhoneReceived {
-		return event
+func (service *WebhookService) createRequest(ctx context.Context, event cloudevents.Event, webhook *entities.Webhook) (*http.Request, []byte, error) {
+	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
+	defer span.End()
+
+	payload, contentType, err := service.getPayload(ctxLogger, event, webhook)
+	if err != nil {
+		msg := fmt.Sprintf("cannot create [%s] payload for user [%s] and webhook [%s] for event [%s]", webhook.PayloadFormat, webhook.UserID, webhook.ID, event.ID())
+		return nil, nil, stacktrace.Propagate(err, msg)
 	}
 
-	if !strings.HasPrefix(webhook.URL, "https://discord.com/api/webhooks/") {
-		return event
+	request, err := http.NewRequestWithContext(ctx, webhook.Method(), webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		msg := fmt.Sprintf("cannot create request for user [%s] and webhook [%s] for event [%s]", webhook.UserID, webhook.ID, event.ID())
+		return nil, nil, stacktrace.Propagate(err, msg)
 	}
 
-	payload := new(events.MessagePhoneReceivedPayload)
+	request.Header.Set("Content-Type", contentType)
+	for key, value := range webhook.Headers {
+		request.Header.Set(key, value)
+	}
+	request.Header.Set("X-Event-Type", event.Type())
 
-	err := event.DataAs(payload)
-	if err != nil {
-		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot unmarshal event [%s] with ID [%s] into [%T]", event.Type(), event.ID(), payload)))
-		return event
-	}
-
-	return map[string]any{
-		"avatar_url": "https://httpsms.com/avatar.png",
-		"username":   "httpsms.com",
-		"content":    "✉ new message received",
-		"embeds": []fiber.Map{
-			{
-				"fields": []fiber.Map{
-					{
-						"name":   "From:",
-						"value":  service.getFormattedNumber(ctxLogger, payload.Contact),
-						"inline": true,
-					},
-					{
-						"name":   "To:",
-						"value":  service.getFormattedNumber(ctxLogger, payload.Owner),
-						"inline": true,
-					},
-					{
-						"name":  "Content:",
-						"value": payload.Content,
-					},
-					{
-						"name":  "MessageID:",
-						"value": payload.MessageID,
-					},
-				},
-			},
-		},
+	if strings.TrimSpace(webhook.SigningKey) != "" && webhook.UsesHMACSignature() {
+		timestamp := time.Now().UTC()
+		request.Header.Set(signature.HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+		request.Header.Set(signature.HeaderSignature, signature.Header(timestamp, payload, webhook.SigningKeys(timestamp)...))
+		return request, payload, nil
 	}
+
+	if strings.TrimSpace(webhook.SigningKey) != "" {
+		token, err := service.getAuthToken(webhook)
+		if err != nil {
+			msg := fmt.Sprintf("cannot generate auth token for user [%s] and webhook [%s]", webhook.UserID, webhook.ID)
+			return nil, nil, stacktrace.Propagate(err, msg)
+		}
+		request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	return request, payload, nil
 }
 
 func (service *WebhookService) getAuthToken(webhook *entities.Webhook) (string, error) {
@@ -334,7 +1145,7 @@ func (service *WebhookService) getAuthToken(webhook *entities.Webhook) (string,
 	return token.SignedString([]byte(webhook.SigningKey))
 }
 
-func (service *WebhookService) handleWebhookSendFailed(ctx context.Context, event cloudevents.Event, webhook *entities.Webhook, owner string, err error, response *http.Response) {
+func (service *WebhookService) handleWebhookSendFailed(ctx context.Context, event cloudevents.Event, webhook *entities.Webhook, delivery *entities.WebhookDelivery) {
 	ctx, span, ctxLogger := service.tracer.StartWithLogger(ctx, service.logger)
 	defer span.End()
 
@@ -343,28 +1154,14 @@ func (service *WebhookService) handleWebhookSendFailed(ctx context.Context, even
 		WebhookURL:             webhook.URL,
 		UserID:                 webhook.UserID,
 		EventID:                event.ID(),
-		Owner:                  owner,
+		Owner:                  delivery.Owner,
 		EventType:              event.Type(),
 		EventPayload:           string(event.Data()),
-		HTTPResponseStatusCode: nil,
-		ErrorMessage:           err.Error(),
-	}
-
-	if errors.Is(err, context.DeadlineExceeded) {
-		payload.ErrorMessage = "TIMOUT after 10 seconds"
-	}
-
-	if response != nil {
-		payload.HTTPResponseStatusCode = &response.StatusCode
-		payload.ErrorMessage = http.StatusText(response.StatusCode)
-
-		body, err := io.ReadAll(response.Body)
-		if err == nil && len(body) > 0 {
-			payload.ErrorMessage = string(body)
-		}
+		HTTPResponseStatusCode: delivery.ResponseStatusCode,
+		ErrorMessage:           service.getDeliveryErrorMessage(delivery),
 	}
 
-	event, err = service.createEvent(events.EventTypeWebhookSendFailed, event.Source(), payload)
+	event, err := service.createEvent(events.EventTypeWebhookSendFailed, event.Source(), payload)
 	if err != nil {
 		msg := fmt.Sprintf("cannot create event [%s] for user with id [%s]", events.EventTypeWebhookSendFailed, payload.UserID)
 		ctxLogger.Error(service.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
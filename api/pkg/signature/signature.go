@@ -0,0 +1,119 @@
+// Package signature signs and verifies the HMAC-SHA256 signature headers which are sent with httpSMS webhook events.
+//
+// The signature is computed over the timestamp and the raw request body joined with a "." e.g. "1717596362.{...}"
+// and it is sent in the X-Httpsms-Signature header as "v1=<hex encoded signature>". While a signing key is being
+// rotated, the header contains one signature for each active key separated with a comma e.g. "v1=abc,v1=def".
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderSignature is the HTTP header containing the signatures of the request body
+	HeaderSignature = "X-Httpsms-Signature"
+
+	// HeaderTimestamp is the HTTP header containing the unix timestamp in seconds when the request was signed
+	HeaderTimestamp = "X-Httpsms-Timestamp"
+
+	// Version is the prefix of each signature in the HeaderSignature header
+	Version = "v1"
+
+	// DefaultTolerance is the maximum age of a signed request which is accepted by Verify
+	DefaultTolerance = 5 * time.Minute
+)
+
+var (
+	// ErrMissingHeader is returned when the signature or timestamp header is empty
+	ErrMissingHeader = errors.New("signature: missing signature or timestamp header")
+
+	// ErrInvalidTimestamp is returned when the timestamp header is not a unix timestamp
+	ErrInvalidTimestamp = errors.New("signature: invalid timestamp header")
+
+	// ErrTimestampOutsideTolerance is returned when the request was signed too long ago, which protects against replay attacks
+	ErrTimestampOutsideTolerance = errors.New("signature: timestamp is outside the tolerance")
+
+	// ErrNoValidSignature is returned when none of the signatures in the header matches the signing key
+	ErrNoValidSignature = errors.New("signature: no valid signature found")
+)
+
+// Compute returns the hex encoded HMAC-SHA256 signature of the payload at the given timestamp
+func Compute(secret string, timestamp time.Time, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Header returns the value of the HeaderSignature header with one signature for each secret
+func Header(timestamp time.Time, payload []byte, secrets ...string) string {
+	signatures := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		signatures = append(signatures, fmt.Sprintf("%s=%s", Version, Compute(secret, timestamp, payload)))
+	}
+	return strings.Join(signatures, ",")
+}
+
+// Verify checks that one of the signatures in the signature header was created with the secret for the payload
+// and that the timestamp header is not older than the tolerance. A tolerance of 0 uses the DefaultTolerance.
+func Verify(payload []byte, signatureHeader string, timestampHeader string, secret string, tolerance time.Duration) error {
+	if strings.TrimSpace(signatureHeader) == "" || strings.TrimSpace(timestampHeader) == "" {
+		return ErrMissingHeader
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(timestampHeader), 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	timestamp := time.Unix(seconds, 0)
+	if age := time.Since(timestamp); age > tolerance || age < -tolerance {
+		return ErrTimestampOutsideTolerance
+	}
+
+	expected := []byte(Compute(secret, timestamp, payload))
+	for _, value := range strings.Split(signatureHeader, ",") {
+		version, signature, found := strings.Cut(strings.TrimSpace(value), "=")
+		if !found || version != Version {
+			continue
+		}
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+
+	return ErrNoValidSignature
+}
+
+// VerifyRequest verifies the signature headers of a webhook request and returns the request body.
+// The body of the request is replaced so that it can be read again by the caller.
+func VerifyRequest(request *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	payload, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, fmt.Errorf("signature: cannot read request body: %w", err)
+	}
+
+	_ = request.Body.Close()
+	request.Body = io.NopCloser(bytes.NewReader(payload))
+
+	if err = Verify(payload, request.Header.Get(HeaderSignature), request.Header.Get(HeaderTimestamp), secret, tolerance); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
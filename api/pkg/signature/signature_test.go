@@ -0,0 +1,202 @@
+package signature
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"type":"message.phone.received"}`)
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		signature string
+		timestamp string
+		secret    string
+		tolerance time.Duration
+		err       error
+	}{
+		{
+			name:      "signature created with the secret is valid",
+			signature: Header(now, payload, "secret"),
+			timestamp: unix(now),
+			secret:    "secret",
+		},
+		{
+			name:      "signature created with a different secret is invalid",
+			signature: Header(now, payload, "other-secret"),
+			timestamp: unix(now),
+			secret:    "secret",
+			err:       ErrNoValidSignature,
+		},
+		{
+			name:      "signature of a different payload is invalid",
+			signature: Header(now, []byte(`{}`), "secret"),
+			timestamp: unix(now),
+			secret:    "secret",
+			err:       ErrNoValidSignature,
+		},
+		{
+			name:      "signature is invalid when the timestamp header does not match the signed timestamp",
+			signature: Header(now, payload, "secret"),
+			timestamp: unix(now.Add(-time.Second)),
+			secret:    "secret",
+			err:       ErrNoValidSignature,
+		},
+		{
+			name:      "old signature is valid during key rotation",
+			signature: Header(now, payload, "new-secret", "old-secret"),
+			timestamp: unix(now),
+			secret:    "old-secret",
+		},
+		{
+			name:      "new signature is valid during key rotation",
+			signature: Header(now, payload, "new-secret", "old-secret"),
+			timestamp: unix(now),
+			secret:    "new-secret",
+		},
+		{
+			name:      "whitespace around the signatures is ignored",
+			signature: strings.ReplaceAll(Header(now, payload, "new-secret", "old-secret"), ",", " , "),
+			timestamp: unix(now),
+			secret:    "old-secret",
+		},
+		{
+			name:      "signature with an unknown version is ignored",
+			signature: "v0=" + Compute("secret", now, payload),
+			timestamp: unix(now),
+			secret:    "secret",
+			err:       ErrNoValidSignature,
+		},
+		{
+			name:      "timestamp within the tolerance is valid",
+			signature: Header(now.Add(-4*time.Minute), payload, "secret"),
+			timestamp: unix(now.Add(-4 * time.Minute)),
+			secret:    "secret",
+		},
+		{
+			name:      "expired timestamp is invalid",
+			signature: Header(now.Add(-6*time.Minute), payload, "secret"),
+			timestamp: unix(now.Add(-6 * time.Minute)),
+			secret:    "secret",
+			err:       ErrTimestampOutsideTolerance,
+		},
+		{
+			name:      "future timestamp is invalid",
+			signature: Header(now.Add(6*time.Minute), payload, "secret"),
+			timestamp: unix(now.Add(6 * time.Minute)),
+			secret:    "secret",
+			err:       ErrTimestampOutsideTolerance,
+		},
+		{
+			name:      "custom tolerance is used",
+			signature: Header(now.Add(-2*time.Minute), payload, "secret"),
+			timestamp: unix(now.Add(-2 * time.Minute)),
+			secret:    "secret",
+			tolerance: time.Minute,
+			err:       ErrTimestampOutsideTolerance,
+		},
+		{
+			name:      "empty signature header is invalid",
+			signature: "",
+			timestamp: unix(now),
+			secret:    "secret",
+			err:       ErrMissingHeader,
+		},
+		{
+			name:      "empty timestamp header is invalid",
+			signature: Header(now, payload, "secret"),
+			timestamp: " ",
+			secret:    "secret",
+			err:       ErrMissingHeader,
+		},
+		{
+			name:      "timestamp header which is not a number is invalid",
+			signature: Header(now, payload, "secret"),
+			timestamp: now.Format(time.RFC3339),
+			secret:    "secret",
+			err:       ErrInvalidTimestamp,
+		},
+		{
+			name:      "signature header without a version is invalid",
+			signature: Compute("secret", now, payload),
+			timestamp: unix(now),
+			secret:    "secret",
+			err:       ErrNoValidSignature,
+		},
+		{
+			name:      "signature header with empty values is invalid",
+			signature: ",v1=,=",
+			timestamp: unix(now),
+			secret:    "secret",
+			err:       ErrNoValidSignature,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			// Setup
+			t.Parallel()
+
+			// Act
+			err := Verify(payload, test.signature, test.timestamp, test.secret, test.tolerance)
+
+			// Assert
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	t.Run("body can be read again after the request is verified", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		payload := `{"type":"message.phone.received"}`
+		now := time.Now()
+
+		request, err := http.NewRequest(http.MethodPost, "https://example.com/webhook", strings.NewReader(payload))
+		assert.Nil(t, err)
+		request.Header.Set(HeaderSignature, Header(now, []byte(payload), "secret"))
+		request.Header.Set(HeaderTimestamp, unix(now))
+
+		// Act
+		verified, err := VerifyRequest(request, "secret", 0)
+
+		// Assert
+		assert.Nil(t, err)
+		assert.Equal(t, payload, string(verified))
+
+		body, err := io.ReadAll(request.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, payload, string(body))
+	})
+
+	t.Run("request without signature headers is invalid", func(t *testing.T) {
+		// Setup
+		t.Parallel()
+
+		// Arrange
+		request, err := http.NewRequest(http.MethodPost, "https://example.com/webhook", strings.NewReader(`{}`))
+		assert.Nil(t, err)
+
+		// Act
+		_, err = VerifyRequest(request, "secret", 0)
+
+		// Assert
+		assert.Equal(t, ErrMissingHeader, err)
+	})
+}
+
+func unix(timestamp time.Time) string {
+	return strconv.FormatInt(timestamp.Unix(), 10)
+}
@@ -0,0 +1,12 @@
+package slack
+
+// AuthTestResponse is the response of the auth.test method
+type AuthTestResponse struct {
+	OK     bool   `json:"ok"`
+	URL    string `json:"url"`
+	Team   string `json:"team"`
+	User   string `json:"user"`
+	TeamID string `json:"team_id"`
+	UserID string `json:"user_id"`
+	BotID  string `json:"bot_id"`
+}
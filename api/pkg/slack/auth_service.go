@@ -0,0 +1,31 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// AuthService is the API client for the auth methods
+type AuthService service
+
+// Test checks the bot token and returns the workspace in which the bot is installed.
+//
+// API Docs: https://api.slack.com/methods/auth.test
+func (service *AuthService) Test(ctx context.Context, token string) (*AuthTestResponse, *Response, error) {
+	request, err := service.client.newRequest(ctx, token, "auth.test", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, err := service.client.do(request)
+	if err != nil {
+		return nil, response, err
+	}
+
+	result := new(AuthTestResponse)
+	if err = json.Unmarshal(*response.Body, result); err != nil {
+		return nil, response, err
+	}
+
+	return result, response, nil
+}
@@ -0,0 +1,13 @@
+package slack
+
+import "context"
+
+// ChatService is the API client for the chat methods
+type ChatService service
+
+// PostMessage sends a message to a channel.
+//
+// API Docs: https://api.slack.com/methods/chat.postMessage
+func (service *ChatService) PostMessage(ctx context.Context, token string, payload map[string]any) (map[string]any, *Response, error) {
+	return service.client.call(ctx, token, "chat.postMessage", payload)
+}
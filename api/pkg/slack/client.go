@@ -0,0 +1,146 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+type service struct {
+	client *Client
+}
+
+// Client is the slack Web API client.
+// Do not instantiate this client with Client{}. Use the New method instead.
+type Client struct {
+	httpClient *http.Client
+	common     service
+	baseURL    string
+
+	Auth          *AuthService
+	Chat          *ChatService
+	Conversations *ConversationsService
+	Views         *ViewsService
+}
+
+// New creates and returns a new slack.Client from a slice of slack.ClientOption.
+func New(options ...Option) *Client {
+	config := defaultClientConfig()
+
+	for _, option := range options {
+		option.apply(config)
+	}
+
+	client := &Client{
+		httpClient: config.httpClient,
+		baseURL:    config.baseURL,
+	}
+
+	client.common.client = client
+
+	client.Auth = (*AuthService)(&client.common)
+	client.Chat = (*ChatService)(&client.common)
+	client.Conversations = (*ConversationsService)(&client.common)
+	client.Views = (*ViewsService)(&client.common)
+
+	return client
+}
+
+// newRequest creates an API request for a Web API method e.g. chat.postMessage. The bot token is sent with each
+// request because every slack workspace has its own token. A url.Values body is form encoded because read methods
+// like conversations.info do not accept JSON.
+func (client *Client) newRequest(ctx context.Context, token string, method string, body any) (*http.Request, error) {
+	buf := &bytes.Buffer{}
+	contentType := "application/json; charset=utf-8"
+
+	if values, ok := body.(url.Values); ok {
+		buf.WriteString(values.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	} else if body != nil {
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		err := enc.Encode(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.baseURL+"/"+method, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return req, nil
+}
+
+// do carries out an HTTP request and returns a Response
+func (client *Client) do(req *http.Request) (*Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("%T cannot be nil", req)
+	}
+
+	httpResponse, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = httpResponse.Body.Close() }()
+
+	resp, err := client.newResponse(httpResponse)
+	if err != nil {
+		return resp, err
+	}
+
+	_, err = io.Copy(io.Discard, httpResponse.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, nil
+}
+
+// newResponse converts an *http.Response to *Response
+func (client *Client) newResponse(httpResponse *http.Response) (*Response, error) {
+	if httpResponse == nil {
+		return nil, fmt.Errorf("%T cannot be nil", httpResponse)
+	}
+
+	resp := new(Response)
+	resp.HTTPResponse = httpResponse
+
+	buf, err := io.ReadAll(resp.HTTPResponse.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &buf
+
+	return resp, resp.Error()
+}
+
+// call sends a request to a Web API method and decodes the response
+func (client *Client) call(ctx context.Context, token string, method string, body any) (map[string]any, *Response, error) {
+	request, err := client.newRequest(ctx, token, method, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, err := client.do(request)
+	if err != nil {
+		return nil, response, err
+	}
+
+	result := map[string]any{}
+	if err = json.Unmarshal(*response.Body, &result); err != nil {
+		return nil, response, err
+	}
+
+	return result, response, nil
+}
@@ -0,0 +1,15 @@
+package slack
+
+import "net/http"
+
+type clientConfig struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func defaultClientConfig() *clientConfig {
+	return &clientConfig{
+		httpClient: http.DefaultClient,
+		baseURL:    "https://slack.com/api",
+	}
+}
@@ -0,0 +1,16 @@
+package slack
+
+import (
+	"context"
+	"net/url"
+)
+
+// ConversationsService is the API client for the conversations methods
+type ConversationsService service
+
+// Info fetches a channel by ID
+//
+// API Docs: https://api.slack.com/methods/conversations.info
+func (service *ConversationsService) Info(ctx context.Context, token string, channelID string) (map[string]any, *Response, error) {
+	return service.client.call(ctx, token, "conversations.info", url.Values{"channel": {channelID}})
+}
@@ -0,0 +1,58 @@
+package slack
+
+// InteractionTypeBlockActions is the type of the payload sent when a user clicks a button in a message
+const InteractionTypeBlockActions = "block_actions"
+
+// InteractionTypeViewSubmission is the type of the payload sent when a user submits a modal
+const InteractionTypeViewSubmission = "view_submission"
+
+// InteractionPayload is the payload sent to the request URL of the interactivity settings of a slack app
+//
+// API Docs: https://api.slack.com/reference/interaction-payloads
+type InteractionPayload struct {
+	Type      string `json:"type"`
+	TriggerID string `json:"trigger_id"`
+	Team      struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	Message struct {
+		TS string `json:"ts"`
+	} `json:"message"`
+	Actions []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	View struct {
+		CallbackID      string `json:"callback_id"`
+		PrivateMetadata string `json:"private_metadata"`
+		State           struct {
+			Values map[string]map[string]struct {
+				Value string `json:"value"`
+			} `json:"values"`
+		} `json:"state"`
+	} `json:"view"`
+}
+
+// ActionID returns the ID of the first action in a block_actions payload
+func (payload *InteractionPayload) ActionID() string {
+	if len(payload.Actions) == 0 {
+		return ""
+	}
+	return payload.Actions[0].ActionID
+}
+
+// ActionValue returns the value of the first action in a block_actions payload
+func (payload *InteractionPayload) ActionValue() string {
+	if len(payload.Actions) == 0 {
+		return ""
+	}
+	return payload.Actions[0].Value
+}
+
+// InputValue returns the value of an input in a submitted modal
+func (payload *InteractionPayload) InputValue(blockID string, actionID string) string {
+	return payload.View.State.Values[blockID][actionID].Value
+}
@@ -0,0 +1,44 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// Response captures the http response
+type Response struct {
+	HTTPResponse *http.Response
+	Body         *[]byte
+}
+
+// Error ensures that the response can be decoded into a string in case it's an error response.
+// The slack Web API returns a 200 status code with "ok": false when a request fails.
+func (r *Response) Error() error {
+	switch r.HTTPResponse.StatusCode {
+	case 200, 201, 202, 204, 205:
+		payload := struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}{}
+		if err := json.Unmarshal(*r.Body, &payload); err != nil || payload.OK {
+			return nil
+		}
+		return errors.New(r.errorMessage())
+	default:
+		return errors.New(r.errorMessage())
+	}
+}
+
+func (r *Response) errorMessage() string {
+	var buf bytes.Buffer
+	buf.WriteString(strconv.Itoa(r.HTTPResponse.StatusCode))
+	buf.WriteString(": ")
+	buf.WriteString(http.StatusText(r.HTTPResponse.StatusCode))
+	buf.WriteString(", Body: ")
+	buf.Write(*r.Body)
+
+	return buf.String()
+}
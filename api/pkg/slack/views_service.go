@@ -0,0 +1,13 @@
+package slack
+
+import "context"
+
+// ViewsService is the API client for the views methods
+type ViewsService service
+
+// Open opens a modal for the user who triggered an interaction
+//
+// API Docs: https://api.slack.com/methods/views.open
+func (service *ViewsService) Open(ctx context.Context, token string, triggerID string, view map[string]any) (map[string]any, *Response, error) {
+	return service.client.call(ctx, token, "views.open", map[string]any{"trigger_id": triggerID, "view": view})
+}
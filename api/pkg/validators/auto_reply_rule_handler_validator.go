@@ -0,0 +1,163 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/palantir/stacktrace"
+	"github.com/thedevsaddam/govalidator"
+)
+
+// AutoReplyRuleHandlerValidator validates models used in handlers.AutoReplyRuleHandler
+type AutoReplyRuleHandlerValidator struct {
+	validator
+	logger       telemetry.Logger
+	tracer       telemetry.Tracer
+	phoneService *services.PhoneService
+}
+
+// NewAutoReplyRuleHandlerValidator creates a new handlers.AutoReplyRuleHandler validator
+func NewAutoReplyRuleHandlerValidator(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	phoneService *services.PhoneService,
+) (v *AutoReplyRuleHandlerValidator) {
+	return &AutoReplyRuleHandlerValidator{
+		logger:       logger.WithService(fmt.Sprintf("%T", v)),
+		tracer:       tracer,
+		phoneService: phoneService,
+	}
+}
+
+// ValidateIndex validates the requests.AutoReplyRuleIndex request
+func (validator *AutoReplyRuleHandlerValidator) ValidateIndex(_ context.Context, request requests.AutoReplyRuleIndex) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"limit": []string{
+				"required",
+				"numeric",
+				"min:1",
+				"max:100",
+			},
+			"skip": []string{
+				"required",
+				"numeric",
+				"min:0",
+			},
+			"query": []string{
+				"max:100",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
+// ValidateStore validates the requests.AutoReplyRuleStore request
+func (validator *AutoReplyRuleHandlerValidator) ValidateStore(ctx context.Context, userID entities.UserID, request requests.AutoReplyRuleStore) url.Values {
+	ctx, span, ctxLogger := validator.tracer.StartWithLogger(ctx, validator.logger)
+	defer span.End()
+
+	result := validator.validateStore(request)
+	if len(result) != 0 {
+		return result
+	}
+
+	_, err := validator.phoneService.Load(ctx, userID, request.Owner)
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		result.Add("owner", fmt.Sprintf("no phone found with with 'owner' [%s]. Install the android app on your phone to start receiving messages", request.Owner))
+	} else if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("could not load phone with owner [%s] for user [%s]", request.Owner, userID)))
+		result.Add("owner", fmt.Sprintf("could not validate the phone [%s], please try again later", request.Owner))
+	}
+
+	return result
+}
+
+// ValidateUpdate validates the requests.AutoReplyRuleUpdate request
+func (validator *AutoReplyRuleHandlerValidator) ValidateUpdate(ctx context.Context, userID entities.UserID, request requests.AutoReplyRuleUpdate) url.Values {
+	result := validator.ValidateUUID(ctx, request.AutoReplyRuleID, "autoReplyRuleID")
+	if len(result) != 0 {
+		return result
+	}
+	return validator.ValidateStore(ctx, userID, request.AutoReplyRuleStore)
+}
+
+func (validator *AutoReplyRuleHandlerValidator) validateStore(request requests.AutoReplyRuleStore) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"owner": []string{
+				"required",
+				phoneNumberRule,
+			},
+			"name": []string{
+				"required",
+				"min:1",
+				"max:255",
+			},
+			"match_type": []string{
+				"required",
+				"in:" + entities.AutoReplyMatchTypeKeyword.String() + "," + entities.AutoReplyMatchTypeRegex.String() + "," + entities.AutoReplyMatchTypeAny.String(),
+			},
+			"pattern": []string{
+				"max:255",
+			},
+			"contacts": []string{
+				"max:100",
+				multipleContactPhoneNumberRule,
+			},
+			"start_time": []string{
+				"regex:^([01][0-9]|2[0-3]):[0-5][0-9]$",
+			},
+			"end_time": []string{
+				"regex:^([01][0-9]|2[0-3]):[0-5][0-9]$",
+			},
+			"cooldown_seconds": []string{
+				fmt.Sprintf("min:%d", entities.AutoReplyMinCooldownSeconds),
+				"max:2678400",
+			},
+			"reply": []string{
+				"required",
+				"min:1",
+				"max:1024",
+			},
+		},
+		Messages: govalidator.MapData{
+			"start_time": []string{
+				"regex:The start_time must be in the 24 hour format HH:MM e.g. 18:00",
+			},
+			"end_time": []string{
+				"regex:The end_time must be in the 24 hour format HH:MM e.g. 08:00",
+			},
+		},
+	})
+
+	result := v.ValidateStruct()
+	if len(result) != 0 {
+		return result
+	}
+
+	if (request.StartTime == "") != (request.EndTime == "") {
+		result.Add("start_time", "The start_time and end_time fields must be set together")
+	}
+
+	if request.MatchType != entities.AutoReplyMatchTypeAny.String() && request.Pattern == "" {
+		result.Add("pattern", fmt.Sprintf("The pattern field is required when the match_type is [%s]", request.MatchType))
+	}
+
+	if request.MatchType == entities.AutoReplyMatchTypeRegex.String() {
+		if _, err := regexp.Compile(request.Pattern); err != nil {
+			result.Add("pattern", fmt.Sprintf("The pattern field must be a valid regular expression: %s", err.Error()))
+		}
+	}
+
+	return result
+}
@@ -26,10 +26,12 @@ import (
 // BulkMessageHandlerValidator validates models used in handlers.BillingHandler
 type BulkMessageHandlerValidator struct {
 	validator
-	phoneService *services.PhoneService
-	userService  *services.UserService
-	logger       telemetry.Logger
-	tracer       telemetry.Tracer
+	phoneService       *services.PhoneService
+	userService        *services.UserService
+	campaignService    *services.CampaignService
+	suppressionService *services.SuppressionService
+	logger             telemetry.Logger
+	tracer             telemetry.Tracer
 }
 
 // NewBulkMessageHandlerValidator creates a new handlers.BulkMessageHandlerValidator validator
@@ -38,20 +40,32 @@ func NewBulkMessageHandlerValidator(
 	tracer telemetry.Tracer,
 	phoneService *services.PhoneService,
 	userService *services.UserService,
+	campaignService *services.CampaignService,
+	suppressionService *services.SuppressionService,
 ) (v *BulkMessageHandlerValidator) {
 	return &BulkMessageHandlerValidator{
-		logger:       logger.WithService(fmt.Sprintf("%T", v)),
-		tracer:       tracer,
-		userService:  userService,
-		phoneService: phoneService,
+		logger:             logger.WithService(fmt.Sprintf("%T", v)),
+		tracer:             tracer,
+		userService:        userService,
+		phoneService:       phoneService,
+		campaignService:    campaignService,
+		suppressionService: suppressionService,
 	}
 }
 
 // ValidateStore validates the requests.BillingUsageHistory request
-func (v *BulkMessageHandlerValidator) ValidateStore(ctx context.Context, userID entities.UserID, header *multipart.FileHeader) ([]*requests.BulkMessage, url.Values) {
+func (v *BulkMessageHandlerValidator) ValidateStore(ctx context.Context, userID entities.UserID, header *multipart.FileHeader, campaignID string) ([]*requests.BulkMessage, url.Values) {
 	ctx, span, ctxLogger := v.tracer.StartWithLogger(ctx, v.logger)
 	defer span.End()
 
+	if campaignID != "" {
+		if msg := v.validateCampaign(ctx, ctxLogger, v.campaignService, userID, campaignID); msg != "" {
+			result := url.Values{}
+			result.Add("campaign_id", msg)
+			return nil, result
+		}
+	}
+
 	user, err := v.userService.GetByID(ctx, userID)
 	if err != nil {
 		result := url.Values{}
@@ -89,7 +103,7 @@ func (v *BulkMessageHandlerValidator) ValidateStore(ctx context.Context, userID
 		return messages, result
 	}
 
-	return messages, result
+	return messages, v.validateContacts(ctx, ctxLogger, userID, messages)
 }
 
 func (v *BulkMessageHandlerValidator) parseFile(ctxLogger telemetry.Logger, user *entities.User, header *multipart.FileHeader) ([]*requests.BulkMessage, url.Values) {
@@ -143,11 +157,17 @@ func (v *BulkMessageHandlerValidator) parseXlsx(ctxLogger telemetry.Logger, user
 			}
 		}
 
+		var callbackURL string
+		if len(row) > 4 {
+			callbackURL = strings.TrimSpace(row[4])
+		}
+
 		messages = append(messages, &requests.BulkMessage{
 			FromPhoneNumber: strings.TrimSpace(row[0]),
 			ToPhoneNumber:   strings.TrimSpace(row[1]),
 			Content:         row[2],
 			SendTime:        sendAt,
+			CallbackURL:     callbackURL,
 		})
 	}
 
@@ -227,6 +247,10 @@ func (v *BulkMessageHandlerValidator) validateMessages(messages []*requests.Bulk
 		if message.SendTime != nil && message.SendTime.After(time.Now().Add(24*time.Hour)) {
 			result.Add("document", fmt.Sprintf("Row [%d]: The SendTime [%s] cannot be more than 24 hours in the future.", index+2, message.SendTime.Format(time.RFC3339)))
 		}
+
+		if message.CallbackURL != "" && !v.isCallbackURL(message.CallbackURL) {
+			result.Add("document", fmt.Sprintf("Row [%d]: The CallbackURL [%s] must be a valid http or https URL with less than 255 characters.", index+2, message.CallbackURL))
+		}
 	}
 	return result
 }
@@ -247,6 +271,30 @@ func (v *BulkMessageHandlerValidator) validateOwners(ctx context.Context, userID
 	return result
 }
 
+func (v *BulkMessageHandlerValidator) validateContacts(ctx context.Context, ctxLogger telemetry.Logger, userID entities.UserID, messages []*requests.BulkMessage) url.Values {
+	numbers := map[string][]int{}
+	var contacts []string
+	for index, message := range messages {
+		if _, ok := numbers[message.ToPhoneNumber]; !ok {
+			contacts = append(contacts, message.ToPhoneNumber)
+		}
+		numbers[message.ToPhoneNumber] = append(numbers[message.ToPhoneNumber], index+2)
+	}
+
+	result := url.Values{}
+	suppressed, err := v.suppressionService.FilterSuppressed(ctx, userID, contacts)
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("cannot filter suppressed contacts for user [%s]", userID)))
+		result.Add("document", "Cannot check if the contacts opted out of receiving messages. Please try again later or contact support.")
+		return result
+	}
+
+	for _, contact := range suppressed {
+		result.Add("document", fmt.Sprintf("Rows [%s]: The ToPhoneNumber [%s] opted out of receiving messages and is on your suppression list", v.toString(numbers[contact]), contact))
+	}
+	return result
+}
+
 func (v *BulkMessageHandlerValidator) toString(value []int) string {
 	result := strings.Builder{}
 	for index, row := range value {
@@ -0,0 +1,68 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/thedevsaddam/govalidator"
+)
+
+// CampaignHandlerValidator validates models used in handlers.CampaignHandler
+type CampaignHandlerValidator struct {
+	validator
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+}
+
+// NewCampaignHandlerValidator creates a new handlers.CampaignHandler validator
+func NewCampaignHandlerValidator(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+) (v *CampaignHandlerValidator) {
+	return &CampaignHandlerValidator{
+		logger: logger.WithService(fmt.Sprintf("%T", v)),
+		tracer: tracer,
+	}
+}
+
+// ValidateIndex validates the requests.CampaignIndex request
+func (validator *CampaignHandlerValidator) ValidateIndex(_ context.Context, request requests.CampaignIndex) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"limit": []string{
+				"required",
+				"numeric",
+				"min:1",
+				"max:100",
+			},
+			"skip": []string{
+				"required",
+				"numeric",
+				"min:0",
+			},
+			"query": []string{
+				"max:100",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
+// ValidateStore validates the requests.CampaignStore request
+func (validator *CampaignHandlerValidator) ValidateStore(_ context.Context, request requests.CampaignStore) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"name": []string{
+				"required",
+				"min:1",
+				"max:255",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
@@ -0,0 +1,152 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/NdoleStudio/httpsms/pkg/entities"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/palantir/stacktrace"
+	"github.com/thedevsaddam/govalidator"
+)
+
+// ContactListHandlerValidator validates models used in handlers.ContactListHandler
+type ContactListHandlerValidator struct {
+	validator
+	logger  telemetry.Logger
+	tracer  telemetry.Tracer
+	service *services.ContactListService
+}
+
+// NewContactListHandlerValidator creates a new handlers.ContactListHandler validator
+func NewContactListHandlerValidator(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	service *services.ContactListService,
+) (v *ContactListHandlerValidator) {
+	return &ContactListHandlerValidator{
+		logger:  logger.WithService(fmt.Sprintf("%T", v)),
+		tracer:  tracer,
+		service: service,
+	}
+}
+
+// ValidateIndex validates the requests.ContactListIndex request
+func (validator *ContactListHandlerValidator) ValidateIndex(_ context.Context, request requests.ContactListIndex) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"limit": []string{
+				"required",
+				"numeric",
+				"min:1",
+				"max:100",
+			},
+			"skip": []string{
+				"required",
+				"numeric",
+				"min:0",
+			},
+			"query": []string{
+				"max:100",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
+// ValidateStore validates the requests.ContactListStore request
+func (validator *ContactListHandlerValidator) ValidateStore(ctx context.Context, userID entities.UserID, request requests.ContactListStore) url.Values {
+	ctx, span, ctxLogger := validator.tracer.StartWithLogger(ctx, validator.logger)
+	defer span.End()
+
+	result := validator.validateStore(request)
+	if len(result) != 0 {
+		return result
+	}
+
+	_, err := validator.service.LoadByKeyword(ctx, userID, request.Keyword)
+	if err == nil {
+		result.Add("keyword", fmt.Sprintf("you already have a contact list with the keyword [%s]", request.Keyword))
+	} else if stacktrace.GetCode(err) != repositories.ErrCodeNotFound {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("could not load contact list with keyword [%s] for user [%s]", request.Keyword, userID)))
+		result.Add("keyword", fmt.Sprintf("could not validate the keyword [%s], please try again later", request.Keyword))
+	}
+
+	return result
+}
+
+// ValidateUpdate validates the requests.ContactListUpdate request
+func (validator *ContactListHandlerValidator) ValidateUpdate(ctx context.Context, userID entities.UserID, request requests.ContactListUpdate) url.Values {
+	ctx, span, ctxLogger := validator.tracer.StartWithLogger(ctx, validator.logger)
+	defer span.End()
+
+	result := validator.ValidateUUID(ctx, request.ContactListID, "contactListID")
+	if len(result) != 0 {
+		return result
+	}
+
+	result = validator.validateStore(request.ContactListStore)
+	if len(result) != 0 {
+		return result
+	}
+
+	list, err := validator.service.LoadByKeyword(ctx, userID, request.Keyword)
+	if err == nil && list.ID.String() != request.ContactListID {
+		result.Add("keyword", fmt.Sprintf("you already have a contact list with the keyword [%s]", request.Keyword))
+	} else if err != nil && stacktrace.GetCode(err) != repositories.ErrCodeNotFound {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("could not load contact list with keyword [%s] for user [%s]", request.Keyword, userID)))
+		result.Add("keyword", fmt.Sprintf("could not validate the keyword [%s], please try again later", request.Keyword))
+	}
+
+	return result
+}
+
+// ValidateMemberStore validates the requests.ContactListMemberStore request
+func (validator *ContactListHandlerValidator) ValidateMemberStore(_ context.Context, request requests.ContactListMemberStore) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"contact": []string{
+				"required",
+				contactPhoneNumberRule,
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
+func (validator *ContactListHandlerValidator) validateStore(request requests.ContactListStore) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"name": []string{
+				"required",
+				"min:1",
+				"max:255",
+			},
+			"keyword": []string{
+				"required",
+				"min:1",
+				"max:50",
+				"regex:^[A-Z0-9]+$",
+			},
+			"join_reply": []string{
+				"max:1024",
+			},
+			"leave_reply": []string{
+				"max:1024",
+			},
+		},
+		Messages: govalidator.MapData{
+			"keyword": []string{
+				"regex:The keyword must be a single word containing only letters and digits",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
@@ -0,0 +1,51 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+)
+
+// streamEventIDRegex matches the ID of an entities.StreamEvent e.g. 1717596362302-0
+var streamEventIDRegex = regexp.MustCompile(`^\d+-\d+$`)
+
+// EventStreamHandlerValidator validates models used in handlers.EventStreamHandler
+type EventStreamHandlerValidator struct {
+	validator
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+}
+
+// NewEventStreamHandlerValidator creates a new handlers.EventStreamHandler validator
+func NewEventStreamHandlerValidator(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+) (v *EventStreamHandlerValidator) {
+	return &EventStreamHandlerValidator{
+		logger: logger.WithService(fmt.Sprintf("%T", v)),
+		tracer: tracer,
+	}
+}
+
+// ValidateStream validates the requests.EventStream request
+func (validator *EventStreamHandlerValidator) ValidateStream(_ context.Context, request requests.EventStream) url.Values {
+	result := url.Values{}
+	for _, eventType := range request.GetEventTypes() {
+		if !slices.Contains(services.StreamEventTypes, eventType) {
+			result.Add("event_types", fmt.Sprintf("The event type [%s] is not supported. It must be one of [%s]", eventType, strings.Join(services.StreamEventTypes, ", ")))
+		}
+	}
+
+	if request.LastEventID != "" && !streamEventIDRegex.MatchString(request.LastEventID) {
+		result.Add("last_event_id", fmt.Sprintf("The last event ID [%s] is not a valid event ID e.g. 1717596362302-0", request.LastEventID))
+	}
+
+	return result
+}
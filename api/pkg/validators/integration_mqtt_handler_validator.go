@@ -0,0 +1,154 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/thedevsaddam/govalidator"
+)
+
+// IntegrationMQTTHandlerValidator validates models used in handlers.IntegrationMQTTHandler
+type IntegrationMQTTHandlerValidator struct {
+	validator
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+}
+
+// NewIntegrationMQTTHandlerValidator creates a new handlers.IntegrationMQTTHandler validator
+func NewIntegrationMQTTHandlerValidator(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+) (v *IntegrationMQTTHandlerValidator) {
+	return &IntegrationMQTTHandlerValidator{
+		logger: logger.WithService(fmt.Sprintf("%T", v)),
+		tracer: tracer,
+	}
+}
+
+// ValidateIndex validates the requests.IntegrationMQTTIndex request
+func (validator *IntegrationMQTTHandlerValidator) ValidateIndex(_ context.Context, request requests.IntegrationMQTTIndex) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"limit": []string{
+				"required",
+				"numeric",
+				"min:1",
+				"max:100",
+			},
+			"skip": []string{
+				"required",
+				"numeric",
+				"min:0",
+			},
+			"query": []string{
+				"max:100",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
+// ValidateStore validates the requests.IntegrationMQTTStore request
+func (validator *IntegrationMQTTHandlerValidator) ValidateStore(_ context.Context, request requests.IntegrationMQTTStore) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data:  &request,
+		Rules: validator.rules(),
+	})
+
+	result := v.ValidateStruct()
+	if len(result) > 0 {
+		return result
+	}
+
+	validator.validateTopics(result, request)
+	return result
+}
+
+// ValidateUpdate validates the requests.IntegrationMQTTUpdate request
+func (validator *IntegrationMQTTHandlerValidator) ValidateUpdate(_ context.Context, request requests.IntegrationMQTTUpdate) url.Values {
+	rules := validator.rules()
+	rules["integrationID"] = []string{
+		"required",
+		"uuid",
+	}
+
+	v := govalidator.New(govalidator.Options{
+		Data:  &request,
+		Rules: rules,
+	})
+
+	result := v.ValidateStruct()
+	if len(result) > 0 {
+		return result
+	}
+
+	validator.validateTopics(result, request.IntegrationMQTTStore)
+	return result
+}
+
+func (validator *IntegrationMQTTHandlerValidator) rules() govalidator.MapData {
+	return govalidator.MapData{
+		"name": []string{
+			"required",
+			"min:1",
+			"max:255",
+		},
+		"host": []string{
+			"required",
+			"max:255",
+		},
+		"port": []string{
+			"min:1",
+			"max:65535",
+		},
+		"username": []string{
+			"max:255",
+		},
+		"password": []string{
+			"max:255",
+		},
+		"topic_template": []string{
+			"required",
+			"max:255",
+		},
+		"qos": []string{
+			"min:0",
+			"max:2",
+		},
+		"events": []string{
+			"required",
+			multipleInRule + ":" + strings.Join(services.StreamEventTypes, ","),
+		},
+		"command_topic": []string{
+			"max:255",
+		},
+	}
+}
+
+// validateTopics validates the host, the topic template and the command topic of requests.IntegrationMQTTStore
+func (validator *IntegrationMQTTHandlerValidator) validateTopics(result url.Values, request requests.IntegrationMQTTStore) {
+	if strings.ContainsAny(request.Host, ":/ ") {
+		result.Add("host", "The host field must be a hostname without the scheme or port e.g. broker.hivemq.com")
+	}
+
+	topic := request.TopicTemplate
+	for _, placeholder := range services.MQTTTopicPlaceholders {
+		topic = strings.ReplaceAll(topic, placeholder, "")
+	}
+	if strings.ContainsAny(topic, "{}") {
+		result.Add("topic_template", fmt.Sprintf("The topic_template field can only contain the %s placeholders", strings.Join(services.MQTTTopicPlaceholders, ", ")))
+	}
+	if strings.ContainsAny(topic, "+#") || strings.HasPrefix(topic, "$") {
+		result.Add("topic_template", "The topic_template field cannot contain the [+] or [#] wildcards or start with [$]")
+	}
+
+	if strings.HasPrefix(request.CommandTopic, "$") {
+		result.Add("command_topic", "The command_topic field cannot start with [$]")
+	}
+}
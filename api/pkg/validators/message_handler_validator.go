@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/NdoleStudio/httpsms/pkg/repositories"
 	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/google/uuid"
 	"github.com/palantir/stacktrace"
 
 	"github.com/NdoleStudio/httpsms/pkg/entities"
@@ -20,10 +22,13 @@ import (
 // MessageHandlerValidator validates models used in handlers.MessageHandler
 type MessageHandlerValidator struct {
 	validator
-	logger         telemetry.Logger
-	tracer         telemetry.Tracer
-	phoneService   *services.PhoneService
-	tokenValidator *TurnstileTokenValidator
+	logger             telemetry.Logger
+	tracer             telemetry.Tracer
+	phoneService       *services.PhoneService
+	campaignService    *services.CampaignService
+	suppressionService *services.SuppressionService
+	contactListService *services.ContactListService
+	tokenValidator     *TurnstileTokenValidator
 }
 
 // NewMessageHandlerValidator creates a new handlers.MessageHandler validator
@@ -31,13 +36,19 @@ func NewMessageHandlerValidator(
 	logger telemetry.Logger,
 	tracer telemetry.Tracer,
 	phoneService *services.PhoneService,
+	campaignService *services.CampaignService,
+	suppressionService *services.SuppressionService,
+	contactListService *services.ContactListService,
 	tokenValidator *TurnstileTokenValidator,
 ) (v *MessageHandlerValidator) {
 	return &MessageHandlerValidator{
-		logger:         logger.WithService(fmt.Sprintf("%T", v)),
-		tracer:         tracer,
-		phoneService:   phoneService,
-		tokenValidator: tokenValidator,
+		logger:             logger.WithService(fmt.Sprintf("%T", v)),
+		tracer:             tracer,
+		phoneService:       phoneService,
+		campaignService:    campaignService,
+		suppressionService: suppressionService,
+		contactListService: contactListService,
+		tokenValidator:     tokenValidator,
 	}
 }
 
@@ -97,6 +108,10 @@ func (validator MessageHandlerValidator) ValidateMessageSend(ctx context.Context
 				"min:1",
 				"max:2048",
 			},
+			"ttl": []string{
+				"min:0",
+				"max:2419200",
+			},
 		},
 	})
 
@@ -105,6 +120,17 @@ func (validator MessageHandlerValidator) ValidateMessageSend(ctx context.Context
 		return result
 	}
 
+	validator.validateExpiry(result, request.ExpiresAt, request.TTL, request.SendAt)
+
+	if request.CallbackURL != "" && !validator.isCallbackURL(request.CallbackURL) {
+		result.Add("callback_url", "The callback_url must be a valid http or https URL with less than 255 characters")
+	}
+	validator.validateRetryPolicy(result, request.RetryPolicy, false)
+
+	if request.Split && request.Encrypted {
+		result.Add("split", "encrypted messages cannot be split, split the content before encrypting it instead")
+	}
+
 	_, err := validator.phoneService.Load(ctx, userID, request.From)
 	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
 		result.Add("from", fmt.Sprintf("no phone found with with 'from' number [%s]. install the android app on your phone to start sending messages", request.From))
@@ -115,6 +141,16 @@ func (validator MessageHandlerValidator) ValidateMessageSend(ctx context.Context
 		result.Add("from", fmt.Sprintf("could not validate 'from' number [%s], please try again later", request.From))
 	}
 
+	if request.CampaignID != "" {
+		if msg := validator.validateCampaign(ctx, ctxLogger, validator.campaignService, userID, request.CampaignID); msg != "" {
+			result.Add("campaign_id", msg)
+		}
+	}
+
+	for _, msg := range validator.validateSuppressed(ctx, ctxLogger, validator.suppressionService, userID, []string{request.To}) {
+		result.Add("to", msg)
+	}
+
 	return result
 }
 
@@ -128,12 +164,7 @@ func (validator MessageHandlerValidator) ValidateMessageBulkSend(ctx context.Con
 	v := govalidator.New(govalidator.Options{
 		Data: &request,
 		Rules: govalidator.MapData{
-			"to": []string{
-				"required",
-				"max:1000",
-				"min:1",
-				multipleContactPhoneNumberRule,
-			},
+			"to": validator.bulkSendToRules(request),
 			"from": []string{
 				"required",
 				phoneNumberRule,
@@ -143,6 +174,10 @@ func (validator MessageHandlerValidator) ValidateMessageBulkSend(ctx context.Con
 				"min:1",
 				"max:1024",
 			},
+			"ttl": []string{
+				"min:0",
+				"max:2419200",
+			},
 		},
 	})
 
@@ -151,6 +186,13 @@ func (validator MessageHandlerValidator) ValidateMessageBulkSend(ctx context.Con
 		return result
 	}
 
+	validator.validateExpiry(result, request.ExpiresAt, request.TTL, nil)
+
+	if request.CallbackURL != "" && !validator.isCallbackURL(request.CallbackURL) {
+		result.Add("callback_url", "The callback_url must be a valid http or https URL with less than 255 characters")
+	}
+	validator.validateRetryPolicy(result, request.RetryPolicy, false)
+
 	_, err := validator.phoneService.Load(ctx, userID, request.From)
 	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
 		result.Add("from", fmt.Sprintf("no phone found with with 'from' number [%s]. Install the android app on your phone to start sending messages", request.From))
@@ -161,9 +203,56 @@ func (validator MessageHandlerValidator) ValidateMessageBulkSend(ctx context.Con
 		result.Add("from", fmt.Sprintf("could not validate 'from' number [%s], please try again later", request.From))
 	}
 
+	if request.CampaignID != "" {
+		if msg := validator.validateCampaign(ctx, ctxLogger, validator.campaignService, userID, request.CampaignID); msg != "" {
+			result.Add("campaign_id", msg)
+		}
+	}
+
+	for _, msg := range validator.validateSuppressed(ctx, ctxLogger, validator.suppressionService, userID, request.To) {
+		result.Add("to", msg)
+	}
+
+	if request.ContactListID != "" {
+		if msg := validator.validateContactList(ctx, ctxLogger, userID, request.ContactListID); msg != "" {
+			result.Add("contact_list_id", msg)
+		}
+	}
+
 	return result
 }
 
+func (validator MessageHandlerValidator) bulkSendToRules(request requests.MessageBulkSend) []string {
+	if request.ContactListID != "" && len(request.To) == 0 {
+		return []string{multipleContactPhoneNumberRule}
+	}
+	return []string{
+		"required",
+		"max:1000",
+		"min:1",
+		multipleContactPhoneNumberRule,
+	}
+}
+
+func (validator MessageHandlerValidator) validateContactList(ctx context.Context, ctxLogger telemetry.Logger, userID entities.UserID, contactListID string) string {
+	id, err := uuid.Parse(contactListID)
+	if err != nil {
+		return fmt.Sprintf("the contact_list_id [%s] is not a valid UUID", contactListID)
+	}
+
+	_, err = validator.contactListService.Load(ctx, userID, id)
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return fmt.Sprintf("no contact list found with ID [%s]", contactListID)
+	}
+
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("could not load contact list [%s] for user [%s]", contactListID, userID)))
+		return fmt.Sprintf("could not validate the contact list with ID [%s], please try again later", contactListID)
+	}
+
+	return ""
+}
+
 // ValidateMessageOutstanding validates the requests.MessageOutstanding request
 func (validator MessageHandlerValidator) ValidateMessageOutstanding(_ context.Context, request requests.MessageOutstanding) url.Values {
 	v := govalidator.New(govalidator.Options{
@@ -235,6 +324,9 @@ func (validator MessageHandlerValidator) ValidateMessageSearch(ctx context.Conte
 					entities.MessageStatusReceived,
 				}, ","),
 			},
+			"failure_codes": []string{
+				multipleInRule + ":" + strings.Join(entities.MessageFailureCodeStrings(entities.MessageFailureCodes), ","),
+			},
 			"sort_by": []string{
 				"in:" + strings.Join([]string{
 					"created_at",
@@ -322,3 +414,21 @@ func (validator MessageHandlerValidator) ValidateCallMissed(_ context.Context, r
 
 	return v.ValidateStruct()
 }
+
+func (validator MessageHandlerValidator) validateExpiry(result url.Values, expiresAt *time.Time, ttl uint, sendAt *time.Time) {
+	if expiresAt == nil {
+		return
+	}
+
+	if ttl != 0 {
+		result.Add("ttl", "you cannot set both the ttl and expires_at")
+	}
+
+	if !expiresAt.After(time.Now().UTC()) {
+		result.Add("expires_at", fmt.Sprintf("expires_at [%s] must be in the future", expiresAt.Format(time.RFC3339)))
+	}
+
+	if sendAt != nil && !expiresAt.After(*sendAt) {
+		result.Add("expires_at", fmt.Sprintf("expires_at [%s] must be after send_at [%s]", expiresAt.Format(time.RFC3339), sendAt.Format(time.RFC3339)))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/NdoleStudio/httpsms/pkg/entities"
 
@@ -96,9 +97,49 @@ func (validator *PhoneHandlerValidator) ValidateUpsert(_ context.Context, reques
 		result.Add("message_expiration_seconds", "message_expiration_seconds cannot be 0 when max_send_attempts is greater than 0")
 	}
 
+	validator.validateKeywords(result, "opt_out_keywords", request.OptOutKeywords)
+	validator.validateKeywords(result, "opt_in_keywords", request.OptInKeywords)
+
+	for _, keyword := range request.OptInKeywords {
+		for _, optOutKeyword := range request.OptOutKeywords {
+			if keyword == optOutKeyword {
+				result.Add("opt_in_keywords", fmt.Sprintf("the keyword [%s] cannot be both an opt-in and an opt-out keyword", keyword))
+			}
+		}
+	}
+
+	if request.OptOutReply != nil && len(*request.OptOutReply) > 1024 {
+		result.Add("opt_out_reply", "opt_out_reply must be less than 1024 characters")
+	}
+
+	if request.OptInReply != nil && len(*request.OptInReply) > 1024 {
+		result.Add("opt_in_reply", "opt_in_reply must be less than 1024 characters")
+	}
+
+	if request.OutOfOfficeReply != nil && len(*request.OutOfOfficeReply) > 1024 {
+		result.Add("out_of_office_reply", "out_of_office_reply must be less than 1024 characters")
+	}
+
+	validator.validateQuietHours(result, request.QuietHoursStart, request.QuietHoursEnd)
+	validator.validateRetryPolicy(result, request.RetryPolicy, true)
+	validator.validateBusinessHours(result, request.BusinessHours)
+	validator.validateHolidays(result, request.Holidays)
+
 	return result
 }
 
+func (validator *PhoneHandlerValidator) validateKeywords(result url.Values, key string, keywords []string) {
+	if len(keywords) > 20 {
+		result.Add(key, fmt.Sprintf("%s cannot contain more than 20 keywords", key))
+	}
+
+	for _, keyword := range keywords {
+		if len(keyword) > 50 {
+			result.Add(key, fmt.Sprintf("the keyword [%s] must be less than 50 characters", keyword))
+		}
+	}
+}
+
 // ValidateDelete ValidateUpsert validates requests.PhoneDelete
 func (validator *PhoneHandlerValidator) ValidateDelete(_ context.Context, request requests.PhoneDelete) url.Values {
 	v := govalidator.New(govalidator.Options{
@@ -113,3 +154,66 @@ func (validator *PhoneHandlerValidator) ValidateDelete(_ context.Context, reques
 
 	return v.ValidateStruct()
 }
+
+func (validator *PhoneHandlerValidator) validateBusinessHours(result url.Values, businessHours []entities.BusinessHours) {
+	if len(businessHours) > 50 {
+		result.Add("business_hours", "business_hours cannot contain more than 50 time windows")
+	}
+
+	for index, hours := range businessHours {
+		if _, ok := hours.Weekday(); !ok {
+			result.Add("business_hours", fmt.Sprintf("the day [%s] in index [%d] must be a day of the week e.g. monday", hours.Day, index))
+		}
+
+		start, err := time.Parse("15:04", hours.StartTime)
+		if err != nil {
+			result.Add("business_hours", fmt.Sprintf("the start_time [%s] in index [%d] must be in the 24 hour format HH:MM e.g. 09:00", hours.StartTime, index))
+			continue
+		}
+
+		end, err := time.Parse("15:04", hours.EndTime)
+		if err != nil {
+			result.Add("business_hours", fmt.Sprintf("the end_time [%s] in index [%d] must be in the 24 hour format HH:MM e.g. 17:00", hours.EndTime, index))
+			continue
+		}
+
+		if !end.After(start) {
+			result.Add("business_hours", fmt.Sprintf("the end_time [%s] in index [%d] must be after the start_time [%s]", hours.EndTime, index, hours.StartTime))
+		}
+	}
+}
+
+func (validator *PhoneHandlerValidator) validateHolidays(result url.Values, holidays []string) {
+	if len(holidays) > 366 {
+		result.Add("holidays", "holidays cannot contain more than 366 dates")
+	}
+
+	for index, holiday := range holidays {
+		if _, err := time.Parse(time.DateOnly, holiday); err != nil {
+			result.Add("holidays", fmt.Sprintf("the holiday [%s] in index [%d] must be a date in the format YYYY-MM-DD", holiday, index))
+		}
+	}
+}
+
+func (validator *PhoneHandlerValidator) validateQuietHours(result url.Values, start *string, end *string) {
+	if (start == nil) != (end == nil) || (start != nil && (*start == "") != (*end == "")) {
+		result.Add("quiet_hours_start", "quiet_hours_start and quiet_hours_end must be set together")
+		return
+	}
+
+	if start == nil || *start == "" {
+		return
+	}
+
+	if _, err := time.Parse("15:04", *start); err != nil {
+		result.Add("quiet_hours_start", fmt.Sprintf("quiet_hours_start [%s] must be in the 24 hour format HH:MM e.g. 21:00", *start))
+	}
+
+	if _, err := time.Parse("15:04", *end); err != nil {
+		result.Add("quiet_hours_end", fmt.Sprintf("quiet_hours_end [%s] must be in the 24 hour format HH:MM e.g. 08:00", *end))
+	}
+
+	if *start == *end {
+		result.Add("quiet_hours_end", "quiet_hours_end must be different from quiet_hours_start")
+	}
+}
@@ -0,0 +1,139 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/slack"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/palantir/stacktrace"
+	"github.com/thedevsaddam/govalidator"
+)
+
+// SlackHandlerValidator validates models used in handlers.SlackHandler
+type SlackHandlerValidator struct {
+	validator
+	client *slack.Client
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+}
+
+// NewSlackHandlerValidator creates a new handlers.SlackHandler validator
+func NewSlackHandlerValidator(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+	client *slack.Client,
+) (v *SlackHandlerValidator) {
+	return &SlackHandlerValidator{
+		logger: logger.WithService(fmt.Sprintf("%T", v)),
+		tracer: tracer,
+		client: client,
+	}
+}
+
+// ValidateIndex validates the requests.SlackIndex request
+func (validator *SlackHandlerValidator) ValidateIndex(_ context.Context, request requests.SlackIndex) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"limit": []string{
+				"required",
+				"numeric",
+				"min:1",
+				"max:100",
+			},
+			"skip": []string{
+				"required",
+				"numeric",
+				"min:0",
+			},
+			"query": []string{
+				"max:100",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
+// ValidateStore validates the requests.SlackStore request
+func (validator *SlackHandlerValidator) ValidateStore(ctx context.Context, request requests.SlackStore) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data:  &request,
+		Rules: validator.rules(),
+	})
+
+	result := v.ValidateStruct()
+	if len(result) > 0 {
+		return result
+	}
+
+	validator.validateWorkspace(ctx, result, request)
+	return result
+}
+
+// ValidateUpdate validates the requests.SlackUpdate request
+func (validator *SlackHandlerValidator) ValidateUpdate(ctx context.Context, request requests.SlackUpdate) url.Values {
+	rules := validator.rules()
+	rules["slackID"] = []string{
+		"required",
+		"uuid",
+	}
+
+	v := govalidator.New(govalidator.Options{
+		Data:  &request,
+		Rules: rules,
+	})
+
+	result := v.ValidateStruct()
+	if len(result) > 0 {
+		return result
+	}
+
+	validator.validateWorkspace(ctx, result, request.SlackStore)
+	return result
+}
+
+func (validator *SlackHandlerValidator) rules() govalidator.MapData {
+	return govalidator.MapData{
+		"name": []string{
+			"required",
+			"min:1",
+			"max:255",
+		},
+		"bot_token": []string{
+			"required",
+			"max:255",
+		},
+		"incoming_channel_id": []string{
+			"required",
+			"alpha_num",
+			"max:255",
+		},
+	}
+}
+
+// validateWorkspace checks that the bot token is valid and that the bot can access the incoming channel
+func (validator *SlackHandlerValidator) validateWorkspace(ctx context.Context, result url.Values, request requests.SlackStore) {
+	ctx, span, ctxLogger := validator.tracer.StartWithLogger(ctx, validator.logger)
+	defer span.End()
+
+	if !strings.HasPrefix(request.BotToken, "xoxb-") {
+		result.Add("bot_token", "The bot_token field must be a slack bot token which starts with [xoxb-]")
+		return
+	}
+
+	if _, _, err := validator.client.Auth.Test(ctx, request.BotToken); err != nil {
+		ctxLogger.Warn(validator.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, "cannot verify slack bot token")))
+		result.Add("bot_token", "The bot_token field is not a valid slack bot token")
+		return
+	}
+
+	if _, _, err := validator.client.Conversations.Info(ctx, request.BotToken, request.IncomingChannelID); err != nil {
+		msg := fmt.Sprintf("cannot fetch slack channel with ID [%s]", request.IncomingChannelID)
+		ctxLogger.Warn(validator.tracer.WrapErrorSpan(span, stacktrace.Propagate(err, msg)))
+		result.Add("incoming_channel_id", fmt.Sprintf("cannot fetch slack channel with ID [%s] make sure the bot has been added to the channel", request.IncomingChannelID))
+	}
+}
@@ -0,0 +1,67 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/NdoleStudio/httpsms/pkg/requests"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/thedevsaddam/govalidator"
+)
+
+// SuppressionHandlerValidator validates models used in handlers.SuppressionHandler
+type SuppressionHandlerValidator struct {
+	validator
+	logger telemetry.Logger
+	tracer telemetry.Tracer
+}
+
+// NewSuppressionHandlerValidator creates a new handlers.SuppressionHandler validator
+func NewSuppressionHandlerValidator(
+	logger telemetry.Logger,
+	tracer telemetry.Tracer,
+) (v *SuppressionHandlerValidator) {
+	return &SuppressionHandlerValidator{
+		logger: logger.WithService(fmt.Sprintf("%T", v)),
+		tracer: tracer,
+	}
+}
+
+// ValidateIndex validates the requests.SuppressionIndex request
+func (validator *SuppressionHandlerValidator) ValidateIndex(_ context.Context, request requests.SuppressionIndex) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"limit": []string{
+				"required",
+				"numeric",
+				"min:1",
+				"max:100",
+			},
+			"skip": []string{
+				"required",
+				"numeric",
+				"min:0",
+			},
+			"query": []string{
+				"max:100",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
+// ValidateStore validates the requests.SuppressionStore request
+func (validator *SuppressionHandlerValidator) ValidateStore(_ context.Context, request requests.SuppressionStore) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"contact": []string{
+				"required",
+				contactPhoneNumberRule,
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
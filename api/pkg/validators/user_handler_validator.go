@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strings"
 
+	"github.com/NdoleStudio/httpsms/pkg/entities"
 	"github.com/NdoleStudio/httpsms/pkg/requests"
 	"github.com/NdoleStudio/httpsms/pkg/telemetry"
 	"github.com/thedevsaddam/govalidator"
@@ -41,3 +43,53 @@ func (validator *UserHandlerValidator) ValidateUpdate(_ context.Context, request
 
 	return v.ValidateStruct()
 }
+
+// ValidateMessageLimitsUpdate validates requests.UserMessageLimitsUpdate
+func (validator *UserHandlerValidator) ValidateMessageLimitsUpdate(_ context.Context, request requests.UserMessageLimitsUpdate) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"duplicate_message_window_seconds": []string{
+				"min:0",
+				"max:86400",
+			},
+			"duplicate_message_action": []string{
+				"required",
+				"in:" + strings.Join([]string{
+					string(entities.DuplicateMessageActionBlock),
+					string(entities.DuplicateMessageActionCollapse),
+				}, ","),
+			},
+			"contact_rate_limit": []string{
+				"min:0",
+				"max:10000",
+			},
+			"contact_rate_limit_window_seconds": []string{
+				"min:60",
+				"max:86400",
+			},
+		},
+	})
+
+	return v.ValidateStruct()
+}
+
+// ValidateCallbackSigningKeyRotate validates requests.UserCallbackSigningKeyRotate
+func (validator *UserHandlerValidator) ValidateCallbackSigningKeyRotate(_ context.Context, request requests.UserCallbackSigningKeyRotate) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"signing_key": []string{
+				"required",
+				"min:1",
+				"max:255",
+			},
+			"overlap_seconds": []string{
+				"min:0",
+				"max:604800",
+			},
+		},
+	})
+
+	return v.ValidateStruct()
+}
@@ -7,7 +7,13 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/NdoleStudio/httpsms/pkg/entities"
 	"github.com/NdoleStudio/httpsms/pkg/events"
+	"github.com/NdoleStudio/httpsms/pkg/repositories"
+	"github.com/NdoleStudio/httpsms/pkg/services"
+	"github.com/NdoleStudio/httpsms/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/palantir/stacktrace"
 
 	"github.com/nyaruka/phonenumbers"
 	"github.com/thedevsaddam/govalidator"
@@ -24,6 +30,19 @@ const (
 	webhookEventsRule              = "webhookEvents"
 )
 
+// webhookEventTypes are the events which can be sent to a webhook
+var webhookEventTypes = []string{
+	events.EventTypeMessagePhoneReceived,
+	events.EventTypeMessagePhoneSent,
+	events.EventTypeMessagePhoneDelivered,
+	events.EventTypeMessageSendFailed,
+	events.EventTypeMessageSendExpired,
+	events.EventTypePhoneHeartbeatOnline,
+	events.EventTypePhoneHeartbeatOffline,
+	events.MessageCallMissed,
+	events.EventTypeCampaignCompleted,
+}
+
 func init() {
 	// custom rules to take fixed length word.
 	// e.g: max_word:5 will throw error if the field contains more than 5 words
@@ -122,15 +141,9 @@ func init() {
 			return fmt.Errorf("The %s field is an empty array", field)
 		}
 
-		validEvents := map[string]bool{
-			events.EventTypeMessagePhoneReceived:  true,
-			events.EventTypeMessagePhoneSent:      true,
-			events.EventTypeMessagePhoneDelivered: true,
-			events.EventTypeMessageSendFailed:     true,
-			events.EventTypeMessageSendExpired:    true,
-			events.EventTypePhoneHeartbeatOnline:  true,
-			events.EventTypePhoneHeartbeatOffline: true,
-			events.MessageCallMissed:              true,
+		validEvents := map[string]bool{}
+		for _, event := range webhookEventTypes {
+			validEvents[event] = true
 		}
 
 		for _, event := range input {
@@ -161,3 +174,84 @@ func (validator *validator) ValidateUUID(_ context.Context, ID string, name stri
 
 	return v.ValidateStruct()
 }
+
+// isCallbackURL checks that the value is an absolute http or https URL which can receive message events
+func (validator *validator) isCallbackURL(value string) bool {
+	if len(value) > 255 {
+		return false
+	}
+
+	callbackURL, err := url.ParseRequestURI(value)
+	if err != nil {
+		return false
+	}
+
+	return (callbackURL.Scheme == "http" || callbackURL.Scheme == "https") && callbackURL.Host != ""
+}
+
+// validateCampaign checks that messages can be added to the entities.Campaign with the given ID
+func (validator *validator) validateCampaign(ctx context.Context, ctxLogger telemetry.Logger, service *services.CampaignService, userID entities.UserID, campaignID string) string {
+	id, err := uuid.Parse(campaignID)
+	if err != nil {
+		return fmt.Sprintf("the campaign_id [%s] is not a valid UUID", campaignID)
+	}
+
+	campaign, err := service.Load(ctx, userID, id)
+	if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
+		return fmt.Sprintf("no campaign found with ID [%s]", campaignID)
+	}
+
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("could not load campaign [%s] for user [%s]", campaignID, userID)))
+		return fmt.Sprintf("could not validate the campaign with ID [%s], please try again later", campaignID)
+	}
+
+	if !campaign.CanAddMessages() {
+		return fmt.Sprintf("messages cannot be added to the campaign [%s] because it has status [%s]", campaign.Name, campaign.Status)
+	}
+
+	return ""
+}
+
+func (validator *validator) validateSuppressed(ctx context.Context, ctxLogger telemetry.Logger, service *services.SuppressionService, userID entities.UserID, contacts []string) []string {
+	suppressed, err := service.FilterSuppressed(ctx, userID, contacts)
+	if err != nil {
+		ctxLogger.Error(stacktrace.Propagate(err, fmt.Sprintf("could not filter [%d] suppressed contacts for user [%s]", len(contacts), userID)))
+		return []string{"could not check if the contacts opted out of receiving messages, please try again later"}
+	}
+
+	result := make([]string, 0, len(suppressed))
+	for _, contact := range suppressed {
+		result = append(result, fmt.Sprintf("the contact [%s] opted out of receiving messages and is on your suppression list", contact))
+	}
+	return result
+}
+
+// validateRetryPolicy checks the entities.RetryPolicy of a phone or a message. A max_attempts of 0 is allowed only when it can be used to remove the policy.
+func (validator *validator) validateRetryPolicy(result url.Values, policy *entities.RetryPolicy, allowEmpty bool) {
+	if policy == nil || (allowEmpty && policy.MaxAttempts == 0) {
+		return
+	}
+
+	if policy.MaxAttempts < 1 || policy.MaxAttempts > 10 {
+		result.Add("retry_policy", fmt.Sprintf("retry_policy.max_attempts [%d] must be between 1 and 10", policy.MaxAttempts))
+	}
+
+	for _, code := range policy.RetryOn {
+		if !entities.MessageFailureCode(code).IsValid() {
+			result.Add("retry_policy", fmt.Sprintf("retry_policy.retry_on contains the invalid failure code [%s], it must be one of [%s]", code, strings.Join(entities.MessageFailureCodeStrings(entities.MessageFailureCodes), ", ")))
+		}
+	}
+
+	if policy.InitialDelaySeconds > 86400 {
+		result.Add("retry_policy", fmt.Sprintf("retry_policy.initial_delay_seconds [%d] must be less than 86400", policy.InitialDelaySeconds))
+	}
+
+	if policy.MaxDelaySeconds > 86400 {
+		result.Add("retry_policy", fmt.Sprintf("retry_policy.max_delay_seconds [%d] must be less than 86400", policy.MaxDelaySeconds))
+	}
+
+	if policy.BackoffMultiplier < 1 || policy.BackoffMultiplier > 10 {
+		result.Add("retry_policy", fmt.Sprintf("retry_policy.backoff_multiplier [%g] must be between 1 and 10", policy.BackoffMultiplier))
+	}
+}
@@ -3,7 +3,10 @@ package validators
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 
 	"github.com/NdoleStudio/httpsms/pkg/entities"
 	"github.com/NdoleStudio/httpsms/pkg/repositories"
@@ -16,6 +19,31 @@ import (
 	"github.com/thedevsaddam/govalidator"
 )
 
+const (
+	webhookMaxHeaders           = 20
+	webhookMaxHeaderValueLength = 1024
+	webhookMaxFilterValues      = 50
+)
+
+// webhookHeaderNameRegex matches valid HTTP header names
+var webhookHeaderNameRegex = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// webhookReservedHeaders are set by httpSMS on every webhook request
+var webhookReservedHeaders = map[string]struct{}{
+	"Host":              {},
+	"Content-Length":    {},
+	"Transfer-Encoding": {},
+	"Connection":        {},
+	"Authorization":     {},
+	"X-Event-Type":      {},
+}
+
+// isWebhookReservedHeader checks if a canonical header name is set by httpSMS
+func isWebhookReservedHeader(name string) bool {
+	_, ok := webhookReservedHeaders[name]
+	return ok || strings.HasPrefix(name, "X-Httpsms-")
+}
+
 // WebhookHandlerValidator validates models used in handlers.WebhookHandler
 type WebhookHandlerValidator struct {
 	validator
@@ -61,6 +89,75 @@ func (validator *WebhookHandlerValidator) ValidateIndex(_ context.Context, reque
 	return v.ValidateStruct()
 }
 
+// ValidateDeliveryIndex validates the requests.WebhookDeliveryIndex request
+func (validator *WebhookHandlerValidator) ValidateDeliveryIndex(_ context.Context, request requests.WebhookDeliveryIndex) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"webhookID": []string{
+				"required",
+				"uuid",
+			},
+			"limit": []string{
+				"required",
+				"numeric",
+				"min:1",
+				"max:100",
+			},
+			"skip": []string{
+				"required",
+				"numeric",
+				"min:0",
+			},
+			"query": []string{
+				"max:100",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
+// ValidateRotateSigningKey validates the requests.WebhookSigningKeyRotate request
+func (validator *WebhookHandlerValidator) ValidateRotateSigningKey(_ context.Context, request requests.WebhookSigningKeyRotate) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"webhookID": []string{
+				"required",
+				"uuid",
+			},
+			"signing_key": []string{
+				"required",
+				"min:1",
+				"max:255",
+			},
+			"overlap_seconds": []string{
+				"min:0",
+				"max:604800",
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
+// ValidateTest validates the requests.WebhookTest request
+func (validator *WebhookHandlerValidator) ValidateTest(_ context.Context, request requests.WebhookTest) url.Values {
+	v := govalidator.New(govalidator.Options{
+		Data: &request,
+		Rules: govalidator.MapData{
+			"webhookID": []string{
+				"required",
+				"uuid",
+			},
+			"event_type": []string{
+				"required",
+				"in:" + strings.Join(webhookEventTypes, ","),
+			},
+		},
+	})
+	return v.ValidateStruct()
+}
+
 // ValidateStore validates the requests.WebhookStore request
 func (validator *WebhookHandlerValidator) ValidateStore(ctx context.Context, userID entities.UserID, request requests.WebhookStore) url.Values {
 	ctx, span := validator.tracer.Start(ctx)
@@ -73,6 +170,22 @@ func (validator *WebhookHandlerValidator) ValidateStore(ctx context.Context, use
 				"min:1",
 				"max:255",
 			},
+			"signature_scheme": []string{
+				"in:" + entities.WebhookSignatureSchemeJWT.String() + "," + entities.WebhookSignatureSchemeHMAC.String(),
+			},
+			"failure_threshold": []string{
+				"min:0",
+				"max:1000",
+			},
+			"payload_format": []string{
+				"in:" + strings.Join(entities.WebhookPayloadFormatStrings(), ","),
+			},
+			"payload_template": []string{
+				"max:10000",
+			},
+			"http_method": []string{
+				"in:" + strings.Join(entities.WebhookHTTPMethods, ","),
+			},
 			"url": []string{
 				"required",
 				"url",
@@ -94,6 +207,13 @@ func (validator *WebhookHandlerValidator) ValidateStore(ctx context.Context, use
 		return result
 	}
 
+	if request.SignatureScheme == entities.WebhookSignatureSchemeHMAC.String() && request.SigningKey == "" {
+		result.Add("signing_key", fmt.Sprintf("The signing key is required when the signature scheme is [%s]", entities.WebhookSignatureSchemeHMAC))
+	}
+
+	validator.validatePayload(result, request)
+	validator.validateFilter(result, request.Filter)
+
 	for _, address := range request.PhoneNumbers {
 		_, err := validator.phoneService.Load(ctx, userID, address)
 		if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
@@ -115,6 +235,22 @@ func (validator *WebhookHandlerValidator) ValidateUpdate(ctx context.Context, us
 				"min:1",
 				"max:255",
 			},
+			"signature_scheme": []string{
+				"in:" + entities.WebhookSignatureSchemeJWT.String() + "," + entities.WebhookSignatureSchemeHMAC.String(),
+			},
+			"failure_threshold": []string{
+				"min:0",
+				"max:1000",
+			},
+			"payload_format": []string{
+				"in:" + strings.Join(entities.WebhookPayloadFormatStrings(), ","),
+			},
+			"payload_template": []string{
+				"max:10000",
+			},
+			"http_method": []string{
+				"in:" + strings.Join(entities.WebhookHTTPMethods, ","),
+			},
 			"webhookID": []string{
 				"required",
 				"uuid",
@@ -140,6 +276,13 @@ func (validator *WebhookHandlerValidator) ValidateUpdate(ctx context.Context, us
 		return result
 	}
 
+	if request.SignatureScheme == entities.WebhookSignatureSchemeHMAC.String() && request.SigningKey == "" {
+		result.Add("signing_key", fmt.Sprintf("The signing key is required when the signature scheme is [%s]", entities.WebhookSignatureSchemeHMAC))
+	}
+
+	validator.validatePayload(result, request.WebhookStore)
+	validator.validateFilter(result, request.Filter)
+
 	for _, address := range request.PhoneNumbers {
 		_, err := validator.phoneService.Load(ctx, userID, address)
 		if stacktrace.GetCode(err) == repositories.ErrCodeNotFound {
@@ -148,3 +291,68 @@ func (validator *WebhookHandlerValidator) ValidateUpdate(ctx context.Context, us
 	}
 	return result
 }
+
+// validatePayload validates the payload template and the custom headers of a webhook
+func (validator *WebhookHandlerValidator) validatePayload(result url.Values, request requests.WebhookStore) {
+	if request.PayloadFormat == entities.WebhookPayloadFormatTemplate.String() && request.PayloadTemplate == "" {
+		result.Add("payload_template", fmt.Sprintf("The payload template is required when the payload format is [%s]", entities.WebhookPayloadFormatTemplate))
+	}
+
+	if request.PayloadTemplate != "" {
+		if _, err := services.ParseWebhookTemplate(request.PayloadTemplate); err != nil {
+			result.Add("payload_template", fmt.Sprintf("The payload template is not a valid Go template: %s", err.Error()))
+		}
+	}
+
+	if len(request.Headers) > webhookMaxHeaders {
+		result.Add("headers", fmt.Sprintf("The headers field must not contain more than %d headers", webhookMaxHeaders))
+	}
+
+	for key, value := range request.Headers {
+		if !webhookHeaderNameRegex.MatchString(key) {
+			result.Add("headers", fmt.Sprintf("The header name [%s] is not a valid HTTP header name", key))
+			continue
+		}
+		if isWebhookReservedHeader(http.CanonicalHeaderKey(key)) {
+			result.Add("headers", fmt.Sprintf("The header [%s] is set by httpSMS and cannot be overridden", key))
+		}
+		if strings.ContainsAny(value, "\r\n") || len(value) > webhookMaxHeaderValueLength {
+			result.Add("headers", fmt.Sprintf("The value of the header [%s] must be a single line with at most %d characters", key, webhookMaxHeaderValueLength))
+		}
+	}
+}
+
+// validateFilter validates the entities.WebhookFilter of a webhook
+func (validator *WebhookHandlerValidator) validateFilter(result url.Values, filter *entities.WebhookFilter) {
+	if filter == nil {
+		return
+	}
+
+	if len(filter.ContactPrefixes) > webhookMaxFilterValues {
+		result.Add("filter", fmt.Sprintf("The filter must not contain more than %d contact prefixes", webhookMaxFilterValues))
+	}
+
+	if len(filter.ContentKeywords) > webhookMaxFilterValues {
+		result.Add("filter", fmt.Sprintf("The filter must not contain more than %d content keywords", webhookMaxFilterValues))
+	}
+
+	if filter.ContactPattern != nil {
+		if len(*filter.ContactPattern) > 255 {
+			result.Add("filter", "The contact pattern of the filter must not be longer than 255 characters")
+		} else if _, err := regexp.Compile(*filter.ContactPattern); err != nil {
+			result.Add("filter", fmt.Sprintf("The contact pattern [%s] of the filter is not a valid regular expression", *filter.ContactPattern))
+		}
+	}
+
+	if filter.SIM != nil && *filter.SIM != entities.SIM1 && *filter.SIM != entities.SIM2 {
+		result.Add("filter", fmt.Sprintf("The SIM of the filter must be [%s] or [%s]", entities.SIM1, entities.SIM2))
+	}
+
+	if filter.MessageType != nil {
+		switch *filter.MessageType {
+		case entities.MessageTypeMobileOriginated, entities.MessageTypeMobileTerminated, entities.MessageTypeCallMissed:
+		default:
+			result.Add("filter", fmt.Sprintf("The message type of the filter must be [%s], [%s] or [%s]", entities.MessageTypeMobileOriginated, entities.MessageTypeMobileTerminated, entities.MessageTypeCallMissed))
+		}
+	}
+}